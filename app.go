@@ -3,6 +3,8 @@ package kratos
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"sync"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/report"
 	"github.com/go-kratos/kratos/v2/transport"
 
 	"github.com/google/uuid"
@@ -33,6 +36,8 @@ type App struct {
 	cancel   func()
 	lk       sync.Mutex
 	instance *registry.ServiceInstance
+	// stopOnce runs the before stop hooks once, Stop may be called again.
+	stopOnce sync.Once
 }
 
 // New create an application lifecycle manager.
@@ -78,14 +83,42 @@ func (a *App) Endpoint() []string {
 	return a.instance.Endpoints
 }
 
+// Clients returns the open clients of the client tracker.
+func (a *App) Clients() []transport.ClientInfo {
+	if a.opts.tracker == nil {
+		return nil
+	}
+	return a.opts.tracker.Clients()
+}
+
+// Client returns the named client registered to the client tracker, dialed
+// once and shared by the modules of the app, e.g. a *grpc.ClientConn.
+func (a *App) Client(name string) (io.Closer, error) {
+	if a.opts.tracker == nil {
+		return nil, fmt.Errorf("%w: %s", transport.ErrClientNotRegistered, name)
+	}
+	return a.opts.tracker.Client(a.ctx, name)
+}
+
 // Run executes all OnStart hooks registered with the application's Lifecycle.
 func (a *App) Run() error {
 	instance, err := a.buildInstance()
 	if err != nil {
+		a.report(err)
+		return err
+	}
+	if err = a.check(); err != nil {
+		a.report(err)
 		return err
 	}
-	ctx := NewContext(a.ctx, a)
-	eg, ctx := errgroup.WithContext(ctx)
+	sctx := NewContext(a.ctx, a)
+	for _, fn := range a.opts.beforeStart {
+		if err = fn(sctx); err != nil {
+			a.report(err)
+			return err
+		}
+	}
+	eg, ctx := errgroup.WithContext(sctx)
 	wg := sync.WaitGroup{}
 	for _, srv := range a.opts.servers {
 		srv := srv
@@ -106,12 +139,23 @@ func (a *App) Run() error {
 		rctx, rcancel := context.WithTimeout(a.opts.ctx, a.opts.registrarTimeout)
 		defer rcancel()
 		if err := a.opts.registrar.Register(rctx, instance); err != nil {
+			a.report(err)
 			return err
 		}
 		a.lk.Lock()
 		a.instance = instance
 		a.lk.Unlock()
 	}
+	for _, fn := range a.opts.afterStart {
+		if herr := fn(sctx); herr != nil {
+			a.opts.logger.Errorf("failed to run after start hook: %v", herr)
+			eg.Go(func() error {
+				_ = a.Stop()
+				return herr
+			})
+			break
+		}
+	}
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, a.opts.sigs...)
 	eg.Go(func() error {
@@ -128,7 +172,20 @@ func (a *App) Run() error {
 			}
 		}
 	})
-	if err := eg.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+	err = eg.Wait()
+	// the clients are closed after the servers drain.
+	if a.opts.tracker != nil {
+		if cerr := a.opts.tracker.Close(); cerr != nil {
+			a.opts.logger.Errorf("failed to close clients: %v", cerr)
+		}
+	}
+	for _, fn := range a.opts.afterStop {
+		if herr := fn(NewContext(a.opts.ctx, a)); herr != nil && (err == nil || errors.Is(err, context.Canceled)) {
+			err = herr
+		}
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		a.report(err)
 		return err
 	}
 	return nil
@@ -136,6 +193,14 @@ func (a *App) Run() error {
 
 // Stop gracefully stops the application.
 func (a *App) Stop() error {
+	a.stopOnce.Do(func() {
+		ctx := NewContext(a.opts.ctx, a)
+		for _, fn := range a.opts.beforeStop {
+			if err := fn(ctx); err != nil {
+				a.opts.logger.Errorf("failed to run before stop hook: %v", err)
+			}
+		}
+	})
 	a.lk.Lock()
 	instance := a.instance
 	a.lk.Unlock()
@@ -152,6 +217,31 @@ func (a *App) Stop() error {
 	return nil
 }
 
+// check checks the hazards of the servers and the tracked clients.
+func (a *App) check() error {
+	if a.opts.guard == nil {
+		return nil
+	}
+	components := make([]interface{}, 0, len(a.opts.servers)+1)
+	for _, srv := range a.opts.servers {
+		components = append(components, srv)
+	}
+	if a.opts.tracker != nil {
+		components = append(components, a.opts.tracker)
+	}
+	return a.opts.guard.Check(components...)
+}
+
+func (a *App) report(err error) {
+	if a.opts.reporter == nil {
+		return
+	}
+	a.opts.reporter.Report(NewContext(a.opts.ctx, a), &report.Event{
+		Err:  err,
+		Tags: map[string]string{"service.id": a.opts.id, "service.name": a.opts.name},
+	})
+}
+
 func (a *App) buildInstance() (*registry.ServiceInstance, error) {
 	endpoints := make([]string, 0, len(a.opts.endpoints))
 	for _, e := range a.opts.endpoints {
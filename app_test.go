@@ -2,15 +2,24 @@ package kratos
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/guard"
 	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
 	"github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/go-kratos/kratos/v2/transport/http/pprof"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 )
 
 type mockRegistry struct {
@@ -192,3 +201,178 @@ func TestApp_Endpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_Clients(t *testing.T) {
+	tracker := transport.NewTracker()
+	gs := grpc.NewServer()
+	app := New(Server(gs), Clients(tracker))
+	e, err := gs.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := grpc.DialInsecure(context.Background(), grpc.WithEndpoint(e.Host), grpc.WithTracker(tracker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc, err := http.NewClient(context.Background(), http.WithEndpoint("127.0.0.1:8000"), http.WithTracker(tracker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clients := app.Clients(); len(clients) != 2 || clients[0].Kind != transport.KindGRPC || clients[1].Target != "127.0.0.1:8000" {
+		t.Fatalf("unexpected clients %v", clients)
+	}
+	_ = hc.Close()
+	if clients := app.Clients(); len(clients) != 1 {
+		t.Fatalf("expect the closed client untracked, got %v", clients)
+	}
+	time.AfterFunc(100*time.Millisecond, func() {
+		_ = app.Stop()
+	})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if state := conn.GetState(); state != connectivity.Shutdown {
+		t.Errorf("expect the connection closed, got %v", state)
+	}
+	if clients := app.Clients(); len(clients) != 0 {
+		t.Errorf("expect no open clients, got %v", clients)
+	}
+}
+
+func TestApp_Client(t *testing.T) {
+	tracker := transport.NewTracker()
+	tracker.Configure(map[string]transport.ClientConfig{"user": {Endpoint: "127.0.0.1:9000", Timeout: time.Second}})
+	app := New(Clients(tracker))
+	dials := 0
+	tracker.Register("user", func(ctx context.Context, c transport.ClientConfig) (io.Closer, error) {
+		dials++
+		if dials == 1 {
+			return nil, errors.New("dial failed")
+		}
+		return grpc.DialInsecure(ctx, grpc.WithEndpoint(c.Endpoint), grpc.WithTimeout(c.Timeout), grpc.WithTracker(tracker))
+	})
+	if _, err := app.Client("user"); err == nil {
+		t.Fatal("expect the dial error")
+	}
+	c1, err := app.Client("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := app.Client("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1 != c2 || dials != 2 || len(app.Clients()) != 1 {
+		t.Fatalf("expect one shared client, got %d dials", dials)
+	}
+	if target := c1.(*grpcgo.ClientConn).Target(); target != "127.0.0.1:9000" {
+		t.Fatalf("expect the client of the config, got %s", target)
+	}
+	// the client closed by a module is dialed again
+	_ = c1.Close()
+	c3, err := app.Client("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c3 == c1 || dials != 3 {
+		t.Fatalf("expect the closed client dialed again, got %d dials", dials)
+	}
+	if _, err := app.Client("order"); !errors.Is(err, transport.ErrClientNotRegistered) {
+		t.Fatalf("expect the client not registered, got %v", err)
+	}
+	if _, err := New().Client("user"); !errors.Is(err, transport.ErrClientNotRegistered) {
+		t.Fatalf("expect the client not registered without a tracker, got %v", err)
+	}
+	_ = tracker.Close()
+	if state := c3.(*grpcgo.ClientConn).GetState(); state != connectivity.Shutdown {
+		t.Fatalf("expect the named client closed, got %v", state)
+	}
+	if c4, err := app.Client("user"); err != nil || c4 == c3 {
+		t.Fatalf("expect the named client dialed again after Close, got %v", err)
+	}
+	_ = tracker.Close()
+
+	// the clients not tracked by their dialers are owned by the tracker
+	closer := &testCloser{}
+	tracker.Register("untracked", func(context.Context, transport.ClientConfig) (io.Closer, error) {
+		return closer, nil
+	})
+	if _, err := app.Client("untracked"); err != nil {
+		t.Fatal(err)
+	}
+	if clients := app.Clients(); len(clients) != 1 || clients[0].Target != "untracked" {
+		t.Fatalf("expect the named client tracked, got %v", clients)
+	}
+	_ = tracker.Close()
+	if !closer.closed {
+		t.Error("expect the named client closed")
+	}
+}
+
+type testCloser struct {
+	closed bool
+}
+
+func (c *testCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestApp_Guard(t *testing.T) {
+	hs := http.NewServer()
+	hs.HandlePrefix("/debug/pprof/", pprof.NewHandler())
+	gs := grpc.NewServer(grpc.Reflection(false))
+	if hazards := gs.Hazards(); len(hazards) != 0 {
+		t.Fatalf("unexpected hazards %v", hazards)
+	}
+	app := New(Server(hs, gs), Guard(guard.New(guard.Production(true))))
+	if err := app.Run(); err == nil || !strings.Contains(err.Error(), "debug_endpoint /debug/pprof/") {
+		t.Fatalf("expect the debug endpoint refused, got %v", err)
+	}
+}
+
+func TestApp_Hooks(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	hook := func(name string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			if _, ok := FromContext(ctx); !ok {
+				t.Errorf("%s: expect the app in the context", name)
+			}
+			mu.Lock()
+			calls = append(calls, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+	app := New(
+		Server(http.NewServer()),
+		BeforeStart(hook("before start")),
+		AfterStart(hook("after start")),
+		BeforeStop(hook("before stop")),
+		AfterStop(hook("after stop")),
+	)
+	stop := app.Stop
+	time.AfterFunc(100*time.Millisecond, func() {
+		_ = stop()
+		_ = stop()
+	})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"before start", "after start", "before stop", "after stop"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got %v, want %v", calls, want)
+	}
+
+	failed := fmt.Errorf("migration failed")
+	app = New(Server(http.NewServer()), BeforeStart(func(context.Context) error { return failed }))
+	if err := app.Run(); err != failed {
+		t.Errorf("expect the before start error, got %v", err)
+	}
+	app = New(Server(http.NewServer()), AfterStart(func(context.Context) error { return failed }))
+	if err := app.Run(); err != failed {
+		t.Errorf("expect the after start error, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+// Package baggage propagates user-defined key values across services,
+// encoded in the W3C Baggage header, see https://www.w3.org/TR/baggage/.
+// The baggage of a context is the OpenTelemetry one, so that it's shared with
+// the OpenTelemetry propagators, e.g. of the tracing middleware.
+package baggage
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	otelbaggage "go.opentelemetry.io/otel/baggage"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// HeaderKey is the W3C Baggage header key.
+const HeaderKey = "baggage"
+
+const (
+	// MaxMembers is the maximum number of members defined by W3C Baggage.
+	MaxMembers = 180
+	// MaxBytes is the maximum size of the header defined by W3C Baggage.
+	MaxBytes = 8192
+)
+
+// Baggage is a set of key values propagated with the requests.
+type Baggage map[string]string
+
+// Policy limits the baggage accepted from and propagated to other services,
+// the members over the limits are dropped.
+type Policy struct {
+	// AllowedKeys are the keys accepted, all keys are accepted if empty.
+	AllowedKeys []string
+	// MaxMembers is the maximum number of members, default is MaxMembers.
+	MaxMembers int
+	// MaxBytes is the maximum size of the encoded baggage, default is MaxBytes.
+	MaxBytes int
+}
+
+func (p Policy) allowed(key string) bool {
+	if len(p.AllowedKeys) == 0 {
+		return true
+	}
+	for _, k := range p.AllowedKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Policy) limits() (members, bytes int) {
+	members, bytes = p.MaxMembers, p.MaxBytes
+	if members <= 0 || members > MaxMembers {
+		members = MaxMembers
+	}
+	if bytes <= 0 || bytes > MaxBytes {
+		bytes = MaxBytes
+	}
+	return
+}
+
+// Parse parses a baggage header, the member properties are ignored
+// and the invalid members or the ones not allowed by p are dropped.
+func Parse(header string, p Policy) Baggage {
+	b := Baggage{}
+	for _, member := range strings.Split(header, ",") {
+		if i := strings.IndexByte(member, ';'); i >= 0 {
+			member = member[:i]
+		}
+		kv := strings.SplitN(member, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(kv[0])
+		value, err := url.PathUnescape(strings.TrimSpace(kv[1]))
+		if err != nil || !validKey(key) {
+			continue
+		}
+		b[key] = value
+	}
+	return b.Filter(p)
+}
+
+// Filter returns the members allowed by p, in key order until the limits are reached.
+func (b Baggage) Filter(p Policy) Baggage {
+	maxMembers, maxBytes := p.limits()
+	out := Baggage{}
+	size := 0
+	for _, key := range b.keys() {
+		if !p.allowed(key) || len(out) >= maxMembers {
+			continue
+		}
+		n := len(encodeMember(key, b[key]))
+		if len(out) > 0 {
+			n++ // the comma
+		}
+		if size+n > maxBytes {
+			continue
+		}
+		size += n
+		out[key] = b[key]
+	}
+	return out
+}
+
+// String encodes the baggage as a header value.
+func (b Baggage) String() string {
+	members := make([]string, 0, len(b))
+	for _, key := range b.keys() {
+		members = append(members, encodeMember(key, b[key]))
+	}
+	return strings.Join(members, ",")
+}
+
+func (b Baggage) keys() []string {
+	keys := make([]string, 0, len(b))
+	for key := range b {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func encodeMember(key, value string) string {
+	return key + "=" + url.PathEscape(value)
+}
+
+// validKey reports whether key is an RFC 7230 token.
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext creates a new context with the baggage, replacing the
+// OpenTelemetry baggage of ctx. The members over the W3C limits are dropped.
+func NewContext(ctx context.Context, b Baggage) context.Context {
+	b = b.Filter(Policy{})
+	members := make([]otelbaggage.Member, 0, len(b))
+	for _, key := range b.keys() {
+		if m, err := otelbaggage.NewMember(key, url.PathEscape(b[key])); err == nil {
+			members = append(members, m)
+		}
+	}
+	ob, err := otelbaggage.New(members...)
+	if err != nil {
+		return ctx
+	}
+	return otelbaggage.ContextWithBaggage(ctx, ob)
+}
+
+// FromContext returns the baggage in ctx if it exists.
+func FromContext(ctx context.Context) (Baggage, bool) {
+	ob := otelbaggage.FromContext(ctx)
+	if ob.Len() == 0 {
+		return nil, false
+	}
+	b := make(Baggage, ob.Len())
+	for _, m := range ob.Members() {
+		if value, err := url.PathUnescape(m.Value()); err == nil {
+			b[m.Key()] = value
+		}
+	}
+	return b, true
+}
+
+// Get returns the value of key in the baggage of ctx.
+func Get(ctx context.Context, key string) string {
+	b, _ := FromContext(ctx)
+	return b[key]
+}
+
+// Set returns a context with key set in a copy of the baggage of ctx.
+func Set(ctx context.Context, key, value string) context.Context {
+	old, _ := FromContext(ctx)
+	b := make(Baggage, len(old)+1)
+	for k, v := range old {
+		b[k] = v
+	}
+	b[key] = value
+	return NewContext(ctx, b)
+}
+
+// Valuer returns a log Valuer of the value of key in the baggage, e.g.:
+//
+//	log.With(logger, "tenant", baggage.Valuer("tenant"))
+func Valuer(key string) log.Valuer {
+	return func(ctx context.Context) interface{} {
+		return Get(ctx, key)
+	}
+}
@@ -0,0 +1,47 @@
+package baggage
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	b := Parse("tenant=acme, user=a%20b;prop=1,invalid,bad key=1,region=eu", Policy{})
+	expect := Baggage{"tenant": "acme", "user": "a b", "region": "eu"}
+	if !reflect.DeepEqual(expect, b) {
+		t.Errorf("expect %v, got %v", expect, b)
+	}
+	if s := b.String(); s != "region=eu,tenant=acme,user=a%20b" {
+		t.Errorf("unexpected header %s", s)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	b := Baggage{"a": "1", "b": "2", "c": strings.Repeat("x", 20)}
+	tests := []struct {
+		policy Policy
+		expect Baggage
+	}{
+		{Policy{AllowedKeys: []string{"a", "c"}}, Baggage{"a": "1", "c": b["c"]}},
+		{Policy{MaxMembers: 1}, Baggage{"a": "1"}},
+		{Policy{MaxBytes: 12}, Baggage{"a": "1", "b": "2"}},
+	}
+	for _, test := range tests {
+		if got := b.Filter(test.policy); !reflect.DeepEqual(test.expect, got) {
+			t.Errorf("%+v: expect %v, got %v", test.policy, test.expect, got)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := Set(context.Background(), "tenant", "acme")
+	child := Set(ctx, "user", "kratos")
+	if v := Valuer("tenant")(child); v != "acme" {
+		t.Errorf("expect acme, got %v", v)
+	}
+	if v := Get(ctx, "user"); v != "" {
+		t.Errorf("expect the parent baggage to be unchanged, got %s", v)
+	}
+}
@@ -0,0 +1,142 @@
+// Package bench drives calls, e.g. of the generated clients, at a target rate
+// against a server, in-memory or real, and reports the latency percentiles,
+// for quick regression checks in CI:
+//
+//	conn, stop, err := bench.ServeGRPC(func(s *grpc.Server) { pb.RegisterGreeterServer(s, &greeter{}) })
+//	defer stop()
+//	client := pb.NewGreeterClient(conn)
+//	report, err := bench.Run(ctx, func(ctx context.Context) error {
+//		_, err := client.SayHello(ctx, &pb.HelloRequest{Name: "kratos"})
+//		return err
+//	}, bench.WithRate(500), bench.WithDuration(5*time.Second))
+//	if err := report.Check(bench.Percentile(99, 10*time.Millisecond), bench.MaxErrorRate(0)); err != nil {
+//		t.Fatal(err)
+//	}
+package bench
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Func is a call driven by the runner.
+type Func func(ctx context.Context) error
+
+// Option is runner option.
+type Option func(*options)
+
+type options struct {
+	rate        float64
+	duration    time.Duration
+	concurrency int
+	timeout     time.Duration
+}
+
+// WithRate with the target rate in calls per second, default is 100.
+func WithRate(rps float64) Option {
+	return func(o *options) {
+		o.rate = rps
+	}
+}
+
+// WithDuration with the duration of the run, default is 10s.
+func WithDuration(d time.Duration) Option {
+	return func(o *options) {
+		o.duration = d
+	}
+}
+
+// WithConcurrency with the maximum number of calls in flight, default is 16.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithTimeout with the timeout of each call, default is 0, no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// Run calls f at the target rate until the duration elapses or ctx is done.
+// The calls are scheduled at fixed intervals and their latencies are measured
+// from their scheduled time, so a slow server delaying the next calls doesn't
+// hide its latency.
+func Run(ctx context.Context, f Func, opts ...Option) (*Report, error) {
+	o := &options{rate: 100, duration: 10 * time.Second, concurrency: 16}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.rate <= 0 || o.concurrency <= 0 {
+		return nil, errors.New("bench: the rate and the concurrency must be positive")
+	}
+	interval := time.Duration(float64(time.Second) / o.rate)
+	ctx, cancel := context.WithTimeout(ctx, o.duration)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		schedule = make(chan time.Time, o.concurrency)
+		report   = &Report{}
+	)
+	for i := 0; i < o.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var (
+				latencies []time.Duration
+				errs      int
+			)
+			for at := range schedule {
+				if err := call(f, o.timeout, at); err != nil {
+					errs++
+				}
+				latencies = append(latencies, time.Since(at))
+			}
+			mu.Lock()
+			report.latencies = append(report.latencies, latencies...)
+			report.Errors += errs
+			mu.Unlock()
+		}()
+	}
+
+	start := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+loop:
+	for at := start; ; at = at.Add(interval) {
+		timer.Reset(time.Until(at))
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-timer.C:
+		}
+		select {
+		case <-ctx.Done():
+			break loop
+		case schedule <- at:
+		}
+	}
+	close(schedule)
+	wg.Wait()
+	report.Duration = time.Since(start)
+	report.finish()
+	return report, nil
+}
+
+// call calls f with a context independent of the run, so the calls in flight
+// when the run ends complete.
+func call(f Func, timeout time.Duration, at time.Time) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, at.Add(timeout))
+		defer cancel()
+	}
+	return f(ctx)
+}
@@ -0,0 +1,106 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type greeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (greeter) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	return &pb.HelloReply{Message: "Hello " + in.Name}, nil
+}
+
+func TestRunGRPC(t *testing.T) {
+	conn, stop, err := ServeGRPC(func(s *grpc.Server) { pb.RegisterGreeterServer(s, greeter{}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	client := pb.NewGreeterClient(conn)
+	report, err := Run(context.Background(), func(ctx context.Context) error {
+		_, err := client.SayHello(ctx, &pb.HelloRequest{Name: "kratos"})
+		return err
+	}, WithRate(200), WithDuration(250*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(report)
+	if report.Requests < 30 || report.Requests > 60 {
+		t.Errorf("got %d requests, want about 50", report.Requests)
+	}
+	if err := report.Check(MaxErrorRate(0), Percentile(50, time.Second)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunHTTP(t *testing.T) {
+	client, stop, err := ServeHTTP(func(s *http.Server) { pb.RegisterGreeterHTTPServer(s, greeter{}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	hc := pb.NewGreeterHTTPClient(client)
+	report, err := Run(context.Background(), func(ctx context.Context) error {
+		_, err := hc.SayHello(ctx, &pb.HelloRequest{Name: "kratos"})
+		return err
+	}, WithRate(100), WithDuration(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Requests == 0 || report.Errors != 0 {
+		t.Errorf("unexpected report %s", report)
+	}
+}
+
+func TestSlowCalls(t *testing.T) {
+	report, err := Run(context.Background(), func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return errors.New("failed")
+	}, WithRate(100), WithDuration(100*time.Millisecond), WithConcurrency(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ErrorRate() != 1 {
+		t.Errorf("got error rate %v, want 1", report.ErrorRate())
+	}
+	// the calls queue behind the single worker, their latency includes the wait.
+	if report.Max() < 40*time.Millisecond {
+		t.Errorf("got max latency %s, want the queueing delay included", report.Max())
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := &Report{Duration: time.Second}
+	for i := 10; i >= 1; i-- {
+		r.latencies = append(r.latencies, time.Duration(i)*time.Millisecond)
+	}
+	r.Errors = 1
+	r.finish()
+	if r.Percentile(50) != 5*time.Millisecond || r.Percentile(99) != 10*time.Millisecond || r.Percentile(0) != time.Millisecond {
+		t.Errorf("unexpected percentiles %s", r)
+	}
+	if r.Rate() != 10 || r.ErrorRate() != 0.1 {
+		t.Errorf("got rate %v and error rate %v", r.Rate(), r.ErrorRate())
+	}
+	if err := r.Check(Percentile(90, 9*time.Millisecond)); err != nil {
+		t.Error(err)
+	}
+	if err := r.Check(Percentile(99, 9*time.Millisecond)); err == nil {
+		t.Error("expect the p99 check to fail")
+	}
+	if err := r.Check(MaxErrorRate(0.05)); err == nil {
+		t.Error("expect the error rate check to fail")
+	}
+	if err := r.Check(MinRate(20)); err == nil {
+		t.Error("expect the rate check to fail")
+	}
+}
@@ -0,0 +1,108 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Report is the report of a run.
+type Report struct {
+	// Requests is the number of calls.
+	Requests int
+	// Errors is the number of calls returning an error.
+	Errors int
+	// Duration is the duration of the run, including the calls in flight at its end.
+	Duration time.Duration
+
+	latencies []time.Duration
+}
+
+func (r *Report) finish() {
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	r.Requests = len(r.latencies)
+}
+
+// Rate returns the achieved rate in calls per second.
+func (r *Report) Rate() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Duration.Seconds()
+}
+
+// ErrorRate returns the ratio of the calls returning an error.
+func (r *Report) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Requests)
+}
+
+// Percentile returns the latency percentile p, between 0 and 100, e.g. 99.
+func (r *Report) Percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(r.latencies)))) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(r.latencies) {
+		i = len(r.latencies) - 1
+	}
+	return r.latencies[i]
+}
+
+// Max returns the maximum latency.
+func (r *Report) Max() time.Duration {
+	return r.Percentile(100)
+}
+
+func (r *Report) String() string {
+	return fmt.Sprintf("requests=%d errors=%d rate=%.1f/s p50=%s p90=%s p99=%s max=%s",
+		r.Requests, r.Errors, r.Rate(), r.Percentile(50), r.Percentile(90), r.Percentile(99), r.Max())
+}
+
+// Check is a check of a report.
+type Check func(r *Report) error
+
+// Check returns the error of the first failing check.
+func (r *Report) Check(checks ...Check) error {
+	for _, c := range checks {
+		if err := c(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Percentile checks the latency percentile p is at most max.
+func Percentile(p float64, max time.Duration) Check {
+	return func(r *Report) error {
+		if got := r.Percentile(p); got > max {
+			return fmt.Errorf("bench: p%g latency %s exceeds %s", p, got, max)
+		}
+		return nil
+	}
+}
+
+// MaxErrorRate checks the error rate is at most rate.
+func MaxErrorRate(rate float64) Check {
+	return func(r *Report) error {
+		if got := r.ErrorRate(); got > rate {
+			return fmt.Errorf("bench: error rate %.4f exceeds %.4f", got, rate)
+		}
+		return nil
+	}
+}
+
+// MinRate checks the achieved rate is at least rps.
+func MinRate(rps float64) Check {
+	return func(r *Report) error {
+		if got := r.Rate(); got < rps {
+			return fmt.Errorf("bench: rate %.1f/s is below %.1f/s", got, rps)
+		}
+		return nil
+	}
+}
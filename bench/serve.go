@@ -0,0 +1,53 @@
+package bench
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/go-kratos/kratos/v2/transport/inmem"
+
+	ggrpc "google.golang.org/grpc"
+)
+
+// ServeGRPC starts a gRPC server on an in-memory listener, with the services
+// registered by register, and returns a connection to it and a func stopping both.
+func ServeGRPC(register func(*grpc.Server), opts ...grpc.ServerOption) (*ggrpc.ClientConn, func(), error) {
+	lis := inmem.Listen()
+	srv := grpc.NewServer(append(opts, grpc.Listener(lis))...)
+	if _, err := srv.Endpoint(); err != nil {
+		return nil, nil, err
+	}
+	register(srv)
+	go func() { _ = srv.Start(context.Background()) }()
+	conn, err := grpc.DialInsecure(context.Background(), grpc.WithEndpoint(inmem.Endpoint), grpc.WithOptions(lis.DialOption()))
+	if err != nil {
+		_ = srv.Stop(context.Background())
+		return nil, nil, err
+	}
+	return conn, func() {
+		_ = conn.Close()
+		_ = srv.Stop(context.Background())
+	}, nil
+}
+
+// ServeHTTP starts an HTTP server on an in-memory listener, with the routes
+// registered by register, and returns a client of it and a func stopping both.
+func ServeHTTP(register func(*http.Server), opts ...http.ServerOption) (*http.Client, func(), error) {
+	lis := inmem.Listen()
+	srv := http.NewServer(append(opts, http.Listener(lis))...)
+	if _, err := srv.Endpoint(); err != nil {
+		return nil, nil, err
+	}
+	register(srv)
+	go func() { _ = srv.Start(context.Background()) }()
+	client, err := http.NewClient(context.Background(), http.WithEndpoint(inmem.Endpoint), http.WithTransport(lis.Transport()))
+	if err != nil {
+		_ = srv.Stop(context.Background())
+		return nil, nil, err
+	}
+	return client, func() {
+		_ = client.Close()
+		_ = srv.Stop(context.Background())
+	}, nil
+}
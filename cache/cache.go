@@ -0,0 +1,228 @@
+// Package cache implements cache-aside helpers over a Store, e.g. Redis,
+// Memcached or memory, the values are serialized with an encoding codec:
+//
+//	c := cache.New(redis.New(client), cache.WithPrefix("user:"))
+//	var user pb.User
+//	err := c.GetOrLoad(ctx, id, &user, time.Hour, func(ctx context.Context) (interface{}, error) {
+//		return repo.GetUser(ctx, id)
+//	})
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/encoding/json"
+	ic "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrMiss is returned when a key isn't cached.
+var ErrMiss = errors.New("cache: miss")
+
+// Store stores the serialized values.
+type Store interface {
+	// Get gets the value of key, it returns ErrMiss if key isn't cached.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set sets the value of key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete deletes keys, the keys not cached are ignored.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// LoadFunc loads a value missing in the cache.
+type LoadFunc func(ctx context.Context) (interface{}, error)
+
+// Option is cache option.
+type Option func(*Cache)
+
+// WithPrefix with the prefix of the keys in the store.
+func WithPrefix(prefix string) Option {
+	return func(c *Cache) {
+		c.prefix = prefix
+	}
+}
+
+// WithTTL with the TTL of the values set with no TTL, default is 1m.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithLoadTimeout with the timeout of the coalesced loads, default is 10s.
+func WithLoadTimeout(timeout time.Duration) Option {
+	return func(c *Cache) {
+		c.loadTimeout = timeout
+	}
+}
+
+// WithCodec with the codec of the values, default is json.
+func WithCodec(codec encoding.Codec) Option {
+	return func(c *Cache) {
+		c.codec = codec
+	}
+}
+
+// WithName with the name of the cache in the metrics, default is "default".
+func WithName(name string) Option {
+	return func(c *Cache) {
+		c.name = name
+	}
+}
+
+// WithMetrics with the hits and the misses counters, labeled by cache name,
+// either may be nil.
+func WithMetrics(hits, misses metrics.Counter) Option {
+	return func(c *Cache) {
+		c.hits = hits
+		c.misses = misses
+	}
+}
+
+// Config is the cache config, e.g. scanned from the config of the app.
+type Config struct {
+	Prefix string `json:"prefix"`
+	// TTL is the default TTL, e.g. 10m.
+	TTL string `json:"ttl"`
+	// Codec is the name of the codec, e.g. proto.
+	Codec string `json:"codec"`
+}
+
+// Options returns the options of the config.
+func (c Config) Options() ([]Option, error) {
+	var opts []Option
+	if c.Prefix != "" {
+		opts = append(opts, WithPrefix(c.Prefix))
+	}
+	if c.TTL != "" {
+		ttl, err := time.ParseDuration(c.TTL)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTTL(ttl))
+	}
+	if c.Codec != "" {
+		codec := encoding.GetCodec(c.Codec)
+		if codec == nil {
+			return nil, fmt.Errorf("cache: unknown codec %q", c.Codec)
+		}
+		opts = append(opts, WithCodec(codec))
+	}
+	return opts, nil
+}
+
+// Cache is a cache of serialized values.
+type Cache struct {
+	store  Store
+	prefix string
+	ttl    time.Duration
+	codec  encoding.Codec
+	name   string
+	hits   metrics.Counter
+	misses metrics.Counter
+	group  singleflight.Group
+	// loadTimeout bounds the loads, which are detached from the callers.
+	loadTimeout time.Duration
+}
+
+// New new a cache of store.
+func New(store Store, opts ...Option) *Cache {
+	c := &Cache{
+		store:       store,
+		ttl:         time.Minute,
+		codec:       encoding.GetCodec(json.Name),
+		name:        "default",
+		loadTimeout: 10 * time.Second,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Get gets the value of key into v, it returns ErrMiss if key isn't cached.
+func (c *Cache) Get(ctx context.Context, key string, v interface{}) error {
+	data, err := c.store.Get(ctx, c.prefix+key)
+	if err != nil {
+		if errors.Is(err, ErrMiss) && c.misses != nil {
+			c.misses.With(c.name).Inc()
+		}
+		return err
+	}
+	if c.hits != nil {
+		c.hits.With(c.name).Inc()
+	}
+	return c.codec.Unmarshal(data, v)
+}
+
+// Set sets the value of key to v for ttl, 0 is the default TTL.
+func (c *Cache) Set(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.store.Set(ctx, c.prefix+key, data, c.ttlOf(ttl))
+}
+
+// Delete deletes keys.
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.store.Delete(ctx, prefixed...)
+}
+
+// GetOrLoad gets the value of key into v, on a miss it loads the value with
+// load and caches it for ttl, 0 is the default TTL. The concurrent loads of a
+// key are coalesced into a single call, which is detached from the callers and
+// bounded by the load timeout, the callers may be canceled or time out on their
+// own. The errors of the store are logged and handled as misses.
+func (c *Cache) GetOrLoad(ctx context.Context, key string, v interface{}, ttl time.Duration, load LoadFunc) error {
+	err := c.Get(ctx, key, v)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrMiss) {
+		log.Warnf("cache: failed to get %s: %v", key, err)
+	}
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ic.Detach(ctx), c.loadTimeout)
+		defer cancel()
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := c.codec.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.store.Set(ctx, c.prefix+key, data, c.ttlOf(ttl)); err != nil {
+			log.Warnf("cache: failed to set %s: %v", key, err)
+		}
+		return data, nil
+	})
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return res.Err
+		}
+		return c.codec.Unmarshal(res.Val.([]byte), v)
+	}
+}
+
+func (c *Cache) ttlOf(ttl time.Duration) time.Duration {
+	if ttl > 0 {
+		return ttl
+	}
+	return c.ttl
+}
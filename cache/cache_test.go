@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/encoding/proto"
+	"github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+type counter struct {
+	lvs   []string
+	value int64
+}
+
+func (c *counter) With(lvs ...string) metrics.Counter { c.lvs = lvs; return c }
+func (c *counter) Inc()                               { atomic.AddInt64(&c.value, 1) }
+func (c *counter) Add(delta float64)                  { atomic.AddInt64(&c.value, int64(delta)) }
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10)
+	hits, misses := &counter{}, &counter{}
+	c := New(store, WithPrefix("user:"), WithName("users"), WithMetrics(hits, misses))
+
+	var v map[string]string
+	if err := c.Get(ctx, "1", &v); !errors.Is(err, ErrMiss) {
+		t.Fatalf("got %v, want a miss", err)
+	}
+	if err := c.Set(ctx, "1", map[string]string{"name": "kratos"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "user:1"); err != nil {
+		t.Errorf("expect the key to be prefixed, got %v", err)
+	}
+	if err := c.Get(ctx, "1", &v); err != nil || v["name"] != "kratos" {
+		t.Fatalf("got %v %v", v, err)
+	}
+	if hits.value != 1 || misses.value != 1 || hits.lvs[0] != "users" {
+		t.Errorf("got %d hits and %d misses, want 1 and 1", hits.value, misses.value)
+	}
+	if err := c.Delete(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, "1", &v); !errors.Is(err, ErrMiss) {
+		t.Errorf("got %v, want a miss after delete", err)
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewMemoryStore(10), WithCodec(encoding.GetCodec(proto.Name)))
+	var (
+		loads int64
+		wg    sync.WaitGroup
+	)
+	load := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt64(&loads, 1)
+		time.Sleep(50 * time.Millisecond)
+		return &helloworld.HelloReply{Message: "hello"}, nil
+	}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var reply helloworld.HelloReply
+			if err := c.GetOrLoad(ctx, "hello", &reply, time.Minute, load); err != nil || reply.Message != "hello" {
+				t.Errorf("got %v %v", reply.Message, err)
+			}
+		}()
+	}
+	wg.Wait()
+	var reply helloworld.HelloReply
+	if err := c.GetOrLoad(ctx, "hello", &reply, time.Minute, load); err != nil || reply.Message != "hello" {
+		t.Errorf("got %v %v", reply.Message, err)
+	}
+	if loads != 1 {
+		t.Errorf("got %d loads, want 1", loads)
+	}
+
+	errLoad := errors.New("load")
+	err := c.GetOrLoad(ctx, "error", &reply, 0, func(ctx context.Context) (interface{}, error) { return nil, errLoad })
+	if !errors.Is(err, errLoad) {
+		t.Errorf("got %v, want the load error", err)
+	}
+}
+
+func TestGetOrLoad_Canceled(t *testing.T) {
+	c := New(NewMemoryStore(10))
+	started, release := make(chan struct{}), make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		close(started)
+		select {
+		case <-release:
+			return "value", ctx.Err()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	leader, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		var v string
+		done <- c.GetOrLoad(leader, "key", &v, 0, load)
+	}()
+	<-started
+	follower := make(chan error, 1)
+	var v string
+	go func() {
+		follower <- c.GetOrLoad(context.Background(), "key", &v, 0, load)
+	}()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want the leader canceled", err)
+	}
+	close(release)
+	if err := <-follower; err != nil || v != "value" {
+		t.Errorf("got %q %v, want the value loaded for the follower", v, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(2)
+	_ = s.Set(ctx, "a", []byte("a"), time.Minute)
+	_ = s.Set(ctx, "b", []byte("b"), time.Minute)
+	_, _ = s.Get(ctx, "a")
+	_ = s.Set(ctx, "c", []byte("c"), time.Minute)
+	if _, err := s.Get(ctx, "b"); !errors.Is(err, ErrMiss) {
+		t.Errorf("expect the least recently used entry to be evicted")
+	}
+	_ = s.Set(ctx, "d", []byte("d"), -time.Second)
+	if _, err := s.Get(ctx, "d"); !errors.Is(err, ErrMiss) {
+		t.Errorf("expect the expired entry to miss")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expect a non-positive size to panic")
+		}
+	}()
+	NewMemoryStore(0)
+}
+
+func TestConfig(t *testing.T) {
+	opts, err := Config{Prefix: "p:", TTL: "10m", Codec: "proto"}.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(NewMemoryStore(1), opts...)
+	if c.prefix != "p:" || c.ttl != 10*time.Minute || c.codec.Name() != "proto" {
+		t.Errorf("unexpected cache %+v", c)
+	}
+	if _, err := (Config{TTL: "1x"}).Options(); err == nil {
+		t.Error("expect an invalid TTL")
+	}
+	if _, err := (Config{Codec: "unknown"}).Options(); err == nil {
+		t.Error("expect an unknown codec")
+	}
+}
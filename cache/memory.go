@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/internal/lru"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is an LRU Store in memory, it isn't shared by the instances.
+type MemoryStore struct {
+	lru *lru.Cache
+}
+
+// NewMemoryStore new a memory store holding at most size entries, it panics
+// if size isn't positive.
+func NewMemoryStore(size int) *MemoryStore {
+	if size <= 0 {
+		panic("cache: the size of the memory store must be positive")
+	}
+	return &MemoryStore{lru: lru.New(size)}
+}
+
+// Get gets the value of key if it isn't expired.
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	v, ok := s.lru.Get(key)
+	if !ok {
+		return nil, ErrMiss
+	}
+	return v.([]byte), nil
+}
+
+// Set sets the value of key for ttl, 0 never expires, evicting the least
+// recently used entry if full.
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.lru.Set(key, append([]byte(nil), value...), ttl)
+	return nil
+}
+
+// Delete deletes keys.
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.lru.Delete(keys...)
+	return nil
+}
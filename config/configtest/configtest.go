@@ -0,0 +1,165 @@
+// Package configtest provides the conformance suite of the config sources, run
+// by the tests of each source:
+//
+//	func TestConformance(t *testing.T) {
+//		path := filepath.Join(t.TempDir(), "config.yaml")
+//		configtest.Conformance(t, file.NewSource(path), func(value []byte) error {
+//			return os.WriteFile(path, value, 0o666)
+//		})
+//	}
+package configtest
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+// Timeout is the time the changes are expected to be watched within.
+var Timeout = 10 * time.Second
+
+// Conformance runs the conformance suite of the source s, set changes the
+// value of a key of s, e.g. writes its file, nil if s can't be changed:
+//
+//   - Load: the value set is loaded.
+//   - Watch: the value set is watched by all the watchers.
+//   - Stop: Next returns an error once the watcher is stopped.
+func Conformance(t *testing.T, s config.Source, set func(value []byte) error) {
+	t.Run("Load", func(t *testing.T) { testLoad(t, s, set) })
+	t.Run("Watch", func(t *testing.T) { testWatch(t, s, set) })
+	t.Run("Stop", func(t *testing.T) { testStop(t, s) })
+}
+
+// Value returns a unique value for the tests.
+func Value() []byte {
+	return []byte(fmt.Sprintf(`{"configtest":"%d"}`, time.Now().UnixNano()))
+}
+
+func testLoad(t *testing.T, s config.Source, set func([]byte) error) {
+	if set == nil {
+		if _, err := s.Load(); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return
+	}
+	value := Value()
+	if err := set(value); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	var kvs []*config.KeyValue
+	for deadline := time.Now().Add(Timeout); ; time.Sleep(10 * time.Millisecond) {
+		var err error
+		if kvs, err = s.Load(); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if contains(kvs, value) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Load: expect the value %s, got %s", value, values(kvs))
+		}
+	}
+}
+
+func testWatch(t *testing.T, s config.Source, set func([]byte) error) {
+	if set == nil {
+		t.Skip("the source can't be changed")
+	}
+	watchers := make([]config.Watcher, 2)
+	for i := range watchers {
+		w, err := s.Watch()
+		if err != nil {
+			t.Fatalf("Watch: %v", err)
+		}
+		defer w.Stop() //nolint:errcheck
+		watchers[i] = w
+	}
+	value := Value()
+	if err := set(value); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	var wg sync.WaitGroup
+	for _, w := range watchers {
+		wg.Add(1)
+		go func(w config.Watcher) {
+			defer wg.Done()
+			Next(t, w, value)
+		}(w)
+	}
+	wg.Wait()
+}
+
+func testStop(t *testing.T, s config.Source) {
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if err = w.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.Next()
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		if err == nil {
+			t.Fatal("Next: expect an error once stopped")
+		}
+	case <-time.After(Timeout):
+		t.Fatal("Next: expect to return once stopped")
+	}
+}
+
+// Next watches w until the value is watched, failing t after Timeout.
+func Next(t *testing.T, w config.Watcher, value []byte) []*config.KeyValue {
+	t.Helper()
+	type result struct {
+		kvs []*config.KeyValue
+		err error
+	}
+	deadline := time.After(Timeout)
+	var got []*config.KeyValue
+	for {
+		ch := make(chan result, 1)
+		go func() {
+			kvs, err := w.Next()
+			ch <- result{kvs, err}
+		}()
+		select {
+		case res := <-ch:
+			if res.err != nil {
+				t.Errorf("Next: %v", res.err)
+				return nil
+			}
+			if got = res.kvs; contains(got, value) {
+				return got
+			}
+		case <-deadline:
+			t.Errorf("Next: expect the value %s, got %s", value, values(got))
+			return nil
+		}
+	}
+}
+
+func contains(kvs []*config.KeyValue, value []byte) bool {
+	for _, kv := range kvs {
+		if bytes.Equal(bytes.TrimSpace(kv.Value), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func values(kvs []*config.KeyValue) []string {
+	ret := make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		ret = append(ret, kv.Key+"="+string(kv.Value))
+	}
+	return ret
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/configtest"
 	"github.com/go-kratos/kratos/v2/config/file"
 )
 
@@ -417,3 +418,8 @@ func Test_matchPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestConformance(t *testing.T) {
+	// the environment variables are not watched
+	configtest.Conformance(t, NewSource("KRATOS_CONFIGTEST_"), nil)
+}
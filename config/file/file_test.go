@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/configtest"
 )
 
 const (
@@ -68,6 +69,26 @@ const (
 	}
 }`
 
+	_testTOML = `
+[test.settings]
+int_key = 1000
+float_key = 1000.1
+duration_key = 10000
+string_key = "string_value"
+
+[test.server]
+addr = "127.0.0.1"
+port = 8000
+
+[[foo]]
+name = "nihao"
+age = 18
+
+[[foo]]
+name = "nihao"
+age = 18
+`
+
 	//	_testYaml = `
 	//Foo:
 	//    bar :
@@ -205,6 +226,22 @@ func TestConfig(t *testing.T) {
 	testConfig(t, c)
 }
 
+func TestConfigTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test_config.toml")
+	if err := os.WriteFile(path, []byte(_testTOML), 0o666); err != nil {
+		t.Error(err)
+	}
+	c := config.New(config.WithSource(
+		NewSource(path),
+	))
+	testScan(t, c)
+
+	testConfig(t, c)
+	if v, err := c.Value("foo").Slice(); err != nil || len(v) != 2 {
+		t.Errorf("expect the array of tables, got %v %v", v, err)
+	}
+}
+
 func testConfig(t *testing.T, c config.Config) {
 	expected := map[string]interface{}{
 		"test.settings.int_key":      int64(1000),
@@ -339,3 +376,13 @@ func TestMergeDataRace(t *testing.T) {
 	close(startCh)
 	wg.Wait()
 }
+
+func TestConformance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	configtest.Conformance(t, NewSource(path), func(value []byte) error {
+		return os.WriteFile(path, value, 0o666)
+	})
+}
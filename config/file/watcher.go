@@ -32,10 +32,17 @@ func newWatcher(f *file) (config.Watcher, error) {
 }
 
 func (w *watcher) Next() ([]*config.KeyValue, error) {
+	// the channels of fsnotify are closed once stopped
+	if err := w.ctx.Err(); err != nil {
+		return nil, err
+	}
 	select {
 	case <-w.ctx.Done():
 		return nil, w.ctx.Err()
-	case event := <-w.fw.Events:
+	case event, ok := <-w.fw.Events:
+		if !ok {
+			return nil, context.Canceled
+		}
 		if event.Op == fsnotify.Rename {
 			if _, err := os.Stat(event.Name); err == nil || os.IsExist(err) {
 				if err := w.fw.Add(event.Name); err != nil {
@@ -56,7 +63,10 @@ func (w *watcher) Next() ([]*config.KeyValue, error) {
 			return nil, err
 		}
 		return []*config.KeyValue{kv}, nil
-	case err := <-w.fw.Errors:
+	case err, ok := <-w.fw.Errors:
+		if !ok {
+			return nil, context.Canceled
+		}
 		return nil, err
 	}
 }
@@ -0,0 +1,41 @@
+//go:build go1.18
+// +build go1.18
+
+package config
+
+import (
+	"testing"
+)
+
+func FuzzResolver(f *testing.F) {
+	f.Add(`{"a":{"b":"${c.d:8000}"},"c":{"d":"${a.b}"}}`, "a.b")
+	f.Add(`{"a":null,"b":"${a}","c":["${b}",{"d":"${a:}"}]}`, "c")
+	f.Add(`{"a":"${${}}","b":"${:}${ }${a.}"}`, "b.")
+	f.Add(`{"":{"":"${.}"}}`, ".")
+	f.Fuzz(func(t *testing.T, data, key string) {
+		values := make(map[string]interface{})
+		if err := defaultDecoder(&KeyValue{Key: "fuzz", Value: []byte(data), Format: "json"}, values); err != nil {
+			return
+		}
+		_ = defaultResolver(values)
+		if v, ok := readValue(values, key); ok {
+			_, _ = v.String()
+			_, _ = v.Map()
+			_, _ = v.Slice()
+		}
+	})
+}
+
+func FuzzDecoder(f *testing.F) {
+	f.Add("a.b.c", "v")
+	f.Add("..", "")
+	f.Add("", "${a}")
+	f.Fuzz(func(t *testing.T, key, value string) {
+		r := newReader(options{decoder: defaultDecoder, resolver: defaultResolver})
+		if err := r.Merge(&KeyValue{Key: key, Value: []byte(value)}, &KeyValue{Key: key + ".sub", Value: []byte(value)}); err != nil {
+			return
+		}
+		_ = r.Resolve()
+		_, _ = r.Value(key)
+	})
+}
@@ -123,7 +123,7 @@ func convertMap(src interface{}) interface{} {
 }
 
 // readValue read Value in given map[string]interface{}
-// by the given path, will return false if not found.
+// by the given path, will return false if not found or null.
 func readValue(values map[string]interface{}, path string) (Value, bool) {
 	var (
 		next = values
@@ -132,7 +132,7 @@ func readValue(values map[string]interface{}, path string) (Value, bool) {
 	)
 	for idx, key := range keys {
 		value, ok := next[key]
-		if !ok {
+		if !ok || value == nil {
 			return nil, false
 		}
 		if idx == last {
@@ -180,6 +180,30 @@ a:
 	}
 }
 
+func TestReader_Null(t *testing.T) {
+	r := newReader(options{decoder: defaultDecoder, resolver: defaultResolver})
+	if err := r.Merge(&KeyValue{Key: "null", Value: []byte(`{"a":null,"b":"${a:x}","c":[null,"${b}"]}`), Format: "json"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Value("a"); ok {
+		t.Error("expect the null value not found")
+	}
+	if v, ok := r.Value("b"); !ok || fmt.Sprint(v.Load()) != "x" {
+		t.Errorf("expect the default of the null placeholder, got %v", v)
+	}
+	v, _ := r.Value("c")
+	vs, err := v.Slice()
+	if err != nil || len(vs) != 2 {
+		t.Fatalf("unexpected slice %v %v", vs, err)
+	}
+	if _, err := vs[0].String(); err != ErrNotFound {
+		t.Errorf("expect the null element not found, got %v", err)
+	}
+}
+
 func TestReader_Source(t *testing.T) {
 	var err error
 	opts := options{
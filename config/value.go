@@ -35,6 +35,16 @@ type atomicValue struct {
 	atomic.Value
 }
 
+// newValue returns the value of v, a null v is not found.
+func newValue(v interface{}) Value {
+	if v == nil {
+		return &errValue{err: ErrNotFound}
+	}
+	a := &atomicValue{}
+	a.Store(v)
+	return a
+}
+
 func (v *atomicValue) Bool() (bool, error) {
 	switch val := v.Load().(type) {
 	case bool:
@@ -65,9 +75,7 @@ func (v *atomicValue) Slice() ([]Value, error) {
 	if vals, ok := v.Load().([]interface{}); ok {
 		var slices []Value
 		for _, val := range vals {
-			a := &atomicValue{}
-			a.Store(val)
-			slices = append(slices, a)
+			slices = append(slices, newValue(val))
 		}
 		return slices, nil
 	}
@@ -78,9 +86,7 @@ func (v *atomicValue) Map() (map[string]Value, error) {
 	if vals, ok := v.Load().(map[string]interface{}); ok {
 		m := make(map[string]Value)
 		for key, val := range vals {
-			a := &atomicValue{}
-			a.Store(val)
-			m[key] = a
+			m[key] = newValue(val)
 		}
 		return m, nil
 	}
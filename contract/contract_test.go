@@ -0,0 +1,110 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/inmem"
+)
+
+type greeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (greeter) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	if in.Name == "error" {
+		return nil, errors.BadRequest("INVALID_NAME", "invalid name")
+	}
+	return &pb.HelloReply{Message: "Hello " + in.Name}, nil
+}
+
+func TestContract(t *testing.T) {
+	dir := t.TempDir()
+	if err := Generate(dir, pb.File_helloworld_helloworld_proto); err != nil {
+		t.Fatal(err)
+	}
+
+	lis := inmem.Listen()
+	srv := grpc.NewServer(grpc.Listener(lis), grpc.Middleware(Recorder(dir, WithSamples(2))))
+	pb.RegisterGreeterServer(srv, greeter{})
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+	conn, err := grpc.DialInsecure(context.Background(), grpc.WithEndpoint(inmem.Endpoint), grpc.WithOptions(lis.DialOption()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := pb.NewGreeterClient(conn)
+	for _, name := range []string{"kratos", "kratos", "error", "go"} {
+		_, _ = client.SayHello(context.Background(), &pb.HelloRequest{Name: name})
+	}
+	cases, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pending, failed int
+	for _, c := range cases {
+		if c.Operation != "/helloworld.Greeter/SayHello" {
+			t.Errorf("unexpected operation %s", c.Operation)
+		}
+		if c.Pending {
+			pending++
+		}
+		if c.Error != nil && c.Error.Code == 400 && c.Error.Reason == "INVALID_NAME" {
+			failed++
+		}
+	}
+	if len(cases) != 3 || pending != 1 || failed != 1 {
+		t.Fatalf("got %d cases, %d pending, %d failed, want 3, 1, 1", len(cases), pending, failed)
+	}
+
+	Verify(t, conn, dir)
+
+	ctx := context.Background()
+	c := &Case{Name: "mismatch", Operation: "/helloworld.Greeter/SayHello", Request: json.RawMessage(`{"name":"kratos"}`), Reply: json.RawMessage(`{"message":"Hi kratos"}`)}
+	if err := newVerifier(dir).verify(ctx, conn, c); err == nil {
+		t.Error("expect a reply mismatch")
+	}
+	if err := newVerifier(dir, IgnoreFields("message")).verify(ctx, conn, c); err != nil {
+		t.Errorf("expect the ignored fields to match, got %v", err)
+	}
+	c.Error = &Error{Code: 400, Reason: "INVALID_NAME"}
+	if err := newVerifier(dir).verify(ctx, conn, c); err == nil {
+		t.Error("expect an error mismatch")
+	}
+	c.Operation = "/helloworld.Greeter/SayGoodbye"
+	if err := newVerifier(dir).verify(ctx, conn, c); err == nil {
+		t.Error("expect an unknown method")
+	}
+
+	c.Operation = "/helloworld.Greeter/SayHello"
+	if err := newVerifier(dir, WithUpdate(true)).verify(ctx, conn, c); err != nil {
+		t.Fatal(err)
+	}
+	cases, err = Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range cases {
+		if got.Name == "mismatch" && (got.Error != nil || !strings.Contains(string(got.Reply), `"Hello kratos"`)) {
+			t.Errorf("expect the case to be updated, got %s %v", got.Reply, got.Error)
+		}
+	}
+}
+
+func TestRecorder_Samples(t *testing.T) {
+	r := &recorder{dir: t.TempDir(), samples: 1, counts: make(map[string]int)}
+	if err := r.record("/helloworld.Greeter/SayHello", &pb.HelloRequest{Name: "kratos"}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	// the invalid request is not marshalled once the samples are recorded.
+	if err := r.record("/helloworld.Greeter/SayHello", &pb.HelloRequest{Name: "\xff"}, nil, nil); err != nil {
+		t.Errorf("expect the request skipped, got %v", err)
+	}
+}
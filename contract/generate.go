@@ -0,0 +1,57 @@
+package contract
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Generate writes a pending example case, with the request and the reply
+// fields unpopulated, for each unary method of the services of files which
+// has no case in dir yet.
+func Generate(dir string, files ...protoreflect.FileDescriptor) error {
+	for _, fd := range files {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			sd := services.Get(i)
+			methods := sd.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				md := methods.Get(j)
+				if md.IsStreamingClient() || md.IsStreamingServer() {
+					continue
+				}
+				if err := generate(dir, md); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func generate(dir string, md protoreflect.MethodDescriptor) error {
+	operation := "/" + path.Join(string(md.Parent().FullName()), string(md.Name()))
+	infos, err := ioutil.ReadDir(operationDir(dir, operation))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(infos) > 0 {
+		return nil
+	}
+	c := &Case{Name: "example", Operation: operation, Pending: true}
+	if c.Request, err = template(md.Input()); err != nil {
+		return err
+	}
+	if c.Reply, err = template(md.Output()); err != nil {
+		return err
+	}
+	return c.Save(dir)
+}
+
+func template(desc protoreflect.MessageDescriptor) ([]byte, error) {
+	return protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(dynamicpb.NewMessage(desc))
+}
@@ -0,0 +1,97 @@
+package contract
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// RecordOption is recorder option.
+type RecordOption func(*recorder)
+
+// WithSamples with the maximum number of cases recorded per operation, default is 5.
+func WithSamples(n int) RecordOption {
+	return func(r *recorder) {
+		r.samples = n
+	}
+}
+
+type recorder struct {
+	dir     string
+	samples int
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// Recorder is a server middleware recording the requests and the replies, or
+// the errors, of the operations as cases in dir. The cases are named by the
+// hash of their request, so identical requests are recorded once. The recorded
+// cases must be reviewed, they may contain personal or non deterministic data.
+func Recorder(dir string, opts ...RecordOption) middleware.Middleware {
+	r := &recorder{dir: dir, samples: 5, counts: make(map[string]int)}
+	for _, o := range opts {
+		o(r)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if in, ok := req.(proto.Message); ok {
+					out, _ := reply.(proto.Message)
+					if rerr := r.record(tr.Operation(), in, out, err); rerr != nil {
+						log.Errorf("contract: failed to record %s: %v", tr.Operation(), rerr)
+					}
+				}
+			}
+			return reply, err
+		}
+	}
+}
+
+func (r *recorder) record(operation string, req, reply proto.Message, err error) error {
+	if r.full(operation) {
+		return nil
+	}
+	data, merr := protojson.Marshal(req)
+	if merr != nil {
+		return merr
+	}
+	sum := sha256.Sum256(data)
+	c := &Case{Name: hex.EncodeToString(sum[:8]), Operation: operation, Request: data}
+	if err != nil {
+		e := errors.FromError(err)
+		c.Error = &Error{Code: e.Code, Reason: e.Reason}
+	} else if reply != nil {
+		if c.Reply, merr = protojson.Marshal(reply); merr != nil {
+			return merr
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counts[operation] >= r.samples {
+		return nil
+	}
+	if _, serr := os.Stat(c.Path(r.dir)); serr == nil {
+		return nil
+	}
+	r.counts[operation]++
+	return c.Save(r.dir)
+}
+
+func (r *recorder) full(operation string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[operation] >= r.samples
+}
@@ -0,0 +1,207 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// VerifyOption is verify option.
+type VerifyOption func(*verifier)
+
+// WithUpdate with the update mode, the replies and the errors of the cases
+// are overwritten by the actual ones and the pending cases are resolved.
+func WithUpdate(update bool) VerifyOption {
+	return func(v *verifier) {
+		v.update = update
+	}
+}
+
+// WithTimeout with the timeout of each case, default is 5s.
+func WithTimeout(timeout time.Duration) VerifyOption {
+	return func(v *verifier) {
+		v.timeout = timeout
+	}
+}
+
+// IgnoreFields with the fields ignored in the replies, e.g. timestamps or
+// generated IDs, as dotted paths of proto field names, e.g. user.create_time.
+func IgnoreFields(paths ...string) VerifyOption {
+	return func(v *verifier) {
+		v.ignore = append(v.ignore, paths...)
+	}
+}
+
+// WithResolver with the resolver of the services and the messages, default is
+// the global registry. The messages not found are built dynamically.
+func WithResolver(files *protoregistry.Files, types *protoregistry.Types) VerifyOption {
+	return func(v *verifier) {
+		v.files, v.types = files, types
+	}
+}
+
+type verifier struct {
+	dir     string
+	update  bool
+	timeout time.Duration
+	ignore  []string
+	files   *protoregistry.Files
+	types   *protoregistry.Types
+}
+
+// Verify runs the cases in dir against the server of cc, one subtest per case.
+// The pending cases are skipped unless in update mode.
+func Verify(t *testing.T, cc grpc.ClientConnInterface, dir string, opts ...VerifyOption) {
+	t.Helper()
+	v := newVerifier(dir, opts...)
+	cases, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatalf("contract: no cases in %s", dir)
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(strings.TrimPrefix(c.Operation, "/")+"/"+c.Name, func(t *testing.T) {
+			if c.Pending && !v.update {
+				t.Skip("pending")
+			}
+			if err := v.verify(context.Background(), cc, c); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func newVerifier(dir string, opts ...VerifyOption) *verifier {
+	v := &verifier{
+		dir:     dir,
+		timeout: 5 * time.Second,
+		files:   protoregistry.GlobalFiles,
+		types:   protoregistry.GlobalTypes,
+	}
+	for _, o := range opts {
+		o(v)
+	}
+	return v
+}
+
+func (v *verifier) verify(ctx context.Context, cc grpc.ClientConnInterface, c *Case) error {
+	md, err := v.method(c.Operation)
+	if err != nil {
+		return err
+	}
+	req, reply := v.message(md.Input()), v.message(md.Output())
+	if err = protojson.Unmarshal(c.Request, req); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	err = cc.Invoke(ctx, c.Operation, req, reply)
+	if v.update {
+		return v.save(c, reply, err)
+	}
+
+	if c.Error != nil {
+		if err == nil {
+			return fmt.Errorf("got reply %s, want error %d %s", protojson.Format(reply), c.Error.Code, c.Error.Reason)
+		}
+		if e := errors.FromError(err); e.Code != c.Error.Code || e.Reason != c.Error.Reason {
+			return fmt.Errorf("got error %d %s, want %d %s", e.Code, e.Reason, c.Error.Code, c.Error.Reason)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("got error %v, want reply", err)
+	}
+	want := v.message(md.Output())
+	if len(c.Reply) > 0 {
+		if err = protojson.Unmarshal(c.Reply, want); err != nil {
+			return fmt.Errorf("invalid reply: %w", err)
+		}
+	}
+	for _, path := range v.ignore {
+		clearField(reply.ProtoReflect(), strings.Split(path, "."))
+		clearField(want.ProtoReflect(), strings.Split(path, "."))
+	}
+	if !proto.Equal(reply, want) {
+		return fmt.Errorf("reply mismatch\n got: %s\nwant: %s", protojson.Format(reply), protojson.Format(want))
+	}
+	return nil
+}
+
+func (v *verifier) save(c *Case, reply proto.Message, err error) error {
+	c.Pending, c.Reply, c.Error = false, nil, nil
+	if err != nil {
+		e := errors.FromError(err)
+		c.Error = &Error{Code: e.Code, Reason: e.Reason}
+	} else if c.Reply, err = protojson.Marshal(reply); err != nil {
+		return err
+	}
+	return c.Save(v.dir)
+}
+
+func (v *verifier) method(operation string) (protoreflect.MethodDescriptor, error) {
+	name := strings.TrimPrefix(operation, "/")
+	i := strings.LastIndexByte(name, '/')
+	if i < 0 {
+		return nil, fmt.Errorf("invalid operation %q", operation)
+	}
+	d, err := v.files.FindDescriptorByName(protoreflect.FullName(name[:i]))
+	if err != nil {
+		return nil, fmt.Errorf("service of %s: %w", operation, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", name[:i])
+	}
+	md := sd.Methods().ByName(protoreflect.Name(name[i+1:]))
+	if md == nil {
+		return nil, fmt.Errorf("method %s not found", operation)
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, fmt.Errorf("streaming method %s is not supported", operation)
+	}
+	return md, nil
+}
+
+func (v *verifier) message(desc protoreflect.MessageDescriptor) proto.Message {
+	if mt, err := v.types.FindMessageByName(desc.FullName()); err == nil {
+		return mt.New().Interface()
+	}
+	return dynamicpb.NewMessage(desc)
+}
+
+func clearField(m protoreflect.Message, path []string) {
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if fd == nil {
+		return
+	}
+	if len(path) == 1 {
+		m.Clear(fd)
+		return
+	}
+	if fd.Message() == nil || fd.IsMap() || !m.Has(fd) {
+		return
+	}
+	if fd.IsList() {
+		list := m.Mutable(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			clearField(list.Get(i).Message(), path[1:])
+		}
+		return
+	}
+	clearField(m.Mutable(fd).Message(), path[1:])
+}
@@ -0,0 +1,10 @@
+module github.com/go-kratos/kratos/contrib/cache/memcache/v2
+
+go 1.16
+
+require (
+	github.com/bradfitz/gomemcache v0.0.0-20220106215444-fb4bf637b56d
+	github.com/go-kratos/kratos/v2 v2.2.0
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
@@ -0,0 +1,75 @@
+package memcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/cache"
+
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+var _ cache.Store = (*Store)(nil)
+
+// maxRelative is the longest expiration memcached handles as relative,
+// longer ones are handled as Unix times.
+const maxRelative = 30 * 24 * time.Hour
+
+// Store is a cache.Store backed by memcached, the keys must be at most 250
+// bytes long without spaces or control characters. The client has no context
+// support, the calls aren't made once ctx is done and each of them is bounded
+// by the Timeout of the client instead.
+type Store struct {
+	client *memcache.Client
+}
+
+// New returns a memcached cache store.
+func New(client *memcache.Client) *Store {
+	return &Store{client: client}
+}
+
+// Get gets the value of key.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	item, err := s.client.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return nil, cache.ErrMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+// Set sets the value of key for ttl, rounded up to the second.
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.client.Set(&memcache.Item{Key: key, Value: value, Expiration: expiration(ttl)})
+}
+
+// Delete deletes keys.
+func (s *Store) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.client.Delete(key); err != nil && err != memcache.ErrCacheMiss {
+			return err
+		}
+	}
+	return nil
+}
+
+func expiration(ttl time.Duration) int32 {
+	if ttl <= 0 {
+		return 0
+	}
+	if ttl > maxRelative {
+		return int32(time.Now().Add(ttl).Unix())
+	}
+	return int32((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,37 @@
+package memcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+func TestExpiration(t *testing.T) {
+	if got := expiration(0); got != 0 {
+		t.Errorf("got %d, want 0", got)
+	}
+	if got := expiration(1500 * time.Millisecond); got != 2 {
+		t.Errorf("got %d, want the TTL rounded up to 2s", got)
+	}
+	if got := expiration(60 * 24 * time.Hour); int64(got) < time.Now().Unix() {
+		t.Errorf("got %d, want a Unix time for the long TTLs", got)
+	}
+}
+
+func TestStore_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := New(memcache.New("127.0.0.1:1"))
+	if _, err := s.Get(ctx, "key"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if err := s.Set(ctx, "key", []byte("value"), time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if err := s.Delete(ctx, "key"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/cache"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ cache.Store = (*Store)(nil)
+
+// Store is a cache.Store backed by redis.
+type Store struct {
+	client redis.UniversalClient
+}
+
+// New returns a redis cache store.
+func New(client redis.UniversalClient) *Store {
+	return &Store{client: client}
+}
+
+// Get gets the value of key.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, cache.ErrMiss
+	}
+	return data, err
+}
+
+// Set sets the value of key for ttl.
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete deletes keys.
+func (s *Store) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
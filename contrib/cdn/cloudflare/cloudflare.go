@@ -0,0 +1,111 @@
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware/surrogate"
+)
+
+var _ surrogate.Purger = (*Purger)(nil)
+
+// maxTags is the maximum number of cache tags purged by a request.
+const maxTags = 30
+
+// Option is cloudflare purger option.
+type Option func(*Purger)
+
+// WithClient with the HTTP client calling the Cloudflare API.
+func WithClient(c *http.Client) Option {
+	return func(p *Purger) {
+		p.client = c
+	}
+}
+
+// WithEndpoint with the Cloudflare API endpoint, default is https://api.cloudflare.com/client/v4.
+func WithEndpoint(endpoint string) Option {
+	return func(p *Purger) {
+		p.endpoint = endpoint
+	}
+}
+
+// Purger is a surrogate.Purger purging the cache tags of a Cloudflare zone,
+// the responses are tagged with surrogate.WithHeader("Cache-Tag", ",").
+type Purger struct {
+	zoneID   string
+	token    string
+	endpoint string
+	client   *http.Client
+}
+
+// NewPurger returns a purger of the zone, token is an API token with the Cache Purge permission.
+func NewPurger(zoneID, token string, opts ...Option) *Purger {
+	p := &Purger{
+		zoneID:   zoneID,
+		token:    token,
+		endpoint: "https://api.cloudflare.com/client/v4",
+		client:   http.DefaultClient,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// Purge purges the responses tagged with the keys.
+func (p *Purger) Purge(ctx context.Context, keys ...string) error {
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > maxTags {
+			n = maxTags
+		}
+		if err := p.purge(ctx, keys[:n]); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+type response struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (p *Purger) purge(ctx context.Context, tags []string) error {
+	body, err := json.Marshal(map[string][]string{"tags": tags})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/zones/%s/purge_cache", p.endpoint, p.zoneID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	var r response
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return fmt.Errorf("cloudflare: purge %d tags: %s: %v", len(tags), res.Status, err)
+	}
+	if !r.Success {
+		msgs := make([]string, 0, len(r.Errors))
+		for _, e := range r.Errors {
+			msgs = append(msgs, fmt.Sprintf("%d %s", e.Code, e.Message))
+		}
+		return fmt.Errorf("cloudflare: purge %d tags: %s: %s", len(tags), res.Status, strings.Join(msgs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPurge(t *testing.T) {
+	var batches []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/zones/zone/purge_cache" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"success":false,"errors":[{"code":10000,"message":"Authentication error"}]}`))
+			return
+		}
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		batches = append(batches, len(body.Tags))
+		_, _ = w.Write([]byte(`{"success":true,"errors":[],"result":{"id":"zone"}}`))
+	}))
+	defer srv.Close()
+
+	tags := make([]string, 45)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("product/%d", i)
+	}
+	if err := NewPurger("zone", "token", WithEndpoint(srv.URL)).Purge(context.Background(), tags...); err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 2 || batches[0] != 30 || batches[1] != 15 {
+		t.Errorf("batches = %v, want [30 15]", batches)
+	}
+	err := NewPurger("zone", "bad", WithEndpoint(srv.URL)).Purge(context.Background(), "product/1")
+	if err == nil || !strings.Contains(err.Error(), "Authentication error") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package fastly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/middleware/surrogate"
+)
+
+var _ surrogate.Purger = (*Purger)(nil)
+
+// maxKeys is the maximum number of surrogate keys purged by a request.
+const maxKeys = 256
+
+// Option is fastly purger option.
+type Option func(*Purger)
+
+// WithSoftPurge marks the purged content as stale instead of removing it,
+// so that it can still be served while revalidated.
+func WithSoftPurge() Option {
+	return func(p *Purger) {
+		p.soft = true
+	}
+}
+
+// WithClient with the HTTP client calling the Fastly API.
+func WithClient(c *http.Client) Option {
+	return func(p *Purger) {
+		p.client = c
+	}
+}
+
+// WithEndpoint with the Fastly API endpoint, default is https://api.fastly.com.
+func WithEndpoint(endpoint string) Option {
+	return func(p *Purger) {
+		p.endpoint = endpoint
+	}
+}
+
+// Purger is a surrogate.Purger purging the surrogate keys of a Fastly service.
+type Purger struct {
+	serviceID string
+	token     string
+	soft      bool
+	endpoint  string
+	client    *http.Client
+}
+
+// NewPurger returns a purger of the service, token is an API token with the purge_select scope.
+func NewPurger(serviceID, token string, opts ...Option) *Purger {
+	p := &Purger{
+		serviceID: serviceID,
+		token:     token,
+		endpoint:  "https://api.fastly.com",
+		client:    http.DefaultClient,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// Purge purges the responses tagged with the keys.
+func (p *Purger) Purge(ctx context.Context, keys ...string) error {
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > maxKeys {
+			n = maxKeys
+		}
+		if err := p.purge(ctx, keys[:n]); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+func (p *Purger) purge(ctx context.Context, keys []string) error {
+	body, err := json.Marshal(map[string][]string{"surrogate_keys": keys})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/service/%s/purge", p.endpoint, p.serviceID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Fastly-Key", p.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if p.soft {
+		req.Header.Set("Fastly-Soft-Purge", "1")
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("fastly: purge %d keys: %s: %s", len(keys), res.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
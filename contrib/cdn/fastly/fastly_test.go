@@ -0,0 +1,42 @@
+package fastly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPurge(t *testing.T) {
+	var batches []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/service/svc/purge" || r.Header.Get("Fastly-Key") != "token" || r.Header.Get("Fastly-Soft-Purge") != "1" {
+			http.Error(w, `{"msg":"bad request"}`, http.StatusBadRequest)
+			return
+		}
+		var body struct {
+			Keys []string `json:"surrogate_keys"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		batches = append(batches, len(body.Keys))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	keys := make([]string, 300)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("product/%d", i)
+	}
+	p := NewPurger("svc", "token", WithEndpoint(srv.URL), WithSoftPurge())
+	if err := p.Purge(context.Background(), keys...); err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 2 || batches[0] != 256 || batches[1] != 44 {
+		t.Errorf("batches = %v, want [256 44]", batches)
+	}
+	if err := NewPurger("other", "token", WithEndpoint(srv.URL)).Purge(context.Background(), "product/1"); err == nil {
+		t.Error("expected an error")
+	}
+}
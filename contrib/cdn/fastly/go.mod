@@ -0,0 +1,7 @@
+module github.com/go-kratos/kratos/contrib/cdn/fastly/v2
+
+go 1.16
+
+require github.com/go-kratos/kratos/v2 v2.2.0
+
+replace github.com/go-kratos/kratos/v2 => ../../../
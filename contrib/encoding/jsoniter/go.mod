@@ -0,0 +1,11 @@
+module github.com/go-kratos/kratos/contrib/encoding/jsoniter/v2
+
+go 1.16
+
+require (
+	github.com/go-kratos/kratos/v2 v2.2.0
+	github.com/json-iterator/go v1.1.12
+	google.golang.org/protobuf v1.27.1
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
@@ -0,0 +1,18 @@
+// Package jsoniter replaces encoding/json by jsoniter in the json codec,
+// import it for its side effect:
+//
+//	import _ "github.com/go-kratos/kratos/contrib/encoding/jsoniter/v2"
+//
+// Proto messages are still encoded by protojson.
+package jsoniter
+
+import (
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/go-kratos/kratos/v2/encoding/json"
+)
+
+func init() {
+	api := jsoniter.ConfigCompatibleWithStandardLibrary
+	json.SetEngine(api.Marshal, api.Unmarshal)
+}
@@ -0,0 +1,88 @@
+package jsoniter
+
+import (
+	stdjson "encoding/json"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/encoding/json"
+)
+
+type user struct {
+	ID      int64             `json:"id,string"`
+	Name    string            `json:"name"`
+	Tags    []string          `json:"tags,omitempty"`
+	Attrs   map[string]string `json:"attrs"`
+	Created time.Time         `json:"created"`
+}
+
+func TestProtoSemantics(t *testing.T) {
+	codec := encoding.GetCodec(json.Name)
+	st, _ := structpb.NewStruct(map[string]interface{}{"a": 1.5, "b": []interface{}{"c"}})
+	tests := []proto.Message{
+		timestamppb.New(time.Unix(1600000000, 500)),
+		durationpb.New(1500 * time.Millisecond),
+		wrapperspb.Int64(1 << 60),
+		st,
+	}
+	for _, m := range tests {
+		got, err := codec.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := json.MarshalOptions.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("marshal %T: have %s, want %s", m, got, want)
+		}
+		out := m.ProtoReflect().New().Interface()
+		if err := codec.Unmarshal(got, out); err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(m, out) {
+			t.Errorf("unmarshal %T: have %v, want %v", m, out, m)
+		}
+		if err := protojson.Unmarshal(got, out); err != nil {
+			t.Errorf("unmarshal %T by protojson: %v", m, err)
+		}
+	}
+}
+
+func TestStdlibCompatible(t *testing.T) {
+	codec := encoding.GetCodec(json.Name)
+	in := &user{ID: 1, Name: "kratos", Attrs: map[string]string{"b": "2", "a": "1"}, Created: time.Unix(1600000000, 0).UTC()}
+	got, err := codec.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := stdjson.Marshal(in)
+	if string(got) != string(want) {
+		t.Errorf("have %s, want %s", got, want)
+	}
+	var out user
+	if err := codec.Unmarshal(got, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.ID != in.ID || out.Name != in.Name || out.Attrs["a"] != "1" || !out.Created.Equal(in.Created) {
+		t.Errorf("have %+v, want %+v", out, in)
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	codec := encoding.GetCodec(json.Name)
+	in := &user{ID: 1, Name: "kratos", Tags: []string{"a", "b"}, Attrs: map[string]string{"a": "1"}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = codec.Marshal(in)
+	}
+}
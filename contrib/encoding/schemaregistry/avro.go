@@ -0,0 +1,98 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hamba/avro"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+var _ encoding.Codec = (*AvroCodec)(nil)
+
+// AvroCodec is a codec of the Avro messages of a subject, the values are
+// structs with avro tags or maps.
+type AvroCodec struct {
+	*serde
+	parsed avro.Schema
+
+	mu      sync.RWMutex
+	writers map[int]avro.Schema
+}
+
+// NewAvroCodec new an Avro codec writing the messages of subject with schema,
+// e.g. the subject of the values of a topic is "<topic>-value".
+func NewAvroCodec(c *Client, subject, schema string, opts ...Option) (*AvroCodec, error) {
+	parsed, err := parseAvro(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &AvroCodec{
+		serde:   newSerde(c, subject, Schema{Type: Avro, Schema: schema}, opts),
+		parsed:  parsed,
+		writers: make(map[int]avro.Schema),
+	}, nil
+}
+
+// Marshal encodes v with the schema of the codec.
+func (c *AvroCodec) Marshal(v interface{}) ([]byte, error) {
+	header, err := c.header()
+	if err != nil {
+		return nil, err
+	}
+	data, err := avro.Marshal(c.parsed, v)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, data...), nil
+}
+
+// Unmarshal decodes data written with a schema compatible with the schema of
+// the codec, the writer schema is resolved against it.
+func (c *AvroCodec) Unmarshal(data []byte, v interface{}) error {
+	id, schema, payload, err := c.writerSchema(data)
+	if err != nil {
+		return err
+	}
+	c.mu.RLock()
+	writer, ok := c.writers[id]
+	c.mu.RUnlock()
+	if !ok {
+		if writer, err = parseAvro(schema.Schema); err != nil {
+			return err
+		}
+		if err = compatibility.Compatible(c.parsed, writer); err != nil {
+			return fmt.Errorf("%w: schema %d can't be read: %v", ErrIncompatible, id, err)
+		}
+		c.mu.Lock()
+		c.writers[id] = writer
+		c.mu.Unlock()
+	}
+	if writer.Fingerprint() == c.parsed.Fingerprint() {
+		return avro.Unmarshal(c.parsed, payload, v)
+	}
+	var generic interface{}
+	if err = avro.Unmarshal(writer, payload, &generic); err != nil {
+		return err
+	}
+	resolved, err := resolve(c.parsed, writer, generic)
+	if err != nil {
+		return err
+	}
+	if data, err = avro.Marshal(c.parsed, resolved); err != nil {
+		return err
+	}
+	return avro.Unmarshal(c.parsed, data, v)
+}
+
+// Name returns the name of the codec.
+func (c *AvroCodec) Name() string {
+	return "avro"
+}
+
+// parseAvro parses schema with its own cache, so that the versions of the
+// same named type don't collide.
+func parseAvro(schema string) (avro.Schema, error) {
+	return avro.ParseWithCache(schema, "", &avro.SchemaCache{})
+}
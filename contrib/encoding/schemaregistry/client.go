@@ -0,0 +1,208 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The schema types.
+const (
+	Avro     = "AVRO"
+	Protobuf = "PROTOBUF"
+)
+
+// Schema is a schema of the registry.
+type Schema struct {
+	Type   string
+	Schema string
+}
+
+// ClientOption is schema registry client option.
+type ClientOption func(*Client)
+
+// WithHTTPClient with the HTTP client, default has a 10s timeout.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(o *Client) {
+		o.hc = c
+	}
+}
+
+// WithBasicAuth with the basic auth credentials, e.g. the API key and secret.
+func WithBasicAuth(username, password string) ClientOption {
+	return func(o *Client) {
+		o.username, o.password = username, password
+	}
+}
+
+type subjectSchema struct {
+	subject string
+	schema  Schema
+}
+
+// Client is a Confluent Schema Registry client caching the schemas and their IDs.
+type Client struct {
+	url      string
+	hc       *http.Client
+	username string
+	password string
+
+	mu      sync.RWMutex
+	schemas map[int]Schema
+	ids     map[subjectSchema]int
+}
+
+// NewClient new a schema registry client of the registry at url.
+func NewClient(url string, opts ...ClientOption) *Client {
+	c := &Client{
+		url:     strings.TrimSuffix(url, "/"),
+		hc:      &http.Client{Timeout: 10 * time.Second},
+		schemas: make(map[int]Schema),
+		ids:     make(map[subjectSchema]int),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type schemaRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+type schemaResponse struct {
+	ID           int    `json:"id"`
+	Schema       string `json:"schema"`
+	SchemaType   string `json:"schemaType"`
+	IsCompatible bool   `json:"is_compatible"`
+}
+
+// Error is an error returned by the registry.
+type Error struct {
+	StatusCode int
+	Code       int    `json:"error_code"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("schemaregistry: %d %s", e.Code, e.Message)
+}
+
+// Register registers schema under subject, it returns the ID of the schema,
+// the existing one if already registered.
+func (c *Client) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	return c.id(ctx, "/subjects/"+url.PathEscape(subject)+"/versions", subject, schema)
+}
+
+// Lookup returns the ID of schema registered under subject.
+func (c *Client) Lookup(ctx context.Context, subject string, schema Schema) (int, error) {
+	return c.id(ctx, "/subjects/"+url.PathEscape(subject), subject, schema)
+}
+
+func (c *Client) id(ctx context.Context, path, subject string, schema Schema) (int, error) {
+	key := subjectSchema{subject: subject, schema: schema}
+	c.mu.RLock()
+	id, ok := c.ids[key]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+	var res schemaResponse
+	if err := c.do(ctx, http.MethodPost, path, request(schema), &res); err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.ids[key] = res.ID
+	c.schemas[res.ID] = schema
+	c.mu.Unlock()
+	return res.ID, nil
+}
+
+// SchemaByID returns the schema of id.
+func (c *Client) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.schemas[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+	var res schemaResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &res); err != nil {
+		return Schema{}, err
+	}
+	schema = Schema{Type: res.SchemaType, Schema: res.Schema}
+	if schema.Type == "" {
+		schema.Type = Avro
+	}
+	c.mu.Lock()
+	c.schemas[id] = schema
+	c.mu.Unlock()
+	return schema, nil
+}
+
+// Compatible reports whether schema is compatible with the latest version
+// of subject, according to the compatibility level of subject. A schema is
+// compatible with a subject not registered yet.
+func (c *Client) Compatible(ctx context.Context, subject string, schema Schema) (bool, error) {
+	var res schemaResponse
+	err := c.do(ctx, http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/latest", request(schema), &res)
+	if e, ok := err.(*Error); ok && e.StatusCode == http.StatusNotFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return res.IsCompatible, nil
+}
+
+func request(schema Schema) *schemaRequest {
+	req := &schemaRequest{Schema: schema.Schema}
+	// AVRO is the default type of the registry.
+	if schema.Type != Avro {
+		req.SchemaType = schema.Type
+	}
+	return req
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	res, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		e := &Error{StatusCode: res.StatusCode}
+		if err = json.NewDecoder(res.Body).Decode(e); err != nil {
+			e.Message = res.Status
+		}
+		return e
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,129 @@
+// Package schemaregistry implements the codecs of the Confluent Schema
+// Registry wire format, a magic byte and the schema ID before the payload, so
+// that the messages interoperate with the Confluent serializers.
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const magic byte = 0
+
+// ErrInvalidMessage is returned when a message isn't in the wire format.
+var ErrInvalidMessage = errors.New("schemaregistry: invalid message")
+
+// ErrIncompatible is returned when the schema isn't compatible with the latest version of the subject.
+var ErrIncompatible = errors.New("schemaregistry: incompatible schema")
+
+// Option is codec option.
+type Option func(*options)
+
+type options struct {
+	autoRegister bool
+	checkCompat  bool
+	timeout      time.Duration
+}
+
+// WithAutoRegister with whether the schema is registered on the first
+// message, else it must be registered already, default is true.
+func WithAutoRegister(enable bool) Option {
+	return func(o *options) {
+		o.autoRegister = enable
+	}
+}
+
+// WithCompatibilityCheck checks that the schema is compatible with the latest
+// version of the subject before the first message, failing with ErrIncompatible.
+func WithCompatibilityCheck() Option {
+	return func(o *options) {
+		o.checkCompat = true
+	}
+}
+
+// WithTimeout with the timeout of the registry requests, default is 5s.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// serde resolves the schema of the messages written and read.
+type serde struct {
+	client  *Client
+	subject string
+	schema  Schema
+	opts    options
+
+	mu sync.Mutex
+	id int
+}
+
+func newSerde(c *Client, subject string, schema Schema, opts []Option) *serde {
+	o := options{autoRegister: true, timeout: 5 * time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &serde{client: c, subject: subject, schema: schema, opts: o}
+}
+
+// schemaID returns the ID of the schema written, resolved on the first message.
+func (s *serde) schemaID() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.id > 0 {
+		return s.id, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.timeout)
+	defer cancel()
+	if s.opts.checkCompat {
+		ok, err := s.client.Compatible(ctx, s.subject, s.schema)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, fmt.Errorf("%w: subject %s", ErrIncompatible, s.subject)
+		}
+	}
+	var err error
+	if s.opts.autoRegister {
+		s.id, err = s.client.Register(ctx, s.subject, s.schema)
+	} else {
+		s.id, err = s.client.Lookup(ctx, s.subject, s.schema)
+	}
+	return s.id, err
+}
+
+// header returns the header of the messages written.
+func (s *serde) header() ([]byte, error) {
+	id, err := s.schemaID()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 5, 64)
+	b[0] = magic
+	binary.BigEndian.PutUint32(b[1:], uint32(id))
+	return b, nil
+}
+
+// writerSchema returns the schema a message was written with and its payload.
+func (s *serde) writerSchema(data []byte) (int, Schema, []byte, error) {
+	if len(data) < 5 || data[0] != magic {
+		return 0, Schema{}, nil, ErrInvalidMessage
+	}
+	id := int(binary.BigEndian.Uint32(data[1:5]))
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.timeout)
+	defer cancel()
+	schema, err := s.client.SchemaByID(ctx, id)
+	if err != nil {
+		return 0, Schema{}, nil, err
+	}
+	if schema.Type != s.schema.Type {
+		return 0, Schema{}, nil, fmt.Errorf("%w: schema %d is %s", ErrInvalidMessage, id, schema.Type)
+	}
+	return id, schema, data[5:], nil
+}
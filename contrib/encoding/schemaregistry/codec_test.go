@@ -0,0 +1,202 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// registry is a fake schema registry.
+type registry struct {
+	mu       sync.Mutex
+	schemas  []schemaRequest
+	subjects map[string][]int
+}
+
+func newRegistry() *httptest.Server {
+	r := &registry{subjects: make(map[string][]int)}
+	return httptest.NewServer(r)
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var in schemaRequest
+	if req.Body != nil {
+		_ = json.NewDecoder(req.Body).Decode(&in)
+	}
+	path := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 3 && path[0] == "schemas":
+		var id int
+		fmt.Sscan(path[2], &id)
+		if id < 1 || id > len(r.schemas) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+			return
+		}
+		s := r.schemas[id-1]
+		_ = json.NewEncoder(w).Encode(schemaResponse{Schema: s.Schema, SchemaType: s.SchemaType})
+	case len(path) == 3 && path[0] == "subjects":
+		for i, s := range r.schemas {
+			if s == in {
+				_ = json.NewEncoder(w).Encode(schemaResponse{ID: i + 1})
+				return
+			}
+		}
+		r.schemas = append(r.schemas, in)
+		r.subjects[path[1]] = append(r.subjects[path[1]], len(r.schemas))
+		_ = json.NewEncoder(w).Encode(schemaResponse{ID: len(r.schemas)})
+	case len(path) == 2 && path[0] == "subjects":
+		for _, id := range r.subjects[path[1]] {
+			if r.schemas[id-1] == in {
+				_ = json.NewEncoder(w).Encode(schemaResponse{ID: id})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+	case path[0] == "compatibility":
+		if len(r.subjects[path[2]]) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found"}`))
+			return
+		}
+		// the fake registry only accepts the schemas with a default.
+		_ = json.NewEncoder(w).Encode(schemaResponse{IsCompatible: strings.Contains(in.Schema, "default")})
+	}
+}
+
+type user struct {
+	Name string `avro:"name"`
+	Age  int    `avro:"age"`
+}
+
+const userSchema = `{"type":"record","name":"User","fields":[{"name":"name","type":"string"},{"name":"age","type":"int"}]}`
+
+func TestAvro(t *testing.T) {
+	ts := newRegistry()
+	defer ts.Close()
+	client := NewClient(ts.URL)
+	codec, err := NewAvroCodec(client, "users-value", userSchema, WithCompatibilityCheck())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := codec.Marshal(&user{Name: "kratos", Age: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[:5], []byte{0, 0, 0, 0, 1}) {
+		t.Errorf("unexpected header %v", data[:5])
+	}
+	// a consumer with its own client resolves the writer schema by ID.
+	consumer, _ := NewAvroCodec(NewClient(ts.URL), "users-value", userSchema)
+	var u user
+	if err = consumer.Unmarshal(data, &u); err != nil || u.Name != "kratos" || u.Age != 5 {
+		t.Errorf("got %+v %v", u, err)
+	}
+	if err = consumer.Unmarshal([]byte("json"), &u); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expect invalid message, got %v", err)
+	}
+
+	incompatible, _ := NewAvroCodec(client, "users-value", `{"type":"record","name":"User","fields":[{"name":"email","type":"string"}]}`, WithCompatibilityCheck())
+	if _, err = incompatible.Marshal(map[string]interface{}{"email": "a@b.c"}); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("expect incompatible schema, got %v", err)
+	}
+	unregistered, _ := NewAvroCodec(client, "others-value", userSchema, WithAutoRegister(false))
+	var e *Error
+	if _, err = unregistered.Marshal(&user{}); !errors.As(err, &e) || e.Code != 40403 {
+		t.Errorf("expect schema not found, got %v", err)
+	}
+}
+
+func TestProtobuf(t *testing.T) {
+	ts := newRegistry()
+	defer ts.Close()
+	codec := NewProtobufCodec(NewClient(ts.URL), "names-value", `syntax = "proto3"; message StringValue { string value = 1; }`)
+	data, err := codec.Marshal(wrapperspb.String("kratos"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// StringValue is the 8th message of wrappers.proto.
+	if !bytes.Equal(data[:7], []byte{0, 0, 0, 0, 1, 2, 14}) {
+		t.Errorf("unexpected header %v", data[:7])
+	}
+	var v wrapperspb.StringValue
+	if err = codec.Unmarshal(data, &v); err != nil || v.Value != "kratos" {
+		t.Errorf("got %v %v", v.Value, err)
+	}
+	if b := appendIndexes(nil, (&wrapperspb.DoubleValue{}).ProtoReflect().Descriptor()); !bytes.Equal(b, []byte{0}) {
+		t.Errorf("expect the first message as 0, got %v", b)
+	}
+	avro, _ := NewAvroCodec(NewClient(ts.URL), "users-value", userSchema)
+	if err = avro.Unmarshal(data, &user{}); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expect the protobuf schema rejected, got %v", err)
+	}
+}
+
+type userV2 struct {
+	Name  string   `avro:"name"`
+	Age   int64    `avro:"age"`
+	Email string   `avro:"email"`
+	Nick  *string  `avro:"nick"`
+	Tags  []string `avro:"tags"`
+}
+
+const userV2Schema = `{"type":"record","name":"User","fields":[
+	{"name":"name","type":"string"},
+	{"name":"age","type":"long"},
+	{"name":"email","type":"string","default":"none"},
+	{"name":"nick","type":["null","string"],"default":null},
+	{"name":"tags","type":{"type":"array","items":"string"},"default":[]}
+]}`
+
+func TestAvro_Resolve(t *testing.T) {
+	ts := newRegistry()
+	defer ts.Close()
+	producer, err := NewAvroCodec(NewClient(ts.URL), "users-value", userSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := producer.Marshal(&user{Name: "kratos", Age: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	consumer, err := NewAvroCodec(NewClient(ts.URL), "users-value", userV2Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var u userV2
+	if err = consumer.Unmarshal(data, &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "kratos" || u.Age != 5 || u.Email != "none" || u.Nick != nil || len(u.Tags) != 0 {
+		t.Errorf("unexpected user %+v", u)
+	}
+
+	// the fields the reader doesn't know are dropped.
+	nick := "k"
+	if data, err = consumer.Marshal(&userV2{Name: "kratos", Age: 6, Email: "a@b.c", Nick: &nick, Tags: []string{"go"}}); err != nil {
+		t.Fatal(err)
+	}
+	var old user
+	if err = producer.Unmarshal(data, &old); err == nil {
+		t.Fatal("expect the long age not to be read as an int")
+	}
+	reader, _ := NewAvroCodec(NewClient(ts.URL), "users-value", `{"type":"record","name":"User","fields":[{"name":"nick","type":["null","string"]},{"name":"age","type":"double"}]}`)
+	var m map[string]interface{}
+	if err = reader.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["age"] != float64(6) || m["nick"] != "k" || len(m) != 2 {
+		t.Errorf("unexpected user %v", m)
+	}
+}
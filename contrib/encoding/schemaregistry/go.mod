@@ -0,0 +1,11 @@
+module github.com/go-kratos/kratos/contrib/encoding/schemaregistry/v2
+
+go 1.16
+
+require (
+	github.com/go-kratos/kratos/v2 v2.2.0
+	github.com/hamba/avro v1.6.6
+	google.golang.org/protobuf v1.27.1
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
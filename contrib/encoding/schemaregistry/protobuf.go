@@ -0,0 +1,102 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+var _ encoding.Codec = (*ProtobufCodec)(nil)
+
+// ProtobufCodec is a codec of the protobuf messages of a subject.
+type ProtobufCodec struct {
+	*serde
+}
+
+// NewProtobufCodec new a protobuf codec writing the messages of subject, the
+// schema is the source of the .proto file of the messages, e.g. embedded with
+// go:embed.
+func NewProtobufCodec(c *Client, subject, schema string, opts ...Option) *ProtobufCodec {
+	return &ProtobufCodec{serde: newSerde(c, subject, Schema{Type: Protobuf, Schema: schema}, opts)}
+}
+
+// Marshal encodes the proto message v, the header is followed by the indexes
+// of the message in the .proto file.
+func (c *ProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("schemaregistry: %T isn't a proto message", v)
+	}
+	header, err := c.header()
+	if err != nil {
+		return nil, err
+	}
+	return proto.MarshalOptions{}.MarshalAppend(appendIndexes(header, m.ProtoReflect().Descriptor()), m)
+}
+
+// Unmarshal decodes data into the proto message v.
+func (c *ProtobufCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("schemaregistry: %T isn't a proto message", v)
+	}
+	_, _, payload, err := c.writerSchema(data)
+	if err != nil {
+		return err
+	}
+	if payload, err = skipIndexes(payload); err != nil {
+		return err
+	}
+	return proto.Unmarshal(payload, m)
+}
+
+// Name returns the name of the codec.
+func (c *ProtobufCodec) Name() string {
+	return "protobuf"
+}
+
+// appendIndexes appends the path of the message in its file, the first
+// message of the file is written as a single 0.
+func appendIndexes(b []byte, md protoreflect.MessageDescriptor) []byte {
+	var indexes []int
+	for d := protoreflect.Descriptor(md); ; {
+		indexes = append([]int{d.Index()}, indexes...)
+		parent, ok := d.Parent().(protoreflect.MessageDescriptor)
+		if !ok {
+			break
+		}
+		d = parent
+	}
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(b, 0)
+	}
+	b = appendVarint(b, int64(len(indexes)))
+	for _, i := range indexes {
+		b = appendVarint(b, int64(i))
+	}
+	return b
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutVarint(buf[:], v)]...)
+}
+
+func skipIndexes(data []byte) ([]byte, error) {
+	n, size := binary.Varint(data)
+	if size <= 0 || n < 0 {
+		return nil, ErrInvalidMessage
+	}
+	data = data[size:]
+	for i := int64(0); i < n; i++ {
+		if _, size = binary.Varint(data); size <= 0 {
+			return nil, ErrInvalidMessage
+		}
+		data = data[size:]
+	}
+	return data, nil
+}
@@ -0,0 +1,222 @@
+package schemaregistry
+
+import (
+	"fmt"
+
+	"github.com/hamba/avro"
+)
+
+var (
+	compatibility = avro.NewSchemaCompatibility()
+	nullSchema    = avro.NewPrimitiveSchema(avro.Null, nil)
+)
+
+// resolve converts v, decoded generically with the writer schema, to the
+// reader schema following the Avro schema resolution: the fields missing in
+// the writer are set to their defaults, the fields missing in the reader are
+// dropped and the numbers are promoted.
+func resolve(reader, writer avro.Schema, v interface{}) (interface{}, error) {
+	reader, writer = deref(reader), deref(writer)
+	if u, ok := writer.(*avro.UnionSchema); ok {
+		if v == nil {
+			return resolve(reader, nullSchema, nil)
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return nil, fmt.Errorf("avro: invalid value %v of union %s", v, writer)
+		}
+		for name, val := range m {
+			branch, _ := u.Types().Get(name)
+			if branch == nil {
+				return nil, fmt.Errorf("avro: unknown branch %s of union %s", name, writer)
+			}
+			return resolve(reader, branch, val)
+		}
+	}
+	if u, ok := reader.(*avro.UnionSchema); ok {
+		branch := unionBranch(u, writer)
+		if branch == nil {
+			return nil, fmt.Errorf("avro: no branch of union %s matches %s", reader, writer)
+		}
+		if branch.Type() == avro.Null {
+			return nil, nil
+		}
+		val, err := resolve(branch, writer, v)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{typeName(branch): val}, nil
+	}
+	switch r := reader.(type) {
+	case *avro.RecordSchema:
+		w, ok := writer.(*avro.RecordSchema)
+		rec, ok2 := v.(map[string]interface{})
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("avro: can't resolve %s as record %s", writer, r.FullName())
+		}
+		written := make(map[string]*avro.Field, len(w.Fields()))
+		for _, f := range w.Fields() {
+			written[f.Name()] = f
+		}
+		out := make(map[string]interface{}, len(r.Fields()))
+		for _, f := range r.Fields() {
+			if wf, ok := written[f.Name()]; ok {
+				val, err := resolve(f.Type(), wf.Type(), rec[f.Name()])
+				if err != nil {
+					return nil, err
+				}
+				out[f.Name()] = val
+				continue
+			}
+			if !f.HasDefault() {
+				return nil, fmt.Errorf("avro: field %s of %s isn't written and has no default", f.Name(), r.FullName())
+			}
+			out[f.Name()] = defaultValue(f.Type(), f.Default())
+		}
+		return out, nil
+	case *avro.ArraySchema:
+		w, ok := writer.(*avro.ArraySchema)
+		items, ok2 := v.([]interface{})
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("avro: can't resolve %s as array", writer)
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			val, err := resolve(r.Items(), w.Items(), item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = val
+		}
+		return out, nil
+	case *avro.MapSchema:
+		w, ok := writer.(*avro.MapSchema)
+		values, ok2 := v.(map[string]interface{})
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("avro: can't resolve %s as map", writer)
+		}
+		out := make(map[string]interface{}, len(values))
+		for k, value := range values {
+			val, err := resolve(r.Values(), w.Values(), value)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = val
+		}
+		return out, nil
+	case *avro.EnumSchema:
+		for _, symbol := range r.Symbols() {
+			if symbol == v {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("avro: unknown symbol %v of enum %s", v, r.FullName())
+	}
+	return promote(reader.Type(), v), nil
+}
+
+// unionBranch returns the branch of u the values of writer are resolved to,
+// the branch of the same type first.
+func unionBranch(u *avro.UnionSchema, writer avro.Schema) avro.Schema {
+	if branch, _ := u.Types().Get(typeName(writer)); branch != nil {
+		return branch
+	}
+	for _, branch := range u.Types() {
+		if compatibility.Compatible(branch, writer) == nil {
+			return branch
+		}
+	}
+	return nil
+}
+
+// defaultValue returns the default def of a field of schema as a generic value.
+func defaultValue(schema avro.Schema, def interface{}) interface{} {
+	switch s := deref(schema).(type) {
+	case *avro.UnionSchema:
+		if def == nil {
+			return nil
+		}
+		first := s.Types()[0]
+		return map[string]interface{}{typeName(first): defaultValue(first, def)}
+	case *avro.RecordSchema:
+		m, _ := def.(map[string]interface{})
+		out := make(map[string]interface{}, len(s.Fields()))
+		for _, f := range s.Fields() {
+			out[f.Name()] = defaultValue(f.Type(), m[f.Name()])
+		}
+		return out
+	case *avro.ArraySchema:
+		items, _ := def.([]interface{})
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			out[i] = defaultValue(s.Items(), item)
+		}
+		return out
+	case *avro.MapSchema:
+		values, _ := def.(map[string]interface{})
+		out := make(map[string]interface{}, len(values))
+		for k, value := range values {
+			out[k] = defaultValue(s.Values(), value)
+		}
+		return out
+	}
+	if str, ok := def.(string); ok && schema.Type() == avro.Bytes {
+		return []byte(str)
+	}
+	return def
+}
+
+// promote promotes the generic value v to the primitive type t.
+func promote(t avro.Type, v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		switch t {
+		case avro.Long:
+			return int64(v)
+		case avro.Float:
+			return float32(v)
+		case avro.Double:
+			return float64(v)
+		}
+	case int64:
+		switch t {
+		case avro.Float:
+			return float32(v)
+		case avro.Double:
+			return float64(v)
+		}
+	case float32:
+		if t == avro.Double {
+			return float64(v)
+		}
+	case string:
+		if t == avro.Bytes {
+			return []byte(v)
+		}
+	case []byte:
+		if t == avro.String {
+			return string(v)
+		}
+	}
+	return v
+}
+
+func deref(schema avro.Schema) avro.Schema {
+	if ref, ok := schema.(*avro.RefSchema); ok {
+		return ref.Schema()
+	}
+	return schema
+}
+
+// typeName returns the name of schema in the generic values of the unions.
+func typeName(schema avro.Schema) string {
+	schema = deref(schema)
+	if n, ok := schema.(avro.NamedSchema); ok {
+		return n.FullName()
+	}
+	name := string(schema.Type())
+	if ls, ok := schema.(avro.LogicalTypeSchema); ok && ls.Logical() != nil {
+		name += "." + string(ls.Logical().Type())
+	}
+	return name
+}
@@ -0,0 +1,111 @@
+package etcd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/lock"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var _ lock.Driver = (*Driver)(nil)
+
+// Option is etcd driver option.
+type Option func(*Driver)
+
+// WithPrefix with the key prefix of the locks, default is "/kratos/lock/".
+func WithPrefix(prefix string) Option {
+	return func(d *Driver) {
+		d.prefix = prefix
+	}
+}
+
+// Driver is a lock.Driver backed by etcd, the locks are the keys holding the
+// token of their owner, attached to a lease granted for the TTL.
+type Driver struct {
+	client *clientv3.Client
+	prefix string
+
+	mu     sync.Mutex
+	leases map[string]clientv3.LeaseID
+}
+
+// New returns an etcd lock driver.
+func New(client *clientv3.Client, opts ...Option) *Driver {
+	d := &Driver{
+		client: client,
+		prefix: "/kratos/lock/",
+		leases: make(map[string]clientv3.LeaseID),
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+// Acquire acquires key for ttl, rounded up to the second.
+func (d *Driver) Acquire(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	lease, err := d.client.Grant(ctx, int64((ttl+time.Second-1)/time.Second))
+	if err != nil {
+		return false, err
+	}
+	k := d.prefix + key
+	resp, err := d.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(k), "=", 0)).
+		Then(clientv3.OpPut(k, token, clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil || !resp.Succeeded {
+		_, _ = d.client.Revoke(context.Background(), lease.ID)
+		return false, err
+	}
+	d.mu.Lock()
+	d.leases[token] = lease.ID
+	d.mu.Unlock()
+	return true, nil
+}
+
+// Refresh keeps the lease of key alive if it is held by token, the lease
+// keeps its TTL.
+func (d *Driver) Refresh(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	d.mu.Lock()
+	id, ok := d.leases[token]
+	d.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	resp, err := d.client.KeepAliveOnce(ctx, id)
+	if err == rpctypes.ErrLeaseNotFound || (err == nil && resp.TTL <= 0) {
+		// the lock is lost and never released, so its lease is forgotten here.
+		d.forget(token, id)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// forget forgets the lease of token if it is still id.
+func (d *Driver) forget(token string, id clientv3.LeaseID) {
+	d.mu.Lock()
+	if d.leases[token] == id {
+		delete(d.leases, token)
+	}
+	d.mu.Unlock()
+}
+
+// Release releases key if it is held by token, revoking its lease.
+func (d *Driver) Release(ctx context.Context, key, token string) error {
+	d.mu.Lock()
+	id, ok := d.leases[token]
+	delete(d.leases, token)
+	d.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	_, err := d.client.Revoke(ctx, id)
+	return err
+}
@@ -0,0 +1,11 @@
+module github.com/go-kratos/kratos/contrib/lock/etcd/v2
+
+go 1.16
+
+require (
+	github.com/go-kratos/kratos/v2 v2.2.0
+	go.etcd.io/etcd/api/v3 v3.5.0
+	go.etcd.io/etcd/client/v3 v3.5.0
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
@@ -0,0 +1,10 @@
+module github.com/go-kratos/kratos/contrib/lock/redis/v2
+
+go 1.16
+
+require (
+	github.com/go-kratos/kratos/v2 v2.2.0
+	github.com/go-redis/redis/v8 v8.11.4
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
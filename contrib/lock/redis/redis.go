@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/lock"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ lock.Driver = (*Driver)(nil)
+
+var (
+	refresh = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) end return 0`)
+	release = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+)
+
+// Option is redis driver option.
+type Option func(*Driver)
+
+// WithPrefix with the key prefix of the locks, default is "kratos:lock:".
+func WithPrefix(prefix string) Option {
+	return func(d *Driver) {
+		d.prefix = prefix
+	}
+}
+
+// Driver is a lock.Driver backed by a single redis, the locks are the keys
+// holding the token of their owner.
+type Driver struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// New returns a redis lock driver.
+func New(client redis.UniversalClient, opts ...Option) *Driver {
+	d := &Driver{
+		client: client,
+		prefix: "kratos:lock:",
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+// Acquire acquires key for ttl.
+func (d *Driver) Acquire(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return d.client.SetNX(ctx, d.prefix+key, token, ttl).Result()
+}
+
+// Refresh extends the ttl of key if it is held by token.
+func (d *Driver) Refresh(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	n, err := refresh.Run(ctx, d.client, []string{d.prefix + key}, token, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// Release releases key if it is held by token.
+func (d *Driver) Release(ctx context.Context, key, token string) error {
+	return release.Run(ctx, d.client, []string{d.prefix + key}, token).Err()
+}
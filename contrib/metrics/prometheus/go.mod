@@ -4,7 +4,8 @@ go 1.16
 
 require (
 	github.com/go-kratos/kratos/v2 v2.2.0
-	github.com/prometheus/client_golang v1.9.0
+	github.com/prometheus/client_golang v1.13.0
+	github.com/prometheus/common v0.37.0
 )
 
 replace github.com/go-kratos/kratos/v2 => ../../../
@@ -0,0 +1,91 @@
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/prometheus/common/expfmt"
+)
+
+var _ transport.Server = (*Pusher)(nil)
+
+// PusherOption is pusher option.
+type PusherOption func(*Pusher)
+
+// WithGatherer with the gatherer collecting pushed metrics, default is prometheus.DefaultGatherer.
+func WithGatherer(g prometheus.Gatherer) PusherOption {
+	return func(p *Pusher) {
+		p.pusher.Gatherer(g)
+	}
+}
+
+// WithGrouping with a grouping label of pushed metrics, e.g. the instance.
+func WithGrouping(name, value string) PusherOption {
+	return func(p *Pusher) {
+		p.pusher.Grouping(name, value)
+	}
+}
+
+// WithInterval with the interval metrics are pushed at while running,
+// default is 0, metrics are only pushed on stop.
+func WithInterval(d time.Duration) PusherOption {
+	return func(p *Pusher) {
+		p.interval = d
+	}
+}
+
+// Pusher pushes metrics of short-lived batch jobs to a Prometheus Pushgateway.
+// It is a transport.Server, register it with the app so the final metrics are
+// pushed when the job stops:
+//
+//	app := kratos.New(kratos.Server(prometheus.NewPusher("http://pushgateway:9091", "job")))
+//
+// Metrics are pushed in the text format, which carries no exemplars.
+type Pusher struct {
+	pusher   *push.Pusher
+	interval time.Duration
+}
+
+// NewPusher new a Pushgateway pusher for job.
+func NewPusher(url, job string, opts ...PusherOption) *Pusher {
+	p := &Pusher{
+		pusher: push.New(url, job).
+			Gatherer(prometheus.DefaultGatherer).
+			Format(expfmt.FmtText),
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// Start pushes metrics periodically if an interval is set, until ctx is done.
+func (p *Pusher) Start(ctx context.Context) error {
+	if p.interval <= 0 {
+		<-ctx.Done()
+		return nil
+	}
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			_ = p.pusher.Push()
+		}
+	}
+}
+
+// Stop pushes the final metrics, giving up when ctx is done.
+func (p *Pusher) Stop(ctx context.Context) error {
+	return p.pusher.PushContext(ctx)
+}
+
+// Push pushes the metrics, replacing the metrics of the same job and grouping.
+func (p *Pusher) Push() error {
+	return p.pusher.Push()
+}
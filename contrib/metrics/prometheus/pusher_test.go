@@ -0,0 +1,48 @@
+package prometheus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPusher(t *testing.T) {
+	var (
+		path string
+		body string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		path, body = r.URL.Path, string(data)
+	}))
+	defer srv.Close()
+
+	reg := prometheus.NewRegistry()
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "job_processed_total", Help: "processed items"})
+	reg.MustRegister(c)
+	c.Add(3)
+
+	p := NewPusher(srv.URL, "batch", WithGatherer(reg), WithGrouping("instance", "worker-1"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/metrics/job/batch/instance/worker-1" {
+		t.Errorf("unexpected path: %s", path)
+	}
+	if !strings.Contains(body, "job_processed_total 3") {
+		t.Errorf("unexpected body: %s", body)
+	}
+	if err := p.Stop(ctx); err == nil {
+		t.Error("expect the push to fail when the context is done")
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/go-kratos/kratos/contrib/middleware/geoip/maxmind/v2
+
+go 1.16
+
+require (
+	github.com/go-kratos/kratos/v2 v2.2.0
+	github.com/oschwald/geoip2-golang v1.9.0
+	github.com/oschwald/maxminddb-golang v1.12.0 // indirect
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../../
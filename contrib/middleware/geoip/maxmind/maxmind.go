@@ -0,0 +1,87 @@
+package maxmind
+
+import (
+	"context"
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+
+	"github.com/go-kratos/kratos/v2/middleware/geoip"
+)
+
+var _ geoip.Resolver = (*Resolver)(nil)
+
+// Reader is the reader of a MaxMind database, implemented by *geoip2.Reader.
+type Reader interface {
+	Country(ip net.IP) (*geoip2.Country, error)
+	ASN(ip net.IP) (*geoip2.ASN, error)
+}
+
+// Resolver resolves the IPs from the MaxMind databases.
+type Resolver struct {
+	country Reader
+	asn     Reader
+}
+
+// New new a resolver from the GeoIP2 or GeoLite2 country and ASN databases,
+// either may be nil.
+func New(country, asn Reader) *Resolver {
+	return &Resolver{country: country, asn: asn}
+}
+
+// Open opens the country and ASN databases at the paths, either may be empty.
+// The close func closes the databases.
+func Open(country, asn string) (*Resolver, func() error, error) {
+	var readers []*geoip2.Reader
+	cleanup := func() error {
+		var err error
+		for _, r := range readers {
+			if e := r.Close(); e != nil {
+				err = e
+			}
+		}
+		return err
+	}
+	open := func(path string) (Reader, error) {
+		if path == "" {
+			return nil, nil
+		}
+		r, err := geoip2.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		readers = append(readers, r)
+		return r, nil
+	}
+	r := &Resolver{}
+	var err error
+	if r.country, err = open(country); err != nil {
+		return nil, nil, err
+	}
+	if r.asn, err = open(asn); err != nil {
+		_ = cleanup()
+		return nil, nil, err
+	}
+	return r, cleanup, nil
+}
+
+// Resolve resolves ip to its location.
+func (r *Resolver) Resolve(_ context.Context, ip net.IP) (*geoip.Location, error) {
+	loc := &geoip.Location{IP: ip}
+	if r.country != nil {
+		c, err := r.country.Country(ip)
+		if err != nil {
+			return nil, err
+		}
+		loc.Country = c.Country.IsoCode
+	}
+	if r.asn != nil {
+		a, err := r.asn.ASN(ip)
+		if err != nil {
+			return nil, err
+		}
+		loc.ASN = a.AutonomousSystemNumber
+		loc.Organization = a.AutonomousSystemOrganization
+	}
+	return loc, nil
+}
@@ -0,0 +1,35 @@
+package maxmind
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+type reader struct{}
+
+func (reader) Country(net.IP) (*geoip2.Country, error) {
+	c := &geoip2.Country{}
+	c.Country.IsoCode = "FR"
+	return c, nil
+}
+
+func (reader) ASN(net.IP) (*geoip2.ASN, error) {
+	return &geoip2.ASN{AutonomousSystemNumber: 64500, AutonomousSystemOrganization: "Example"}, nil
+}
+
+func TestResolve(t *testing.T) {
+	loc, err := New(reader{}, reader{}).Resolve(context.Background(), net.ParseIP("203.0.113.7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Country != "FR" || loc.ASN != 64500 || loc.Organization != "Example" {
+		t.Errorf("unexpected location %+v", loc)
+	}
+	loc, err = New(nil, nil).Resolve(context.Background(), net.ParseIP("203.0.113.7"))
+	if err != nil || loc.Country != "" || loc.IP.String() != "203.0.113.7" {
+		t.Errorf("unexpected location %+v %v", loc, err)
+	}
+}
@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware/replay"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ replay.Store = (*Store)(nil)
+
+// Option is redis store option.
+type Option func(*Store)
+
+// WithPrefix with the key prefix of recorded nonces, default is "kratos:nonce:".
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// Store is a replay.Store backed by redis, it is shared by all instances.
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// New returns a redis nonce store.
+func New(client redis.UniversalClient, opts ...Option) *Store {
+	s := &Store{
+		client: client,
+		prefix: "kratos:nonce:",
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Add records nonce for ttl, it returns false if the nonce is already recorded.
+func (s *Store) Add(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.prefix+nonce, 1, ttl).Result()
+}
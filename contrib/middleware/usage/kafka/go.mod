@@ -0,0 +1,10 @@
+module github.com/go-kratos/kratos/contrib/middleware/usage/kafka/v2
+
+go 1.16
+
+require (
+	github.com/go-kratos/kratos/v2 v2.2.0
+	github.com/segmentio/kafka-go v0.4.27
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../../
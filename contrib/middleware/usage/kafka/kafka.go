@@ -0,0 +1,41 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-kratos/kratos/v2/middleware/usage"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var _ usage.Sink = (*Sink)(nil)
+
+// Writer writes messages to Kafka, it is implemented by *kafka.Writer.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Sink is a usage.Sink writing the records as JSON messages to the topic of a
+// writer, keyed by the key of the records so the records of a key are ordered.
+type Sink struct {
+	writer Writer
+}
+
+// New returns a Kafka usage sink.
+func New(writer Writer) *Sink {
+	return &Sink{writer: writer}
+}
+
+// Write writes the records.
+func (s *Sink) Write(ctx context.Context, records []usage.Record) error {
+	msgs := make([]kafka.Message, 0, len(records))
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, kafka.Message{Key: []byte(r.Key), Value: data})
+	}
+	return s.writer.WriteMessages(ctx, msgs...)
+}
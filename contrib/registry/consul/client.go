@@ -27,6 +27,8 @@ type Client struct {
 	healthcheckInterval int
 	// heartbeat enable heartbeat
 	heartbeat bool
+	// datacenter of the services resolved, default is the datacenter of the agent
+	datacenter string
 }
 
 // NewClient creates consul client
@@ -76,8 +78,9 @@ type ServiceResolver func(ctx context.Context, entries []*api.ServiceEntry) []*r
 // Service get services from consul
 func (c *Client) Service(ctx context.Context, service string, index uint64, passingOnly bool) ([]*registry.ServiceInstance, uint64, error) {
 	opts := &api.QueryOptions{
-		WaitIndex: index,
-		WaitTime:  time.Second * 55,
+		WaitIndex:  index,
+		WaitTime:   time.Second * 55,
+		Datacenter: c.datacenter,
 	}
 	opts = opts.WithContext(ctx)
 	entries, meta, err := c.cli.Health().Service(service, "", passingOnly, opts)
@@ -0,0 +1,24 @@
+//go:build integration
+// +build integration
+
+package consul
+
+import (
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/registry/registrytest"
+	"github.com/hashicorp/consul/api"
+)
+
+// TestConformance runs the registry conformance suite against the consul at
+// 127.0.0.1:8500, e.g.: go test -tags integration -run Conformance
+func TestConformance(t *testing.T) {
+	client, err := api.NewClient(&api.Config{Address: "127.0.0.1:8500"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the instances of the suite don't listen on their endpoints.
+	r := New(client, WithHealthCheck(false))
+	registrytest.Conformance(t, r, registry.Resilient(r))
+}
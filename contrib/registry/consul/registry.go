@@ -54,6 +54,16 @@ func WithHealthCheckInterval(interval int) Option {
 	}
 }
 
+// WithDatacenter with the datacenter the services are resolved in, default
+// is the datacenter of the agent. The instances are registered to the agent.
+func WithDatacenter(dc string) Option {
+	return func(o *Registry) {
+		if o.cli != nil {
+			o.cli.datacenter = dc
+		}
+	}
+}
+
 // Config is consul registry config
 type Config struct {
 	*api.Config
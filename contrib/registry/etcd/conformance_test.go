@@ -0,0 +1,30 @@
+//go:build integration
+// +build integration
+
+package etcd
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/registry/registrytest"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// TestConformance runs the registry conformance suite against the etcd at
+// 127.0.0.1:2379, e.g.: go test -tags integration -run Conformance
+func TestConformance(t *testing.T) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{"127.0.0.1:2379"},
+		DialTimeout: time.Second, DialOptions: []grpc.DialOption{grpc.WithBlock()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	r := New(client)
+	registrytest.Conformance(t, r, registry.Resilient(r))
+}
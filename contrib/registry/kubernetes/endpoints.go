@@ -0,0 +1,265 @@
+package kuberegistry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listerv1 "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var _ registry.Discovery = &EndpointsDiscovery{}
+
+// EndpointsDiscovery resolves the ready endpoints of the Kubernetes services by
+// watching their EndpointSlices, so the clients running in the cluster reach
+// the pods directly without registering them.
+//
+// The scheme of an endpoint is the appProtocol of the service port, or else
+// the prefix of its name, e.g. "grpc" of "grpc-api", the ports of no scheme
+// are skipped:
+//
+//	apiVersion: v1
+//	kind: Service
+//	metadata:
+//	  name: user
+//	spec:
+//	  selector:
+//	    app: user
+//	  ports:
+//	    - name: http
+//	      port: 8000
+//	    - name: grpc
+//	      port: 9000
+type EndpointsDiscovery struct {
+	namespace string
+	factory   informers.SharedInformerFactory
+	informer  cache.SharedIndexInformer
+	lister    listerv1.EndpointSliceLister
+
+	mu       sync.Mutex
+	watchers map[string]map[*endpointsWatcher]struct{}
+
+	stopCh chan struct{}
+}
+
+// NewEndpointsDiscovery new an EndpointSlices discovery of the services in the
+// namespace, the namespace of the current pod if empty.
+func NewEndpointsDiscovery(clientSet kubernetes.Interface, namespace string) *EndpointsDiscovery {
+	if namespace == "" {
+		namespace = GetNamespace()
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(clientSet, time.Minute*10, informers.WithNamespace(namespace))
+	slices := factory.Discovery().V1().EndpointSlices()
+	d := &EndpointsDiscovery{
+		namespace: namespace,
+		factory:   factory,
+		informer:  slices.Informer(),
+		lister:    slices.Lister(),
+		watchers:  make(map[string]map[*endpointsWatcher]struct{}),
+		stopCh:    make(chan struct{}),
+	}
+	// a single handler dispatches to the watchers, which the informer can't remove.
+	d.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    d.changed,
+		UpdateFunc: func(_, obj interface{}) { d.changed(obj) },
+		DeleteFunc: d.changed,
+	})
+	return d
+}
+
+// GetService return the ready endpoints of the service.
+func (d *EndpointsDiscovery) GetService(ctx context.Context, name string) ([]*registry.ServiceInstance, error) {
+	slices, err := d.lister.EndpointSlices(d.namespace).List(labels.SelectorFromSet(map[string]string{
+		discoveryv1.LabelServiceName: name,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		ret  []*registry.ServiceInstance
+		seen = make(map[string]struct{})
+	)
+	for _, slice := range slices {
+		for _, ins := range getServiceInstancesFromSlice(name, slice) {
+			if _, ok := seen[ins.ID]; ok {
+				continue
+			}
+			seen[ins.ID] = struct{}{}
+			ret = append(ret, ins)
+		}
+	}
+	return ret, nil
+}
+
+// Watch creates a watcher of the endpoints of the service.
+func (d *EndpointsDiscovery) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	w := &endpointsWatcher{
+		Iterator: NewIterator(make(chan []*registry.ServiceInstance, 1), make(chan struct{})),
+		d:        d,
+		name:     name,
+	}
+	d.mu.Lock()
+	if d.watchers[name] == nil {
+		d.watchers[name] = make(map[*endpointsWatcher]struct{})
+	}
+	d.watchers[name][w] = struct{}{}
+	d.mu.Unlock()
+	if instances, err := d.GetService(ctx, name); err == nil {
+		w.announce(instances)
+	}
+	return w, nil
+}
+
+// changed announces the endpoints of the service of the EndpointSlice obj to
+// its watchers.
+func (d *EndpointsDiscovery) changed(obj interface{}) {
+	select {
+	case <-d.stopCh:
+		return
+	default:
+	}
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return
+	}
+	name := slice.GetLabels()[discoveryv1.LabelServiceName]
+	d.mu.Lock()
+	watchers := make([]*endpointsWatcher, 0, len(d.watchers[name]))
+	for w := range d.watchers[name] {
+		watchers = append(watchers, w)
+	}
+	d.mu.Unlock()
+	if len(watchers) == 0 {
+		return
+	}
+	instances, err := d.GetService(context.Background(), name)
+	if err != nil {
+		return
+	}
+	for _, w := range watchers {
+		w.announce(instances)
+	}
+}
+
+// endpointsWatcher is a watcher of the endpoints of a service, removed from
+// the discovery once stopped.
+type endpointsWatcher struct {
+	*Iterator
+	d    *EndpointsDiscovery
+	name string
+}
+
+// announce keeps only the latest instances for the watcher.
+func (w *endpointsWatcher) announce(instances []*registry.ServiceInstance) {
+	w.d.mu.Lock()
+	defer w.d.mu.Unlock()
+	select {
+	case <-w.ch:
+	default:
+	}
+	w.ch <- instances
+}
+
+// Stop stops the watcher.
+func (w *endpointsWatcher) Stop() error {
+	w.d.mu.Lock()
+	delete(w.d.watchers[w.name], w)
+	if len(w.d.watchers[w.name]) == 0 {
+		delete(w.d.watchers, w.name)
+	}
+	w.d.mu.Unlock()
+	return w.Iterator.Stop()
+}
+
+// Start is used to start the EndpointsDiscovery
+// It blocks until the informer cache is synced or the discovery is closed,
+// and reports whether the cache was synced
+func (d *EndpointsDiscovery) Start() bool {
+	d.factory.Start(d.stopCh)
+	return cache.WaitForCacheSync(d.stopCh, d.informer.HasSynced)
+}
+
+// Close is used to close the EndpointsDiscovery
+// After closing, any callbacks generated by Watch will not be executed
+func (d *EndpointsDiscovery) Close() {
+	select {
+	case <-d.stopCh:
+	default:
+		close(d.stopCh)
+	}
+}
+
+func getServiceInstancesFromSlice(name string, slice *discoveryv1.EndpointSlice) []*registry.ServiceInstance {
+	if slice.AddressType == discoveryv1.AddressTypeFQDN {
+		return nil
+	}
+	type schemePort struct{ scheme, port string }
+	var ports []schemePort
+	for _, port := range slice.Ports {
+		if port.Port == nil {
+			continue
+		}
+		if scheme := portScheme(port); scheme != "" {
+			ports = append(ports, schemePort{scheme: scheme, port: strconv.Itoa(int(*port.Port))})
+		}
+	}
+	var ret []*registry.ServiceInstance
+	for _, ep := range slice.Endpoints {
+		if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+			continue
+		}
+		if len(ep.Addresses) == 0 {
+			continue
+		}
+		addr := ep.Addresses[0]
+		ins := &registry.ServiceInstance{
+			ID:       addr,
+			Name:     name,
+			Metadata: map[string]string{},
+		}
+		if ep.TargetRef != nil && ep.TargetRef.Name != "" {
+			ins.ID = ep.TargetRef.Name
+		}
+		if ep.Zone != nil {
+			ins.Metadata["zone"] = *ep.Zone
+		}
+		if ep.NodeName != nil {
+			ins.Metadata["node"] = *ep.NodeName
+		}
+		for _, p := range ports {
+			ins.Endpoints = append(ins.Endpoints, fmt.Sprintf("%s://%s", p.scheme, net.JoinHostPort(addr, p.port)))
+		}
+		ret = append(ret, ins)
+	}
+	return ret
+}
+
+// portScheme returns the appProtocol of the port, or else the prefix of its
+// name, e.g. "grpc" of "grpc-api".
+func portScheme(port discoveryv1.EndpointPort) string {
+	if port.AppProtocol != nil && *port.AppProtocol != "" {
+		return strings.ToLower(*port.AppProtocol)
+	}
+	if port.Name == nil {
+		return ""
+	}
+	scheme := strings.ToLower(strings.SplitN(*port.Name, "-", 2)[0])
+	switch scheme {
+	case "http", "https", "grpc", "grpcs":
+		return scheme
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+package kuberegistry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newSlice(name, service string, ready bool, pods ...string) *discoveryv1.EndpointSlice {
+	grpc, http, metrics := "grpc-api", "http", "metrics"
+	grpcPort, httpPort, metricsPort := int32(9000), int32(8000), int32(9090)
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports: []discoveryv1.EndpointPort{
+			{Name: &grpc, Port: &grpcPort},
+			{Name: &http, Port: &httpPort},
+			{Name: &metrics, Port: &metricsPort},
+		},
+	}
+	for i, pod := range pods {
+		zone := "zone-a"
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+			Addresses:  []string{"10.0.0." + string(rune('1'+i))},
+			Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: pod},
+			Zone:       &zone,
+		})
+	}
+	return slice
+}
+
+func TestGetServiceInstancesFromSlice(t *testing.T) {
+	slice := newSlice("user-1", "user", true, "user-a")
+	h2c := "h2c"
+	slice.Ports[2].AppProtocol = &h2c
+	got := getServiceInstancesFromSlice("user", slice)
+	want := []*registry.ServiceInstance{{
+		ID:        "user-a",
+		Name:      "user",
+		Metadata:  map[string]string{"zone": "zone-a"},
+		Endpoints: []string{"grpc://10.0.0.1:9000", "http://10.0.0.1:8000", "h2c://10.0.0.1:9090"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got[0], want[0])
+	}
+	if got := getServiceInstancesFromSlice("user", newSlice("user-1", "user", false, "user-a")); len(got) != 0 {
+		t.Errorf("expect the endpoints not ready skipped, got %v", got)
+	}
+	slice.AddressType = discoveryv1.AddressTypeFQDN
+	if got := getServiceInstancesFromSlice("user", slice); len(got) != 0 {
+		t.Errorf("expect the FQDN slices skipped, got %v", got)
+	}
+}
+
+func ids(instances []*registry.ServiceInstance) []string {
+	ret := make([]string, 0, len(instances))
+	for _, ins := range instances {
+		ret = append(ret, ins.ID)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestEndpointsDiscovery(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		newSlice("user-1", "user", true, "user-a", "user-b"),
+		// the endpoints moving between slices are listed in both.
+		newSlice("user-2", "user", true, "user-b"),
+		newSlice("user-3", "user", false, "user-c"),
+		newSlice("order-1", "order", true, "order-a"),
+	)
+	d := NewEndpointsDiscovery(client, "default")
+	if !d.Start() {
+		t.Fatal("the cache was not synced")
+	}
+	defer d.Close()
+
+	instances, err := d.GetService(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(instances); !reflect.DeepEqual(got, []string{"user-a", "user-b"}) {
+		t.Errorf("got %v, want the ready and deduplicated instances", got)
+	}
+
+	w, err := d.Watch(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := d.Watch(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if instances, err = w.Next(); err != nil || len(instances) != 2 {
+		t.Fatalf("got %v %v, want the current instances", ids(instances), err)
+	}
+	if err = other.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.DiscoveryV1().EndpointSlices("default").Create(ctx, newSlice("user-4", "user", true, "user-d"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	next := make(chan []*registry.ServiceInstance, 1)
+	go func() {
+		instances, _ := w.Next()
+		next <- instances
+	}()
+	select {
+	case instances = <-next:
+		if got := ids(instances); !reflect.DeepEqual(got, []string{"user-a", "user-b", "user-d"}) {
+			t.Errorf("got %v, want the new instance", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expect the new instance watched")
+	}
+	if _, err = other.Next(); !errors.Is(err, ErrIteratorClosed) {
+		t.Errorf("got %v, want the stopped watcher closed", err)
+	}
+	if err = w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.watchers) != 0 {
+		t.Errorf("expect the stopped watchers removed, got %v", d.watchers)
+	}
+}
@@ -291,6 +291,12 @@ func NewIterator(channel chan []*registry.ServiceInstance, stopCh chan struct{})
 
 // Next will block until ServiceInstance changes
 func (iter *Iterator) Next() ([]*registry.ServiceInstance, error) {
+	// a stopped iterator doesn't return the instances still buffered.
+	select {
+	case <-iter.stopCh:
+		return nil, ErrIteratorClosed
+	default:
+	}
 	select {
 	case instances := <-iter.ch:
 		return instances, nil
@@ -0,0 +1,121 @@
+package bugsnag
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/report"
+
+	"github.com/bugsnag/bugsnag-go/v2"
+	"github.com/bugsnag/bugsnag-go/v2/errors"
+)
+
+var (
+	_ report.Reporter = (*Reporter)(nil)
+	_ log.Logger      = (*logger)(nil)
+)
+
+// Option is bugsnag reporter option.
+type Option func(*Reporter)
+
+// WithRelease with the app version tagged on reported events.
+func WithRelease(release string) Option {
+	return func(r *Reporter) {
+		r.release = release
+	}
+}
+
+// WithMaxBreadcrumbs with the number of recent log lines attached to events, default is 0 which
+// disables the breadcrumbs. The log lines are shared by all the requests, so an event may carry
+// the log lines of other concurrent requests.
+func WithMaxBreadcrumbs(n int) Option {
+	return func(r *Reporter) {
+		r.max = n
+	}
+}
+
+// Reporter is a report.Reporter sending events to bugsnag.
+type Reporter struct {
+	notifier *bugsnag.Notifier
+	release  string
+	max      int
+
+	mu          sync.Mutex
+	breadcrumbs []map[string]interface{}
+}
+
+// NewReporter returns a bugsnag reporter using notifier.
+func NewReporter(notifier *bugsnag.Notifier, opts ...Option) *Reporter {
+	r := &Reporter{
+		notifier: notifier,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Report sends the event to bugsnag.
+func (r *Reporter) Report(ctx context.Context, event *report.Event) {
+	md := bugsnag.MetaData{}
+	for k, v := range event.Tags {
+		md.Add("tags", k, v)
+	}
+	if event.Request != nil {
+		md.Add("request", "body", event.Request)
+	}
+	r.mu.Lock()
+	if len(r.breadcrumbs) > 0 {
+		md.Add("breadcrumbs", "logs", append([]map[string]interface{}(nil), r.breadcrumbs...))
+	}
+	r.mu.Unlock()
+	rawData := []interface{}{ctx, md}
+	if operation, ok := event.Tags["operation"]; ok {
+		rawData = append(rawData, bugsnag.Context{String: operation})
+	}
+	if r.release != "" {
+		rawData = append(rawData, bugsnag.Configuration{AppVersion: r.release})
+	}
+	err := event.Err
+	if event.Panic != nil {
+		err = errors.New(event.Panic, 1)
+		rawData = append(rawData, bugsnag.HandledState{
+			SeverityReason:   bugsnag.SeverityReasonHandledPanic,
+			OriginalSeverity: bugsnag.SeverityError,
+		})
+	}
+	_ = r.notifier.Notify(err, rawData...)
+}
+
+// Logger returns a log.Logger recording log lines as breadcrumbs attached to reported events,
+// combine it with the service logger using log.MultiLogger. It records nothing unless enabled
+// by WithMaxBreadcrumbs, the log lines are not tied to the request reporting the event.
+func (r *Reporter) Logger() log.Logger {
+	return &logger{r: r}
+}
+
+type logger struct {
+	r *Reporter
+}
+
+func (l *logger) Log(level log.Level, keyvals ...interface{}) error {
+	b := make(map[string]interface{}, len(keyvals)/2+2)
+	b["time"] = time.Now().Format(time.RFC3339Nano)
+	b["level"] = level.String()
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		b[fmt.Sprint(keyvals[i])] = keyvals[i+1]
+	}
+	l.r.mu.Lock()
+	defer l.r.mu.Unlock()
+	if l.r.max <= 0 {
+		return nil
+	}
+	if len(l.r.breadcrumbs) >= l.r.max {
+		l.r.breadcrumbs = l.r.breadcrumbs[1:]
+	}
+	l.r.breadcrumbs = append(l.r.breadcrumbs, b)
+	return nil
+}
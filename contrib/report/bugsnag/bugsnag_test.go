@@ -0,0 +1,46 @@
+package bugsnag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/report"
+
+	"github.com/bugsnag/bugsnag-go/v2"
+)
+
+func TestReporter(t *testing.T) {
+	var events []*bugsnag.Event
+	var versions []string
+	bugsnag.OnBeforeNotify(func(event *bugsnag.Event, config *bugsnag.Configuration) error {
+		events = append(events, event)
+		versions = append(versions, config.AppVersion)
+		return errors.New("not sent in tests")
+	})
+	notifier := bugsnag.New(bugsnag.Configuration{APIKey: "00000000000000000000000000000000", Synchronous: true})
+	r := NewReporter(notifier, WithRelease("v1.0.0"), WithMaxBreadcrumbs(1))
+	logger := log.NewHelper(r.Logger())
+	logger.Info("dropped")
+	logger.Info("handling request")
+
+	r.Report(context.Background(), &report.Event{
+		Err:  errors.New("boom"),
+		Tags: map[string]string{"operation": "/test.Report/Call"},
+	})
+	r.Report(context.Background(), &report.Event{Panic: "panic reason"})
+	if len(events) != 2 {
+		t.Fatalf("expect 2 events, but got %d", len(events))
+	}
+	if e := events[0]; e.Context != "/test.Report/Call" || e.Message != "boom" || versions[0] != "v1.0.0" {
+		t.Errorf("unexpected event: context=%s message=%s version=%s", e.Context, e.Message, versions[0])
+	}
+	logs, _ := events[0].MetaData["breadcrumbs"]["logs"].([]map[string]interface{})
+	if len(logs) != 1 || logs[0][log.DefaultMessageKey] != "handling request" {
+		t.Errorf("unexpected breadcrumbs: %v", logs)
+	}
+	if events[1].Message != "panic reason" {
+		t.Errorf("expect panic message, but got %q", events[1].Message)
+	}
+}
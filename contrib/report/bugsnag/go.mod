@@ -0,0 +1,10 @@
+module github.com/go-kratos/kratos/contrib/report/bugsnag/v2
+
+go 1.16
+
+require (
+	github.com/bugsnag/bugsnag-go/v2 v2.1.2
+	github.com/go-kratos/kratos/v2 v2.2.0
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
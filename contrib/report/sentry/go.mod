@@ -0,0 +1,10 @@
+module github.com/go-kratos/kratos/contrib/report/sentry/v2
+
+go 1.16
+
+require (
+	github.com/getsentry/sentry-go v0.12.0
+	github.com/go-kratos/kratos/v2 v2.2.0
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
@@ -0,0 +1,126 @@
+package sentry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/report"
+
+	"github.com/getsentry/sentry-go"
+)
+
+var (
+	_ report.Reporter = (*Reporter)(nil)
+	_ log.Logger      = (*Logger)(nil)
+)
+
+// Option is sentry reporter option.
+type Option func(*Reporter)
+
+// WithHub with sentry hub, default is sentry.CurrentHub().
+func WithHub(hub *sentry.Hub) Option {
+	return func(r *Reporter) {
+		r.hub = hub
+	}
+}
+
+// WithRelease with the release tagged on reported events.
+func WithRelease(release string) Option {
+	return func(r *Reporter) {
+		r.release = release
+	}
+}
+
+// Reporter is a report.Reporter sending events to sentry.
+type Reporter struct {
+	hub     *sentry.Hub
+	release string
+}
+
+// NewReporter returns a sentry reporter, sentry.Init must be called before.
+func NewReporter(opts ...Option) *Reporter {
+	r := &Reporter{
+		hub: sentry.CurrentHub(),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Report sends the event to sentry.
+func (r *Reporter) Report(ctx context.Context, event *report.Event) {
+	hub := r.hub.Clone()
+	scope := hub.Scope()
+	scope.SetTags(event.Tags)
+	if event.Request != nil {
+		scope.SetExtra("request", event.Request)
+	}
+	if r.release != "" {
+		scope.AddEventProcessor(func(e *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			e.Release = r.release
+			return e
+		})
+	}
+	if event.Panic != nil {
+		hub.RecoverWithContext(ctx, event.Panic)
+		return
+	}
+	hub.CaptureException(event.Err)
+}
+
+// Flush waits until the buffered events are sent or the timeout is reached.
+func (r *Reporter) Flush(timeout time.Duration) bool {
+	return r.hub.Flush(timeout)
+}
+
+// Logger is a log.Logger recording log lines as sentry breadcrumbs,
+// combine it with the service logger using log.MultiLogger.
+type Logger struct {
+	hub *sentry.Hub
+}
+
+// NewLogger returns a breadcrumb logger for hub, nil means sentry.CurrentHub().
+func NewLogger(hub *sentry.Hub) *Logger {
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	return &Logger{hub: hub}
+}
+
+// Log records keyvals as a breadcrumb.
+func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
+	b := &sentry.Breadcrumb{
+		Category:  "log",
+		Level:     breadcrumbLevel(level),
+		Timestamp: time.Now(),
+		Data:      make(map[string]interface{}, len(keyvals)/2),
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		k := fmt.Sprint(keyvals[i])
+		if k == log.DefaultMessageKey {
+			b.Message = fmt.Sprint(keyvals[i+1])
+			continue
+		}
+		b.Data[k] = keyvals[i+1]
+	}
+	l.hub.AddBreadcrumb(b, nil)
+	return nil
+}
+
+func breadcrumbLevel(level log.Level) sentry.Level {
+	switch level {
+	case log.LevelDebug:
+		return sentry.LevelDebug
+	case log.LevelWarn:
+		return sentry.LevelWarning
+	case log.LevelError:
+		return sentry.LevelError
+	case log.LevelFatal:
+		return sentry.LevelFatal
+	default:
+		return sentry.LevelInfo
+	}
+}
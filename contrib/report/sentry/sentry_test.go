@@ -0,0 +1,56 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/report"
+
+	"github.com/getsentry/sentry-go"
+)
+
+type transport struct {
+	events []*sentry.Event
+}
+
+func (t *transport) Configure(options sentry.ClientOptions) {}
+func (t *transport) SendEvent(event *sentry.Event)          { t.events = append(t.events, event) }
+func (t *transport) Flush(timeout time.Duration) bool       { return true }
+
+func newHub(t *testing.T) (*sentry.Hub, *transport) {
+	tr := &transport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: tr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sentry.NewHub(client, sentry.NewScope()), tr
+}
+
+func TestReporter(t *testing.T) {
+	hub, tr := newHub(t)
+	logger := log.NewHelper(NewLogger(hub))
+	logger.Infow(log.DefaultMessageKey, "handling request", "user", "kratos")
+
+	r := NewReporter(WithHub(hub), WithRelease("v1.0.0"))
+	r.Report(context.Background(), &report.Event{
+		Err:  errors.New("boom"),
+		Tags: map[string]string{"operation": "/test.Report/Call"},
+	})
+	r.Report(context.Background(), &report.Event{Panic: "panic reason"})
+	if len(tr.events) != 2 {
+		t.Fatalf("expect 2 events, but got %d", len(tr.events))
+	}
+	e := tr.events[0]
+	if e.Release != "v1.0.0" || e.Tags["operation"] != "/test.Report/Call" {
+		t.Errorf("unexpected event: release=%s tags=%v", e.Release, e.Tags)
+	}
+	if len(e.Breadcrumbs) != 1 || e.Breadcrumbs[0].Message != "handling request" || e.Breadcrumbs[0].Data["user"] != "kratos" {
+		t.Errorf("unexpected breadcrumbs: %+v", e.Breadcrumbs)
+	}
+	if tr.events[1].Message != "panic reason" {
+		t.Errorf("expect panic message, but got %q", tr.events[1].Message)
+	}
+}
@@ -0,0 +1,227 @@
+// Package crash writes a crash report, the goroutine dump, the build info and
+// the recent logs, when the process dies of a panic escaping the recovery or
+// of a fatal signal, to aid the postmortems. The Dumper is a
+// transport.Server watching the signals while the app runs:
+//
+//	dumper := crash.New(crash.WithDir("/var/log/app"))
+//	defer dumper.Recover()
+//	logger = dumper.Logger(logger)
+//	app := kratos.New(kratos.Logger(logger), kratos.Server(httpSrv, dumper))
+package crash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Server = (*Dumper)(nil)
+
+// maxStack is the maximum size of the goroutine dump.
+const maxStack = 64 << 20
+
+// Report is a crash report.
+type Report struct {
+	Time      time.Time `json:"time"`
+	PID       int       `json:"pid"`
+	Reason    string    `json:"reason"`
+	GoVersion string    `json:"go_version"`
+	Path      string    `json:"path,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	// Goroutines is the stack dump of all the goroutines.
+	Goroutines string `json:"goroutines"`
+	// Logs are the recent logs, the oldest first.
+	Logs []string `json:"logs,omitempty"`
+}
+
+// Sink writes the crash reports.
+type Sink interface {
+	Write(r *Report) error
+}
+
+// SinkFunc is a func Sink.
+type SinkFunc func(r *Report) error
+
+// Write calls f.
+func (f SinkFunc) Write(r *Report) error {
+	return f(r)
+}
+
+// Option is dumper option.
+type Option func(*Dumper)
+
+// WithDir with the directory of the reports written as crash-<pid>-<time>.json,
+// default is the temporary directory.
+func WithDir(dir string) Option {
+	return func(d *Dumper) {
+		d.dir = dir
+	}
+}
+
+// WithSink with the sink of the reports instead of the directory.
+func WithSink(s Sink) Option {
+	return func(d *Dumper) {
+		d.sink = s
+	}
+}
+
+// WithLogSize with the number of recent logs kept, default is 256.
+func WithLogSize(n int) Option {
+	return func(d *Dumper) {
+		d.logs = log.NewRing(n)
+	}
+}
+
+// WithSignals with the fatal signals dumped, default is SIGABRT.
+func WithSignals(sigs ...os.Signal) Option {
+	return func(d *Dumper) {
+		d.sigs = sigs
+	}
+}
+
+// Dumper writes the crash reports.
+type Dumper struct {
+	dir  string
+	sink Sink
+	logs *log.Ring
+	sigs []os.Signal
+	exit func(code int)
+
+	mu      sync.Mutex
+	stopped bool
+	c       chan os.Signal
+	restore func()
+}
+
+// New new a crash dumper.
+func New(opts ...Option) *Dumper {
+	d := &Dumper{
+		dir:  os.TempDir(),
+		logs: log.NewRing(256),
+		sigs: []os.Signal{syscall.SIGABRT},
+		exit: os.Exit,
+		c:    make(chan os.Signal, 1),
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	if d.sink == nil {
+		d.sink = SinkFunc(d.writeFile)
+	}
+	return d
+}
+
+// Logger returns a logger keeping the recent logs of next for the reports.
+func (d *Dumper) Logger(next log.Logger) log.Logger {
+	return log.MultiLogger(next, d.logs)
+}
+
+// Recover writes a report of a panic and panics again, it must be deferred
+// in main and in the goroutines:
+//
+//	defer dumper.Recover()
+func (d *Dumper) Recover() {
+	if p := recover(); p != nil {
+		d.Dump(fmt.Sprintf("panic: %v", p))
+		panic(p)
+	}
+}
+
+// Dump writes a report of the current state of the process.
+func (d *Dumper) Dump(reason string) {
+	r := &Report{
+		Time:       time.Now(),
+		PID:        os.Getpid(),
+		Reason:     reason,
+		GoVersion:  runtime.Version(),
+		Goroutines: stack(),
+	}
+	for _, rec := range d.logs.Records(log.LevelDebug) {
+		r.Logs = append(r.Logs, rec.String())
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		r.Path, r.Version = bi.Main.Path, bi.Main.Version
+	}
+	if err := d.sink.Write(r); err != nil {
+		fmt.Fprintf(os.Stderr, "crash: failed to write the report: %v\n", err)
+	}
+}
+
+// Start watches the fatal signals, dumping and exiting with the code 2 on
+// one. With Go 1.23 the fatal errors of the runtime, e.g. the panics of the
+// goroutines without Recover, are also written to the directory.
+func (d *Dumper) Start(ctx context.Context) error {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return nil
+	}
+	if len(d.sigs) > 0 {
+		signal.Notify(d.c, d.sigs...)
+	}
+	restore, err := setCrashOutput(d.dir)
+	if err != nil {
+		log.Warnf("crash: failed to set the crash output: %v", err)
+	}
+	d.restore = restore
+	d.mu.Unlock()
+	for sig := range d.c {
+		d.Dump("signal: " + sig.String())
+		d.exit(2)
+	}
+	return nil
+}
+
+// Stop stops watching the signals and restores the crash output, Start
+// returns at once if called after it.
+func (d *Dumper) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return nil
+	}
+	d.stopped = true
+	signal.Stop(d.c)
+	close(d.c)
+	if d.restore != nil {
+		d.restore()
+		d.restore = nil
+	}
+	return nil
+}
+
+func (d *Dumper) writeFile(r *Report) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(d.dir, fmt.Sprintf("crash-%d-%s.json", r.PID, r.Time.UTC().Format("20060102T150405Z")))
+	if err = os.WriteFile(name, data, 0o600); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "crash: report written to %s\n", name)
+	return nil
+}
+
+// stack returns the stacks of all the goroutines.
+func stack() string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxStack {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
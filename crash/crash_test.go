@@ -0,0 +1,92 @@
+package crash
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestRecover(t *testing.T) {
+	var got *Report
+	d := New(WithSink(SinkFunc(func(r *Report) error {
+		got = r
+		return nil
+	})), WithLogSize(2))
+	logger := log.NewHelper(d.Logger(log.DefaultLogger))
+	logger.Info("first")
+	logger.Info("second")
+	logger.Warnw("msg", "third", "order", 42)
+
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("expect the panic again, got %v", p)
+			}
+		}()
+		defer d.Recover()
+		panic("boom")
+	}()
+	if got == nil || got.Reason != "panic: boom" || !strings.Contains(got.Goroutines, "TestRecover") {
+		t.Fatalf("unexpected report %+v", got)
+	}
+	if len(got.Logs) != 2 || !strings.HasSuffix(got.Logs[0], "INFO msg=second") || !strings.HasSuffix(got.Logs[1], "WARN msg=third order=42") {
+		t.Errorf("unexpected logs %q", got.Logs)
+	}
+}
+
+func TestSignal(t *testing.T) {
+	dir := t.TempDir()
+	d := New(WithDir(dir), WithSignals())
+	exited := make(chan int, 1)
+	d.exit = func(code int) { exited <- code }
+	done := make(chan error, 1)
+	go func() { done <- d.Start(context.Background()) }()
+	d.c <- syscall.SIGABRT
+	select {
+	case code := <-exited:
+		if code != 2 {
+			t.Errorf("expect the exit code 2, got %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expect the dumper to exit")
+	}
+	_ = d.Stop(context.Background())
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "crash-*"))
+	if len(files) != 1 || filepath.Ext(files[0]) != ".json" {
+		t.Fatalf("expect only the report left, got %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil || r.Reason != "signal: "+syscall.SIGABRT.String() || r.PID != os.Getpid() {
+		t.Errorf("unexpected report %+v %v", r, err)
+	}
+}
+
+func TestStopBeforeStart(t *testing.T) {
+	dir := t.TempDir()
+	d := New(WithDir(dir), WithSignals(syscall.SIGUSR2))
+	_ = d.Stop(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.Start(context.Background()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expect Start to return once stopped")
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "crash-*")); len(files) != 0 {
+		t.Errorf("expect the crash output not set, got %v", files)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !go1.23
+// +build !go1.23
+
+package crash
+
+// setCrashOutput is unsupported before Go 1.23.
+func setCrashOutput(string) (func(), error) {
+	return nil, nil
+}
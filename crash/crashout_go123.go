@@ -0,0 +1,30 @@
+//go:build go1.23
+// +build go1.23
+
+package crash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+)
+
+// setCrashOutput writes the fatal errors of the runtime to a file of dir,
+// removed when restored without a crash.
+func setCrashOutput(dir string) (func(), error) {
+	f, err := os.Create(filepath.Join(dir, fmt.Sprintf("crash-%d.txt", os.Getpid())))
+	if err != nil {
+		return nil, err
+	}
+	if err = debug.SetCrashOutput(f, debug.CrashOptions{}); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return func() {
+		_ = debug.SetCrashOutput(nil, debug.CrashOptions{})
+		f.Close()
+		os.Remove(f.Name())
+	}, nil
+}
@@ -0,0 +1,189 @@
+// Package dependencies waits at startup for the dependencies of the app, e.g.
+// the database, Redis or the registry, to be ready, retrying their probes
+// with a backoff, so the app fails with a clear error instead of the first
+// requests failing:
+//
+//	deps := dependencies.New(dependencies.WithTimeout(time.Minute))
+//	deps.Add("mysql", dependencies.Pinger(db))
+//	deps.Add("redis", dependencies.TCP("redis:6379"))
+//	app := kratos.New(kratos.BeforeStart(deps.Wait), ...)
+package dependencies
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Probe returns an error while the dependency isn't ready.
+type Probe func(ctx context.Context) error
+
+// TCP returns a probe dialing addr.
+func TCP(addr string) Probe {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Pinger returns a probe pinging p, e.g. a *sql.DB.
+func Pinger(p pinger) Probe {
+	return p.PingContext
+}
+
+// NotReadyError is returned when dependencies aren't ready in time.
+type NotReadyError struct {
+	// Errors are the last errors of the dependencies not ready by name.
+	Errors map[string]error
+}
+
+func (e *NotReadyError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msgs := make([]string, 0, len(names))
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", name, e.Errors[name]))
+	}
+	return "dependencies not ready: " + strings.Join(msgs, "; ")
+}
+
+// Option is dependencies option.
+type Option func(*Dependencies)
+
+// WithTimeout with the maximum duration waited for the dependencies, default is 1m.
+func WithTimeout(d time.Duration) Option {
+	return func(o *Dependencies) {
+		o.timeout = d
+	}
+}
+
+// WithBackoff with the initial and the maximum delays between the probes,
+// default are 100ms and 5s. The non-positive delays are ignored, and the
+// maximum delay is at least the initial one.
+func WithBackoff(initial, maxDelay time.Duration) Option {
+	return func(o *Dependencies) {
+		if initial > 0 {
+			o.initial = initial
+		}
+		if maxDelay > 0 {
+			o.max = maxDelay
+		}
+	}
+}
+
+// WithLogger with the logger of the dependencies waited for.
+func WithLogger(logger log.Logger) Option {
+	return func(o *Dependencies) {
+		o.log = log.NewHelper(logger)
+	}
+}
+
+type dependency struct {
+	name  string
+	probe Probe
+}
+
+// Dependencies are the dependencies waited for.
+type Dependencies struct {
+	timeout time.Duration
+	initial time.Duration
+	max     time.Duration
+	log     *log.Helper
+	deps    []dependency
+}
+
+// New new the dependencies of the app.
+func New(opts ...Option) *Dependencies {
+	d := &Dependencies{
+		timeout: time.Minute,
+		initial: 100 * time.Millisecond,
+		max:     5 * time.Second,
+		log:     log.NewHelper(log.GetLogger()),
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	if d.max < d.initial {
+		d.max = d.initial
+	}
+	return d
+}
+
+// Add adds the dependency name ready once probe succeeds.
+func (d *Dependencies) Add(name string, probe Probe) {
+	d.deps = append(d.deps, dependency{name: name, probe: probe})
+}
+
+// Wait waits for all the dependencies to be ready, probed concurrently, it
+// returns a NotReadyError with the last errors of the others on timeout.
+func (d *Dependencies) Wait(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	var (
+		mu   sync.Mutex
+		errs = make(map[string]error)
+		wg   sync.WaitGroup
+	)
+	for _, dep := range d.deps {
+		dep := dep
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.wait(ctx, dep); err != nil {
+				mu.Lock()
+				errs[dep.name] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return &NotReadyError{Errors: errs}
+	}
+	return nil
+}
+
+func (d *Dependencies) wait(ctx context.Context, dep dependency) error {
+	start := time.Now()
+	delay := d.initial
+	for attempt := 1; ; attempt++ {
+		err := dep.probe(ctx)
+		if err == nil {
+			if attempt > 1 {
+				d.log.Infof("dependency %s ready after %s", dep.name, time.Since(start).Round(time.Millisecond))
+			}
+			return nil
+		}
+		// jitter the delay by up to 20% so the instances don't retry in step.
+		wait := delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+		d.log.Warnf("waiting for dependency %s, attempt %d failed: %v, retrying in %s", dep.name, attempt, err, wait.Round(time.Millisecond))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if delay *= 2; delay > d.max {
+			delay = d.max
+		}
+	}
+}
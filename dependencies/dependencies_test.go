@@ -0,0 +1,63 @@
+package dependencies
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	d := New(WithBackoff(time.Millisecond, 5*time.Millisecond))
+	attempts := 0
+	d.Add("flaky", func(context.Context) error {
+		if attempts++; attempts < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	d.Add("tcp", TCP(ln.Addr().String()))
+	if err = d.Wait(context.Background()); err != nil || attempts != 3 {
+		t.Fatalf("got %v after %d attempts", err, attempts)
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	d := New(WithTimeout(50*time.Millisecond), WithBackoff(time.Millisecond, 10*time.Millisecond))
+	d.Add("ready", func(context.Context) error { return nil })
+	d.Add("redis", func(context.Context) error { return errors.New("connection refused") })
+	start := time.Now()
+	err := d.Wait(context.Background())
+	var nre *NotReadyError
+	if !errors.As(err, &nre) || len(nre.Errors) != 1 || !strings.Contains(err.Error(), "redis: connection refused") {
+		t.Fatalf("expect redis not ready, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expect the timeout honored, waited %s", elapsed)
+	}
+}
+
+func TestWithBackoff(t *testing.T) {
+	tests := []struct {
+		initial, max         time.Duration
+		wantInitial, wantMax time.Duration
+	}{
+		{0, 0, 100 * time.Millisecond, 5 * time.Second},
+		{-time.Second, -time.Second, 100 * time.Millisecond, 5 * time.Second},
+		{10 * time.Second, time.Second, 10 * time.Second, 10 * time.Second},
+		{time.Millisecond, time.Second, time.Millisecond, time.Second},
+	}
+	for _, test := range tests {
+		d := New(WithBackoff(test.initial, test.max))
+		if d.initial != test.wantInitial || d.max != test.wantMax {
+			t.Errorf("WithBackoff(%v, %v): expect %v %v, got %v %v", test.initial, test.max, test.wantInitial, test.wantMax, d.initial, d.max)
+		}
+	}
+}
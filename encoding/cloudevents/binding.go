@@ -0,0 +1,157 @@
+package cloudevents
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Binding maps the attributes of an event to the headers of a message in the
+// binary mode, the data being the payload of the message.
+type Binding struct {
+	// Prefix is the prefix of the attribute headers.
+	Prefix string
+	// ContentType is the header of the datacontenttype attribute.
+	ContentType string
+	// Escape whether the header values are percent-encoded, as in HTTP.
+	Escape bool
+}
+
+// The bindings of the protocols.
+var (
+	HTTP  = Binding{Prefix: "ce-", ContentType: "Content-Type", Escape: true}
+	Kafka = Binding{Prefix: "ce_", ContentType: "content-type"}
+	// NATS is the binding of the NATS headers.
+	NATS = Binding{Prefix: "ce-", ContentType: "content-type"}
+	// MQTT is the binding of the MQTT 5 user properties.
+	MQTT = Binding{ContentType: "Content-Type"}
+)
+
+// Write writes the attributes of e to h, it returns the payload of the message.
+func (b Binding) Write(h transport.Header, e *Event) ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	b.set(h, "id", e.ID)
+	b.set(h, "source", e.Source)
+	b.set(h, "specversion", e.SpecVersion)
+	b.set(h, "type", e.Type)
+	b.set(h, "dataschema", e.DataSchema)
+	b.set(h, "subject", e.Subject)
+	if !e.Time.IsZero() {
+		b.set(h, "time", e.Time.Format(time.RFC3339Nano))
+	}
+	for k, v := range e.Extensions {
+		if attributes[k] {
+			continue
+		}
+		b.set(h, k, fmt.Sprint(v))
+	}
+	if e.DataContentType != "" {
+		h.Set(b.ContentType, e.DataContentType)
+	}
+	return e.Data, nil
+}
+
+// Read reads an event from the headers h and the payload data of a message.
+func (b Binding) Read(h transport.Header, data []byte) (*Event, error) {
+	e := &Event{Data: data}
+	for _, key := range h.Keys() {
+		if len(key) < len(b.Prefix) || !strings.EqualFold(key[:len(b.Prefix)], b.Prefix) || strings.EqualFold(key, b.ContentType) {
+			continue
+		}
+		name := strings.ToLower(key[len(b.Prefix):])
+		value := h.Get(key)
+		if b.Escape {
+			if v, err := url.PathUnescape(value); err == nil {
+				value = v
+			}
+		}
+		switch name {
+		case "id":
+			e.ID = value
+		case "source":
+			e.Source = value
+		case "specversion":
+			e.SpecVersion = value
+		case "type":
+			e.Type = value
+		case "dataschema":
+			e.DataSchema = value
+		case "subject":
+			e.Subject = value
+		case "time":
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, fmt.Errorf("cloudevents: time: %w", err)
+			}
+			e.Time = t
+		default:
+			if b.Prefix == "" && !isAttribute(name) {
+				// the properties aren't prefixed, only the known names are taken.
+				continue
+			}
+			if e.Extensions == nil {
+				e.Extensions = make(map[string]interface{})
+			}
+			e.Extensions[name] = value
+		}
+	}
+	e.DataContentType = h.Get(b.ContentType)
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// IsBinary reports whether the message of h is an event in the binary mode.
+func (b Binding) IsBinary(h transport.Header) bool {
+	return h.Get(b.Prefix+"specversion") != ""
+}
+
+// IsStructured reports whether the message of h is an event in the structured mode.
+func (b Binding) IsStructured(h transport.Header) bool {
+	return strings.HasPrefix(h.Get(b.ContentType), "application/"+Name)
+}
+
+func (b Binding) set(h transport.Header, name, value string) {
+	if value == "" {
+		return
+	}
+	if b.Escape {
+		value = escape(value)
+	}
+	h.Set(b.Prefix+name, value)
+}
+
+// isAttribute reports whether name is a context attribute name, lowercase
+// letters and digits.
+func isAttribute(name string) bool {
+	if name == "" || len(name) > 20 {
+		return false
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// escape percent-encodes the space, the double quote, the percent and the
+// characters not printable in ASCII.
+func escape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c > '~' || c == '"' || c == '%' {
+			fmt.Fprintf(&sb, "%%%02X", c)
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
@@ -0,0 +1,204 @@
+// Package cloudevents implements the CloudEvents v1.0 envelope, the
+// structured mode as the "cloudevents+json" codec and the binary mode as the
+// protocol bindings of the transport headers.
+package cloudevents
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+)
+
+// Name is the name registered for the structured mode codec, the content
+// type is "application/cloudevents+json".
+const Name = "cloudevents+json"
+
+// SpecVersion is the version of the CloudEvents specification.
+const SpecVersion = "1.0"
+
+// ErrInvalidEvent is returned when an event misses a required attribute.
+var ErrInvalidEvent = errors.New("cloudevents: invalid event")
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// Event is a CloudEvents v1.0 event.
+type Event struct {
+	ID              string
+	Source          string
+	SpecVersion     string
+	Type            string
+	DataContentType string
+	DataSchema      string
+	Subject         string
+	Time            time.Time
+	// Extensions are the extension attributes, strings, booleans or integers.
+	Extensions map[string]interface{}
+	// Data is the encoded data of the event.
+	Data []byte
+}
+
+// New new an event of type from source, the data is encoded in JSON.
+func New(id, source, typ string, data interface{}) (*Event, error) {
+	e := &Event{ID: id, Source: source, SpecVersion: SpecVersion, Type: typ, Time: time.Now().UTC()}
+	if data != nil {
+		b, err := encoding.GetCodec("json").Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		e.DataContentType, e.Data = "application/json", b
+	}
+	return e, nil
+}
+
+// Validate checks the required attributes of the event.
+func (e *Event) Validate() error {
+	switch {
+	case e.ID == "":
+		return fmt.Errorf("%w: id is required", ErrInvalidEvent)
+	case e.Source == "":
+		return fmt.Errorf("%w: source is required", ErrInvalidEvent)
+	case e.Type == "":
+		return fmt.Errorf("%w: type is required", ErrInvalidEvent)
+	case e.SpecVersion != SpecVersion:
+		return fmt.Errorf("%w: unsupported specversion %q", ErrInvalidEvent, e.SpecVersion)
+	}
+	return nil
+}
+
+// DataAs decodes the data of the event into v by its content type.
+func (e *Event) DataAs(v interface{}) error {
+	name := "json"
+	if ct := e.DataContentType; ct != "" && !isJSON(ct) {
+		name = strings.TrimPrefix(strings.SplitN(ct, ";", 2)[0], "application/")
+	}
+	codec := encoding.GetCodec(name)
+	if codec == nil {
+		return fmt.Errorf("cloudevents: no codec for %q", e.DataContentType)
+	}
+	return codec.Unmarshal(e.Data, v)
+}
+
+// context attributes, the other attributes are extensions.
+var attributes = map[string]bool{
+	"id": true, "source": true, "specversion": true, "type": true, "datacontenttype": true,
+	"dataschema": true, "subject": true, "time": true, "data": true, "data_base64": true,
+}
+
+func isJSON(contentType string) bool {
+	ct := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	return ct == "application/json" || ct == "text/json" || strings.HasSuffix(ct, "+json")
+}
+
+// MarshalJSON encodes the event in the structured mode, the data is inlined
+// when it's JSON, else base64 encoded.
+func (e *Event) MarshalJSON() ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{}, len(e.Extensions)+9)
+	for k, v := range e.Extensions {
+		m[k] = v
+	}
+	m["id"], m["source"], m["specversion"], m["type"] = e.ID, e.Source, e.SpecVersion, e.Type
+	setString(m, "datacontenttype", e.DataContentType)
+	setString(m, "dataschema", e.DataSchema)
+	setString(m, "subject", e.Subject)
+	if !e.Time.IsZero() {
+		m["time"] = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.Data != nil {
+		if (e.DataContentType == "" || isJSON(e.DataContentType)) && json.Valid(e.Data) {
+			m["data"] = json.RawMessage(e.Data)
+		} else {
+			m["data_base64"] = base64.StdEncoding.EncodeToString(e.Data)
+		}
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes an event in the structured mode.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*e = Event{}
+	for k, raw := range m {
+		if attributes[k] {
+			continue
+		}
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		if e.Extensions == nil {
+			e.Extensions = make(map[string]interface{})
+		}
+		e.Extensions[k] = v
+	}
+	for k, p := range map[string]*string{
+		"id": &e.ID, "source": &e.Source, "specversion": &e.SpecVersion, "type": &e.Type,
+		"datacontenttype": &e.DataContentType, "dataschema": &e.DataSchema, "subject": &e.Subject,
+	} {
+		if raw, ok := m[k]; ok {
+			if err := json.Unmarshal(raw, p); err != nil {
+				return fmt.Errorf("cloudevents: %s: %w", k, err)
+			}
+		}
+	}
+	if raw, ok := m["time"]; ok {
+		if err := json.Unmarshal(raw, &e.Time); err != nil {
+			return fmt.Errorf("cloudevents: time: %w", err)
+		}
+	}
+	if raw, ok := m["data_base64"]; ok {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf("cloudevents: data_base64: %w", err)
+		}
+		e.Data = data
+	} else if raw, ok := m["data"]; ok {
+		e.Data = []byte(raw)
+	}
+	return e.Validate()
+}
+
+func setString(m map[string]interface{}, k, v string) {
+	if v != "" {
+		m[k] = v
+	}
+}
+
+// codec is the structured mode codec of the events.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	e, ok := v.(*Event)
+	if !ok {
+		return nil, fmt.Errorf("cloudevents: %T isn't an event", v)
+	}
+	return e.MarshalJSON()
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	e, ok := v.(*Event)
+	if !ok {
+		return fmt.Errorf("cloudevents: %T isn't an event", v)
+	}
+	return e.UnmarshalJSON(data)
+}
+
+func (codec) Name() string {
+	return Name
+}
@@ -0,0 +1,123 @@
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/internal/httputil"
+)
+
+type header http.Header
+
+func (h header) Get(key string) string        { return http.Header(h).Get(key) }
+func (h header) Set(key string, value string) { http.Header(h).Set(key, value) }
+func (h header) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// mapHeader is a case sensitive header, e.g. the Kafka headers.
+type mapHeader map[string]string
+
+func (h mapHeader) Get(key string) string        { return h[key] }
+func (h mapHeader) Set(key string, value string) { h[key] = value }
+func (h mapHeader) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestStructured(t *testing.T) {
+	e, err := New("1", "/orders", "com.example.order.created", map[string]interface{}{"id": 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Extensions = map[string]interface{}{"traceparent": "00-abc-def-01"}
+	codec := encoding.GetCodec(httputil.ContentSubtype("application/cloudevents+json; charset=utf-8"))
+	if codec == nil {
+		t.Fatal("the codec isn't registered")
+	}
+	data, err := codec.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]json.RawMessage
+	_ = json.Unmarshal(data, &m)
+	if string(m["data"]) != `{"id":42}` || string(m["traceparent"]) != `"00-abc-def-01"` {
+		t.Errorf("unexpected structured event %s", data)
+	}
+	var got Event
+	if err = codec.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "1" || !got.Time.Equal(e.Time) || got.Extensions["traceparent"] != "00-abc-def-01" {
+		t.Errorf("got %+v", got)
+	}
+	var v struct{ ID int }
+	if err = got.DataAs(&v); err != nil || v.ID != 42 {
+		t.Errorf("got %+v %v", v, err)
+	}
+
+	binary := &Event{ID: "2", Source: "/s", SpecVersion: SpecVersion, Type: "t", DataContentType: "application/octet-stream", Data: []byte{0xff, 0}}
+	data, _ = binary.MarshalJSON()
+	if err = got.UnmarshalJSON(data); err != nil || !bytes.Equal(got.Data, binary.Data) {
+		t.Errorf("got %v %v from %s", got.Data, err, data)
+	}
+	if err = got.UnmarshalJSON([]byte(`{"id":"3","source":"/s","type":"t","specversion":"0.3"}`)); !errors.Is(err, ErrInvalidEvent) {
+		t.Errorf("expect invalid event, got %v", err)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	e := &Event{
+		ID:              "1",
+		Source:          "/orders",
+		SpecVersion:     SpecVersion,
+		Type:            "com.example.order.created",
+		Subject:         "order 42",
+		Time:            time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+		DataContentType: "application/json",
+		Extensions:      map[string]interface{}{"partitionkey": "42"},
+		Data:            []byte(`{"id":42}`),
+	}
+	h := header{}
+	data, err := HTTP.Write(h, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Get("ce-subject") != "order%2042" || h.Get("Content-Type") != "application/json" || !HTTP.IsBinary(h) {
+		t.Errorf("unexpected headers %v", h)
+	}
+	got, err := HTTP.Read(h, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Subject != e.Subject || !got.Time.Equal(e.Time) || got.Extensions["partitionkey"] != "42" || got.DataContentType != e.DataContentType {
+		t.Errorf("got %+v", got)
+	}
+
+	k := mapHeader{}
+	_, _ = Kafka.Write(k, e)
+	if k["ce_subject"] != "order 42" || k["content-type"] != "application/json" {
+		t.Errorf("unexpected kafka headers %v", k)
+	}
+	if got, err = Kafka.Read(k, data); err != nil || got.Subject != e.Subject {
+		t.Errorf("got %+v %v", got, err)
+	}
+	if _, err = Kafka.Read(mapHeader{"ce_id": "1", "ce_specversion": SpecVersion}, nil); !errors.Is(err, ErrInvalidEvent) {
+		t.Errorf("expect invalid event, got %v", err)
+	}
+	if !HTTP.IsStructured(header{"Content-Type": {"application/cloudevents+json"}}) {
+		t.Error("expect structured mode")
+	}
+}
@@ -0,0 +1,373 @@
+// Package jose defines a codec that protects payloads with JWS compact
+// signatures and/or JWE compact encryption. The codec is not registered by
+// default, services register it with the keys they own:
+//
+//	encoding.RegisterCodec(jose.NewCodec(keys, jose.WithMode(jose.ModeSign|jose.ModeEncrypt)))
+//
+// Requests and responses are then negotiated via "application/jose".
+package jose
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	kjson "github.com/go-kratos/kratos/v2/encoding/json"
+)
+
+// Name is the name registered for the jose codec.
+const Name = "jose"
+
+const (
+	algHS256   = "HS256"
+	algDir     = "dir"
+	encA256GCM = "A256GCM"
+	ctyNested  = "JWT"
+
+	// minSignKey is the minimum size of the HS256 keys, the size of its hash.
+	minSignKey = sha256.Size
+)
+
+var (
+	// ErrInvalidToken is returned when the payload is not a well-formed JWS/JWE.
+	ErrInvalidToken = errors.New("jose: invalid token")
+	// ErrInvalidSignature is returned when the JWS signature does not match.
+	ErrInvalidSignature = errors.New("jose: invalid signature")
+	// ErrUnsupportedAlgorithm is returned when the header names an unsupported algorithm.
+	ErrUnsupportedAlgorithm = errors.New("jose: unsupported algorithm")
+	// ErrInvalidKey is returned when a key is unknown or has a wrong size.
+	ErrInvalidKey = errors.New("jose: invalid key")
+	// ErrSharedKey is returned when the same key is used to sign and to encrypt.
+	ErrSharedKey = errors.New("jose: shared signing and encryption key")
+)
+
+// Mode is the protection applied to payloads.
+type Mode int
+
+const (
+	// ModeSign signs payloads with JWS (HS256).
+	ModeSign Mode = 1 << iota
+	// ModeEncrypt encrypts payloads with JWE (dir, A256GCM).
+	ModeEncrypt
+)
+
+// Use is the use of a key, a key is never used both to sign and to encrypt.
+type Use int
+
+const (
+	// UseSign is the use of the HS256 keys, at least 32 bytes.
+	UseSign Use = iota
+	// UseEncrypt is the use of the A256GCM keys, exactly 32 bytes.
+	UseEncrypt
+)
+
+// KeyProvider resolves the keys used to protect payloads.
+type KeyProvider interface {
+	// CurrentKey returns the key id and key of the use for outgoing payloads.
+	CurrentKey(use Use) (kid string, key []byte, err error)
+	// Key returns the key of the use identified by kid, used to verify or
+	// decrypt incoming payloads.
+	Key(use Use, kid string) ([]byte, error)
+}
+
+// KeySet is a fixed key set, Current names the key used for outgoing payloads.
+type KeySet struct {
+	Current string
+	Keys    map[string][]byte
+}
+
+// StaticKeys is a KeyProvider backed by a fixed key set per use.
+type StaticKeys struct {
+	Sign    KeySet
+	Encrypt KeySet
+}
+
+// CurrentKey returns the current key of the use.
+func (s StaticKeys) CurrentKey(use Use) (string, []byte, error) {
+	kid := s.set(use).Current
+	key, err := s.Key(use, kid)
+	return kid, key, err
+}
+
+// Key returns the key of the use identified by kid.
+func (s StaticKeys) Key(use Use, kid string) ([]byte, error) {
+	key, ok := s.set(use).Keys[kid]
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+func (s StaticKeys) set(use Use) KeySet {
+	if use == UseEncrypt {
+		return s.Encrypt
+	}
+	return s.Sign
+}
+
+// Option is jose codec option.
+type Option func(*codec)
+
+// WithMode with payload protection mode, default is ModeSign.
+func WithMode(m Mode) Option {
+	return func(c *codec) {
+		c.mode = m
+	}
+}
+
+// WithCodec with the codec used for the protected payload, default is json.
+func WithCodec(inner encoding.Codec) Option {
+	return func(c *codec) {
+		c.inner = inner
+	}
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Cty string `json:"cty,omitempty"`
+}
+
+// codec is a Codec implementation with JWS/JWE compact serialization.
+type codec struct {
+	keys  KeyProvider
+	inner encoding.Codec
+	mode  Mode
+}
+
+// NewCodec returns a jose codec protecting payloads with keys.
+func NewCodec(keys KeyProvider, opts ...Option) encoding.Codec {
+	c := &codec{
+		keys:  keys,
+		inner: encoding.GetCodec(kjson.Name),
+		mode:  ModeSign,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *codec) Marshal(v interface{}) ([]byte, error) {
+	data, err := c.inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var signKey []byte
+	if c.mode&ModeSign != 0 {
+		var kid string
+		if kid, signKey, err = c.keys.CurrentKey(UseSign); err != nil {
+			return nil, err
+		}
+		if data, err = Sign(data, kid, signKey); err != nil {
+			return nil, err
+		}
+	}
+	if c.mode&ModeEncrypt != 0 {
+		kid, key, err := c.keys.CurrentKey(UseEncrypt)
+		if err != nil {
+			return nil, err
+		}
+		if signKey != nil && hmac.Equal(signKey, key) {
+			return nil, ErrSharedKey
+		}
+		cty := ""
+		if signKey != nil {
+			cty = ctyNested
+		}
+		if data, err = encrypt(data, kid, key, cty); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (c *codec) Unmarshal(data []byte, v interface{}) error {
+	var err error
+	if c.mode&ModeEncrypt != 0 {
+		if data, err = Decrypt(data, c.keys); err != nil {
+			return err
+		}
+	}
+	if c.mode&ModeSign != 0 {
+		if data, err = Verify(data, c.keys); err != nil {
+			return err
+		}
+	}
+	return c.inner.Unmarshal(data, v)
+}
+
+func (c *codec) Name() string {
+	return Name
+}
+
+// Sign returns the JWS compact serialization of payload signed with key,
+// the key must be at least 32 bytes for HS256.
+func Sign(payload []byte, kid string, key []byte) ([]byte, error) {
+	if len(key) < minSignKey {
+		return nil, ErrInvalidKey
+	}
+	h, err := encodeHeader(header{Alg: algHS256, Kid: kid})
+	if err != nil {
+		return nil, err
+	}
+	input := h + "." + encodeSegment(payload)
+	return []byte(input + "." + encodeSegment(signHS256(input, key))), nil
+}
+
+// Verify verifies the JWS compact serialization and returns its payload.
+func Verify(token []byte, keys KeyProvider) ([]byte, error) {
+	parts := strings.Split(string(token), ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	h, err := decodeHeader(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if h.Alg != algHS256 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	key, err := keys.Key(UseSign, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) < minSignKey {
+		return nil, ErrInvalidKey
+	}
+	sig, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(sig, signHS256(parts[0]+"."+parts[1], key)) {
+		return nil, ErrInvalidSignature
+	}
+	return decodeSegment(parts[1])
+}
+
+// Encrypt returns the JWE compact serialization of payload encrypted with key,
+// the key must be 32 bytes for A256GCM.
+func Encrypt(payload []byte, kid string, key []byte) ([]byte, error) {
+	return encrypt(payload, kid, key, "")
+}
+
+func encrypt(payload []byte, kid string, key []byte, cty string) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	h, err := encodeHeader(header{Alg: algDir, Enc: encA256GCM, Kid: kid, Cty: cty})
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(iv); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nil, iv, payload, []byte(h))
+	n := len(sealed) - aead.Overhead()
+	return []byte(strings.Join([]string{
+		h,
+		"", // encrypted key is empty for direct encryption
+		encodeSegment(iv),
+		encodeSegment(sealed[:n]),
+		encodeSegment(sealed[n:]),
+	}, ".")), nil
+}
+
+// Decrypt decrypts the JWE compact serialization and returns its payload.
+func Decrypt(token []byte, keys KeyProvider) ([]byte, error) {
+	parts := strings.Split(string(token), ".")
+	if len(parts) != 5 || parts[1] != "" {
+		return nil, ErrInvalidToken
+	}
+	h, err := decodeHeader(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if h.Alg != algDir || h.Enc != encA256GCM {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	key, err := keys.Key(UseEncrypt, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	segs := make([][]byte, 0, 3)
+	for _, p := range parts[2:] {
+		seg, err := decodeSegment(p)
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, seg)
+	}
+	if len(segs[0]) != aead.NonceSize() {
+		return nil, ErrInvalidToken
+	}
+	payload, err := aead.Open(nil, segs[0], append(segs[1], segs[2]...), []byte(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("jose: decrypt: %w", err)
+	}
+	return payload, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 { //nolint:gomnd
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func signHS256(input string, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+func encodeHeader(h header) (string, error) {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+	return encodeSegment(data), nil
+}
+
+func decodeHeader(seg string) (*header, error) {
+	data, err := decodeSegment(seg)
+	if err != nil {
+		return nil, err
+	}
+	h := new(header)
+	if err = json.Unmarshal(data, h); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return h, nil
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSegment(seg string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return data, nil
+}
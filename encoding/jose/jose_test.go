@@ -0,0 +1,127 @@
+package jose
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testMessage struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+var testKeys = StaticKeys{
+	Sign: KeySet{
+		Current: "s1",
+		Keys: map[string][]byte{
+			"s1": []byte("sign0123456789abcdef0123456789ab"),
+		},
+	},
+	Encrypt: KeySet{
+		Current: "k1",
+		Keys: map[string][]byte{
+			"k1": []byte("0123456789abcdef0123456789abcdef"),
+			"k2": []byte("fedcba9876543210fedcba9876543210"),
+		},
+	},
+}
+
+func TestName(t *testing.T) {
+	c := NewCodec(testKeys)
+	if !reflect.DeepEqual(c.Name(), "jose") {
+		t.Errorf("expect name: %v, but got: %v", "jose", c.Name())
+	}
+}
+
+func TestCodec(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  Mode
+		parts int
+	}{
+		{"sign", ModeSign, 3},
+		{"encrypt", ModeEncrypt, 5},
+		{"sign+encrypt", ModeSign | ModeEncrypt, 5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewCodec(testKeys, WithMode(test.mode))
+			in := &testMessage{ID: 1, Name: "kratos"}
+			data, err := c.Marshal(in)
+			if err != nil {
+				t.Fatalf("Marshal() should be nil, but got %s", err)
+			}
+			if n := len(strings.Split(string(data), ".")); n != test.parts {
+				t.Errorf("expect %d parts, but got %d", test.parts, n)
+			}
+			out := new(testMessage)
+			if err = c.Unmarshal(data, out); err != nil {
+				t.Fatalf("Unmarshal() should be nil, but got %s", err)
+			}
+			if !reflect.DeepEqual(in, out) {
+				t.Errorf("expect %v, but got %v", in, out)
+			}
+		})
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	token, err := Sign([]byte(`{"id":1}`), "s1", testKeys.Sign.Keys["s1"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(string(token), ".")
+	parts[1] = encodeSegment([]byte(`{"id":2}`))
+	if _, err = Verify([]byte(strings.Join(parts, ".")), testKeys); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expect %v, but got %v", ErrInvalidSignature, err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	token, err := Encrypt([]byte("payload"), "k2", testKeys.Encrypt.Keys["k2"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// current key is k1, but k2 is still accepted for incoming payloads.
+	data, err := Decrypt(token, testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte("payload")) {
+		t.Errorf("expect %s, but got %s", "payload", data)
+	}
+	if _, err = Decrypt(token, StaticKeys{Sign: testKeys.Encrypt}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expect %v, but got %v", ErrInvalidKey, err)
+	}
+}
+
+func TestKeyUse(t *testing.T) {
+	if _, err := Sign([]byte("payload"), "short", []byte("0123456789abcdef")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expect %v, but got %v", ErrInvalidKey, err)
+	}
+	// the signing keys can't decrypt and the encryption keys can't verify.
+	token, err := Sign([]byte("payload"), "k1", testKeys.Encrypt.Keys["k1"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Verify(token, testKeys); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expect %v, but got %v", ErrInvalidKey, err)
+	}
+	shared := StaticKeys{Sign: testKeys.Encrypt, Encrypt: testKeys.Encrypt}
+	if _, err = NewCodec(shared, WithMode(ModeSign|ModeEncrypt)).Marshal(&testMessage{ID: 1}); !errors.Is(err, ErrSharedKey) {
+		t.Errorf("expect %v, but got %v", ErrSharedKey, err)
+	}
+}
+
+func TestInvalidToken(t *testing.T) {
+	c := NewCodec(testKeys, WithMode(ModeEncrypt))
+	if err := c.Unmarshal([]byte("a.b.c"), new(testMessage)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expect %v, but got %v", ErrInvalidToken, err)
+	}
+	if _, err := Encrypt([]byte("payload"), "short", []byte("short")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expect %v, but got %v", ErrInvalidKey, err)
+	}
+}
@@ -21,8 +21,18 @@ var (
 	UnmarshalOptions = protojson.UnmarshalOptions{
 		DiscardUnknown: true,
 	}
+
+	marshal   = json.Marshal
+	unmarshal = json.Unmarshal
 )
 
+// SetEngine replaces encoding/json for the values which aren't proto messages,
+// e.g. by jsoniter or sonic. Proto messages are always handled by protojson to
+// keep the proto JSON mapping. It must be called before the codec is used.
+func SetEngine(m func(v interface{}) ([]byte, error), u func(data []byte, v interface{}) error) {
+	marshal, unmarshal = m, u
+}
+
 func init() {
 	encoding.RegisterCodec(codec{})
 }
@@ -37,7 +47,7 @@ func (codec) Marshal(v interface{}) ([]byte, error) {
 	case proto.Message:
 		return MarshalOptions.Marshal(m)
 	default:
-		return json.Marshal(m)
+		return marshal(m)
 	}
 }
 
@@ -58,7 +68,7 @@ func (codec) Unmarshal(data []byte, v interface{}) error {
 		if m, ok := reflect.Indirect(rv).Interface().(proto.Message); ok {
 			return UnmarshalOptions.Unmarshal(data, m)
 		}
-		return json.Unmarshal(data, m)
+		return unmarshal(data, m)
 	}
 }
 
@@ -145,3 +145,36 @@ func TestJSON_Unmarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestSetEngine(t *testing.T) {
+	var calls int
+	SetEngine(func(v interface{}) ([]byte, error) {
+		calls++
+		return json.Marshal(v)
+	}, func(data []byte, v interface{}) error {
+		calls++
+		return json.Unmarshal(data, v)
+	})
+	defer SetEngine(json.Marshal, json.Unmarshal)
+
+	data, err := (codec{}).Marshal(&testData.TestModel{Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (codec{}).Unmarshal(data, &testData.TestModel{}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Errorf("expect proto messages to bypass the engine, got %d calls", calls)
+	}
+	data, err = (codec{}).Marshal(&testMessage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (codec{}).Unmarshal(data, &testMessage{}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expect 2 engine calls, got %d", calls)
+	}
+}
@@ -3,6 +3,8 @@
 package proto
 
 import (
+	"fmt"
+
 	"github.com/go-kratos/kratos/v2/encoding"
 
 	"google.golang.org/protobuf/proto"
@@ -19,11 +21,19 @@ func init() {
 type codec struct{}
 
 func (codec) Marshal(v interface{}) ([]byte, error) {
-	return proto.Marshal(v.(proto.Message))
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("proto: %T isn't a proto message", v)
+	}
+	return proto.Marshal(m)
 }
 
 func (codec) Unmarshal(data []byte, v interface{}) error {
-	return proto.Unmarshal(data, v.(proto.Message))
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("proto: %T isn't a proto message", v)
+	}
+	return proto.Unmarshal(data, m)
 }
 
 func (codec) Name() string {
@@ -0,0 +1,50 @@
+package toml
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testConfig struct {
+	Server struct {
+		Addr string `toml:"addr"`
+		Port int    `toml:"port"`
+	} `toml:"server"`
+}
+
+func TestCodec(t *testing.T) {
+	var c testConfig
+	c.Server.Addr, c.Server.Port = "127.0.0.1", 8000
+	data, err := codec{}.Marshal(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got testConfig
+	if err = (codec{}).Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, got) {
+		t.Errorf("expect %v, got %v", c, got)
+	}
+}
+
+func TestCodec_UnmarshalMap(t *testing.T) {
+	data := `
+name = "kratos"
+[[servers]]
+addr = "127.0.0.1"
+[[servers]]
+addr = "127.0.0.2"
+`
+	m := map[string]interface{}{}
+	if err := (codec{}).Unmarshal([]byte(data), &m); err != nil {
+		t.Fatal(err)
+	}
+	servers, ok := m["servers"].([]interface{})
+	if !ok || len(servers) != 2 || m["name"] != "kratos" {
+		t.Fatalf("unexpected map %v", m)
+	}
+	if s, ok := servers[1].(map[string]interface{}); !ok || s["addr"] != "127.0.0.2" {
+		t.Errorf("unexpected server %v", servers[1])
+	}
+}
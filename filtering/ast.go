@@ -0,0 +1,97 @@
+package filtering
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Expr is a node of a filter expression.
+type Expr interface {
+	String() string
+}
+
+// And is the conjunction of two expressions, explicit or implicit (a b).
+type And struct {
+	Left, Right Expr
+}
+
+// Or is the disjunction of two expressions, it binds tighter than And.
+type Or struct {
+	Left, Right Expr
+}
+
+// Not is the negation of an expression, NOT a or -a.
+type Not struct {
+	Expr Expr
+}
+
+// Restriction compares a comparable to an argument, e.g. a.b >= 10,
+// a restriction without comparator is a global restriction, e.g. "prod".
+type Restriction struct {
+	Comparable Expr
+	// Comparator is one of = != < <= > >= : or empty for a global restriction.
+	Comparator string
+	Arg        Expr
+}
+
+// Member is a field path or an unquoted value, e.g. a.b.c, 10, true or foo*.
+type Member struct {
+	Path []string
+}
+
+// String is a quoted string value.
+type String struct {
+	Value string
+}
+
+// Call is a function call, e.g. time.now() or regex(name, "^a").
+type Call struct {
+	Name string
+	Args []Expr
+}
+
+func (e *And) String() string { return "(" + e.Left.String() + " AND " + e.Right.String() + ")" }
+func (e *Or) String() string  { return "(" + e.Left.String() + " OR " + e.Right.String() + ")" }
+func (e *Not) String() string { return "NOT " + e.Expr.String() }
+
+func (e *Restriction) String() string {
+	if e.Comparator == "" {
+		return e.Comparable.String()
+	}
+	return e.Comparable.String() + " " + e.Comparator + " " + e.Arg.String()
+}
+
+func (e *Member) String() string { return strings.Join(e.Path, ".") }
+func (e *String) String() string { return strconv.Quote(e.Value) }
+
+func (e *Call) String() string {
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = arg.String()
+	}
+	return e.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// Walk calls f for expr and its children depth-first, f returns false to skip the children.
+func Walk(expr Expr, f func(Expr) bool) {
+	if expr == nil || !f(expr) {
+		return
+	}
+	switch e := expr.(type) {
+	case *And:
+		Walk(e.Left, f)
+		Walk(e.Right, f)
+	case *Or:
+		Walk(e.Left, f)
+		Walk(e.Right, f)
+	case *Not:
+		Walk(e.Expr, f)
+	case *Restriction:
+		Walk(e.Comparable, f)
+		Walk(e.Arg, f)
+	case *Call:
+		for _, arg := range e.Args {
+			Walk(arg, f)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+// Package filtering parses the filter and the order_by parameters of the list
+// endpoints following AIP-160 and AIP-132, the handlers translate the AST to
+// their query language, e.g. SQL.
+package filtering
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// InvalidFilter returns an invalid filter error.
+func InvalidFilter(format string, a ...interface{}) *errors.Error {
+	return errors.BadRequest("INVALID_FILTER", fmt.Sprintf(format, a...))
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenText
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+	// space reports whether the token is preceded by whitespace.
+	space bool
+}
+
+var comparators = []string{"<=", ">=", "!=", "=", "<", ">", ":"}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		start := i
+		for i < len(s) && unicode.IsSpace(rune(s[i])) {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		space := i > start || i == 0
+		pos := i
+		c := s[i]
+		switch {
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(s) && s[j] != c {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, InvalidFilter("unterminated string at %d", pos)
+			}
+			value, err := unquote(s[i+1 : j])
+			if err != nil {
+				return nil, InvalidFilter("invalid string at %d: %v", pos, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: pos, space: space})
+			i = j + 1
+		case strings.IndexByte("().,", c) >= 0:
+			tokens = append(tokens, token{kind: tokenSymbol, value: string(c), pos: pos, space: space})
+			i++
+		case c == '-' && (i+1 >= len(s) || !isDigit(s[i+1])):
+			tokens = append(tokens, token{kind: tokenSymbol, value: "-", pos: pos, space: space})
+			i++
+		default:
+			if op := comparator(s[i:]); op != "" {
+				tokens = append(tokens, token{kind: tokenSymbol, value: op, pos: pos, space: space})
+				i += len(op)
+				continue
+			}
+			number := c == '-' || isDigit(c)
+			j := i + 1
+			for j < len(s) && !unicode.IsSpace(rune(s[j])) && strings.IndexByte("()\"',", s[j]) < 0 && comparator(s[j:]) == "" {
+				// the dots separate the members, except in numbers.
+				if s[j] == '.' && !number {
+					break
+				}
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenText, value: s[i:j], pos: pos, space: space})
+			i = j
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(s)}), nil
+}
+
+func comparator(s string) string {
+	for _, op := range comparators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// unquote unescapes the content of a quoted string, either quote may be
+// escaped in both kinds of strings.
+func unquote(s string) (string, error) {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+		case c == '\\' && i+1 < len(s):
+			b.WriteByte(c)
+			b.WriteByte(s[i+1])
+			i++
+		case c == '"':
+			b.WriteString(`\"`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return strconv.Unquote(b.String())
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+// Parse parses a filter, an empty filter returns a nil expression.
+func Parse(filter string) (Expr, error) {
+	tokens, err := lex(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, InvalidFilter("unexpected %q at %d", t.value, t.pos)
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.i] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) keyword(k string) bool {
+	t := p.peek()
+	return t.kind == tokenText && t.value == k
+}
+
+func (p *parser) symbol(s string) bool {
+	t := p.peek()
+	return t.kind == tokenSymbol && t.value == s
+}
+
+// expression: sequence {AND sequence}
+func (p *parser) expression() (Expr, error) {
+	left, err := p.sequence()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		p.next()
+		right, err := p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// sequence: factor {factor}
+func (p *parser) sequence() (Expr, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.startsTerm() {
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) startsTerm() bool {
+	t := p.peek()
+	switch t.kind {
+	case tokenText:
+		return t.value != "AND" && t.value != "OR"
+	case tokenString:
+		return true
+	case tokenSymbol:
+		return t.value == "(" || t.value == "-"
+	}
+	return false
+}
+
+// factor: term {OR term}
+func (p *parser) factor() (Expr, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		p.next()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// term: [NOT | -] simple
+func (p *parser) term() (Expr, error) {
+	if p.keyword("NOT") || p.symbol("-") {
+		p.next()
+		expr, err := p.simple()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: expr}, nil
+	}
+	return p.simple()
+}
+
+// simple: restriction | "(" expression ")"
+func (p *parser) simple() (Expr, error) {
+	if p.symbol("(") {
+		p.next()
+		expr, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if !p.symbol(")") {
+			t := p.peek()
+			return nil, InvalidFilter("expect ) at %d", t.pos)
+		}
+		p.next()
+		return expr, nil
+	}
+	comparable, err := p.comparable()
+	if err != nil {
+		return nil, err
+	}
+	r := &Restriction{Comparable: comparable}
+	if t := p.peek(); t.kind == tokenSymbol && comparator(t.value) == t.value {
+		p.next()
+		r.Comparator = t.value
+		if p.symbol("(") {
+			r.Arg, err = p.simple()
+		} else {
+			r.Arg, err = p.comparable()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// comparable: member | function | string
+func (p *parser) comparable() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return &String{Value: t.value}, nil
+	case tokenText:
+		if t.value == "AND" || t.value == "OR" || t.value == "NOT" {
+			return nil, InvalidFilter("unexpected %s at %d", t.value, t.pos)
+		}
+	default:
+		return nil, InvalidFilter("unexpected %q at %d", t.value, t.pos)
+	}
+	path := []string{t.value}
+	for p.symbol(".") && !p.peek().space {
+		p.next()
+		t := p.next()
+		if t.kind != tokenText && t.kind != tokenString {
+			return nil, InvalidFilter("expect a field at %d", t.pos)
+		}
+		path = append(path, t.value)
+	}
+	if !p.symbol("(") || p.peek().space {
+		return &Member{Path: path}, nil
+	}
+	p.next()
+	call := &Call{Name: strings.Join(path, ".")}
+	for !p.symbol(")") {
+		if len(call.Args) > 0 {
+			if !p.symbol(",") {
+				return nil, InvalidFilter("expect , at %d", p.peek().pos)
+			}
+			p.next()
+		}
+		var (
+			arg Expr
+			err error
+		)
+		if p.symbol("(") {
+			arg, err = p.simple()
+		} else {
+			arg, err = p.comparable()
+		}
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+	}
+	p.next()
+	return call, nil
+}
+
+// Fields returns the field paths restricted by expr, e.g. to check them against an allow list.
+func Fields(expr Expr) []string {
+	var fields []string
+	Walk(expr, func(e Expr) bool {
+		if r, ok := e.(*Restriction); ok && r.Comparator != "" {
+			if m, ok := r.Comparable.(*Member); ok {
+				fields = append(fields, m.String())
+			}
+		}
+		return true
+	})
+	return fields
+}
@@ -0,0 +1,84 @@
+package filtering
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		filter string
+		expect string
+	}{
+		{``, ``},
+		{`a = 1`, `a = 1`},
+		{`a.b.c >= -1.5`, `a.b.c >= -1.5`},
+		{`name = "foo bar" AND age > 18`, `(name = "foo bar" AND age > 18)`},
+		{`a b`, `(a AND b)`},
+		{`a AND b OR c`, `(a AND (b OR c))`},
+		{`NOT a = 1`, `NOT a = 1`},
+		{`-deleted:true`, `NOT deleted : true`},
+		{`(a OR b) c`, `((a OR b) AND c)`},
+		{`create_time > time.now()`, `create_time > time.now()`},
+		{`regex(name, 'a\'b')`, `regex(name, "a'b")`},
+		{`name = 'it\'s'`, `name = "it's"`},
+		{`name = 'a"b'`, `name = "a\"b"`},
+		{`name = 'a\"b'`, `name = "a\"b"`},
+		{`name = "a\"b"`, `name = "a\"b"`},
+		{`name = "it\'s"`, `name = "it's"`},
+		{`name = 'a\\'`, `name = "a\\"`},
+		{`date >= 2021-01-01`, `date >= 2021-01-01`},
+		{`labels.env:prod*`, `labels.env : prod*`},
+		{`a != "x" OR a = null`, `(a != "x" OR a = null)`},
+	}
+	for _, test := range tests {
+		expr, err := Parse(test.filter)
+		if err != nil {
+			t.Errorf("%s: %v", test.filter, err)
+			continue
+		}
+		got := ""
+		if expr != nil {
+			got = expr.String()
+		}
+		if got != test.expect {
+			t.Errorf("%s: expect %s, got %s", test.filter, test.expect, got)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, filter := range []string{`a =`, `(a`, `a)`, `"a`, `AND a`, `a = b OR`, `f(a b)`} {
+		if _, err := Parse(filter); !errors.IsBadRequest(err) {
+			t.Errorf("%s: expect an invalid filter error, got %v", filter, err)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	expr, err := Parse(`a = 1 AND (b.c > 2 OR NOT d:x) global`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := Fields(expr); !reflect.DeepEqual(fields, []string{"a", "b.c", "d"}) {
+		t.Errorf("unexpected fields %v", fields)
+	}
+}
+
+func TestParseOrderBy(t *testing.T) {
+	fields, err := ParseOrderBy(" name desc,author.age , id asc", "name", "author.age", "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []OrderField{{Path: "name", Desc: true}, {Path: "author.age"}, {Path: "id"}}
+	if !reflect.DeepEqual(expect, fields) {
+		t.Errorf("expect %v, got %v", expect, fields)
+	}
+	for _, orderBy := range []string{"name up", "name desc extra", "a..b", "name,name", "secret"} {
+		if _, err := ParseOrderBy(orderBy, "name", "a.b"); !errors.IsBadRequest(err) {
+			t.Errorf("%s: expect an invalid order_by error, got %v", orderBy, err)
+		}
+	}
+}
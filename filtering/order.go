@@ -0,0 +1,82 @@
+package filtering
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// InvalidOrderBy returns an invalid order_by error.
+func InvalidOrderBy(format string, a ...interface{}) *errors.Error {
+	return errors.BadRequest("INVALID_ORDER_BY", fmt.Sprintf(format, a...))
+}
+
+// OrderField is a field of an order_by parameter.
+type OrderField struct {
+	// Path is the field path, e.g. author.name.
+	Path string
+	// Desc reports whether the field is sorted in descending order.
+	Desc bool
+}
+
+// ParseOrderBy parses an order_by parameter such as "foo desc, bar.baz",
+// the fields must be allowed if any field is.
+func ParseOrderBy(orderBy string, allowed ...string) ([]OrderField, error) {
+	if strings.TrimSpace(orderBy) == "" {
+		return nil, nil
+	}
+	var fields []OrderField
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(orderBy, ",") {
+		words := strings.Fields(item)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, InvalidOrderBy("invalid order %q", strings.TrimSpace(item))
+		}
+		f := OrderField{Path: words[0]}
+		if len(words) == 2 {
+			switch words[1] {
+			case "desc":
+				f.Desc = true
+			case "asc":
+			default:
+				return nil, InvalidOrderBy("invalid direction %q of %s", words[1], f.Path)
+			}
+		}
+		for _, name := range strings.Split(f.Path, ".") {
+			if !validName(name) {
+				return nil, InvalidOrderBy("invalid field %q", f.Path)
+			}
+		}
+		if len(allowed) > 0 && !contains(allowed, f.Path) {
+			return nil, InvalidOrderBy("field %q isn't sortable", f.Path)
+		}
+		if seen[f.Path] {
+			return nil, InvalidOrderBy("field %q is repeated", f.Path)
+		}
+		seen[f.Path] = true
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+// Package guard checks the dangerous settings of the servers and the clients
+// at startup in production, e.g. debug endpoints exposed to the internet.
+package guard
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// The kinds of hazards.
+const (
+	// InsecureDial is a client dialing without TLS.
+	InsecureDial = "insecure_dial"
+	// DebugEndpoint is a debug endpoint served, e.g. pprof or channelz.
+	DebugEndpoint = "debug_endpoint"
+	// Reflection is the gRPC reflection service registered.
+	Reflection = "reflection"
+)
+
+// Hazard is a dangerous setting of a server or a client.
+type Hazard struct {
+	Kind string
+	// Target is the endpoint of the client or the path served, e.g. "/debug/pprof/".
+	Target string
+}
+
+func (h Hazard) String() string {
+	return h.Kind + " " + h.Target
+}
+
+// Reporter is implemented by the servers and the clients reporting their hazards.
+type Reporter interface {
+	Hazards() []Hazard
+}
+
+// Policy is the action on a hazard.
+type Policy int
+
+const (
+	// Warn logs the hazard.
+	Warn Policy = iota
+	// Refuse logs the hazard and fails the check.
+	Refuse
+	// Ignore ignores the hazard.
+	Ignore
+)
+
+// Option is guard option.
+type Option func(*Guard)
+
+// Production with whether it runs in production, the hazards are only checked in production.
+func Production(production bool) Option {
+	return func(g *Guard) {
+		g.production = production
+	}
+}
+
+// WithPolicy with the policy of a kind of hazards. By default the insecure
+// dials and the debug endpoints are refused and the reflection is warned.
+func WithPolicy(kind string, p Policy) Option {
+	return func(g *Guard) {
+		g.policies[kind] = p
+	}
+}
+
+// Allow allows the hazards of a kind whose target matches one of the
+// patterns, in the syntax of path.Match, e.g. "discovery:///internal.*".
+func Allow(kind string, patterns ...string) Option {
+	return func(g *Guard) {
+		g.allowlist[kind] = append(g.allowlist[kind], patterns...)
+	}
+}
+
+// WithLogger with guard logger.
+func WithLogger(logger log.Logger) Option {
+	return func(g *Guard) {
+		g.log = log.NewHelper(logger)
+	}
+}
+
+// Guard checks the hazards of the servers and the clients.
+type Guard struct {
+	production bool
+	policies   map[string]Policy
+	allowlist  map[string][]string
+	log        *log.Helper
+}
+
+// New new a guard.
+func New(opts ...Option) *Guard {
+	g := &Guard{
+		policies: map[string]Policy{
+			InsecureDial:  Refuse,
+			DebugEndpoint: Refuse,
+			Reflection:    Warn,
+		},
+		allowlist: make(map[string][]string),
+		log:       log.NewHelper(log.GetLogger()),
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	return g
+}
+
+// Check checks the hazards of the components implementing Reporter, it
+// returns an error listing the refused ones. It does nothing unless in production.
+func (g *Guard) Check(components ...interface{}) error {
+	if !g.production {
+		return nil
+	}
+	var refused []string
+	for _, c := range components {
+		r, ok := c.(Reporter)
+		if !ok {
+			continue
+		}
+		for _, h := range r.Hazards() {
+			if g.allowed(h) {
+				continue
+			}
+			switch g.policies[h.Kind] {
+			case Refuse:
+				g.log.Errorf("guard: refused %s", h)
+				refused = append(refused, h.String())
+			case Warn:
+				g.log.Warnf("guard: %s", h)
+			}
+		}
+	}
+	if len(refused) > 0 {
+		return fmt.Errorf("guard: refused in production: %s", strings.Join(refused, ", "))
+	}
+	return nil
+}
+
+func (g *Guard) allowed(h Hazard) bool {
+	for _, pattern := range g.allowlist[h.Kind] {
+		if ok, _ := path.Match(pattern, h.Target); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package guard
+
+import (
+	"strings"
+	"testing"
+)
+
+type reporter []Hazard
+
+func (r reporter) Hazards() []Hazard { return r }
+
+func TestCheck(t *testing.T) {
+	components := []interface{}{
+		reporter{{Kind: Reflection, Target: "/grpc.reflection.v1alpha.ServerReflection/"}},
+		reporter{
+			{Kind: InsecureDial, Target: "discovery:///internal.user"},
+			{Kind: InsecureDial, Target: "10.0.0.1:9000"},
+		},
+		"not a reporter",
+	}
+	if err := New().Check(components...); err != nil {
+		t.Fatalf("expect no check outside production, got %v", err)
+	}
+	g := New(Production(true), Allow(InsecureDial, "discovery:///internal.*"))
+	err := g.Check(components...)
+	if err == nil || !strings.Contains(err.Error(), "insecure_dial 10.0.0.1:9000") || strings.Contains(err.Error(), "internal.user") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	g = New(Production(true), WithPolicy(InsecureDial, Warn), WithPolicy(Reflection, Refuse))
+	if err = g.Check(components...); err == nil || !strings.Contains(err.Error(), Reflection) {
+		t.Fatalf("expect the reflection refused, got %v", err)
+	}
+	g = New(Production(true), WithPolicy(InsecureDial, Ignore))
+	if err = g.Check(components...); err != nil {
+		t.Fatalf("expect the hazards warned or ignored, got %v", err)
+	}
+}
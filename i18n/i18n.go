@@ -0,0 +1,261 @@
+// Package i18n provides message bundles and the Localizer of a request,
+// negotiated by the localize middleware:
+//
+//	//go:embed locales/*.yaml
+//	var locales embed.FS
+//
+//	bundle := i18n.NewBundle(language.English)
+//	if err := bundle.LoadFS(locales, "locales"); err != nil {
+//		panic(err)
+//	}
+//
+// A message is a string with {name} placeholders, e.g. "Hello {name}".
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/encoding"
+	// init the message file codecs.
+	_ "github.com/go-kratos/kratos/v2/encoding/json"
+	_ "github.com/go-kratos/kratos/v2/encoding/yaml"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Bundle holds the messages of each language.
+type Bundle struct {
+	fallback language.Tag
+
+	mu sync.RWMutex
+	// added are the messages of AddMessages and LoadFS, loaded the ones of the
+	// config replaced on reload, both are merged into messages.
+	added    map[language.Tag]map[string]string
+	loaded   map[language.Tag]map[string]string
+	tags     []language.Tag
+	messages map[language.Tag]map[string]string
+	matcher  language.Matcher
+}
+
+// NewBundle returns an empty bundle, fallback is the language of the
+// requests matching none of the bundle languages.
+func NewBundle(fallback language.Tag) *Bundle {
+	b := &Bundle{
+		fallback: fallback,
+		added:    make(map[language.Tag]map[string]string),
+	}
+	b.rebuild()
+	return b
+}
+
+// AddMessages adds the messages of the language, replacing the existing ones with the same ids.
+func (b *Bundle) AddMessages(tag language.Tag, messages map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.added[tag]
+	if !ok {
+		m = make(map[string]string, len(messages))
+		b.added[tag] = m
+	}
+	for id, msg := range messages {
+		m[id] = msg
+	}
+	b.rebuild()
+}
+
+// rebuild merges the messages loaded from the config over the added ones.
+func (b *Bundle) rebuild() {
+	messages := make(map[language.Tag]map[string]string, len(b.added)+len(b.loaded)+1)
+	messages[b.fallback] = make(map[string]string)
+	var tags []language.Tag
+	for _, layer := range []map[language.Tag]map[string]string{b.added, b.loaded} {
+		for tag, msgs := range layer {
+			m, ok := messages[tag]
+			if !ok {
+				m = make(map[string]string, len(msgs))
+				messages[tag] = m
+				tags = append(tags, tag)
+			}
+			for id, msg := range msgs {
+				m[id] = msg
+			}
+		}
+	}
+	// the fallback first, the others in a stable order for the matcher.
+	sort.Slice(tags, func(i, j int) bool { return tags[i].String() < tags[j].String() })
+	b.tags = append([]language.Tag{b.fallback}, tags...)
+	b.messages = messages
+	b.matcher = language.NewMatcher(b.tags)
+}
+
+// LoadFS loads the message files of dir, e.g. from an embed.FS. A file is named
+// after its language and decoded by the codec of its extension, e.g. zh-CN.yaml,
+// the nested keys are joined by dots.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		ext := path.Ext(e.Name())
+		codec := encoding.GetCodec(strings.TrimPrefix(ext, "."))
+		if codec == nil {
+			continue
+		}
+		tag, err := language.Parse(strings.TrimSuffix(e.Name(), ext))
+		if err != nil {
+			return fmt.Errorf("i18n: %s: %w", e.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		var v map[string]interface{}
+		if err := codec.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("i18n: %s: %w", e.Name(), err)
+		}
+		b.AddMessages(tag, flatten(v))
+	}
+	return nil
+}
+
+// LoadConfig loads the messages of the config value, a map from the languages
+// to their messages, replacing the ones loaded before, they take precedence
+// over the messages added otherwise.
+func (b *Bundle) LoadConfig(v config.Value) error {
+	var langs map[string]map[string]interface{}
+	if err := v.Scan(&langs); err != nil {
+		return err
+	}
+	loaded := make(map[language.Tag]map[string]string, len(langs))
+	for lang, messages := range langs {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return fmt.Errorf("i18n: %w", err)
+		}
+		loaded[tag] = flatten(messages)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.loaded = loaded
+	b.rebuild()
+	return nil
+}
+
+// Watch loads the messages of the config key and reloads them when they change.
+func (b *Bundle) Watch(c config.Config, key string) error {
+	if err := b.LoadConfig(c.Value(key)); err != nil {
+		return err
+	}
+	return c.Watch(key, func(_ string, v config.Value) {
+		if err := b.LoadConfig(v); err != nil {
+			log.Errorf("failed to reload i18n messages: %v", err)
+		}
+	})
+}
+
+func flatten(v map[string]interface{}) map[string]string {
+	m := make(map[string]string, len(v))
+	var walk func(prefix string, v map[string]interface{})
+	walk = func(prefix string, v map[string]interface{}) {
+		for key, value := range v {
+			switch value := value.(type) {
+			case map[string]interface{}:
+				walk(prefix+key+".", value)
+			case map[interface{}]interface{}:
+				nested := make(map[string]interface{}, len(value))
+				for k, v := range value {
+					nested[fmt.Sprint(k)] = v
+				}
+				walk(prefix+key+".", nested)
+			default:
+				m[prefix+key] = fmt.Sprint(value)
+			}
+		}
+	}
+	walk("", v)
+	return m
+}
+
+// Localizer returns the localizer of the best language of the bundle for
+// the preferred languages, either tags or Accept-Language values.
+func (b *Bundle) Localizer(langs ...string) *Localizer {
+	var tags []language.Tag
+	for _, lang := range langs {
+		if ts, _, err := language.ParseAcceptLanguage(lang); err == nil {
+			tags = append(tags, ts...)
+		}
+	}
+	tag := b.fallback
+	b.mu.RLock()
+	if _, i, confidence := b.matcher.Match(tags...); confidence != language.No {
+		tag = b.tags[i]
+	}
+	b.mu.RUnlock()
+	return &Localizer{bundle: b, tag: tag}
+}
+
+// Localizer localizes the messages in a language.
+type Localizer struct {
+	bundle *Bundle
+	tag    language.Tag
+}
+
+// Language returns the language of the localizer.
+func (l *Localizer) Language() language.Tag {
+	return l.tag
+}
+
+// Message returns the message id in the language of the localizer, or in
+// the fallback language of the bundle, with its placeholders replaced by data.
+func (l *Localizer) Message(id string, data map[string]string) (string, bool) {
+	l.bundle.mu.RLock()
+	msg, ok := l.bundle.messages[l.tag][id]
+	if !ok {
+		msg, ok = l.bundle.messages[l.bundle.fallback][id]
+	}
+	l.bundle.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	if len(data) > 0 {
+		pairs := make([]string, 0, 2*len(data))
+		for k, v := range data {
+			pairs = append(pairs, "{"+k+"}", v)
+		}
+		msg = strings.NewReplacer(pairs...).Replace(msg)
+	}
+	return msg, true
+}
+
+// Localize returns the message id like Message, or id if it doesn't exist.
+func (l *Localizer) Localize(id string, data map[string]string) string {
+	if msg, ok := l.Message(id, data); ok {
+		return msg
+	}
+	return id
+}
+
+type localizerKey struct{}
+
+// NewContext returns a new context with the localizer.
+func NewContext(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+// FromContext returns the localizer of the context.
+func FromContext(ctx context.Context) (*Localizer, bool) {
+	l, ok := ctx.Value(localizerKey{}).(*Localizer)
+	return l, ok
+}
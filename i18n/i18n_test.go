@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/text/language"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+)
+
+func TestBundle(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/en.yaml":    {Data: []byte("hello: Hello {name}\nuser:\n  not_found: User {id} not found\n")},
+		"locales/zh-CN.json": {Data: []byte(`{"hello": "你好 {name}"}`)},
+		"locales/README.md":  {Data: []byte("ignored")},
+	}
+	b := NewBundle(language.English)
+	if err := b.LoadFS(fsys, "locales"); err != nil {
+		t.Fatal(err)
+	}
+	b.AddMessages(language.French, map[string]string{"hello": "Bonjour {name}"})
+
+	tests := []struct {
+		langs []string
+		lang  string
+		hello string
+		user  string
+	}{
+		{nil, "en", "Hello kratos", "User 1 not found"},
+		{[]string{"zh-CN,zh;q=0.9,en;q=0.8"}, "zh-CN", "你好 kratos", "User 1 not found"},
+		{[]string{"zh"}, "zh-CN", "你好 kratos", "User 1 not found"},
+		{[]string{"fr-CA", "zh"}, "fr", "Bonjour kratos", "User 1 not found"},
+		{[]string{"de"}, "en", "Hello kratos", "User 1 not found"},
+		{[]string{"not a language"}, "en", "Hello kratos", "User 1 not found"},
+	}
+	for _, test := range tests {
+		l := b.Localizer(test.langs...)
+		if lang := l.Language().String(); lang != test.lang {
+			t.Errorf("%v: language = %s, want %s", test.langs, lang, test.lang)
+		}
+		if hello := l.Localize("hello", map[string]string{"name": "kratos"}); hello != test.hello {
+			t.Errorf("%v: hello = %s, want %s", test.langs, hello, test.hello)
+		}
+		if user := l.Localize("user.not_found", map[string]string{"id": "1"}); user != test.user {
+			t.Errorf("%v: user = %s, want %s", test.langs, user, test.user)
+		}
+		if missing := l.Localize("missing", nil); missing != "missing" {
+			t.Errorf("%v: missing = %s", test.langs, missing)
+		}
+	}
+
+	ctx := NewContext(context.Background(), b.Localizer("fr"))
+	if l, ok := FromContext(ctx); !ok || l.Language() != language.French {
+		t.Error("localizer not in context")
+	}
+}
+
+func TestBundle_LoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	load := func(b *Bundle, data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		c := config.New(config.WithSource(file.NewSource(path)))
+		defer c.Close()
+		if err := c.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.LoadConfig(c.Value("i18n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := NewBundle(language.English)
+	b.AddMessages(language.English, map[string]string{"hello": "Hello", "bye": "Bye"})
+	load(b, `{"i18n": {"de": {"hello": "Hallo"}, "en": {"bye": "Goodbye"}}}`)
+	if got := b.Localizer("de").Localize("hello", nil); got != "Hallo" {
+		t.Errorf("hello = %s, want Hallo", got)
+	}
+	if got := b.Localizer("en").Localize("bye", nil); got != "Goodbye" {
+		t.Errorf("bye = %s, want the config message", got)
+	}
+
+	load(b, `{"i18n": {"fr": {"hello": "Bonjour"}}}`)
+	if l := b.Localizer("de"); l.Language() != language.English {
+		t.Errorf("expect the language removed on reload, got %s", l.Language())
+	}
+	if got := b.Localizer("en").Localize("bye", nil); got != "Bye" {
+		t.Errorf("bye = %s, want the added message back", got)
+	}
+	if got := b.Localizer("fr").Localize("hello", nil); got != "Bonjour" {
+		t.Errorf("hello = %s, want Bonjour", got)
+	}
+}
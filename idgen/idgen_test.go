@@ -0,0 +1,167 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/lock"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+func TestSnowflake(t *testing.T) {
+	if _, err := NewSnowflake(MaxWorker + 1); err == nil {
+		t.Fatal("expect the worker to be out of range")
+	}
+	s, err := NewSnowflake(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	var last int64
+	for i := 0; i < maxSequence+10; i++ {
+		if i == maxSequence+1 {
+			// the sequence is exhausted, the clock moves on.
+			now = now.Add(time.Millisecond)
+		}
+		id, err := s.Next()
+		if err != nil || id <= last {
+			t.Fatalf("got %d %v after %d, want increasing IDs", id, err, last)
+		}
+		last = id
+	}
+	now = now.Add(-500 * time.Millisecond)
+	if id, err := s.Next(); err != nil || id <= last {
+		t.Fatalf("got %d %v after %d, want increasing IDs when the clock goes backwards", id, err, last)
+	}
+	id, _ := s.Next()
+	ts, worker, seq := s.Decompose(id)
+	if !ts.Equal(time.Date(2022, 3, 1, 0, 0, 0, 1e6, time.UTC)) || worker != 7 || seq != 10 {
+		t.Errorf("got %v %d %d", ts.UTC(), worker, seq)
+	}
+	now = now.Add(-time.Second)
+	if _, err := s.Next(); !errors.Is(err, ErrClockDrift) {
+		t.Errorf("got %v, want %v beyond the maximum drift", err, ErrClockDrift)
+	}
+}
+
+func TestSnowflake_Exhausted(t *testing.T) {
+	s, _ := NewSnowflake(1, WithMaxDrift(time.Millisecond))
+	now := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	// the sequences of the millisecond and of the next one borrowed.
+	for i := 0; i < 2*(maxSequence+1); i++ {
+		if _, err := s.Next(); err != nil {
+			t.Fatalf("got %v after %d IDs", err, i)
+		}
+	}
+	if _, err := s.Next(); !errors.Is(err, ErrClockDrift) {
+		t.Errorf("got %v, want %v rather than waiting", err, ErrClockDrift)
+	}
+}
+
+func TestULID(t *testing.T) {
+	g := NewULIDGenerator()
+	now := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+	a, err := g.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := g.Next()
+	if a.String() >= b.String() {
+		t.Errorf("got %s then %s, want monotonic ULIDs", a, b)
+	}
+	if !a.Time().Equal(now) {
+		t.Errorf("got time %v, want %v", a.Time(), now)
+	}
+	s := a.String()
+	if len(s) != 26 {
+		t.Fatalf("got %s, want 26 characters", s)
+	}
+	u, err := ParseULID(strings.ToLower(s))
+	if err != nil || u != a {
+		t.Errorf("got %s %v, want %s", u, err, a)
+	}
+	if _, err = ParseULID("8" + s[1:]); !errors.Is(err, ErrInvalidULID) {
+		t.Errorf("expect the overflowing ULID to be invalid, got %v", err)
+	}
+	if u, _ := ParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAV"); u.Time().UnixNano()/1e6 != 1469922850259 {
+		t.Errorf("got %d, want the time of the spec example", u.Time().UnixNano()/1e6)
+	}
+}
+
+type testDiscovery struct {
+	instances []*registry.ServiceInstance
+}
+
+func (d *testDiscovery) GetService(ctx context.Context, name string) ([]*registry.ServiceInstance, error) {
+	return d.instances, nil
+}
+
+func (d *testDiscovery) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestAllocateWorker(t *testing.T) {
+	ctx := context.Background()
+	locker := lock.New(lock.NewMemoryDriver())
+	defer locker.Stop(ctx)
+	d := &testDiscovery{instances: []*registry.ServiceInstance{{Metadata: map[string]string{MetadataKey: "0"}}}}
+
+	id, lk, err := AllocateWorker(ctx, locker, "user", WithDiscovery(d), WithMaxWorker(2))
+	if err != nil || id != 1 {
+		t.Fatalf("got %d %v, want 1", id, err)
+	}
+	if id, _, err = AllocateWorker(ctx, locker, "user", WithDiscovery(d), WithMaxWorker(2)); err != nil || id != 2 {
+		t.Fatalf("got %d %v, want 2", id, err)
+	}
+	if _, _, err = AllocateWorker(ctx, locker, "user", WithDiscovery(d), WithMaxWorker(2)); !errors.Is(err, ErrNoWorker) {
+		t.Fatalf("got %v, want no worker", err)
+	}
+	_ = lk.Unlock(ctx)
+	if id, _, err = AllocateWorker(ctx, locker, "user", WithDiscovery(d), WithMaxWorker(2)); err != nil || id != 1 {
+		t.Fatalf("got %d %v, want the released worker 1", id, err)
+	}
+}
+
+// expiringDriver fails the renewals once expired.
+type expiringDriver struct {
+	*lock.MemoryDriver
+	expired int32
+}
+
+func (d *expiringDriver) Refresh(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	if atomic.LoadInt32(&d.expired) == 1 {
+		return false, nil
+	}
+	return d.MemoryDriver.Refresh(ctx, key, token, ttl)
+}
+
+func TestSnowflake_Lost(t *testing.T) {
+	ctx := context.Background()
+	driver := &expiringDriver{MemoryDriver: lock.NewMemoryDriver()}
+	locker := lock.New(driver, lock.WithTTL(30*time.Millisecond), lock.WithRetry(5*time.Millisecond))
+	defer locker.Stop(ctx)
+	id, lk, err := AllocateWorker(ctx, locker, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := NewSnowflake(id, WithLock(lk))
+	if _, err = s.Next(); err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&driver.expired, 1)
+	select {
+	case <-lk.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("expect the lock to be lost")
+	}
+	if _, err = s.Next(); !errors.Is(err, ErrWorkerLost) {
+		t.Errorf("got %v, want %v", err, ErrWorkerLost)
+	}
+}
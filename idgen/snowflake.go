@@ -0,0 +1,128 @@
+// Package idgen generates sortable unique IDs: 64 bit snowflake IDs of a
+// worker, whose ID is allocated through the locks and the registry, and ULIDs.
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/lock"
+)
+
+const (
+	workerBits   = 10
+	sequenceBits = 12
+	// MaxWorker is the maximum worker ID of a snowflake.
+	MaxWorker    = 1<<workerBits - 1
+	maxSequence  = 1<<sequenceBits - 1
+	timeShift    = workerBits + sequenceBits
+	workerShift  = sequenceBits
+	defaultEpoch = 1640995200000 // 2022-01-01T00:00:00Z in milliseconds
+)
+
+var (
+	// ErrClockDrift is returned when the IDs would be ahead of the clock by more
+	// than the maximum drift, e.g. the clock went backwards.
+	ErrClockDrift = errors.New("idgen: clock drifted beyond the maximum drift")
+	// ErrWorkerLost is returned once the lock of the worker is lost.
+	ErrWorkerLost = errors.New("idgen: worker lock lost")
+)
+
+// SnowflakeOption is snowflake option.
+type SnowflakeOption func(*Snowflake)
+
+// WithEpoch with the epoch of the timestamps, default is 2022-01-01T00:00:00Z.
+// It must never change once IDs are generated.
+func WithEpoch(epoch time.Time) SnowflakeOption {
+	return func(s *Snowflake) {
+		s.epoch = epoch.UnixNano() / int64(time.Millisecond)
+	}
+}
+
+// WithMaxDrift with how far the IDs may be ahead of the clock, borrowing the
+// next milliseconds when the clock goes backwards or the sequence is exhausted,
+// default is 1s.
+func WithMaxDrift(d time.Duration) SnowflakeOption {
+	return func(s *Snowflake) {
+		s.maxDrift = int64(d / time.Millisecond)
+	}
+}
+
+// WithLock with the lock of the worker allocated by AllocateWorker, no ID is
+// generated once it is lost.
+func WithLock(lk *lock.Lock) SnowflakeOption {
+	return func(s *Snowflake) {
+		s.lost = lk.Lost()
+	}
+}
+
+// Snowflake generates the IDs of a worker: 41 bits of milliseconds since the
+// epoch, 10 bits of worker ID and 12 bits of sequence, up to 4096 IDs per
+// millisecond. The IDs stay increasing when the clock goes backwards, within
+// the maximum drift.
+type Snowflake struct {
+	mu       sync.Mutex
+	epoch    int64
+	worker   int64
+	last     int64
+	sequence int64
+	maxDrift int64
+	lost     <-chan struct{}
+	now      func() time.Time
+}
+
+// NewSnowflake new a snowflake of worker, between 0 and MaxWorker.
+func NewSnowflake(worker int64, opts ...SnowflakeOption) (*Snowflake, error) {
+	if worker < 0 || worker > MaxWorker {
+		return nil, fmt.Errorf("idgen: worker %d out of range [0, %d]", worker, MaxWorker)
+	}
+	s := &Snowflake{
+		epoch:    defaultEpoch,
+		worker:   worker,
+		maxDrift: int64(time.Second / time.Millisecond),
+		now:      time.Now,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s, nil
+}
+
+// Next returns the next ID, it fails rather than waiting for the clock when
+// the IDs would be ahead of it by more than the maximum drift.
+func (s *Snowflake) Next() (int64, error) {
+	select {
+	case <-s.lost:
+		return 0, ErrWorkerLost
+	default:
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ms := s.millis()
+	last, sequence := s.last, s.sequence
+	if ms > last {
+		last, sequence = ms, 0
+	} else if sequence < maxSequence {
+		sequence++
+	} else {
+		// the sequence of the millisecond is exhausted, borrow the next one.
+		last, sequence = last+1, 0
+	}
+	if last-ms > s.maxDrift {
+		return 0, ErrClockDrift
+	}
+	s.last, s.sequence = last, sequence
+	return last<<timeShift | s.worker<<workerShift | sequence, nil
+}
+
+// Decompose returns the time, the worker and the sequence of id.
+func (s *Snowflake) Decompose(id int64) (t time.Time, worker, sequence int64) {
+	ms := id>>timeShift + s.epoch
+	return time.Unix(0, ms*int64(time.Millisecond)), id >> workerShift & MaxWorker, id & maxSequence
+}
+
+func (s *Snowflake) millis() int64 {
+	return s.now().UnixNano()/int64(time.Millisecond) - s.epoch
+}
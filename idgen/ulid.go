@@ -0,0 +1,128 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrInvalidULID is returned when parsing a malformed ULID.
+var ErrInvalidULID = errors.New("idgen: invalid ULID")
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var decoding [256]byte
+
+func init() {
+	for i := range decoding {
+		decoding[i] = 0xFF
+	}
+	for i := 0; i < len(crockford); i++ {
+		decoding[crockford[i]] = byte(i)
+		decoding[crockford[i]|0x20] = byte(i)
+	}
+}
+
+// ULID is a universally unique lexicographically sortable identifier:
+// 48 bits of milliseconds since the Unix epoch and 80 random bits.
+type ULID [16]byte
+
+// Time returns the time of the ULID.
+func (u ULID) Time() time.Time {
+	var ms int64
+	for i := 0; i < 6; i++ {
+		ms = ms<<8 | int64(u[i])
+	}
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// String returns the 26 characters Crockford base32 encoding of the ULID.
+func (u ULID) String() string {
+	b := make([]byte, 26)
+	// 128 bits are encoded from the least significant 5 bits.
+	var acc uint16
+	var bits uint
+	j := 25
+	for i := 15; i >= 0; i-- {
+		acc |= uint16(u[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			b[j] = crockford[acc&0x1F]
+			acc >>= 5
+			bits -= 5
+			j--
+		}
+	}
+	b[0] = crockford[acc&0x1F]
+	return string(b)
+}
+
+// ParseULID parses the string encoding of a ULID, case insensitively.
+func ParseULID(s string) (ULID, error) {
+	var u ULID
+	if len(s) != 26 || decoding[s[0]] > 7 {
+		return u, ErrInvalidULID
+	}
+	var acc uint16
+	var bits uint
+	j := 15
+	for i := 25; i >= 0; i-- {
+		v := decoding[s[i]]
+		if v == 0xFF {
+			return u, ErrInvalidULID
+		}
+		acc |= uint16(v) << bits
+		bits += 5
+		if bits >= 8 && j >= 0 {
+			u[j] = byte(acc)
+			acc >>= 8
+			bits -= 8
+			j--
+		}
+	}
+	return u, nil
+}
+
+// ULIDGenerator generates monotonic ULIDs: the random part of the ULIDs of a
+// same millisecond is incremented.
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	entropy io.Reader
+	last    ULID
+	now     func() time.Time
+}
+
+// NewULIDGenerator new a ULID generator using crypto/rand.
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{entropy: rand.Reader, now: time.Now}
+}
+
+// Next returns the next ULID.
+func (g *ULIDGenerator) Next() (ULID, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var u ULID
+	ms := uint64(g.now().UnixNano() / int64(time.Millisecond))
+	for i := 5; i >= 0; i-- {
+		u[i] = byte(ms)
+		ms >>= 8
+	}
+	if u.Time().Equal(g.last.Time()) || u.Time().Before(g.last.Time()) {
+		u = g.last
+		for i := 15; i >= 6; i-- {
+			u[i]++
+			if u[i] != 0 {
+				g.last = u
+				return u, nil
+			}
+		}
+		return ULID{}, errors.New("idgen: ULID random part overflow")
+	}
+	if _, err := io.ReadFull(g.entropy, u[6:]); err != nil {
+		return ULID{}, err
+	}
+	g.last = u
+	return u, nil
+}
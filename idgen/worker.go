@@ -0,0 +1,85 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/go-kratos/kratos/v2/lock"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// MetadataKey is the key of the worker ID in the metadata of the instances.
+const MetadataKey = "idgen.worker"
+
+// ErrNoWorker is returned when all the worker IDs of a service are allocated.
+var ErrNoWorker = errors.New("idgen: no worker ID available")
+
+// WorkerOption is worker allocation option.
+type WorkerOption func(*workerOptions)
+
+type workerOptions struct {
+	discovery registry.Discovery
+	max       int64
+}
+
+// WithDiscovery with the discovery of the instances of the service, the worker
+// IDs in their metadata are skipped, e.g. of the instances not using the locks.
+func WithDiscovery(d registry.Discovery) WorkerOption {
+	return func(o *workerOptions) {
+		o.discovery = d
+	}
+}
+
+// WithMaxWorker with the maximum worker ID, default is MaxWorker.
+func WithMaxWorker(n int64) WorkerOption {
+	return func(o *workerOptions) {
+		o.max = n
+	}
+}
+
+// AllocateWorker allocates the lowest worker ID of service available, by holding
+// its lock, until the lock is unlocked or lost, or the locker stops with the app.
+// The snowflake of the worker stops once the lock is lost, and the worker ID
+// should be published in the metadata of the instance:
+//
+//	worker, lk, err := idgen.AllocateWorker(ctx, locker, "user", idgen.WithDiscovery(r))
+//	sf, err := idgen.NewSnowflake(worker, idgen.WithLock(lk))
+//	app := kratos.New(
+//		kratos.Name("user"),
+//		kratos.Metadata(map[string]string{idgen.MetadataKey: strconv.FormatInt(worker, 10)}),
+//		kratos.Server(srv, locker),
+//	)
+func AllocateWorker(ctx context.Context, locker *lock.Locker, service string, opts ...WorkerOption) (int64, *lock.Lock, error) {
+	o := &workerOptions{max: MaxWorker}
+	for _, opt := range opts {
+		opt(o)
+	}
+	used := make(map[int64]bool)
+	if o.discovery != nil {
+		instances, err := o.discovery.GetService(ctx, service)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, instance := range instances {
+			if id, err := strconv.ParseInt(instance.Metadata[MetadataKey], 10, 64); err == nil {
+				used[id] = true
+			}
+		}
+	}
+	for id := int64(0); id <= o.max; id++ {
+		if used[id] {
+			continue
+		}
+		lk, err := locker.TryLock(ctx, fmt.Sprintf("idgen/%s/%d", service, id))
+		if errors.Is(err, lock.ErrNotAcquired) {
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		return id, lk, nil
+	}
+	return 0, nil, ErrNoWorker
+}
@@ -113,3 +113,22 @@ func (mc *mergeCtx) Value(key interface{}) interface{} {
 	}
 	return mc.parent2.Value(key)
 }
+
+type detachedCtx struct {
+	context.Context
+}
+
+// Detach returns a context carrying the values of ctx, which is never canceled
+// and has no deadline.
+func Detach(ctx context.Context) context.Context {
+	return detachedCtx{ctx}
+}
+
+// Deadline implements context.Context.
+func (detachedCtx) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+// Done implements context.Context.
+func (detachedCtx) Done() <-chan struct{} { return nil }
+
+// Err implements context.Context.
+func (detachedCtx) Err() error { return nil }
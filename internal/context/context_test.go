@@ -278,3 +278,43 @@ func Test_Err2(t *testing.T) {
 		t.Errorf("expect %v, got %v", context.Canceled, ctx.Err())
 	}
 }
+
+func TestDetach(t *testing.T) {
+	type ctxKey struct{}
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "value"), time.Millisecond)
+	cancel()
+	ctx := Detach(parent)
+	if ctx.Err() != nil || ctx.Done() != nil {
+		t.Errorf("expect the detached context not canceled, got %v", ctx.Err())
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expect no deadline")
+	}
+	if v := ctx.Value(ctxKey{}); v != "value" {
+		t.Errorf("expect the values kept, got %v", v)
+	}
+}
+
+func TestMergeRequest(t *testing.T) {
+	if ctx, _ := MergeRequest(context.Background()); ctx != context.Background() {
+		t.Error("expect the context without request kept")
+	}
+	req, cancelCalls := WithRequest(context.Background())
+	call, release := MergeRequest(Detach(req))
+	released, releaseOther := MergeRequest(req)
+	releaseOther()
+	if !errors.Is(released.Err(), context.Canceled) {
+		t.Error("expect the released call canceled")
+	}
+	if call.Err() != nil {
+		t.Fatal("expect the call running until the request is canceled")
+	}
+	cancelCalls()
+	if !errors.Is(call.Err(), context.Canceled) {
+		t.Errorf("expect the detached call canceled with the request, got %v", call.Err())
+	}
+	release()
+	if late, _ := MergeRequest(req); !errors.Is(late.Err(), context.Canceled) {
+		t.Error("expect the calls made once the request is canceled to be canceled")
+	}
+}
@@ -0,0 +1,61 @@
+package context
+
+import (
+	"context"
+	"sync"
+)
+
+type requestKey struct{}
+
+// request cancels the contexts of the calls made while handling it.
+type request struct {
+	mu      sync.Mutex
+	done    bool
+	next    uint64
+	cancels map[uint64]context.CancelFunc
+}
+
+// WithRequest returns a context carrying a request, the calls merged with it by
+// MergeRequest are canceled by the returned func, e.g. once the request is done
+// or handled.
+func WithRequest(ctx context.Context) (context.Context, func()) {
+	r := &request{cancels: make(map[uint64]context.CancelFunc)}
+	return context.WithValue(ctx, requestKey{}, r), r.cancel
+}
+
+// MergeRequest returns a context of ctx canceled with the request it carries,
+// if any, even if ctx is detached from the cancellation of the request. No
+// goroutine is started, the returned func must be called once the call is done.
+func MergeRequest(ctx context.Context) (context.Context, context.CancelFunc) {
+	r, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r.mu.Lock()
+	if r.done {
+		r.mu.Unlock()
+		cancel()
+		return ctx, cancel
+	}
+	id := r.next
+	r.next++
+	r.cancels[id] = cancel
+	r.mu.Unlock()
+	return ctx, func() {
+		r.mu.Lock()
+		delete(r.cancels, id)
+		r.mu.Unlock()
+		cancel()
+	}
+}
+
+func (r *request) cancel() {
+	r.mu.Lock()
+	cancels := r.cancels
+	r.done, r.cancels = true, nil
+	r.mu.Unlock()
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
@@ -0,0 +1,18 @@
+package host
+
+import (
+	"context"
+	"net"
+)
+
+// Listen announces on the local address, with SO_REUSEPORT set when reusePort
+// is true so that several processes can share the port, e.g. for a rolling
+// restart. The listener is bound when Listen returns, so its real port, e.g.
+// of ":0", is known before the server starts.
+func Listen(network, address string, reusePort bool) (net.Listener, error) {
+	if !reusePort {
+		return net.Listen(network, address)
+	}
+	lc := net.ListenConfig{Control: reusePortControl}
+	return lc.Listen(context.Background(), network, address)
+}
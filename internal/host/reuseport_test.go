@@ -0,0 +1,23 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package host
+
+import "testing"
+
+func TestListenReusePort(t *testing.T) {
+	lis1, err := Listen("tcp", "127.0.0.1:0", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis1.Close()
+	lis2, err := Listen("tcp", lis1.Addr().String(), true)
+	if err != nil {
+		t.Fatalf("expect the port shared, got %v", err)
+	}
+	_ = lis2.Close()
+	if lis3, err := Listen("tcp", lis1.Addr().String(), false); err == nil {
+		_ = lis3.Close()
+		t.Fatal("expect the port in use without SO_REUSEPORT")
+	}
+}
@@ -0,0 +1,88 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	key      string
+	value    interface{}
+	expireAt time.Time
+}
+
+// Cache is an LRU cache holding at most size entries, which may expire.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	ll      *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+// New new a cache holding at most size entries, size must be positive.
+func New(size int) *Cache {
+	if size <= 0 {
+		panic("lru: the size must be positive")
+	}
+	return &Cache{
+		size:    size,
+		ll:      list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// Get gets the value of key if it isn't expired.
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if !e.expireAt.IsZero() && c.now().After(e.expireAt) {
+		c.ll.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+// Set sets the value of key for ttl, 0 never expires, evicting the least
+// recently used entry if full.
+func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
+	var expireAt time.Time
+	if ttl != 0 {
+		expireAt = c.now().Add(ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry)
+		e.value, e.expireAt = value, expireAt
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.ll.PushFront(&entry{key: key, value: value, expireAt: expireAt})
+	if c.ll.Len() > c.size {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.entries, el.Value.(*entry).key)
+	}
+}
+
+// Delete deletes keys, the keys not cached are ignored.
+func (c *Cache) Delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := c.entries[key]; ok {
+			c.ll.Remove(el)
+			delete(c.entries, key)
+		}
+	}
+}
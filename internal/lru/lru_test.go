@@ -0,0 +1,45 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := New(2)
+	c.now = func() time.Time { return now }
+	c.Set("a", 1, 0)
+	c.Set("b", 2, time.Minute)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expect a to be cached")
+	}
+	c.Set("c", 3, 0)
+	if _, ok := c.Get("b"); ok {
+		t.Error("expect the least recently used entry to be evicted")
+	}
+	c.Set("c", 4, time.Second)
+	if v, ok := c.Get("c"); !ok || v != 4 {
+		t.Errorf("got %v %v, want 4", v, ok)
+	}
+	now = now.Add(time.Hour)
+	if _, ok := c.Get("c"); ok {
+		t.Error("expect the expired entry to miss")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expect the entry with no ttl not to expire")
+	}
+	c.Delete("a", "unknown")
+	if _, ok := c.Get("a"); ok {
+		t.Error("expect the deleted entry to miss")
+	}
+}
+
+func TestNew(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expect a non-positive size to panic")
+		}
+	}()
+	New(0)
+}
@@ -0,0 +1,54 @@
+// Package matcher matches the middleware of an operation by selector.
+package matcher
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// Matcher is a middleware matcher, it isn't safe to Add while matching.
+type Matcher struct {
+	prefix   []string
+	prefixMs map[string][]middleware.Middleware
+	exact    map[string][]middleware.Middleware
+}
+
+// New new a middleware matcher.
+func New() *Matcher {
+	return &Matcher{
+		prefixMs: make(map[string][]middleware.Middleware),
+		exact:    make(map[string][]middleware.Middleware),
+	}
+}
+
+// Add adds middleware for a selector, either an operation such as
+// "/helloworld.Greeter/SayHello", or a prefix ending with "*" such as "/helloworld.Greeter/*".
+func (m *Matcher) Add(selector string, ms ...middleware.Middleware) {
+	if strings.HasSuffix(selector, "*") {
+		selector = strings.TrimSuffix(selector, "*")
+		if _, ok := m.prefixMs[selector]; !ok {
+			m.prefix = append(m.prefix, selector)
+			sort.Slice(m.prefix, func(i, j int) bool { return len(m.prefix[i]) < len(m.prefix[j]) })
+		}
+		m.prefixMs[selector] = append(m.prefixMs[selector], ms...)
+		return
+	}
+	m.exact[selector] = append(m.exact[selector], ms...)
+}
+
+// Match returns the middleware of the operation, the prefix ones first from
+// the shortest prefix, then the ones of the operation. A nil Matcher matches none.
+func (m *Matcher) Match(operation string) []middleware.Middleware {
+	if m == nil {
+		return nil
+	}
+	var ms []middleware.Middleware
+	for _, prefix := range m.prefix {
+		if strings.HasPrefix(operation, prefix) {
+			ms = append(ms, m.prefixMs[prefix]...)
+		}
+	}
+	return append(ms, m.exact[operation]...)
+}
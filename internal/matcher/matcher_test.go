@@ -0,0 +1,42 @@
+package matcher
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+func tag(name string) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			return handler(ctx, append(req.([]string), name))
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	m := New()
+	m.Add("/helloworld.Greeter/SayHello", tag("method"))
+	m.Add("/helloworld.Greeter/*", tag("service"))
+	m.Add("/*", tag("all"))
+
+	tests := []struct {
+		operation string
+		expect    []string
+	}{
+		{"/helloworld.Greeter/SayHello", []string{"all", "service", "method"}},
+		{"/helloworld.Greeter/SayHi", []string{"all", "service"}},
+		{"/other.Service/Call", []string{"all"}},
+	}
+	for _, test := range tests {
+		h := middleware.Chain(m.Match(test.operation)...)(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})
+		reply, _ := h(context.Background(), []string(nil))
+		if !reflect.DeepEqual(reply, test.expect) {
+			t.Errorf("%s: expect %v, got %v", test.operation, test.expect, reply)
+		}
+	}
+}
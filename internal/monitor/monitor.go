@@ -0,0 +1,46 @@
+// Package monitor reports the requests a middleware in monitor only mode lets
+// through instead of rejecting them, so that its limits can be tuned safely.
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/internal/throttle"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+// HashKey returns a short SHA-256 of key, identifying it in the metrics and
+// logs without revealing it.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Reporter logs the requests that would be rejected, at most once per second,
+// and counts them.
+type Reporter struct {
+	name    string
+	counter metrics.Counter
+	logger  log.Logger
+	warn    *throttle.Throttle
+}
+
+// NewReporter returns a reporter logging as name, the counter may be nil.
+func NewReporter(name string, counter metrics.Counter, logger log.Logger) *Reporter {
+	return &Reporter{name: name, counter: counter, logger: logger, warn: throttle.New(time.Second)}
+}
+
+// Report reports the request of ctx described by format and a, counted with
+// the label values lvs.
+func (r *Reporter) Report(ctx context.Context, lvs []string, format string, a ...interface{}) {
+	if n, ok := r.warn.Allow(); ok {
+		log.NewHelper(r.logger).WithContext(ctx).Warnf(r.name+": monitor only, would reject "+format+" (%d suppressed since the last warning)", append(a, n)...)
+	}
+	if r.counter != nil {
+		r.counter.With(lvs...).Inc()
+	}
+}
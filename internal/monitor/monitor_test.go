@@ -0,0 +1,42 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+type counter struct {
+	lvs   []string
+	value float64
+}
+
+func (c *counter) With(lvs ...string) metrics.Counter { c.lvs = lvs; return c }
+func (c *counter) Inc()                               { c.value++ }
+func (c *counter) Add(delta float64)                  { c.value += delta }
+
+func TestReporter(t *testing.T) {
+	var buf bytes.Buffer
+	c := &counter{}
+	r := NewReporter("test", c, log.NewStdLogger(&buf))
+	for i := 0; i < 3; i++ {
+		r.Report(context.Background(), []string{"op"}, "%s", "op")
+	}
+	if c.value != 3 || len(c.lvs) != 1 || c.lvs[0] != "op" {
+		t.Errorf("counted %v %v, want 3 [op]", c.value, c.lvs)
+	}
+	if got := buf.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "test: monitor only, would reject op (0 suppressed") {
+		t.Errorf("logged %q, want one warning", got)
+	}
+	NewReporter("test", nil, log.NewStdLogger(&buf)).Report(context.Background(), nil, "op")
+}
+
+func TestHashKey(t *testing.T) {
+	if h := HashKey("alice"); h == "alice" || len(h) != 16 || h != HashKey("alice") {
+		t.Errorf("unexpected hash %q", h)
+	}
+}
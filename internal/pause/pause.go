@@ -0,0 +1,109 @@
+package pause
+
+import (
+	"net"
+	"sync"
+)
+
+// Gate pauses the accept loop of the listeners it wraps,
+// the connections already accepted are kept.
+type Gate struct {
+	mu       sync.Mutex
+	resume   chan struct{}
+	changeMu sync.Mutex // serializes the state changes with their callbacks.
+	onChange func(paused bool)
+}
+
+// New returns an open gate, onChange is called in order on each state change
+// and may be nil, it must not pause or resume the gate.
+func New(onChange func(paused bool)) *Gate {
+	return &Gate{onChange: onChange}
+}
+
+// Pause stops accepting new connections.
+func (g *Gate) Pause() {
+	g.set(true)
+}
+
+// Resume resumes accepting new connections.
+func (g *Gate) Resume() {
+	g.set(false)
+}
+
+func (g *Gate) set(paused bool) {
+	g.changeMu.Lock()
+	defer g.changeMu.Unlock()
+	g.mu.Lock()
+	if (g.resume != nil) == paused {
+		g.mu.Unlock()
+		return
+	}
+	if paused {
+		g.resume = make(chan struct{})
+	} else {
+		close(g.resume)
+		g.resume = nil
+	}
+	g.mu.Unlock()
+	if g.onChange != nil {
+		g.onChange(paused)
+	}
+}
+
+// Paused reports whether the gate is paused.
+func (g *Gate) Paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.resume != nil
+}
+
+// wait blocks while the gate is paused, it returns false if done is closed first.
+func (g *Gate) wait(done <-chan struct{}) bool {
+	for {
+		g.mu.Lock()
+		resume := g.resume
+		g.mu.Unlock()
+		if resume == nil {
+			return true
+		}
+		select {
+		case <-resume:
+		case <-done:
+			return false
+		}
+	}
+}
+
+// Listener wraps lis so that Accept blocks while the gate is paused,
+// the pending connections wait in the listen backlog.
+func (g *Gate) Listener(lis net.Listener) net.Listener {
+	return &listener{Listener: lis, gate: g, done: make(chan struct{})}
+}
+
+type listener struct {
+	net.Listener
+	gate *Gate
+	once sync.Once
+	done chan struct{}
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	if !l.gate.wait(l.done) {
+		return nil, net.ErrClosed
+	}
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	// paused while blocked in Accept.
+	if !l.gate.wait(l.done) {
+		_ = conn.Close()
+		return nil, net.ErrClosed
+	}
+	return conn, nil
+}
+
+func (l *listener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
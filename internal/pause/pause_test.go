@@ -0,0 +1,104 @@
+package pause
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGate(t *testing.T) {
+	var changes []bool
+	g := New(func(paused bool) { changes = append(changes, paused) })
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := g.Listener(lis)
+	defer l.Close()
+
+	g.Pause()
+	g.Pause()
+	if !g.Paused() {
+		t.Fatal("gate not paused")
+	}
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	select {
+	case <-accepted:
+		t.Fatal("connection accepted while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	g.Resume()
+	select {
+	case c := <-accepted:
+		c.Close()
+	case <-time.After(time.Second):
+		t.Fatal("connection not accepted after resume")
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("changes = %v, want [true false]", changes)
+	}
+}
+
+func TestGateClose(t *testing.T) {
+	g := New(nil)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := g.Listener(lis)
+	g.Pause()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := l.Accept()
+		errc <- err
+	}()
+	_ = l.Close()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("err = %v, want net.ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Accept blocked after Close")
+	}
+}
+
+func TestGate_OnChangeOrder(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		changes []bool
+	)
+	g := New(func(paused bool) {
+		mu.Lock()
+		changes = append(changes, paused)
+		mu.Unlock()
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); g.Pause() }()
+		go func() { defer wg.Done(); g.Resume() }()
+	}
+	wg.Wait()
+	for i, paused := range changes {
+		if paused != (i%2 == 0) {
+			t.Fatalf("changes = %v, want alternating from true", changes)
+		}
+	}
+	if last := len(changes) > 0 && changes[len(changes)-1]; last != g.Paused() {
+		t.Errorf("last change %v, but paused %v", last, g.Paused())
+	}
+}
@@ -0,0 +1,67 @@
+// Package reply copies a reply returned by client middleware into the reply of the caller.
+package reply
+
+import (
+	"context"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Copy copies src into dst when a client middleware returned a reply
+// other than the one of the caller, e.g. a shared or cached reply.
+// Proto messages are deep copied, other types are shallow copied. A src
+// of another type than dst is ignored, like any reply returned by the
+// middleware used to be, so the existing middleware keeps working.
+func Copy(dst, src interface{}) {
+	if src == nil || dst == src {
+		return
+	}
+	if d, ok := dst.(proto.Message); ok {
+		if s, ok := src.(proto.Message); ok && d.ProtoReflect().Descriptor() == s.ProtoReflect().Descriptor() {
+			proto.Reset(d)
+			proto.Merge(d, s)
+			return
+		}
+	}
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Type() == dv.Type() {
+		if sv.IsNil() {
+			return
+		}
+		sv = sv.Elem()
+	}
+	if sv.Type().AssignableTo(dv.Elem().Type()) {
+		dv.Elem().Set(sv)
+	}
+}
+
+type targetKey struct{}
+
+// NewContext returns a context carrying the reply the transports decode into,
+// e.g. a private reply of a call shared by several callers.
+func NewContext(ctx context.Context, reply interface{}) context.Context {
+	return context.WithValue(ctx, targetKey{}, reply)
+}
+
+// FromContext returns the reply the transports decode into, if any.
+func FromContext(ctx context.Context) (interface{}, bool) {
+	reply := ctx.Value(targetKey{})
+	return reply, reply != nil
+}
+
+// New returns a new empty reply of the type of reply, nil if it isn't a pointer.
+func New(reply interface{}) interface{} {
+	if m, ok := reply.(proto.Message); ok {
+		return m.ProtoReflect().New().Interface()
+	}
+	v := reflect.ValueOf(reply)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+	return reflect.New(v.Type().Elem()).Interface()
+}
@@ -0,0 +1,41 @@
+package reply
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestCopy(t *testing.T) {
+	src := wrapperspb.String("kratos")
+	dst := wrapperspb.String("stale")
+	Copy(dst, src)
+	if dst.Value != "kratos" || dst == src {
+		t.Errorf("expect a deep copy, got %v", dst)
+	}
+
+	type reply struct{ Name string }
+	var r reply
+	Copy(&r, &reply{Name: "kratos"})
+	if r.Name != "kratos" {
+		t.Errorf("expect kratos, got %s", r.Name)
+	}
+	Copy(&r, 1)
+	Copy(&r, &r)
+	if r.Name != "kratos" {
+		t.Errorf("expect a mismatched type ignored, got %s", r.Name)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if r, ok := New(wrapperspb.String("kratos")).(*wrapperspb.StringValue); !ok || r.Value != "" {
+		t.Errorf("expect an empty proto reply, got %v", r)
+	}
+	type reply struct{ Name string }
+	if r, ok := New(&reply{Name: "kratos"}).(*reply); !ok || r.Name != "" {
+		t.Errorf("expect an empty reply, got %v", r)
+	}
+	if r := New(reply{}); r != nil {
+		t.Errorf("expect nil for a non-pointer, got %v", r)
+	}
+}
@@ -0,0 +1,36 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttle allows an action at most once per interval, e.g. logging a warning
+// repeated under load, counting the suppressed ones.
+type Throttle struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu         sync.Mutex
+	last       time.Time
+	suppressed int
+}
+
+// New returns a throttle allowing an action at most once per interval.
+func New(interval time.Duration) *Throttle {
+	return &Throttle{interval: interval, now: time.Now}
+}
+
+// Allow reports whether the action is allowed now, and the count of the ones
+// suppressed since the last one allowed.
+func (t *Throttle) Allow() (suppressed int, ok bool) {
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.last.IsZero() && now.Sub(t.last) < t.interval {
+		t.suppressed++
+		return 0, false
+	}
+	suppressed, t.suppressed, t.last = t.suppressed, 0, now
+	return suppressed, true
+}
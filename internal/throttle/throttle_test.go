@@ -0,0 +1,24 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	now := time.Unix(0, 0)
+	th := New(time.Second)
+	th.now = func() time.Time { return now }
+	if _, ok := th.Allow(); !ok {
+		t.Fatal("expect the first action allowed")
+	}
+	for i := 0; i < 3; i++ {
+		if _, ok := th.Allow(); ok {
+			t.Fatal("expect the actions within the interval suppressed")
+		}
+	}
+	now = now.Add(time.Second)
+	if n, ok := th.Allow(); !ok || n != 3 {
+		t.Errorf("expect allowed after the interval with 3 suppressed, got %d %v", n, ok)
+	}
+}
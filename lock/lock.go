@@ -0,0 +1,244 @@
+// Package lock implements distributed locks over a Driver, e.g. Redis or etcd.
+// The locks are acquired for a TTL and renewed until unlocked, the Locker is a
+// transport.Server releasing the locks still held when the app stops:
+//
+//	locker := lock.New(redis.New(client))
+//	app := kratos.New(kratos.Server(httpSrv, locker))
+//
+//	lk, err := locker.TryLock(ctx, "jobs:report")
+//	if errors.Is(err, lock.ErrNotAcquired) {
+//		return nil
+//	}
+//	defer lk.Unlock(ctx)
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Server = (*Locker)(nil)
+
+// ErrNotAcquired is returned by TryLock when the lock is held by another owner.
+var ErrNotAcquired = errors.New("lock: not acquired")
+
+// Driver acquires, renews and releases the locks, identified by a key and
+// owned by a unique token.
+type Driver interface {
+	// Acquire acquires key for ttl, it returns false if key is held by another token.
+	Acquire(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// Refresh extends the ttl of key, it returns false if key isn't held by token anymore.
+	Refresh(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// Release releases key if it is held by token.
+	Release(ctx context.Context, key, token string) error
+}
+
+// Option is locker option.
+type Option func(*Locker)
+
+// WithTTL with the TTL of the locks, they are renewed every third of it, default is 30s.
+func WithTTL(ttl time.Duration) Option {
+	return func(l *Locker) {
+		l.ttl = ttl
+	}
+}
+
+// WithRetry with the interval between the attempts of Lock, and of the
+// renewals failed, default is 100ms.
+func WithRetry(interval time.Duration) Option {
+	return func(l *Locker) {
+		l.retry = interval
+	}
+}
+
+// WithLogger with locker logger.
+func WithLogger(logger log.Logger) Option {
+	return func(l *Locker) {
+		l.log = log.NewHelper(logger)
+	}
+}
+
+// Locker acquires the locks of a driver.
+type Locker struct {
+	driver Driver
+	ttl    time.Duration
+	retry  time.Duration
+	log    *log.Helper
+
+	mu   sync.Mutex
+	held map[*Lock]struct{}
+}
+
+// New new a locker of driver.
+func New(driver Driver, opts ...Option) *Locker {
+	l := &Locker{
+		driver: driver,
+		ttl:    30 * time.Second,
+		retry:  100 * time.Millisecond,
+		log:    log.NewHelper(log.GetLogger()),
+		held:   make(map[*Lock]struct{}),
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+// TryLock acquires the lock of key, it returns ErrNotAcquired if the lock is held.
+func (l *Locker) TryLock(ctx context.Context, key string) (*Lock, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	// the TTL runs from the request at the latest
+	acquired := time.Now()
+	ok, err := l.driver.Acquire(ctx, key, token, l.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotAcquired
+	}
+	lk := &Lock{
+		locker: l,
+		key:    key,
+		token:  token,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		lost:   make(chan struct{}),
+	}
+	l.mu.Lock()
+	l.held[lk] = struct{}{}
+	l.mu.Unlock()
+	go lk.renew(acquired)
+	return lk, nil
+}
+
+// Lock acquires the lock of key, waiting until it is released or ctx is done.
+func (l *Locker) Lock(ctx context.Context, key string) (*Lock, error) {
+	for {
+		lk, err := l.TryLock(ctx, key)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lk, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(l.retry):
+		}
+	}
+}
+
+// Start does nothing, the locks are acquired on demand.
+func (l *Locker) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop releases the locks still held.
+func (l *Locker) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	held := make([]*Lock, 0, len(l.held))
+	for lk := range l.held {
+		held = append(held, lk)
+	}
+	l.mu.Unlock()
+	var err error
+	for _, lk := range held {
+		if uerr := lk.Unlock(ctx); uerr != nil {
+			l.log.Errorf("failed to release lock %s: %v", lk.key, uerr)
+			err = uerr
+		}
+	}
+	return err
+}
+
+// Lock is an acquired lock.
+type Lock struct {
+	locker *Locker
+	key    string
+	token  string
+	once   sync.Once
+	stop   chan struct{}
+	done   chan struct{}
+	lost   chan struct{}
+}
+
+// Key returns the key of the lock.
+func (lk *Lock) Key() string {
+	return lk.key
+}
+
+// Lost returns a channel closed when the lock is lost, it couldn't be renewed
+// within two thirds of its TTL, so that the work it guards stops while the
+// lock is still held.
+func (lk *Lock) Lost() <-chan struct{} {
+	return lk.lost
+}
+
+// Unlock stops renewing and releases the lock.
+func (lk *Lock) Unlock(ctx context.Context) error {
+	var err error
+	lk.once.Do(func() {
+		close(lk.stop)
+		<-lk.done
+		lk.locker.mu.Lock()
+		delete(lk.locker.held, lk)
+		lk.locker.mu.Unlock()
+		err = lk.locker.driver.Release(ctx, lk.key, lk.token)
+	})
+	return err
+}
+
+// renew renews the lock every third of its TTL since renewed, retrying the
+// failed renewals, it is lost once not renewed within two thirds of the TTL,
+// leaving a third of it as the safety margin of the clock drifts and the
+// latencies.
+func (lk *Lock) renew(renewed time.Time) {
+	defer close(lk.done)
+	ttl := lk.locker.ttl
+	margin := ttl / 3
+	timer := time.NewTimer(ttl / 3)
+	defer timer.Stop()
+	for {
+		select {
+		case <-lk.stop:
+			return
+		case <-timer.C:
+		}
+		start := time.Now()
+		ctx, cancel := context.WithDeadline(context.Background(), renewed.Add(ttl-margin))
+		ok, err := lk.locker.driver.Refresh(ctx, lk.key, lk.token, ttl)
+		cancel()
+		switch {
+		case err == nil && ok:
+			renewed = start
+			timer.Reset(ttl / 3)
+			continue
+		case err != nil && time.Since(renewed)+lk.locker.retry < ttl-margin:
+			lk.locker.log.Warnf("failed to renew lock %s: %v", lk.key, err)
+			timer.Reset(lk.locker.retry)
+			continue
+		}
+		lk.locker.log.Errorf("lock %s lost", lk.key)
+		lk.locker.mu.Lock()
+		delete(lk.locker.held, lk)
+		lk.locker.mu.Unlock()
+		close(lk.lost)
+		return
+	}
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
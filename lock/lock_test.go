@@ -0,0 +1,122 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemoryDriver()
+	l := New(d, WithTTL(30*time.Millisecond), WithRetry(5*time.Millisecond))
+
+	lk, err := l.TryLock(ctx, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.TryLock(ctx, "job"); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("got %v, want not acquired", err)
+	}
+	// the lock is renewed past its TTL.
+	time.Sleep(100 * time.Millisecond)
+	if _, err = l.TryLock(ctx, "job"); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("got %v, want the lock renewed", err)
+	}
+
+	acquired := make(chan *Lock)
+	go func() {
+		lk, err := l.Lock(ctx, "job")
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- lk
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if err = lk.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case lk = <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expect Lock to acquire the released lock")
+	}
+
+	ctx2, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err = l.Lock(ctx2, "job"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+
+	if err = l.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.TryLock(ctx, "job"); err != nil {
+		t.Errorf("expect the locks to be released on stop, got %v", err)
+	}
+}
+
+func TestLost(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemoryDriver()
+	l := New(d, WithTTL(30*time.Millisecond))
+	lk, err := l.TryLock(ctx, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// another owner takes over the lock.
+	d.mu.Lock()
+	d.locks["job"] = memoryLock{token: "other", expireAt: time.Now().Add(time.Minute)}
+	d.mu.Unlock()
+	select {
+	case <-lk.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("expect the lock to be lost")
+	}
+	if err = lk.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = d.Refresh(ctx, "job", "other", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := d.Acquire(ctx, "job", "third", time.Minute); ok {
+		t.Error("expect Unlock not to release the lock of another owner")
+	}
+}
+
+type failingDriver struct {
+	*MemoryDriver
+}
+
+func (failingDriver) Refresh(context.Context, string, string, time.Duration) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestLostBeforeExpired(t *testing.T) {
+	ctx := context.Background()
+	d := failingDriver{NewMemoryDriver()}
+	ttl := 90 * time.Millisecond
+	l := New(d, WithTTL(ttl), WithRetry(5*time.Millisecond))
+	start := time.Now()
+	lk, err := l.TryLock(ctx, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-lk.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("expect the lock to be lost")
+	}
+	if elapsed := time.Since(start); elapsed >= ttl {
+		t.Errorf("expect the lock lost before it expired, got lost after %s", elapsed)
+	}
+	if ok, _ := d.Acquire(ctx, "job", "other", ttl); ok {
+		t.Error("expect the lock still held when lost")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.held) != 0 {
+		t.Errorf("expect the lost lock no longer held, got %d", len(l.held))
+	}
+}
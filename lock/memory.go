@@ -0,0 +1,58 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Driver = (*MemoryDriver)(nil)
+
+type memoryLock struct {
+	token    string
+	expireAt time.Time
+}
+
+// MemoryDriver is a Driver in memory, the locks aren't shared by the instances.
+type MemoryDriver struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+}
+
+// NewMemoryDriver new a memory driver.
+func NewMemoryDriver() *MemoryDriver {
+	return &MemoryDriver{locks: make(map[string]memoryLock)}
+}
+
+// Acquire acquires key for ttl if it isn't held or expired.
+func (d *MemoryDriver) Acquire(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if l, ok := d.locks[key]; ok && time.Now().Before(l.expireAt) {
+		return false, nil
+	}
+	d.locks[key] = memoryLock{token: token, expireAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+// Refresh extends the ttl of key if it is held by token.
+func (d *MemoryDriver) Refresh(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	l, ok := d.locks[key]
+	if !ok || l.token != token || time.Now().After(l.expireAt) {
+		return false, nil
+	}
+	d.locks[key] = memoryLock{token: token, expireAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+// Release releases key if it is held by token.
+func (d *MemoryDriver) Release(ctx context.Context, key, token string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if l, ok := d.locks[key]; ok && l.token == token {
+		delete(d.locks, key)
+	}
+	return nil
+}
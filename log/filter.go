@@ -1,5 +1,7 @@
 package log
 
+import "context"
+
 // FilterOption is filter option.
 type FilterOption func(*Filter)
 
@@ -61,7 +63,20 @@ func NewFilter(logger Logger, opts ...FilterOption) *Filter {
 
 // Log Print log by level and keyvals.
 func (f *Filter) Log(level Level, keyvals ...interface{}) error {
-	if level < f.level {
+	return f.log(nil, f.level, level, keyvals...)
+}
+
+// logContext logs the keyvals of ctx, the level of ctx lowers the filter level.
+func (f *Filter) logContext(ctx context.Context, level Level, keyvals ...interface{}) error {
+	threshold := f.level
+	if l, ok := LevelFromContext(ctx); ok && l < threshold {
+		threshold = l
+	}
+	return f.log(ctx, threshold, level, keyvals...)
+}
+
+func (f *Filter) log(ctx context.Context, threshold, level Level, keyvals ...interface{}) error {
+	if level < threshold {
 		return nil
 	}
 	if f.filter != nil && f.filter(level, keyvals...) {
@@ -81,5 +96,8 @@ func (f *Filter) Log(level Level, keyvals ...interface{}) error {
 			}
 		}
 	}
+	if cl, ok := f.logger.(contextLogger); ok && ctx != nil {
+		return cl.logContext(ctx, level, keyvals...)
+	}
 	return f.logger.Log(level, keyvals...)
 }
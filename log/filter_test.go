@@ -1,7 +1,10 @@
 package log
 
 import (
+	"bytes"
+	"context"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -57,6 +60,20 @@ func TestFilterFunc(t *testing.T) {
 	log.Infow("password", "123456")
 }
 
+func TestFilterLevelContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewFilter(NewStdLogger(&buf), FilterLevel(LevelWarn))
+	ctx := NewLevelContext(context.Background(), LevelDebug)
+	NewHelper(logger).WithContext(ctx).Debug("boosted")
+	NewHelper(With(logger, "caller", DefaultCaller)).WithContext(ctx).Info("boosted with caller")
+	NewHelper(logger).Debug("filtered")
+	NewHelper(logger).WithContext(context.Background()).Info("filtered")
+	out := buf.String()
+	if !strings.Contains(out, "msg=boosted") || !strings.Contains(out, "caller=filter_test.go") || strings.Contains(out, "filtered") {
+		t.Errorf("unexpected logs %q", out)
+	}
+}
+
 func BenchmarkFilterKey(b *testing.B) {
 	log := NewHelper(NewFilter(NewStdLogger(io.Discard), FilterKey("password")))
 	for i := 0; i < b.N; i++ {
@@ -1,6 +1,9 @@
 package log
 
-import "strings"
+import (
+	"context"
+	"strings"
+)
 
 // Level is a logger level.
 type Level int8
@@ -54,3 +57,17 @@ func ParseLevel(s string) Level {
 	}
 	return LevelInfo
 }
+
+type levelKey struct{}
+
+// NewLevelContext returns a new Context lowering the level of the filters to
+// level for the logs of ctx, e.g. the debug logs of a single request.
+func NewLevelContext(ctx context.Context, level Level) context.Context {
+	return context.WithValue(ctx, levelKey{}, level)
+}
+
+// LevelFromContext returns the level of ctx if any.
+func LevelFromContext(ctx context.Context) (Level, bool) {
+	level, ok := ctx.Value(levelKey{}).(Level)
+	return level, ok
+}
@@ -28,6 +28,12 @@ func (c *logger) Log(level Level, keyvals ...interface{}) error {
 	}
 	kvs = append(kvs, keyvals...)
 	for _, l := range c.logs {
+		if cl, ok := l.(contextLogger); ok && c.ctx != nil {
+			if err := cl.logContext(c.ctx, level, kvs...); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := l.Log(level, kvs...); err != nil {
 			return err
 		}
@@ -35,6 +41,12 @@ func (c *logger) Log(level Level, keyvals ...interface{}) error {
 	return nil
 }
 
+// contextLogger is implemented by the loggers depending on the context, e.g.
+// the Filter of the level boosted with NewLevelContext.
+type contextLogger interface {
+	logContext(ctx context.Context, level Level, keyvals ...interface{}) error
+}
+
 // With with logger fields.
 func With(l Logger, kv ...interface{}) Logger {
 	if c, ok := l.(*logger); ok {
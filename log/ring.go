@@ -0,0 +1,114 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	_ Logger       = (*Ring)(nil)
+	_ http.Handler = (*Ring)(nil)
+)
+
+// Record is a log kept by a Ring.
+type Record struct {
+	Time  time.Time
+	Level Level
+	// Message is the key values formatted as the std logger, "key=value ...".
+	Message string
+}
+
+// MarshalJSON encodes the record with its level name.
+func (r Record) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Time    time.Time `json:"time"`
+		Level   string    `json:"level"`
+		Message string    `json:"message"`
+	}{r.Time, r.Level.String(), r.Message})
+}
+
+// String returns the record formatted as the std logger with its time.
+func (r Record) String() string {
+	return r.Time.Format(time.RFC3339Nano) + " " + r.Level.String() + " " + r.Message
+}
+
+// Ring is a logger keeping the last logs in memory, so the operators see the
+// recent logs when the central pipeline lags. Use it with MultiLogger:
+//
+//	ring := log.NewRing(1000)
+//	logger := log.MultiLogger(log.NewStdLogger(os.Stdout), ring)
+type Ring struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	count   int
+}
+
+// NewRing new a logger keeping the last size logs.
+func NewRing(size int) *Ring {
+	return &Ring{records: make([]Record, size)}
+}
+
+// Log keeps the kv pairs log.
+func (r *Ring) Log(level Level, keyvals ...interface{}) error {
+	if len(r.records) == 0 || len(keyvals) == 0 {
+		return nil
+	}
+	if (len(keyvals) & 1) == 1 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+	var buf bytes.Buffer
+	for i := 0; i < len(keyvals); i += 2 {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		_, _ = fmt.Fprintf(&buf, "%s=%v", keyvals[i], keyvals[i+1])
+	}
+	rec := Record{Time: time.Now(), Level: level, Message: buf.String()}
+	r.mu.Lock()
+	r.records[r.next] = rec
+	r.next = (r.next + 1) % len(r.records)
+	if r.count < len(r.records) {
+		r.count++
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// Records returns the logs kept of level or above, the oldest first.
+func (r *Ring) Records(level Level) []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records := make([]Record, 0, r.count)
+	for i := 0; i < r.count; i++ {
+		rec := r.records[(r.next-r.count+i+len(r.records))%len(r.records)]
+		if rec.Level >= level {
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+// ServeHTTP dumps the logs kept as JSON, mount it on an admin route:
+//
+//	srv.Handle("/debug/logs", ring)
+//
+// The "level" query filters the logs below, e.g. "?level=warn", and "limit"
+// keeps the most recent ones.
+func (r *Ring) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	level := LevelDebug
+	if s := req.URL.Query().Get("level"); s != "" {
+		level = ParseLevel(s)
+	}
+	records := r.Records(level)
+	if limit, err := strconv.Atoi(req.URL.Query().Get("limit")); err == nil && limit >= 0 && limit < len(records) {
+		records = records[len(records)-limit:]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(records)
+}
@@ -0,0 +1,41 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	ring := NewRing(3)
+	logger := NewHelper(ring)
+	logger.Info("first")
+	logger.Warnw("msg", "second", "order", 42)
+	logger.Error("third")
+	logger.Debug("fourth")
+	_ = ring.Log(LevelInfo, "unpaired")
+
+	records := ring.Records(LevelDebug)
+	if len(records) != 3 || records[0].Message != "msg=third" || records[2].Message != "unpaired=KEYVALS UNPAIRED" {
+		t.Fatalf("unexpected records %v", records)
+	}
+
+	w := httptest.NewRecorder()
+	ring.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/logs?level=warn", nil))
+	var got []struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Level != "ERROR" {
+		t.Errorf("expect the warn logs and above, got %+v", got)
+	}
+	w = httptest.NewRecorder()
+	ring.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/logs?limit=1", nil))
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || len(got) != 1 || got[0].Message != "unpaired=KEYVALS UNPAIRED" {
+		t.Errorf("expect the last log, got %+v %v", got, err)
+	}
+}
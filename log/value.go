@@ -33,11 +33,7 @@ func Caller(depth int) Valuer {
 	return func(context.Context) interface{} {
 		d := depth
 		_, file, line, _ := runtime.Caller(d)
-		if strings.LastIndex(file, "/log/filter.go") > 0 {
-			d++
-			_, file, line, _ = runtime.Caller(d)
-		}
-		if strings.LastIndex(file, "/log/helper.go") > 0 {
+		for strings.LastIndex(file, "/log/filter.go") > 0 || strings.LastIndex(file, "/log/helper.go") > 0 {
 			d++
 			_, file, line, _ = runtime.Caller(d)
 		}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/singleflight"
+)
+
+// KeyFunc returns the key the reply is cached by,
+// the reply isn't cached if ok is false.
+type KeyFunc func(ctx context.Context, req interface{}) (key string, ok bool)
+
+// Option is cache option.
+type Option func(*options)
+
+// WithCache with the reply cache, default is a memory cache of 1024 entries.
+func WithCache(c Cache) Option {
+	return func(o *options) {
+		o.cache = c
+	}
+}
+
+// WithKey with the request key func, default is singleflight.DefaultKey. The
+// requests of the same key share the cached reply, so the key must identify
+// the caller, e.g. its credentials, as well as the request.
+func WithKey(f KeyFunc) Option {
+	return func(o *options) {
+		o.key = f
+	}
+}
+
+// WithTTL with the TTL of the replies, default is 1m.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithErrorTTL caches the errors of the given code for ttl, e.g. 404 for 10s,
+// to suppress the repeated failing lookups. Errors aren't cached by default.
+func WithErrorTTL(code int, ttl time.Duration) Option {
+	return func(o *options) {
+		o.errorTTL[code] = ttl
+	}
+}
+
+type options struct {
+	cache    Cache
+	key      KeyFunc
+	ttl      time.Duration
+	errorTTL map[int]time.Duration
+}
+
+// Client is a client middleware caching the proto replies, and optionally the
+// errors of some codes, the callers get a copy of them. Only use it for
+// idempotent operations, e.g. composed with selector, after the middlewares
+// setting the credentials so that they are part of the key.
+func Client(opts ...Option) middleware.Middleware {
+	o := &options{
+		key:      singleflight.DefaultKey,
+		ttl:      time.Minute,
+		errorTTL: make(map[int]time.Duration),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.cache == nil {
+		o.cache = NewMemoryCache(1024)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			key, ok := o.key(ctx, req)
+			if !ok {
+				return handler(ctx, req)
+			}
+			if v, ok := o.cache.Get(key); ok {
+				if e, ok := v.(*errors.Error); ok {
+					return nil, proto.Clone(e).(*errors.Error)
+				}
+				if m, ok := v.(proto.Message); ok {
+					return proto.Clone(m), nil
+				}
+			}
+			reply, err := handler(ctx, req)
+			if err != nil {
+				e := errors.FromError(err)
+				if ttl, ok := o.errorTTL[int(e.Code)]; ok && ttl > 0 {
+					o.cache.Set(key, proto.Clone(e), ttl)
+				}
+				return nil, err
+			}
+			// only the proto replies are cached, which are cloned
+			if m, ok := reply.(proto.Message); ok && o.ttl > 0 {
+				o.cache.Set(key, proto.Clone(m), o.ttl)
+			}
+			return reply, nil
+		}
+	}
+}
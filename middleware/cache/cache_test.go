@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier map[string]string
+
+func (hc headerCarrier) Get(key string) string      { return hc[key] }
+func (hc headerCarrier) Set(key string, val string) { hc[key] = val }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	transport.Transporter
+	header headerCarrier
+}
+
+func (tr *Transport) Operation() string               { return "/test.Cache/Get" }
+func (tr *Transport) RequestHeader() transport.Header { return tr.header }
+
+func TestClient(t *testing.T) {
+	var calls int
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		switch req.(*wrapperspb.StringValue).Value {
+		case "missing":
+			return nil, errors.NotFound("NOT_FOUND", "not found")
+		case "invalid":
+			return nil, errors.BadRequest("INVALID", "invalid")
+		}
+		return wrapperspb.String("value"), nil
+	}
+	h := Client(WithErrorTTL(404, time.Minute))(next)
+	ctx := transport.NewClientContext(context.Background(), &Transport{header: headerCarrier{}})
+
+	tests := []struct {
+		req   string
+		calls int
+		code  int
+	}{
+		{"found", 1, 200},
+		{"missing", 1, 404},
+		{"invalid", 2, 400},
+	}
+	for _, test := range tests {
+		calls = 0
+		for i := 0; i < 2; i++ {
+			reply, err := h(ctx, wrapperspb.String(test.req))
+			if code := errors.Code(err); code != test.code {
+				t.Fatalf("%s: expect code %d, got %d", test.req, test.code, code)
+			}
+			if err == nil && reply.(*wrapperspb.StringValue).Value != "value" {
+				t.Errorf("%s: unexpected reply %v", test.req, reply)
+			}
+		}
+		if calls != test.calls {
+			t.Errorf("%s: expect %d upstream calls, got %d", test.req, test.calls, calls)
+		}
+	}
+}
+
+func TestClient_Isolation(t *testing.T) {
+	var calls int
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		tr, _ := transport.FromClientContext(ctx)
+		return wrapperspb.String(tr.RequestHeader().Get("authorization")), nil
+	}
+	h := Client()(next)
+	alice := transport.NewClientContext(context.Background(), &Transport{header: headerCarrier{"authorization": "alice"}})
+	bob := transport.NewClientContext(context.Background(), &Transport{header: headerCarrier{"authorization": "bob"}})
+	reply, _ := h(alice, wrapperspb.String("key"))
+	reply.(*wrapperspb.StringValue).Value = "mutated"
+	if reply, _ = h(alice, wrapperspb.String("key")); reply.(*wrapperspb.StringValue).Value != "alice" {
+		t.Errorf("expect the cached reply not mutated by the caller, got %v", reply)
+	}
+	reply.(*wrapperspb.StringValue).Value = "mutated"
+	if reply, _ = h(bob, wrapperspb.String("key")); reply.(*wrapperspb.StringValue).Value != "bob" {
+		t.Errorf("expect the replies cached by caller, got %v", reply)
+	}
+	if reply, _ = h(alice, wrapperspb.String("key")); reply.(*wrapperspb.StringValue).Value != "alice" {
+		t.Errorf("expect the cached reply not mutated by the caller, got %v", reply)
+	}
+	if calls != 2 {
+		t.Errorf("expect 2 upstream calls, got %d", calls)
+	}
+
+	calls = 0
+	h = Client()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return map[string]string{"key": "value"}, nil
+	})
+	_, _ = h(alice, wrapperspb.String("key"))
+	_, _ = h(alice, wrapperspb.String("key"))
+	if calls != 2 {
+		t.Errorf("expect the non-proto replies not cached, got %d upstream calls", calls)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(2)
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	c.Get("a")
+	c.Set("c", 3, time.Minute)
+	if _, ok := c.Get("b"); ok {
+		t.Error("expect the least recently used entry to be evicted")
+	}
+	c.Set("d", 4, -time.Second)
+	if _, ok := c.Get("d"); ok {
+		t.Error("expect the expired entry to be missed")
+	}
+}
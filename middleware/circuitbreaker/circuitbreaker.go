@@ -7,6 +7,9 @@ import (
 	"github.com/go-kratos/aegis/circuitbreaker/sre"
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/group"
+	"github.com/go-kratos/kratos/v2/internal/monitor"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 )
@@ -25,8 +28,29 @@ func WithGroup(g *group.Group) Option {
 	}
 }
 
+// WithMonitorOnly keeps calling through an open breaker, counting the calls it
+// would reject by operation in c, which may be nil.
+func WithMonitorOnly(c metrics.Counter) Option {
+	return func(o *options) {
+		o.monitorOnly = true
+		o.wouldReject = c
+	}
+}
+
+// WithLogger with the logger of the calls the breaker would reject in monitor
+// only mode.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
 type options struct {
-	group *group.Group
+	group  *group.Group
+	logger log.Logger
+	// counter: client_requests_would_reject_total{operation}
+	wouldReject metrics.Counter
+	monitorOnly bool
 }
 
 // Client circuitbreaker middleware will return errBreakerTriggered when the circuit
@@ -36,20 +60,25 @@ func Client(opts ...Option) middleware.Middleware {
 		group: group.NewGroup(func() interface{} {
 			return sre.NewBreaker()
 		}),
+		logger: log.GetLogger(),
 	}
 	for _, o := range opts {
 		o(opt)
 	}
+	reporter := monitor.NewReporter("circuitbreaker", opt.wouldReject, opt.logger)
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (interface{}, error) {
 			info, _ := transport.FromClientContext(ctx)
 			breaker := opt.group.Get(info.Operation()).(circuitbreaker.CircuitBreaker)
 			if err := breaker.Allow(); err != nil {
-				// rejected
-				// NOTE: when client reject requets locally,
-				// continue add counter let the drop ratio higher.
-				breaker.MarkFailed()
-				return nil, ErrNotAllowed
+				if !opt.monitorOnly {
+					// rejected
+					// NOTE: when client reject requets locally,
+					// continue add counter let the drop ratio higher.
+					breaker.MarkFailed()
+					return nil, ErrNotAllowed
+				}
+				reporter.Report(ctx, []string{info.Operation()}, "%s", info.Operation())
 			}
 			// allowed
 			reply, err := handler(ctx, req)
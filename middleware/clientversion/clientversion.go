@@ -0,0 +1,195 @@
+package clientversion
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type versionKey struct{}
+
+const (
+	// headerKey holds the key used to carry the client version, formatted as "<client>/<version>",
+	// trailing product tokens and comments are ignored so User-Agent values are accepted too.
+	headerKey = "X-Client-Version"
+
+	// reason holds the error reason.
+	reason = "UPGRADE_REQUIRED"
+)
+
+var (
+	// ErrMissingVersion is returned when the version is required but not sent.
+	ErrMissingVersion = errors.BadRequest("CLIENT_VERSION_MISSING", "client version is missing")
+	// ErrInvalidVersion is returned when the version can not be parsed.
+	ErrInvalidVersion = errors.BadRequest("CLIENT_VERSION_INVALID", "client version is invalid")
+)
+
+// UpgradeRequired returns an upgrade-required error for a client below its minimum version.
+func UpgradeRequired(v *Version, minimum *Version) *errors.Error {
+	return errors.New(426, reason, fmt.Sprintf("client %s is not supported, upgrade to %s or later", v, minimum)).
+		WithMetadata(map[string]string{"client": v.Client, "min_version": minimum.String()})
+}
+
+// IsUpgradeRequired determines if err is an error which indicates an upgrade is required.
+func IsUpgradeRequired(err error) bool {
+	return errors.Reason(err) == reason
+}
+
+// Version is a parsed client version.
+type Version struct {
+	Client string
+	Major  int
+	Minor  int
+	Patch  int
+}
+
+// Parse parses a "<client>/<major>[.<minor>[.<patch>]]" value,
+// a leading "v" and pre-release or build suffixes are accepted.
+func Parse(s string) (*Version, error) {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		s = fields[0]
+	}
+	i := strings.LastIndexByte(s, '/')
+	if i <= 0 {
+		return nil, ErrInvalidVersion
+	}
+	v := &Version{Client: s[:i]}
+	num := strings.TrimPrefix(s[i+1:], "v")
+	if j := strings.IndexAny(num, "-+"); j >= 0 {
+		num = num[:j]
+	}
+	parts := strings.Split(num, ".")
+	if len(parts) > 3 { //nolint:gomnd
+		return nil, ErrInvalidVersion
+	}
+	dst := []*int{&v.Major, &v.Minor, &v.Patch}
+	for k, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, ErrInvalidVersion
+		}
+		*dst[k] = n
+	}
+	return v, nil
+}
+
+// MustParse is like Parse but panics if the value can not be parsed.
+func MustParse(s string) *Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("clientversion: invalid version %q", s))
+	}
+	return v
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or greater than o, the client is not compared.
+func (v *Version) Compare(o *Version) int {
+	a := [...]int{v.Major, v.Minor, v.Patch}
+	b := [...]int{o.Major, o.Minor, o.Patch}
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// AtLeast reports whether v is equal to or greater than the version s ("1.2.3"), it is meant for feature gating.
+func (v *Version) AtLeast(s string) bool {
+	o, err := Parse(v.Client + "/" + s)
+	if err != nil {
+		return false
+	}
+	return v.Compare(o) >= 0
+}
+
+func (v *Version) String() string {
+	return fmt.Sprintf("%s/%d.%d.%d", v.Client, v.Major, v.Minor, v.Patch)
+}
+
+// Option is client version option.
+type Option func(*options)
+
+type options struct {
+	headerKey string
+	required  bool
+	min       map[string]*Version
+}
+
+// WithHeaderKey with the request header key carrying the version, default is X-Client-Version.
+func WithHeaderKey(key string) Option {
+	return func(o *options) {
+		o.headerKey = key
+	}
+}
+
+// WithRequired rejects requests without a version, by default they are passed through.
+func WithRequired(required bool) Option {
+	return func(o *options) {
+		o.required = required
+	}
+}
+
+// WithMinVersion with the minimum supported versions, e.g. "ios/3.2.0".
+// Clients without a minimum version are not restricted.
+func WithMinVersion(versions ...string) Option {
+	return func(o *options) {
+		for _, s := range versions {
+			v := MustParse(s)
+			o.min[v.Client] = v
+		}
+	}
+}
+
+// Server is a server middleware parsing the client version and rejecting unsupported clients.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{
+		headerKey: headerKey,
+		min:       make(map[string]*Version),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			value := tr.RequestHeader().Get(o.headerKey)
+			if value == "" {
+				if o.required {
+					return nil, ErrMissingVersion
+				}
+				return handler(ctx, req)
+			}
+			v, err := Parse(value)
+			if err != nil {
+				return nil, err
+			}
+			if minimum, ok := o.min[v.Client]; ok && v.Compare(minimum) < 0 {
+				return nil, UpgradeRequired(v, minimum)
+			}
+			return handler(NewContext(ctx, v), req)
+		}
+	}
+}
+
+// NewContext put client version into context.
+func NewContext(ctx context.Context, v *Version) context.Context {
+	return context.WithValue(ctx, versionKey{}, v)
+}
+
+// FromContext extract client version from context.
+func FromContext(ctx context.Context) (v *Version, ok bool) {
+	v, ok = ctx.Value(versionKey{}).(*Version)
+	return
+}
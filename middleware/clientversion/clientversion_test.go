@@ -0,0 +1,96 @@
+package clientversion
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	reqHeader transport.Header
+}
+
+func (tr *Transport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return "/test.Version/Call" }
+func (tr *Transport) RequestHeader() transport.Header { return tr.reqHeader }
+func (tr *Transport) ReplyHeader() transport.Header   { return nil }
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in  string
+		out *Version
+		err error
+	}{
+		{"ios/3.2.1", &Version{Client: "ios", Major: 3, Minor: 2, Patch: 1}, nil},
+		{"android/v4", &Version{Client: "android", Major: 4}, nil},
+		{"web/1.2.0-beta.1+build", &Version{Client: "web", Major: 1, Minor: 2}, nil},
+		{"MyApp/2.1 (iPhone; iOS 15.0)", &Version{Client: "MyApp", Major: 2, Minor: 1}, nil},
+		{"ios", nil, ErrInvalidVersion},
+		{"ios/x.1", nil, ErrInvalidVersion},
+		{"ios/1.2.3.4", nil, ErrInvalidVersion},
+	}
+	for _, test := range tests {
+		v, err := Parse(test.in)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: expect %v, but got %v", test.in, test.err, err)
+		}
+		if !reflect.DeepEqual(v, test.out) {
+			t.Errorf("%s: expect %v, but got %v", test.in, test.out, v)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	var got *Version
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got, _ = FromContext(ctx)
+		return req, nil
+	}
+	h := Server(WithRequired(true), WithMinVersion("ios/3.2.0"))(next)
+	call := func(version string) error {
+		header := headerCarrier{}
+		if version != "" {
+			header.Set(headerKey, version)
+		}
+		_, err := h(transport.NewServerContext(context.Background(), &Transport{reqHeader: header}), "req")
+		return err
+	}
+
+	if err := call("ios/3.2.1"); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || !got.AtLeast("3.2") || got.AtLeast("3.3") {
+		t.Errorf("unexpected version in context: %v", got)
+	}
+	if err := call("android/1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := call("ios/3.1.9"); !IsUpgradeRequired(err) {
+		t.Errorf("expect upgrade required, but got %v", err)
+	}
+	if err := call(""); !errors.Is(err, ErrMissingVersion) {
+		t.Errorf("expect %v, but got %v", ErrMissingVersion, err)
+	}
+	if err := call("ios/?"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("expect %v, but got %v", ErrInvalidVersion, err)
+	}
+}
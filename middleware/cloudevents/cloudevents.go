@@ -0,0 +1,109 @@
+// Package cloudevents provides the middlewares carrying the CloudEvents
+// attributes of the requests in the binary mode.
+package cloudevents
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/encoding/cloudevents"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// reason is the error reason of an invalid event.
+const reason = "INVALID_CLOUDEVENT"
+
+type (
+	eventKey       struct{}
+	clientEventKey struct{}
+)
+
+// Option is cloudevents option.
+type Option func(*options)
+
+type options struct {
+	binding cloudevents.Binding
+}
+
+// WithBinding with the binding of the request headers, default is the HTTP binding.
+func WithBinding(b cloudevents.Binding) Option {
+	return func(o *options) {
+		o.binding = b
+	}
+}
+
+// Server is a server middleware putting the event of the request in the
+// context. In the binary mode the event is read from the request headers,
+// its data being the request decoded by the handler; in the structured mode
+// the request is bound to a *cloudevents.Event by the "cloudevents+json"
+// codec. An invalid event is rejected.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{binding: cloudevents.HTTP}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if e, ok := req.(*cloudevents.Event); ok {
+				return handler(NewContext(ctx, e), req)
+			}
+			if tr, ok := transport.FromServerContext(ctx); ok && o.binding.IsBinary(tr.RequestHeader()) {
+				e, err := o.binding.Read(tr.RequestHeader(), nil)
+				if err != nil {
+					return nil, errors.BadRequest(reason, err.Error())
+				}
+				ctx = NewContext(ctx, e)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Client is a client middleware writing the attributes of the event put in the
+// context by NewClientContext to the request headers, the request being the
+// data of the event. The event received by Server isn't sent again, and the
+// content type is the one of the encoded request, not the datacontenttype of
+// the event.
+func Client(opts ...Option) middleware.Middleware {
+	o := &options{binding: cloudevents.HTTP}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if e, ok := FromClientContext(ctx); ok {
+				if tr, ok := transport.FromClientContext(ctx); ok {
+					out := *e
+					out.DataContentType = ""
+					if _, err := o.binding.Write(tr.RequestHeader(), &out); err != nil {
+						return nil, err
+					}
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// NewContext returns a new Context that carries the event received.
+func NewContext(ctx context.Context, e *cloudevents.Event) context.Context {
+	return context.WithValue(ctx, eventKey{}, e)
+}
+
+// FromContext returns the event received in ctx if any.
+func FromContext(ctx context.Context) (*cloudevents.Event, bool) {
+	e, ok := ctx.Value(eventKey{}).(*cloudevents.Event)
+	return e, ok
+}
+
+// NewClientContext returns a new Context that carries the event sent by Client.
+func NewClientContext(ctx context.Context, e *cloudevents.Event) context.Context {
+	return context.WithValue(ctx, clientEventKey{}, e)
+}
+
+// FromClientContext returns the event sent in ctx if any.
+func FromClientContext(ctx context.Context) (*cloudevents.Event, bool) {
+	e, ok := ctx.Value(clientEventKey{}).(*cloudevents.Event)
+	return e, ok
+}
@@ -0,0 +1,67 @@
+package cloudevents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/encoding/cloudevents"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware/middlewaretest"
+)
+
+func TestServer(t *testing.T) {
+	var got *cloudevents.Event
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got, _ = FromContext(ctx)
+		return nil, nil
+	}
+	ctx, _ := middlewaretest.NewServerContext(context.Background(),
+		middlewaretest.WithHeader("ce-id", "1"),
+		middlewaretest.WithHeader("ce-source", "/orders"),
+		middlewaretest.WithHeader("ce-type", "created"),
+		middlewaretest.WithHeader("ce-specversion", "1.0"),
+	)
+	if _, err := Server()(next)(ctx, "data"); err != nil || got == nil || got.Source != "/orders" {
+		t.Fatalf("got %+v %v", got, err)
+	}
+
+	structured := &cloudevents.Event{ID: "2", Source: "/s", SpecVersion: "1.0", Type: "t"}
+	if _, _ = Server()(next)(context.Background(), structured); got != structured {
+		t.Errorf("expect the structured event, got %+v", got)
+	}
+
+	got = nil
+	ctx, _ = middlewaretest.NewServerContext(context.Background(), middlewaretest.WithHeader("ce-specversion", "1.0"))
+	if _, err := Server()(next)(ctx, "data"); errors.Reason(err) != reason {
+		t.Errorf("expect invalid event, got %v", err)
+	}
+	ctx, _ = middlewaretest.NewServerContext(context.Background())
+	if _, _ = Server()(next)(ctx, "data"); got != nil {
+		t.Errorf("expect no event, got %+v", got)
+	}
+}
+
+func TestClient(t *testing.T) {
+	ctx, tr := middlewaretest.NewClientContext(context.Background())
+	tr.RequestHeader().Set("Content-Type", "application/x-protobuf")
+	e := &cloudevents.Event{ID: "1", Source: "/orders", SpecVersion: "1.0", Type: "created", DataContentType: "application/json"}
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	if _, err := Client()(next)(NewClientContext(ctx, e), "data"); err != nil {
+		t.Fatal(err)
+	}
+	if h := tr.RequestHeader(); h.Get("ce-id") != "1" || h.Get("Content-Type") != "application/x-protobuf" {
+		t.Errorf("unexpected headers %v", h.Keys())
+	}
+	if _, err := Client()(next)(NewClientContext(ctx, &cloudevents.Event{}), "data"); err == nil {
+		t.Error("expect invalid event")
+	}
+
+	// the event received isn't sent downstream
+	ctx, tr = middlewaretest.NewClientContext(NewContext(context.Background(), e))
+	if _, err := Client()(next)(ctx, "data"); err != nil {
+		t.Fatal(err)
+	}
+	if h := tr.RequestHeader(); h.Get("ce-id") != "" {
+		t.Errorf("expect the received event not sent, got %v", h.Keys())
+	}
+}
@@ -0,0 +1,233 @@
+// Package cost provides a server middleware enforcing budgets of cost units
+// per client instead of request counts, the heavy operations like the search
+// or the export costing more units than the light ones. The budgets are only
+// per client if the key is authenticated, e.g. the subject of the auth claims
+// returned by WithKey, set after the auth middleware:
+//
+//	cost.Server(cost.NewMemoryLimiter(cost.Fixed(cost.Budget{Rate: 100, Burst: 1000})),
+//		cost.WithKey(func(ctx context.Context) string {
+//			claims, _ := jwt.FromContext(ctx)
+//			return subject(claims)
+//		}),
+//		cost.WithCosts(map[string]int64{"/api.v1.Orders/Export": 50}),
+//	)
+//
+// The handlers charge the cost known after the fact, e.g. by the rows returned,
+// with Add.
+package cost
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/monitor"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const reason = "COST_BUDGET_EXCEEDED"
+
+// MaxRetryAfter is the maximum wait before retrying reported, e.g. for the
+// requests costing more than the burst, which never fit in the budget.
+const MaxRetryAfter = time.Hour
+
+// BudgetExceeded returns a budget exceeded error, its metadata contains the
+// cost of the request and the seconds to wait before retrying, at most MaxRetryAfter.
+func BudgetExceeded(cost int64, retryAfter time.Duration) *errors.Error {
+	if retryAfter > MaxRetryAfter {
+		retryAfter = MaxRetryAfter
+	}
+	seconds := int64(retryAfter / time.Second)
+	if retryAfter%time.Second > 0 {
+		seconds++
+	}
+	return errors.New(429, reason, fmt.Sprintf("cost budget exceeded by a request of %d units", cost)).WithMetadata(map[string]string{
+		"cost":        strconv.FormatInt(cost, 10),
+		"retry_after": strconv.FormatInt(seconds, 10),
+	})
+}
+
+// IsBudgetExceeded reports whether err is a budget exceeded error.
+func IsBudgetExceeded(err error) bool {
+	return errors.Reason(err) == reason
+}
+
+// Coster is implemented by the requests declaring their own cost, e.g. by page size.
+type Coster interface {
+	Cost() int64
+}
+
+type costKey struct{}
+
+// Add charges n more units to the request of ctx after the handler returns,
+// e.g. by the rows an export returned. It is a no-op outside the middleware.
+func Add(ctx context.Context, n int64) {
+	if c, ok := ctx.Value(costKey{}).(*int64); ok {
+		atomic.AddInt64(c, n)
+	}
+}
+
+// Option is cost option.
+type Option func(*options)
+
+type options struct {
+	key         func(ctx context.Context) string
+	costs       map[string]int64
+	costFunc    func(ctx context.Context, req interface{}) int64
+	defaultCost int64
+	spent       metrics.Counter
+	label       func(key string) string
+
+	monitorOnly bool
+	wouldReject metrics.Counter
+	logger      log.Logger
+}
+
+// WithHeaderKey with the request header identifying the client, default is X-API-Key.
+// The header isn't authenticated, so a caller sending a new key gets a new
+// budget, use WithKey returning the authenticated client instead unless the
+// key is validated before, e.g. by a gateway.
+func WithHeaderKey(header string) Option {
+	return func(o *options) {
+		o.key = func(ctx context.Context) string {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				return tr.RequestHeader().Get(header)
+			}
+			return ""
+		}
+	}
+}
+
+// WithKey with the func returning the authenticated client of a request,
+// e.g. from the auth claims, the requests without client aren't accounted.
+func WithKey(f func(ctx context.Context) string) Option {
+	return func(o *options) {
+		o.key = f
+	}
+}
+
+// WithCosts with the costs of the operations.
+func WithCosts(costs map[string]int64) Option {
+	return func(o *options) {
+		o.costs = costs
+	}
+}
+
+// WithCostFunc with the func returning the cost of a request, it takes
+// precedence over the costs of the operations and the Coster requests.
+func WithCostFunc(f func(ctx context.Context, req interface{}) int64) Option {
+	return func(o *options) {
+		o.costFunc = f
+	}
+}
+
+// WithDefaultCost with the cost of the operations without one, default is 1.
+func WithDefaultCost(n int64) Option {
+	return func(o *options) {
+		o.defaultCost = n
+	}
+}
+
+// WithSpent with the counter of the units spent, labeled by the label of the
+// key and operation.
+func WithSpent(c metrics.Counter) Option {
+	return func(o *options) {
+		o.spent = c
+	}
+}
+
+// WithLabel with the func returning the label of a key, e.g. its plan,
+// default is a hash of the key.
+func WithLabel(f func(key string) string) Option {
+	return func(o *options) {
+		o.label = f
+	}
+}
+
+// WithLogger with the logger of the requests the limiter would reject in
+// monitor only mode and of the charge errors.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithMonitorOnly lets the requests over budget through, counting them by the
+// label of the key and operation in c, which may be nil.
+func WithMonitorOnly(c metrics.Counter) Option {
+	return func(o *options) {
+		o.monitorOnly = true
+		o.wouldReject = c
+	}
+}
+
+// cost returns the cost of the request, never negative.
+func (o *options) cost(ctx context.Context, operation string, req interface{}) (n int64) {
+	if o.costFunc != nil {
+		n = o.costFunc(ctx, req)
+	} else if c, ok := req.(Coster); ok {
+		n = c.Cost()
+	} else if c, ok := o.costs[operation]; ok {
+		n = c
+	} else {
+		n = o.defaultCost
+	}
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// Server is a server middleware taking the cost of each request from the
+// budget of its client, rejecting the requests over budget.
+func Server(limiter Limiter, opts ...Option) middleware.Middleware {
+	o := &options{defaultCost: 1, label: monitor.HashKey, logger: log.GetLogger()}
+	WithHeaderKey("X-API-Key")(o)
+	for _, opt := range opts {
+		opt(o)
+	}
+	reporter := monitor.NewReporter("cost", o.wouldReject, o.logger)
+	helper := log.NewHelper(o.logger)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			key := o.key(ctx)
+			if key == "" {
+				return handler(ctx, req)
+			}
+			var operation string
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				operation = tr.Operation()
+			}
+			cost := o.cost(ctx, operation, req)
+			retryAfter, ok, err := limiter.Allow(ctx, key, cost)
+			if err != nil {
+				helper.WithContext(ctx).Errorf("cost: failed to take %d units from the budget of key %s: %v", cost, o.label(key), err)
+				return nil, errors.InternalServer("COST", "failed to take the cost from the budget")
+			}
+			if !ok {
+				if !o.monitorOnly {
+					return nil, BudgetExceeded(cost, retryAfter)
+				}
+				label := o.label(key)
+				reporter.Report(ctx, []string{label, operation}, "key %s: %s costs %d units", label, operation, cost)
+			}
+			var extra int64
+			reply, err := handler(context.WithValue(ctx, costKey{}, &extra), req)
+			if extra = atomic.LoadInt64(&extra); extra != 0 {
+				if cerr := limiter.Charge(ctx, key, extra); cerr != nil {
+					helper.WithContext(ctx).Errorf("cost: failed to charge %d units to key %s: %v", extra, o.label(key), cerr)
+				}
+			}
+			if o.spent != nil {
+				o.spent.With(o.label(key), operation).Add(float64(cost + extra))
+			}
+			return reply, err
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package cost
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/middlewaretest"
+)
+
+type search struct{ pageSize int64 }
+
+func (s search) Cost() int64 { return s.pageSize / 10 }
+
+func TestServer(t *testing.T) {
+	now := time.Date(2022, 1, 30, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter(Fixed(Budget{Rate: 1, Burst: 10}))
+	limiter.now = func() time.Time { return now }
+	h := Server(limiter, WithCosts(map[string]int64{"/test.Orders/Export": 6}))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if n, ok := req.(int64); ok {
+			Add(ctx, n)
+		}
+		return req, nil
+	})
+	call := func(key, operation string, req interface{}) error {
+		ctx, _ := middlewaretest.NewServerContext(context.Background(),
+			middlewaretest.WithHeader("X-API-Key", key),
+			middlewaretest.WithOperation(operation),
+		)
+		_, err := h(ctx, req)
+		return err
+	}
+	if err := call("a", "/test.Orders/Export", nil); err != nil {
+		t.Fatal(err)
+	}
+	err := call("a", "/test.Orders/Export", nil)
+	if !IsBudgetExceeded(err) || errors.FromError(err).Metadata["retry_after"] != "2" {
+		t.Fatalf("expect budget exceeded after 2s, got %v", err)
+	}
+	// the light operations still fit the budget, another client has its own.
+	if err = call("a", "/test.Orders/Get", nil); err != nil {
+		t.Errorf("expect allowed, got %v", err)
+	}
+	if err = call("b", "/test.Orders/Export", nil); err != nil {
+		t.Errorf("expect allowed, got %v", err)
+	}
+	if err = call("a", "/test.Orders/Search", search{pageSize: 40}); !IsBudgetExceeded(err) {
+		t.Errorf("expect the Coster request rejected, got %v", err)
+	}
+
+	now = now.Add(10 * time.Second)
+	// the 8 units added by the handler are charged after the fact.
+	if err = call("a", "/test.Orders/Get", int64(8)); err != nil {
+		t.Fatal(err)
+	}
+	if err = call("a", "/test.Orders/Get", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = call("a", "/test.Orders/Get", nil); !IsBudgetExceeded(err) {
+		t.Errorf("expect the charged units spent, got %v", err)
+	}
+	if err = call("", "/test.Orders/Export", nil); err != nil {
+		t.Errorf("expect the requests without key allowed, got %v", err)
+	}
+}
+
+func TestMonitorOnly(t *testing.T) {
+	var buf bytes.Buffer
+	h := Server(NewMemoryLimiter(Fixed(Budget{Rate: 1, Burst: 1})), WithDefaultCost(5), WithMonitorOnly(nil), WithLogger(log.NewStdLogger(&buf)))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "reply", nil
+	})
+	ctx, _ := middlewaretest.NewServerContext(context.Background(), middlewaretest.WithHeader("X-API-Key", "a"))
+	if _, err := h(ctx, nil); err != nil {
+		t.Errorf("expect monitor only, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "cost: monitor only") {
+		t.Errorf("expect the warning logged with the logger, got %q", buf.String())
+	}
+}
+
+func TestBudgetExceeded(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       string
+	}{
+		{0, "0"},
+		{time.Millisecond, "1"},
+		{2 * time.Second, "2"},
+		{MaxRetryAfter + time.Second, "3600"},
+		{time.Duration(math.MaxInt64), "3600"},
+	}
+	for _, test := range tests {
+		if got := BudgetExceeded(1, test.retryAfter).Metadata["retry_after"]; got != test.want {
+			t.Errorf("%v: expect retry after %s, got %s", test.retryAfter, test.want, got)
+		}
+	}
+}
+
+func TestServer_NegativeCost(t *testing.T) {
+	limiter := NewMemoryLimiter(Fixed(Budget{Rate: 0, Burst: 2}))
+	h := Server(limiter)(func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil })
+	ctx, _ := middlewaretest.NewServerContext(context.Background(), middlewaretest.WithHeader("X-API-Key", "a"))
+	for i := 0; i < 3; i++ {
+		if _, err := h(ctx, search{pageSize: -100}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := h(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := h(ctx, nil); !IsBudgetExceeded(err) {
+		t.Errorf("expect the negative costs not minting units, got %v", err)
+	}
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, int64) (time.Duration, bool, error) {
+	return 0, false, errors.New(500, "REDIS", "dial tcp 10.0.0.1:6379: connection refused")
+}
+
+func (failingLimiter) Charge(context.Context, string, int64) error { return nil }
+
+func TestServer_LimiterError(t *testing.T) {
+	var buf bytes.Buffer
+	h := Server(failingLimiter{}, WithLogger(log.NewStdLogger(&buf)))(func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil })
+	ctx, _ := middlewaretest.NewServerContext(context.Background(), middlewaretest.WithHeader("X-API-Key", "a"))
+	_, err := h(ctx, nil)
+	if e := errors.FromError(err); e.Code != 500 || strings.Contains(e.Message, "10.0.0.1") {
+		t.Errorf("expect an internal error without the limiter error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "connection refused") {
+		t.Errorf("expect the limiter error logged, got %q", buf.String())
+	}
+}
+
+func TestMemoryLimiter_Sweep(t *testing.T) {
+	now := time.Date(2022, 1, 30, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter(Fixed(Budget{Rate: 1, Burst: 10}))
+	limiter.now = func() time.Time { return now }
+	for i := 0; i < 100; i++ {
+		if _, ok, _ := limiter.Allow(context.Background(), strconv.Itoa(i), 1); !ok {
+			t.Fatal("expect the budget available")
+		}
+	}
+	now = now.Add(time.Minute)
+	for i := 0; i < 1000 && len(limiter.buckets) > 1; i++ {
+		_, _, _ = limiter.Allow(context.Background(), "hot", 1)
+	}
+	if n := len(limiter.buckets); n != 1 {
+		t.Errorf("expect the full buckets evicted, got %d buckets", n)
+	}
+}
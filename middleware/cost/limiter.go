@@ -0,0 +1,119 @@
+package cost
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+var _ Limiter = (*MemoryLimiter)(nil)
+
+// Limiter holds the budgets of the clients.
+type Limiter interface {
+	// Allow takes n units from the budget of key if available, else it
+	// returns the wait before they are.
+	Allow(ctx context.Context, key string, n int64) (retryAfter time.Duration, ok bool, err error)
+	// Charge takes n units from the budget of key even if not available, the
+	// budget going into debt.
+	Charge(ctx context.Context, key string, n int64) error
+}
+
+// Budget is a token bucket of cost units, refilled at Rate units per second up to Burst units.
+type Budget struct {
+	Rate  float64
+	Burst int64
+}
+
+// Fixed returns a func giving b to all keys.
+func Fixed(b Budget) func(key string) Budget {
+	return func(string) Budget { return b }
+}
+
+// sweepBatch is the number of buckets examined by each call to evict the full ones.
+const sweepBatch = 2
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	budget Budget
+}
+
+// full reports whether the bucket is refilled to the burst at now.
+func (bk *bucket) full(now time.Time) bool {
+	return bk.budget.Rate > 0 && bk.tokens+now.Sub(bk.last).Seconds()*bk.budget.Rate >= float64(bk.budget.Burst)
+}
+
+// MemoryLimiter is an in-memory Limiter, it is only suitable for a single instance.
+type MemoryLimiter struct {
+	budget func(key string) Budget
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewMemoryLimiter returns an in-memory Limiter of the budgets of the keys, e.g. by plan.
+func NewMemoryLimiter(budget func(key string) Budget) *MemoryLimiter {
+	return &MemoryLimiter{
+		budget:  budget,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes n units from the budget of key if available, n is at least 0.
+func (l *MemoryLimiter) Allow(_ context.Context, key string, n int64) (time.Duration, bool, error) {
+	if n < 0 {
+		n = 0
+	}
+	b := l.budget(key)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bk := l.bucket(key, b)
+	if bk.tokens >= float64(n) {
+		bk.tokens -= float64(n)
+		return 0, true, nil
+	}
+	if b.Rate <= 0 || n > b.Burst {
+		return time.Duration(math.MaxInt64), false, nil
+	}
+	return time.Duration((float64(n) - bk.tokens) / b.Rate * float64(time.Second)), false, nil
+}
+
+// Charge takes n units from the budget of key, a negative n gives units back
+// up to the burst.
+func (l *MemoryLimiter) Charge(_ context.Context, key string, n int64) error {
+	b := l.budget(key)
+	l.mu.Lock()
+	bk := l.bucket(key, b)
+	bk.tokens = math.Min(float64(b.Burst), bk.tokens-float64(n))
+	l.mu.Unlock()
+	return nil
+}
+
+// bucket returns the bucket of key refilled up to now.
+func (l *MemoryLimiter) bucket(key string, b Budget) *bucket {
+	now := l.now()
+	// the full buckets are the same as the missing ones, a few of them are
+	// evicted by each call rather than all of them at once under the lock.
+	n := 0
+	for k, bk := range l.buckets {
+		if n++; n > sweepBatch {
+			break
+		}
+		if k != key && bk.full(now) {
+			delete(l.buckets, k)
+		}
+	}
+	bk, ok := l.buckets[key]
+	if !ok {
+		bk = &bucket{tokens: float64(b.Burst), last: now, budget: b}
+		l.buckets[key] = bk
+		return bk
+	}
+	bk.tokens = math.Min(float64(b.Burst), bk.tokens+now.Sub(bk.last).Seconds()*b.Rate)
+	bk.last = now
+	bk.budget = b
+	return bk
+}
@@ -0,0 +1,107 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// authorizationKey holds the key used to carry the bearer token.
+	authorizationKey = "Authorization"
+
+	// bearerFormat authorization token format
+	bearerFormat = "Bearer %s"
+
+	// reason holds the error reason.
+	reason = "CREDENTIALS"
+)
+
+// ErrWrongContext is returned when the middleware is not used in a client.
+var ErrWrongContext = errors.Unauthorized(reason, "wrong context for middleware")
+
+// Provider returns the credentials attached to an outgoing request,
+// they are evaluated for every request.
+type Provider func(ctx context.Context) (map[string]string, error)
+
+// Client is a client middleware attaching per-request credentials to the request header.
+func Client(providers ...Provider) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromClientContext(ctx)
+			if !ok {
+				return nil, ErrWrongContext
+			}
+			for _, p := range providers {
+				md, err := p(ctx)
+				if err != nil {
+					return nil, errors.Unauthorized(reason, err.Error())
+				}
+				for k, v := range md {
+					tr.RequestHeader().Set(k, v)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Forward returns a Provider propagating the given keys from the incoming server request,
+// e.g. Forward("Authorization") makes calls on behalf of the original caller.
+func Forward(keys ...string) Provider {
+	return func(ctx context.Context) (map[string]string, error) {
+		tr, ok := transport.FromServerContext(ctx)
+		if !ok {
+			return nil, nil
+		}
+		md := make(map[string]string, len(keys))
+		for _, k := range keys {
+			if v := tr.RequestHeader().Get(k); v != "" {
+				md[k] = v
+			}
+		}
+		return md, nil
+	}
+}
+
+// Bearer returns a Provider setting the Authorization header to the token returned by f.
+func Bearer(f func(ctx context.Context) (string, error)) Provider {
+	return func(ctx context.Context) (map[string]string, error) {
+		token, err := f(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if token == "" {
+			return nil, nil
+		}
+		return map[string]string{authorizationKey: fmt.Sprintf(bearerFormat, token)}, nil
+	}
+}
+
+// PerRPC returns a Provider backed by gRPC per-RPC credentials,
+// so credentials.PerRPCCredentials implementations can be used from middleware.
+// The credentials requiring transport security are refused unless the client
+// transport reports a secure connection, see transport.Securer.
+func PerRPC(creds credentials.PerRPCCredentials) Provider {
+	return func(ctx context.Context) (map[string]string, error) {
+		var (
+			uri    string
+			secure bool
+		)
+		if tr, ok := transport.FromClientContext(ctx); ok {
+			uri = tr.Endpoint()
+			if s, ok := tr.(transport.Securer); ok {
+				secure = s.Secure()
+			}
+		}
+		if creds.RequireTransportSecurity() && !secure {
+			return nil, fmt.Errorf("the credentials require transport security, but %q is insecure", uri)
+		}
+		return creds.GetRequestMetadata(ctx, uri)
+	}
+}
@@ -0,0 +1,165 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	endpoint  string
+	reqHeader transport.Header
+	secure    bool
+}
+
+func (tr *Transport) Kind() transport.Kind            { return transport.KindGRPC }
+func (tr *Transport) Endpoint() string                { return tr.endpoint }
+func (tr *Transport) Operation() string               { return "/test.Credentials/Call" }
+func (tr *Transport) RequestHeader() transport.Header { return tr.reqHeader }
+func (tr *Transport) ReplyHeader() transport.Header   { return nil }
+func (tr *Transport) Secure() bool                    { return tr.secure }
+
+type perRPC struct {
+	secure bool
+}
+
+func (perRPC) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"x-md-uri": uri[0]}, nil
+}
+
+func (p perRPC) RequireTransportSecurity() bool { return p.secure }
+
+func TestClient(t *testing.T) {
+	in := headerCarrier{}
+	in.Set("Authorization", "Bearer caller")
+	out := headerCarrier{}
+	ctx := transport.NewServerContext(context.Background(), &Transport{reqHeader: in})
+	ctx = transport.NewClientContext(ctx, &Transport{endpoint: "discovery:///test", reqHeader: out})
+
+	m := Client(
+		Forward("Authorization", "X-Missing"),
+		func(ctx context.Context) (map[string]string, error) {
+			return map[string]string{"X-Impersonate": "alice"}, nil
+		},
+		PerRPC(perRPC{}),
+	)
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	if _, err := m(next)(ctx, "req"); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{
+		"Authorization": "Bearer caller",
+		"X-Impersonate": "alice",
+		"X-Md-Uri":      "discovery:///test",
+		"X-Missing":     "",
+	}
+	for k, v := range expect {
+		if got := out.Get(k); got != v {
+			t.Errorf("expect %s: %q, but got %q", k, v, got)
+		}
+	}
+}
+
+func TestBearer(t *testing.T) {
+	out := headerCarrier{}
+	ctx := transport.NewClientContext(context.Background(), &Transport{reqHeader: out})
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+
+	m := Client(Bearer(func(ctx context.Context) (string, error) { return "token", nil }))
+	if _, err := m(next)(ctx, "req"); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("expect %q, but got %q", "Bearer token", got)
+	}
+
+	m = Client(Bearer(func(ctx context.Context) (string, error) { return "", errors.New("no token") }))
+	if _, err := m(next)(ctx, "req"); !kerrors.IsUnauthorized(err) {
+		t.Errorf("expect unauthorized, but got %v", err)
+	}
+	if _, err := m(next)(context.Background(), "req"); !errors.Is(err, ErrWrongContext) {
+		t.Errorf("expect %v, but got %v", ErrWrongContext, err)
+	}
+}
+
+func TestPerRPC_TransportSecurity(t *testing.T) {
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	m := Client(PerRPC(perRPC{secure: true}))
+	for _, secure := range []bool{false, true} {
+		ctx := transport.NewClientContext(context.Background(), &Transport{endpoint: "127.0.0.1:9000", reqHeader: headerCarrier{}, secure: secure})
+		_, err := m(next)(ctx, "req")
+		if secure && err != nil {
+			t.Errorf("secure: expect no error, but got %v", err)
+		}
+		if !secure && !kerrors.IsUnauthorized(err) {
+			t.Errorf("insecure: expect unauthorized, but got %v", err)
+		}
+	}
+}
+
+func TestPerRPC_TLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	serverTLS := ts.TLS.Clone()
+	clientTLS := ts.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	clientTLS.ServerName = "example.com"
+	ts.Close()
+
+	got := make(chan string, 1)
+	srv := grpc.NewServer(
+		grpc.Address("127.0.0.1:0"),
+		grpc.TLSConfig(serverTLS),
+		grpc.Middleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				if tr, ok := transport.FromServerContext(ctx); ok {
+					got <- tr.RequestHeader().Get("x-md-uri")
+				}
+				return handler(ctx, req)
+			}
+		}),
+	)
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+
+	conn, err := grpc.Dial(context.Background(),
+		grpc.WithEndpoint(u.Host),
+		grpc.WithTLSConfig(clientTLS),
+		grpc.WithMiddleware(Client(PerRPC(perRPC{secure: true}))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if uri := <-got; uri != u.Host {
+		t.Errorf("expect the credentials of %q, but got %q", u.Host, uri)
+	}
+}
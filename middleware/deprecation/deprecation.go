@@ -0,0 +1,122 @@
+// Package deprecation signals deprecated operations to callers,
+// use it with the selector middleware to target routes or methods:
+//
+//	selector.Server(
+//		deprecation.Server(deprecation.WithSunset(sunset)),
+//	).Path("/helloworld.Greeter/SayHelloV1").Build()
+package deprecation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// deprecationKey holds the key signaling the operation is deprecated.
+	deprecationKey = "Deprecation"
+	// sunsetKey holds the key signaling when the operation will be removed, see RFC 8594.
+	sunsetKey = "Sunset"
+	// linkKey holds the key linking to the deprecation documentation.
+	linkKey = "Link"
+	// warningKey holds the key carrying a human readable warning on gRPC.
+	warningKey = "Warning"
+)
+
+// Option is deprecation option.
+type Option func(*options)
+
+type options struct {
+	since    time.Time
+	sunset   time.Time
+	link     string
+	message  string
+	requests metrics.Counter
+}
+
+// WithSince with the time the operation was deprecated, default is unknown.
+func WithSince(t time.Time) Option {
+	return func(o *options) {
+		o.since = t
+	}
+}
+
+// WithSunset with the time the operation will stop responding.
+func WithSunset(t time.Time) Option {
+	return func(o *options) {
+		o.sunset = t
+	}
+}
+
+// WithLink with a link to the deprecation documentation.
+func WithLink(link string) Option {
+	return func(o *options) {
+		o.link = link
+	}
+}
+
+// WithMessage with the warning message sent on gRPC.
+func WithMessage(msg string) Option {
+	return func(o *options) {
+		o.message = msg
+	}
+}
+
+// WithRequests with deprecated requests counter.
+func WithRequests(c metrics.Counter) Option {
+	return func(o *options) {
+		o.requests = c
+	}
+}
+
+// Server is a server middleware signaling the operation is deprecated.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{
+		message: "this operation is deprecated",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	deprecation := "true"
+	if !o.since.IsZero() {
+		deprecation = "@" + strconv.FormatInt(o.since.Unix(), 10)
+	}
+	var sunset string
+	if !o.sunset.IsZero() {
+		sunset = o.sunset.UTC().Format(http.TimeFormat)
+	}
+	warning := o.message
+	if sunset != "" {
+		warning = fmt.Sprintf("%s, it will be removed after %s", o.message, sunset)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			if o.requests != nil {
+				// counter: server_requests_deprecated_total{kind, operation}
+				o.requests.With(tr.Kind().String(), tr.Operation()).Inc()
+			}
+			header := tr.ReplyHeader()
+			header.Set(deprecationKey, deprecation)
+			if sunset != "" {
+				header.Set(sunsetKey, sunset)
+			}
+			if o.link != "" {
+				header.Set(linkKey, fmt.Sprintf(`<%s>; rel="deprecation"`, o.link))
+			}
+			if tr.Kind() == transport.KindGRPC {
+				header.Set(warningKey, warning)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package deprecation
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	kind        transport.Kind
+	replyHeader transport.Header
+}
+
+func (tr *Transport) Kind() transport.Kind            { return tr.kind }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return "/test.Deprecation/Call" }
+func (tr *Transport) RequestHeader() transport.Header { return headerCarrier{} }
+func (tr *Transport) ReplyHeader() transport.Header   { return tr.replyHeader }
+
+type counter struct {
+	lvs   []string
+	value float64
+}
+
+func (c *counter) With(lvs ...string) metrics.Counter { c.lvs = lvs; return c }
+func (c *counter) Inc()                               { c.value++ }
+func (c *counter) Add(delta float64)                  { c.value += delta }
+
+func TestServer(t *testing.T) {
+	since := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC)
+	requests := &counter{}
+	m := Server(
+		WithSince(since),
+		WithSunset(sunset),
+		WithLink("https://go-kratos.dev/deprecation"),
+		WithRequests(requests),
+	)
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+
+	tests := []struct {
+		kind    transport.Kind
+		warning string
+	}{
+		{transport.KindHTTP, ""},
+		{transport.KindGRPC, "this operation is deprecated, it will be removed after Thu, 30 Jun 2022 00:00:00 GMT"},
+	}
+	for _, test := range tests {
+		reply := headerCarrier{}
+		ctx := transport.NewServerContext(context.Background(), &Transport{kind: test.kind, replyHeader: reply})
+		if _, err := m(next)(ctx, "req"); err != nil {
+			t.Fatal(err)
+		}
+		expect := map[string]string{
+			deprecationKey: "@1609459200",
+			sunsetKey:      "Thu, 30 Jun 2022 00:00:00 GMT",
+			linkKey:        `<https://go-kratos.dev/deprecation>; rel="deprecation"`,
+			warningKey:     test.warning,
+		}
+		for k, v := range expect {
+			if got := reply.Get(k); got != v {
+				t.Errorf("%s: expect %s: %q, but got %q", test.kind, k, v, got)
+			}
+		}
+	}
+	if requests.value != 2 || requests.lvs[1] != "/test.Deprecation/Call" {
+		t.Errorf("unexpected requests counter: %v %v", requests.lvs, requests.value)
+	}
+}
@@ -0,0 +1,199 @@
+// Package egress provides a client middleware enforcing the allowed targets
+// of the outbound calls.
+package egress
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/monitor"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// reason is the error reason of a call to a target not allowed.
+const reason = "EGRESS_DENIED"
+
+// Rules are the target patterns, hosts like "api.example.com" or
+// "*.example.com" and the service names of the discovery targets. The
+// patterns are matched with path.Match, case-insensitively and regardless of
+// the trailing dot of the fully qualified names.
+type Rules struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// Policy decides the targets allowed, the denied patterns first, then the
+// allowed ones if any, else any target is allowed. It may be updated at runtime.
+type Policy struct {
+	mu    sync.RWMutex
+	rules Rules
+}
+
+// NewPolicy new a policy of the rules.
+func NewPolicy(rules Rules) (*Policy, error) {
+	p := &Policy{}
+	if err := p.Update(rules); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Update replaces the rules of the policy.
+func (p *Policy) Update(rules Rules) error {
+	allow, err := normalizePatterns(rules.Allow)
+	if err != nil {
+		return err
+	}
+	deny, err := normalizePatterns(rules.Deny)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.rules = Rules{Allow: allow, Deny: deny}
+	p.mu.Unlock()
+	return nil
+}
+
+func normalizePatterns(patterns []string) ([]string, error) {
+	ret := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("egress pattern %q: %w", pattern, err)
+		}
+		ret = append(ret, normalize(pattern))
+	}
+	return ret, nil
+}
+
+// normalize lowercases the name and trims its trailing dot.
+func normalize(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
+
+// Allowed reports whether the calls to the target are allowed, the target
+// is a client endpoint like "discovery:///orders" or "api.example.com:443".
+func (p *Policy) Allowed(target string) bool {
+	name := Name(target)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if match(p.rules.Deny, name) {
+		return false
+	}
+	return len(p.rules.Allow) == 0 || match(p.rules.Allow, name)
+}
+
+// Watch drives the rules of the policy by the config key:
+//
+//	egress:
+//	  allow: ["orders", "*.example.com"]
+//	  deny: ["metadata.google.internal"]
+//
+// Invalid rules are logged and the previous rules kept.
+func (p *Policy) Watch(c config.Config, key string) error {
+	apply := func(v config.Value) error {
+		var rules Rules
+		if err := v.Scan(&rules); err != nil {
+			return err
+		}
+		return p.Update(rules)
+	}
+	if err := apply(c.Value(key)); err != nil {
+		return err
+	}
+	return c.Watch(key, func(_ string, v config.Value) {
+		if err := apply(v); err != nil {
+			log.Errorf("failed to apply egress rules: %v", err)
+		}
+	})
+}
+
+func match(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the name matched of a target, the service name of the
+// discovery targets, else the host without the port, e.g. "api.example.com"
+// of "dns:///API.example.com.:443", lowercased without the trailing dot.
+func Name(target string) string {
+	host := target
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return normalize(target)
+		}
+		host = u.Host
+		if u.Host == "" || u.Scheme == "discovery" {
+			host = strings.TrimPrefix(u.Path, "/")
+		}
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return normalize(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+}
+
+// Option is egress option.
+type Option func(*options)
+
+type options struct {
+	logger log.Logger
+
+	monitorOnly bool
+	wouldReject metrics.Counter
+}
+
+// WithLogger with the logger of the calls denied.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithMonitorOnly lets the calls denied through, counting them by target name
+// and operation in c, which may be nil, e.g. to collect the targets before
+// enforcing the policy.
+func WithMonitorOnly(c metrics.Counter) Option {
+	return func(o *options) {
+		o.monitorOnly = true
+		o.wouldReject = c
+	}
+}
+
+// Client is a client middleware logging and rejecting the calls to the
+// targets the policy doesn't allow.
+func Client(p *Policy, opts ...Option) middleware.Middleware {
+	o := &options{logger: log.GetLogger()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	logger := log.NewHelper(o.logger)
+	reporter := monitor.NewReporter("egress", o.wouldReject, o.logger)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromClientContext(ctx); ok && !p.Allowed(tr.Endpoint()) {
+				name := Name(tr.Endpoint())
+				if !o.monitorOnly {
+					logger.WithContext(ctx).Warnw("msg", "egress denied", "target", tr.Endpoint(), "operation", tr.Operation())
+					return nil, errors.Forbidden(reason, fmt.Sprintf("egress to %s is not allowed", name))
+				}
+				reporter.Report(ctx, []string{name, tr.Operation()}, "%s: %s", tr.Endpoint(), tr.Operation())
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package egress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware/middlewaretest"
+)
+
+func TestName(t *testing.T) {
+	tests := map[string]string{
+		"discovery:///orders":          "orders",
+		"127.0.0.1:9000":               "127.0.0.1",
+		"https://api.example.com:8443": "api.example.com",
+		"api.example.com":              "api.example.com",
+		"dns:///api.example.com:443":   "api.example.com",
+		"API.Example.com.:443":         "api.example.com",
+		"http://[::1]:8000":            "::1",
+		"[::1]":                        "::1",
+	}
+	for target, want := range tests {
+		if got := Name(target); got != want {
+			t.Errorf("Name(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	p, err := NewPolicy(Rules{Allow: []string{"orders", "*.example.com"}, Deny: []string{"admin.example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return "reply", nil }
+	for target, allowed := range map[string]bool{
+		"discovery:///orders":      true,
+		"api.example.com:443":      true,
+		"admin.example.com:443":    false,
+		"discovery:///payments":    false,
+		"metadata.google.internal": false,
+	} {
+		ctx, _ := middlewaretest.NewClientContext(context.Background(), middlewaretest.WithEndpoint(target))
+		_, err := Client(p)(next)(ctx, nil)
+		if allowed != (err == nil) {
+			t.Errorf("%s: allowed %v, got %v", target, allowed, err)
+		}
+		if err != nil && errors.Reason(err) != reason {
+			t.Errorf("%s: unexpected error %v", target, err)
+		}
+	}
+	ctx, _ := middlewaretest.NewClientContext(context.Background(), middlewaretest.WithEndpoint("discovery:///payments"))
+	wouldReject := &counter{}
+	if _, err = Client(p, WithMonitorOnly(wouldReject))(next)(ctx, nil); err != nil {
+		t.Errorf("expect monitor only, got %v", err)
+	}
+	if wouldReject.value != 1 || len(wouldReject.lvs) != 2 || wouldReject.lvs[0] != "payments" {
+		t.Errorf("would reject %v %v, want 1 [payments operation]", wouldReject.value, wouldReject.lvs)
+	}
+	p, err = NewPolicy(Rules{Deny: []string{"API.example.com."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, target := range []string{"dns:///api.example.com:443", "API.example.com:443", "api.example.com.:443", "https://api.example.com./v1"} {
+		ctx, _ := middlewaretest.NewClientContext(context.Background(), middlewaretest.WithEndpoint(target))
+		if _, err = Client(p)(next)(ctx, nil); err == nil {
+			t.Errorf("%s: expect denied", target)
+		}
+	}
+	if err = p.Update(Rules{Allow: []string{"[orders"}}); err == nil {
+		t.Error("expect invalid pattern")
+	}
+}
+
+type counter struct {
+	lvs   []string
+	value float64
+}
+
+func (c *counter) With(lvs ...string) metrics.Counter { c.lvs = lvs; return c }
+func (c *counter) Inc()                               { c.value++ }
+func (c *counter) Add(delta float64)                  { c.value += delta }
+
+type testSource struct {
+	kvs chan []*config.KeyValue
+	kv  *config.KeyValue
+}
+
+func (s *testSource) Load() ([]*config.KeyValue, error) { return []*config.KeyValue{s.kv}, nil }
+func (s *testSource) Watch() (config.Watcher, error)    { return s, nil }
+func (s *testSource) Stop() error                       { return nil }
+
+func (s *testSource) Next() ([]*config.KeyValue, error) {
+	kvs, ok := <-s.kvs
+	if !ok {
+		return nil, context.Canceled
+	}
+	return kvs, nil
+}
+
+func TestPolicyWatch(t *testing.T) {
+	source := &testSource{
+		kvs: make(chan []*config.KeyValue),
+		kv:  &config.KeyValue{Key: "test", Format: "json", Value: []byte(`{"egress":{"allow":["orders"]}}`)},
+	}
+	c := config.New(config.WithSource(source))
+	defer close(source.kvs)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := NewPolicy(Rules{})
+	if err := p.Watch(c, "egress"); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Allowed("discovery:///orders") || p.Allowed("discovery:///payments") {
+		t.Fatal("rules not applied")
+	}
+
+	source.kvs <- []*config.KeyValue{{Key: "test", Format: "json", Value: []byte(`{"egress":{"allow":["orders","payments"]}}`)}}
+	deadline := time.Now().Add(time.Second)
+	for !p.Allowed("discovery:///payments") {
+		if time.Now().After(deadline) {
+			t.Fatal("rules not updated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,184 @@
+// Package geoip resolves the client IP of the requests to its country and
+// autonomous system, e.g. for fraud detection and analytics.
+package geoip
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+
+	"google.golang.org/grpc/peer"
+)
+
+// Location is the location of an IP.
+type Location struct {
+	IP net.IP
+	// Country is the ISO 3166-1 alpha-2 code of the country, e.g. "CN".
+	Country string
+	// ASN is the autonomous system number.
+	ASN uint
+	// Organization is the organization of the autonomous system.
+	Organization string
+}
+
+// Resolver resolves an IP to its location, e.g. from a MaxMind database.
+type Resolver interface {
+	Resolve(ctx context.Context, ip net.IP) (*Location, error)
+}
+
+// ResolverFunc is a func resolving an IP to its location.
+type ResolverFunc func(ctx context.Context, ip net.IP) (*Location, error)
+
+// Resolve calls f(ctx, ip).
+func (f ResolverFunc) Resolve(ctx context.Context, ip net.IP) (*Location, error) {
+	return f(ctx, ip)
+}
+
+// Option is geoip option.
+type Option func(*options)
+
+type options struct {
+	header  string
+	proxies []*net.IPNet
+	logger  log.Logger
+}
+
+// WithHeader with the request header carrying the client IP set by the
+// trusted proxies, default is "X-Forwarded-For".
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// WithTrustedProxies with the networks of the trusted proxies, the client IP
+// is the rightmost address of the forwarded header not in these networks when
+// the request comes from one of them. By default the header is ignored.
+func WithTrustedProxies(proxies ...*net.IPNet) Option {
+	return func(o *options) {
+		o.proxies = proxies
+	}
+}
+
+// WithLogger with the logger of the resolution errors.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Server is a server middleware resolving the client IP of the requests and
+// putting its location in the context. The requests are served even though
+// the resolution fails.
+func Server(resolver Resolver, opts ...Option) middleware.Middleware {
+	o := &options{
+		header: "X-Forwarded-For",
+		logger: log.GetLogger(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	helper := log.NewHelper(o.logger)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			ip := o.clientIP(ctx)
+			if ip == nil {
+				return handler(ctx, req)
+			}
+			loc, err := resolver.Resolve(ctx, ip)
+			if err != nil {
+				helper.WithContext(ctx).Debugf("geoip: failed to resolve %s: %v", ip, err)
+				loc = &Location{}
+			}
+			if loc.IP == nil {
+				loc.IP = ip
+			}
+			return handler(NewContext(ctx, loc), req)
+		}
+	}
+}
+
+// clientIP returns the client IP of the request, nil if it's unknown.
+func (o *options) clientIP(ctx context.Context) net.IP {
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return nil
+	}
+	var remote string
+	if ht, ok := tr.(khttp.Transporter); ok {
+		remote = ht.Request().RemoteAddr
+	} else if p, ok := peer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+	}
+	ip := parseIP(remote)
+	if ip == nil || !o.trusted(ip) {
+		return ip
+	}
+	hops := strings.Split(tr.RequestHeader().Get(o.header), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop
+		if !o.trusted(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+func (o *options) trusted(ip net.IP) bool {
+	for _, n := range o.proxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIP parses an IP with or without port.
+func parseIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+type locationKey struct{}
+
+// NewContext returns a new context with the location of the client.
+func NewContext(ctx context.Context, loc *Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// FromContext returns the location of the client in the context.
+func FromContext(ctx context.Context) (*Location, bool) {
+	loc, ok := ctx.Value(locationKey{}).(*Location)
+	return loc, ok
+}
+
+// Country returns a country valuer.
+func Country() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		if loc, ok := FromContext(ctx); ok {
+			return loc.Country
+		}
+		return ""
+	}
+}
+
+// ASN returns an autonomous system number valuer.
+func ASN() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		if loc, ok := FromContext(ctx); ok && loc.ASN > 0 {
+			return loc.ASN
+		}
+		return ""
+	}
+}
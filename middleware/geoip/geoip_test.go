@@ -0,0 +1,83 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware/middlewaretest"
+
+	"google.golang.org/grpc/peer"
+)
+
+var locations = map[string]*Location{
+	"203.0.113.7":  {Country: "FR", ASN: 64500, Organization: "Example"},
+	"198.51.100.1": {Country: "US", ASN: 64501},
+}
+
+var resolver = ResolverFunc(func(_ context.Context, ip net.IP) (*Location, error) {
+	if loc, ok := locations[ip.String()]; ok {
+		return &Location{Country: loc.Country, ASN: loc.ASN, Organization: loc.Organization}, nil
+	}
+	return nil, errors.New("not found")
+})
+
+func resolve(t *testing.T, ctx context.Context, opts ...Option) *Location {
+	var loc *Location
+	_, err := Server(resolver, opts...)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		loc, _ = FromContext(ctx)
+		return req, nil
+	})(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestServer(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	tests := []struct {
+		name    string
+		remote  string
+		forward string
+		opts    []Option
+		ip      string
+		country string
+	}{
+		{"remote", "203.0.113.7:1234", "", nil, "203.0.113.7", "FR"},
+		{"untrusted proxy", "203.0.113.7:1234", "198.51.100.1", nil, "203.0.113.7", "FR"},
+		{"trusted proxy", "10.0.0.1:1234", "192.0.2.1, 198.51.100.1, 10.0.0.2", []Option{WithTrustedProxies(proxies)}, "198.51.100.1", "US"},
+		{"unresolved", "192.0.2.1:1234", "", nil, "192.0.2.1", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = test.remote
+			if test.forward != "" {
+				req.Header.Set("X-Forwarded-For", test.forward)
+			}
+			ctx, _ := middlewaretest.NewServerContext(context.Background(), middlewaretest.WithRequest(req, "/"))
+			loc := resolve(t, ctx, test.opts...)
+			if loc == nil || loc.IP.String() != test.ip || loc.Country != test.country {
+				t.Errorf("got %+v, want %s %s", loc, test.ip, test.country)
+			}
+		})
+	}
+}
+
+func TestServerGRPC(t *testing.T) {
+	ctx, _ := middlewaretest.NewServerContext(context.Background())
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 1234}})
+	loc := resolve(t, ctx)
+	if loc == nil || loc.Country != "US" || ASN()(NewContext(ctx, loc)) != uint(64501) {
+		t.Errorf("got %+v, want US 64501", loc)
+	}
+	if loc := resolve(t, context.Background()); loc != nil {
+		t.Errorf("expect no location without the client IP, got %+v", loc)
+	}
+	if v := Country()(context.Background()); v != "" {
+		t.Errorf("expect empty country, got %v", v)
+	}
+}
@@ -0,0 +1,112 @@
+package inflight
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/peer"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+var _ http.Handler = (*Registry)(nil)
+
+// Request is an in-flight request.
+type Request struct {
+	ID        uint64        `json:"id"`
+	Kind      string        `json:"kind"`
+	Operation string        `json:"operation"`
+	Peer      string        `json:"peer,omitempty"`
+	TraceID   string        `json:"trace_id,omitempty"`
+	Start     time.Time     `json:"start"`
+	Elapsed   time.Duration `json:"elapsed"`
+}
+
+// Registry tracks the requests being handled by the server.
+type Registry struct {
+	mu   sync.Mutex
+	seq  uint64
+	reqs map[uint64]*Request
+}
+
+// NewRegistry returns an in-flight request registry.
+func NewRegistry() *Registry {
+	return &Registry{reqs: make(map[uint64]*Request)}
+}
+
+// Server is a server middleware registering requests until they are handled.
+func (r *Registry) Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			id := r.add(newRequest(ctx))
+			defer r.remove(id)
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Requests returns a snapshot of the in-flight requests, the oldest first.
+func (r *Registry) Requests() []Request {
+	now := time.Now()
+	r.mu.Lock()
+	reqs := make([]Request, 0, len(r.reqs))
+	for _, req := range r.reqs {
+		reqs = append(reqs, *req)
+	}
+	r.mu.Unlock()
+	for i := range reqs {
+		reqs[i].Elapsed = now.Sub(reqs[i].Start)
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].ID < reqs[j].ID })
+	return reqs
+}
+
+// ServeHTTP dumps the in-flight requests as JSON, mount it on an admin route:
+//
+//	srv.Handle("/debug/inflight", registry)
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(r.Requests())
+}
+
+func (r *Registry) add(req *Request) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	req.ID = r.seq
+	r.reqs[req.ID] = req
+	return req.ID
+}
+
+func (r *Registry) remove(id uint64) {
+	r.mu.Lock()
+	delete(r.reqs, id)
+	r.mu.Unlock()
+}
+
+func newRequest(ctx context.Context) *Request {
+	req := &Request{Start: time.Now()}
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		req.Kind = tr.Kind().String()
+		req.Operation = tr.Operation()
+		if ht, ok := tr.(khttp.Transporter); ok {
+			req.Peer = ht.Request().RemoteAddr
+		}
+	}
+	if req.Peer == "" {
+		if p, ok := peer.FromContext(ctx); ok {
+			req.Peer = p.Addr.String()
+		}
+	}
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		req.TraceID = span.TraceID().String()
+	}
+	return req
+}
@@ -0,0 +1,49 @@
+package inflight
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/peer"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type Transport struct{}
+
+func (tr *Transport) Kind() transport.Kind            { return transport.KindGRPC }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return "/test.Inflight/Call" }
+func (tr *Transport) RequestHeader() transport.Header { return nil }
+func (tr *Transport) ReplyHeader() transport.Header   { return nil }
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	ctx := transport.NewServerContext(context.Background(), &Transport{})
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9000}})
+
+	var dumped []Request
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/debug/inflight", nil))
+		if err := json.Unmarshal(w.Body.Bytes(), &dumped); err != nil {
+			t.Fatal(err)
+		}
+		return req, nil
+	}
+	if _, err := r.Server()(next)(ctx, "req"); err != nil {
+		t.Fatal(err)
+	}
+	if len(dumped) != 1 {
+		t.Fatalf("expect 1 in-flight request, but got %d", len(dumped))
+	}
+	if req := dumped[0]; req.Operation != "/test.Inflight/Call" || req.Kind != "grpc" || req.Peer != "127.0.0.1:9000" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if n := len(r.Requests()); n != 0 {
+		t.Errorf("expect no in-flight request after handling, but got %d", n)
+	}
+}
@@ -0,0 +1,135 @@
+package localize
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/i18n"
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Option is localize option.
+type Option func(*options)
+
+type options struct {
+	query       string
+	metadataKey string
+	errors      bool
+}
+
+// WithQuery with the query parameter of the HTTP requests selecting the language, default is "lang".
+func WithQuery(key string) Option {
+	return func(o *options) {
+		o.query = key
+	}
+}
+
+// WithMetadataKey with the metadata key of the user language, default is "x-md-global-locale".
+func WithMetadataKey(key string) Option {
+	return func(o *options) {
+		o.metadataKey = key
+	}
+}
+
+// WithErrors translates the message of the errors returned by the handler,
+// looked up by their reason with the metadata as the placeholder values. Default is true.
+func WithErrors(translate bool) Option {
+	return func(o *options) {
+		o.errors = translate
+	}
+}
+
+// Server is a server middleware negotiating the language of the request from,
+// in order, the query parameter, the user metadata and the Accept-Language header,
+// and putting the localizer of the bundle in the context.
+func Server(bundle *i18n.Bundle, opts ...Option) middleware.Middleware {
+	o := &options{
+		query:       "lang",
+		metadataKey: "x-md-global-locale",
+		errors:      true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var langs []string
+			tr, ok := transport.FromServerContext(ctx)
+			if ok {
+				if ht, ok := tr.(http.Transporter); ok && o.query != "" {
+					if lang := ht.Request().URL.Query().Get(o.query); lang != "" {
+						langs = append(langs, lang)
+					}
+				}
+			}
+			if md, ok := metadata.FromServerContext(ctx); ok && o.metadataKey != "" {
+				if lang := md.Get(o.metadataKey); lang != "" {
+					langs = append(langs, lang)
+				}
+			}
+			if tr != nil {
+				if lang := tr.RequestHeader().Get("Accept-Language"); lang != "" {
+					langs = append(langs, lang)
+				}
+			}
+			l := bundle.Localizer(langs...)
+			if tr != nil {
+				tr.ReplyHeader().Set("Content-Language", l.Language().String())
+			}
+			reply, err := handler(i18n.NewContext(ctx, l), req)
+			if err != nil && o.errors {
+				err = Error(l, err)
+			}
+			return reply, err
+		}
+	}
+}
+
+// Error translates the message of err, looked up by its reason with its
+// metadata as the placeholder values, err is returned as is without a message.
+// The translated error still unwraps to err.
+func Error(l *i18n.Localizer, err error) error {
+	e := errors.FromError(err)
+	if e == nil {
+		return err
+	}
+	msg, ok := l.Message(e.Reason, e.Metadata)
+	if !ok {
+		return err
+	}
+	se := proto.Clone(e).(*errors.Error)
+	se.Message = msg
+	if err == error(e) {
+		return se
+	}
+	return &localizedError{err: se, cause: err}
+}
+
+// localizedError is a translated error wrapped by another error, it keeps
+// that error as its cause.
+type localizedError struct {
+	err   *errors.Error
+	cause error
+}
+
+func (e *localizedError) Error() string { return e.err.Error() }
+
+func (e *localizedError) GRPCStatus() *status.Status { return e.err.GRPCStatus() }
+
+func (e *localizedError) Is(err error) bool { return e.err.Is(err) }
+
+func (e *localizedError) Unwrap() error { return e.cause }
+
+// As finds the translated error before the one wrapped by its cause.
+func (e *localizedError) As(target interface{}) bool {
+	if se, ok := target.(**errors.Error); ok {
+		*se = e.err
+		return true
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package localize
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang.org/x/text/language"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/i18n"
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	reqHeader   transport.Header
+	replyHeader transport.Header
+}
+
+func (tr *Transport) Kind() transport.Kind            { return transport.KindGRPC }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return "/test.User/Get" }
+func (tr *Transport) RequestHeader() transport.Header { return tr.reqHeader }
+func (tr *Transport) ReplyHeader() transport.Header   { return tr.replyHeader }
+
+func TestServer(t *testing.T) {
+	b := i18n.NewBundle(language.English)
+	b.AddMessages(language.English, map[string]string{"USER_NOT_FOUND": "user {id} not found"})
+	b.AddMessages(language.German, map[string]string{"USER_NOT_FOUND": "Benutzer {id} nicht gefunden"})
+	b.AddMessages(language.French, map[string]string{"hello": "bonjour"})
+
+	tests := []struct {
+		name   string
+		header string
+		md     string
+		lang   string
+		msg    string
+	}{
+		{"default", "", "", "en", "user 1 not found"},
+		{"header", "de-DE,de;q=0.9", "", "de", "Benutzer 1 nicht gefunden"},
+		{"metadata", "de", "fr", "fr", "user 1 not found"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reply := http.Header{}
+			ctx := transport.NewServerContext(context.Background(), &Transport{
+				reqHeader:   headerCarrier(http.Header{"Accept-Language": {test.header}}),
+				replyHeader: headerCarrier(reply),
+			})
+			if test.md != "" {
+				ctx = metadata.NewServerContext(ctx, metadata.Metadata{"x-md-global-locale": test.md})
+			}
+			_, err := Server(b)(func(ctx context.Context, req interface{}) (interface{}, error) {
+				if l, ok := i18n.FromContext(ctx); !ok || l.Language().String() != test.lang {
+					t.Errorf("localizer = %v", l)
+				}
+				return nil, errors.NotFound("USER_NOT_FOUND", "user not found").WithMetadata(map[string]string{"id": "1"})
+			})(ctx, nil)
+			if e := errors.FromError(err); e.Message != test.msg || e.Code != 404 || e.Metadata["id"] != "1" {
+				t.Errorf("error = %v, want message %s", err, test.msg)
+			}
+			if lang := reply.Get("Content-Language"); lang != test.lang {
+				t.Errorf("Content-Language = %s, want %s", lang, test.lang)
+			}
+		})
+	}
+}
+
+func TestError_Cause(t *testing.T) {
+	b := i18n.NewBundle(language.English)
+	b.AddMessages(language.English, map[string]string{"USER_NOT_FOUND": "user {id} not found"})
+	l := b.Localizer("en")
+
+	notFound := errors.NotFound("USER_NOT_FOUND", "user not found").WithMetadata(map[string]string{"id": "1"})
+	cause := fmt.Errorf("get user: %w", notFound)
+	err := Error(l, cause)
+	if e := errors.FromError(err); e.Message != "user 1 not found" || e.Code != 404 {
+		t.Errorf("error = %v, want the translated message", err)
+	}
+	if !errors.Is(err, notFound) || errors.Unwrap(err) != cause {
+		t.Errorf("error = %v, want it to unwrap to %v", err, cause)
+	}
+	if notFound.Message != "user not found" {
+		t.Errorf("the original error is changed to %v", notFound)
+	}
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"context"
+	"strconv"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// BoostOption is debug boost option.
+type BoostOption func(*boostOptions)
+
+type boostOptions struct {
+	header  string
+	trusted func(ctx context.Context) bool
+	sampled bool
+	level   log.Level
+}
+
+// WithDebugHeader with the request header turning the boost on, e.g. "X-Debug"
+// for "X-Debug: true", default is none. The header is honored for the callers
+// trusted by WithTrusted only, any caller may set it otherwise.
+func WithDebugHeader(key string) BoostOption {
+	return func(o *boostOptions) {
+		o.header = key
+	}
+}
+
+// WithTrusted with the func reporting whether the caller of the request is
+// trusted to set the debug header, e.g. an authenticated operator.
+func WithTrusted(f func(ctx context.Context) bool) BoostOption {
+	return func(o *boostOptions) {
+		o.trusted = f
+	}
+}
+
+// WithSampled with whether the requests of the sampled traces are boosted,
+// default is false, the callers propagating the trace may sample it.
+func WithSampled(enable bool) BoostOption {
+	return func(o *boostOptions) {
+		o.sampled = enable
+	}
+}
+
+// WithBoostLevel with the level of the boosted requests, default is debug.
+func WithBoostLevel(level log.Level) BoostOption {
+	return func(o *boostOptions) {
+		o.level = level
+	}
+}
+
+// Boost is a server middleware lowering the level of the log filters for the
+// requests with the debug header or of a sampled trace, both opt-in, so only
+// their flow emits the debug logs. The loggers must log with the context of the
+// request, e.g. log.WithContext, and it must follow the tracing middleware.
+func Boost(opts ...BoostOption) middleware.Middleware {
+	o := &boostOptions{level: log.LevelDebug}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if o.boosted(ctx) {
+				ctx = log.NewLevelContext(ctx, o.level)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func (o *boostOptions) boosted(ctx context.Context) bool {
+	if o.sampled && trace.SpanContextFromContext(ctx).IsSampled() {
+		return true
+	}
+	if o.header == "" {
+		return false
+	}
+	tr, ok := transport.FromServerContext(ctx)
+	if !ok {
+		return false
+	}
+	if debug, _ := strconv.ParseBool(tr.RequestHeader().Get(o.header)); !debug {
+		return false
+	}
+	return o.trusted == nil || o.trusted(ctx)
+}
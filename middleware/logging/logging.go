@@ -11,6 +11,12 @@ import (
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
+// Redacter defines how to log an object, e.g. the requests with secrets
+// logging their args without the sensitive fields.
+type Redacter interface {
+	Redact() string
+}
+
 // Server is an server logging middleware.
 func Server(logger log.Logger) middleware.Middleware {
 	return func(handler middleware.Handler) middleware.Handler {
@@ -83,8 +89,22 @@ func Client(logger log.Logger) middleware.Middleware {
 	}
 }
 
-// extractArgs returns the string of the req
+// stream is implemented by the gRPC streams, passed as the request of the
+// stream handlers.
+type stream interface {
+	SendMsg(m interface{}) error
+	RecvMsg(m interface{}) error
+}
+
+// extractArgs returns the string of the req, redacted if it's a Redacter,
+// empty for the streams which have no request.
 func extractArgs(req interface{}) string {
+	if _, ok := req.(stream); ok {
+		return ""
+	}
+	if redacter, ok := req.(Redacter); ok {
+		return redacter.Redact()
+	}
 	if stringer, ok := req.(fmt.Stringer); ok {
 		return stringer.String()
 	}
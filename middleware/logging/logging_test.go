@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/middlewaretest"
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
@@ -97,3 +101,62 @@ func TestHTTP(t *testing.T) {
 		})
 	}
 }
+
+type login struct {
+	username string
+	password string
+}
+
+func (l login) Redact() string {
+	return "username:" + l.username + " password:***"
+}
+
+func TestRedacter(t *testing.T) {
+	bf := bytes.NewBuffer(nil)
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	_, _ = Server(log.NewStdLogger(bf))(next)(context.Background(), login{username: "kratos", password: "secret"})
+	if s := bf.String(); !strings.Contains(s, "password:***") || strings.Contains(s, "secret") {
+		t.Errorf("expect the args redacted, got %s", s)
+	}
+}
+
+type testStream struct {
+	state string
+}
+
+func (s *testStream) SendMsg(interface{}) error { return nil }
+func (s *testStream) RecvMsg(interface{}) error { return nil }
+
+func TestStream(t *testing.T) {
+	bf := bytes.NewBuffer(nil)
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	_, _ = Server(log.NewStdLogger(bf))(next)(context.Background(), &testStream{state: "internal"})
+	if s := bf.String(); strings.Contains(s, "internal") {
+		t.Errorf("expect the stream not logged, got %s", s)
+	}
+}
+
+func TestBoost(t *testing.T) {
+	bf := bytes.NewBuffer(nil)
+	logger := log.NewFilter(log.NewStdLogger(bf), log.FilterLevel(log.LevelInfo))
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		log.NewHelper(log.WithContext(ctx, logger)).Debugf("debug %v", req)
+		return nil, nil
+	}
+	h := Boost(WithDebugHeader("X-Debug"), WithSampled(true))(next)
+
+	header, _ := middlewaretest.NewServerContext(context.Background(), middlewaretest.WithHeader("X-Debug", "true"))
+	_, _ = h(header, "header")
+	ctx, _ := middlewaretest.NewServerContext(context.Background())
+	_, _ = h(ctx, "none")
+	sampled := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}, TraceFlags: trace.FlagsSampled})
+	_, _ = h(trace.ContextWithSpanContext(context.Background(), sampled), "sampled")
+	// both are opt-in.
+	_, _ = Boost()(next)(trace.ContextWithSpanContext(header, sampled), "default")
+	untrusted := func(context.Context) bool { return false }
+	_, _ = Boost(WithDebugHeader("X-Debug"), WithTrusted(untrusted))(next)(header, "untrusted")
+
+	if s := bf.String(); !strings.Contains(s, "debug header") || !strings.Contains(s, "debug sampled") || strings.Contains(s, "none") || strings.Contains(s, "default") || strings.Contains(s, "untrusted") {
+		t.Errorf("unexpected logs %q", s)
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-kratos/kratos/v2/baggage"
 	"github.com/go-kratos/kratos/v2/metadata"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
@@ -13,8 +17,15 @@ import (
 type Option func(*options)
 
 type options struct {
-	prefix []string
-	md     metadata.Metadata
+	prefix     []string
+	md         metadata.Metadata
+	baggage    *baggage.Policy
+	attributes []string
+}
+
+// deleter is implemented by the transport headers the keys can be deleted from.
+type deleter interface {
+	Del(key string)
 }
 
 func (o *options) hasPrefix(key string) bool {
@@ -41,6 +52,24 @@ func WithPropagatedPrefix(prefix ...string) Option {
 	}
 }
 
+// WithBaggage with the W3C baggage propagated under the policy p. The server
+// puts the received baggage in the context, the client sends the baggage of
+// the context.
+func WithBaggage(p baggage.Policy) Option {
+	return func(o *options) {
+		o.baggage = &p
+	}
+}
+
+// WithBaggageAttributes with the keys of the received baggage the server adds
+// to the current span as "baggage.<key>" attributes, default is none, since
+// the baggage comes from the callers.
+func WithBaggageAttributes(keys ...string) Option {
+	return func(o *options) {
+		o.attributes = keys
+	}
+}
+
 // Server is middleware server-side metadata.
 func Server(opts ...Option) middleware.Middleware {
 	options := &options{
@@ -60,6 +89,9 @@ func Server(opts ...Option) middleware.Middleware {
 					}
 				}
 				ctx = metadata.NewServerContext(ctx, md)
+				if options.baggage != nil {
+					ctx = serverBaggage(ctx, header.Get(baggage.HeaderKey), *options.baggage, options.attributes)
+				}
 			}
 			return handler(ctx, req)
 		}
@@ -95,8 +127,40 @@ func Client(opts ...Option) middleware.Middleware {
 						}
 					}
 				}
+				if options.baggage != nil {
+					if b, ok := baggage.FromContext(ctx); ok {
+						// the baggage of the context is filtered too, so that the
+						// OpenTelemetry propagators after this middleware follow the
+						// policy, and the header they set before is replaced.
+						b = b.Filter(*options.baggage)
+						ctx = baggage.NewContext(ctx, b)
+						if v := b.String(); v != "" {
+							header.Set(baggage.HeaderKey, v)
+						} else if d, ok := header.(deleter); ok {
+							d.Del(baggage.HeaderKey)
+						} else if header.Get(baggage.HeaderKey) != "" {
+							header.Set(baggage.HeaderKey, "")
+						}
+					}
+				}
 			}
 			return handler(ctx, req)
 		}
 	}
 }
+
+func serverBaggage(ctx context.Context, header string, p baggage.Policy, attributes []string) context.Context {
+	b := baggage.Parse(header, p)
+	attrs := make([]attribute.KeyValue, 0, len(attributes))
+	for _, k := range attributes {
+		if v, ok := b[k]; ok {
+			attrs = append(attrs, attribute.String("baggage."+k, v))
+		}
+	}
+	if len(attrs) > 0 {
+		trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	}
+	// the baggage is installed even if empty, since the OpenTelemetry propagators,
+	// e.g. of the tracing middleware, may have stored the header as it is.
+	return baggage.NewContext(ctx, b)
+}
@@ -3,10 +3,20 @@ package metadata
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/go-kratos/kratos/v2/baggage"
 	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
@@ -16,6 +26,8 @@ func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key)
 
 func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
 
+func (hc headerCarrier) Del(key string) { http.Header(hc).Del(key) }
+
 // Keys lists the keys stored in this carrier.
 func (hc headerCarrier) Keys() []string {
 	keys := make([]string, 0, len(hc))
@@ -126,3 +138,120 @@ func TestClient(t *testing.T) {
 		t.Fatalf("want foo got %v", reply)
 	}
 }
+
+func TestBaggage(t *testing.T) {
+	policy := baggage.Policy{AllowedKeys: []string{"tenant"}}
+	hs := func(ctx context.Context, in interface{}) (interface{}, error) {
+		if v := baggage.Get(ctx, "tenant"); v != "acme" {
+			return nil, fmt.Errorf("expect tenant acme, got %q", v)
+		}
+		if v := baggage.Get(ctx, "secret"); v != "" {
+			return nil, fmt.Errorf("expect secret to be dropped, got %q", v)
+		}
+		return in, nil
+	}
+	hc := func(ctx context.Context, in interface{}) (interface{}, error) {
+		tr, _ := transport.FromClientContext(ctx)
+		if v := tr.RequestHeader().Get(baggage.HeaderKey); v != "tenant=acme" {
+			return nil, fmt.Errorf("expect tenant=acme, got %q", v)
+		}
+		return in, nil
+	}
+	// the server passes its baggage to the client calls made while handling the request.
+	forward := func(ctx context.Context, in interface{}) (interface{}, error) {
+		if _, err := hs(ctx, in); err != nil {
+			return nil, err
+		}
+		ctx = transport.NewClientContext(baggage.Set(ctx, "secret", "1"), &testTransport{headerCarrier{}})
+		return Client(WithBaggage(policy))(hc)(ctx, in)
+	}
+	hdr := headerCarrier{}
+	hdr.Set(baggage.HeaderKey, "tenant=acme,secret=1")
+	ctx := transport.NewServerContext(context.Background(), &testTransport{hdr})
+	if _, err := Server(WithBaggage(policy))(forward)(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBaggage_Tracing(t *testing.T) {
+	policy := baggage.Policy{AllowedKeys: []string{"tenant"}}
+	handler := func(ctx context.Context, in interface{}) (interface{}, error) {
+		tr, _ := transport.FromClientContext(ctx)
+		if v := tr.RequestHeader().Get(baggage.HeaderKey); v != "tenant=acme" {
+			return nil, fmt.Errorf("expect tenant=acme, got %q", v)
+		}
+		return in, nil
+	}
+	chains := map[string][]middleware.Middleware{
+		"tracing first":  {tracing.Client(), Client(WithBaggage(policy))},
+		"metadata first": {Client(WithBaggage(policy)), tracing.Client()},
+	}
+	for name, chain := range chains {
+		ctx := baggage.Set(baggage.Set(context.Background(), "tenant", "acme"), "secret", "1")
+		ctx = transport.NewClientContext(ctx, &testTransport{headerCarrier{}})
+		if _, err := middleware.Chain(chain...)(handler)(ctx, "foo"); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestBaggage_TracingServer(t *testing.T) {
+	policy := baggage.Policy{AllowedKeys: []string{"tenant"}}
+	handler := func(ctx context.Context, in interface{}) (interface{}, error) {
+		if b, ok := baggage.FromContext(ctx); ok {
+			return nil, fmt.Errorf("expect no baggage, got %v", b)
+		}
+		return in, nil
+	}
+	hdr := headerCarrier{}
+	hdr.Set(baggage.HeaderKey, "secret=1,token=2")
+	ctx := transport.NewServerContext(context.Background(), &testTransport{hdr})
+	chain := middleware.Chain(tracing.Server(), Server(WithBaggage(policy)))
+	if _, err := chain(handler)(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBaggage_EmptyHeader(t *testing.T) {
+	policy := baggage.Policy{AllowedKeys: []string{"tenant"}}
+	handler := func(ctx context.Context, in interface{}) (interface{}, error) {
+		tr, _ := transport.FromClientContext(ctx)
+		for _, k := range tr.RequestHeader().Keys() {
+			if strings.EqualFold(k, baggage.HeaderKey) {
+				return nil, fmt.Errorf("expect no baggage header, got %q", tr.RequestHeader().Get(k))
+			}
+		}
+		return in, nil
+	}
+	hdr := headerCarrier{}
+	hdr.Set(baggage.HeaderKey, "secret=1")
+	ctx := transport.NewClientContext(baggage.Set(context.Background(), "secret", "1"), &testTransport{hdr})
+	if _, err := Client(WithBaggage(policy))(handler)(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBaggage_Attributes(t *testing.T) {
+	hdr := headerCarrier{}
+	hdr.Set(baggage.HeaderKey, "tenant=acme,user=bob")
+	tests := map[string]struct {
+		opts  []Option
+		attrs []attribute.KeyValue
+	}{
+		"none by default": {nil, nil},
+		"allowed keys":    {[]Option{WithBaggageAttributes("tenant", "missing")}, []attribute.KeyValue{attribute.String("baggage.tenant", "acme")}},
+	}
+	for name, test := range tests {
+		recorder := tracetest.NewSpanRecorder()
+		ctx, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "test")
+		ctx = transport.NewServerContext(ctx, &testTransport{hdr})
+		opts := append([]Option{WithBaggage(baggage.Policy{})}, test.opts...)
+		if _, err := Server(opts...)(func(ctx context.Context, in interface{}) (interface{}, error) { return in, nil })(ctx, "foo"); err != nil {
+			t.Fatal(err)
+		}
+		span.End()
+		if attrs := recorder.Ended()[0].Attributes(); !reflect.DeepEqual(attrs, test.attrs) {
+			t.Errorf("%s: expect %v, got %v", name, test.attrs, attrs)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// OtherLabel is the label value reported for values outside of the top K.
+const OtherLabel = "other"
+
+// topKRefresh is the number of observations between two refreshes of the top K.
+const topKRefresh = 128
+
+// LabelFunc returns a label value extracted from the request context, e.g. the tenant.
+type LabelFunc func(ctx context.Context) string
+
+// TopK limits the cardinality of the values returned by fn to the k most
+// frequent values seen, the other values are reported as OtherLabel.
+// The first k values seen are reported until a value is seen more often than
+// the least frequent of them, it then replaces it at the next refresh.
+// Frequencies are estimated with the space-saving algorithm over 4*k counters.
+// If k <= 0, every value is reported as OtherLabel.
+func TopK(fn LabelFunc, k int) LabelFunc {
+	if k <= 0 {
+		return func(ctx context.Context) string { return OtherLabel }
+	}
+	t := &topK{
+		k:      k,
+		size:   4 * k,
+		counts: make(map[string]*ssCounter, 4*k),
+		top:    make(map[string]uint64, k),
+	}
+	return func(ctx context.Context) string {
+		return t.observe(fn(ctx))
+	}
+}
+
+// ssCounter is a space-saving counter, count overestimates the frequency by at most err.
+type ssCounter struct {
+	count uint64
+	err   uint64
+}
+
+type topK struct {
+	mu   sync.Mutex
+	k    int
+	size int
+	n    int
+	// counts are the candidates, top the values reported with their exact counts.
+	counts map[string]*ssCounter
+	top    map[string]uint64
+}
+
+func (t *topK) observe(v string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.n++; t.n%topKRefresh == 0 {
+		defer t.refresh()
+	}
+	if c, ok := t.top[v]; ok {
+		t.top[v] = c + 1
+		return v
+	}
+	if len(t.top) < t.k {
+		t.top[v] = 1
+		return v
+	}
+	if c, ok := t.counts[v]; ok {
+		c.count++
+		return OtherLabel
+	}
+	if len(t.counts) < t.size {
+		t.counts[v] = &ssCounter{count: 1}
+		return OtherLabel
+	}
+	// replace the least frequent candidate, the new one inherits its count.
+	var (
+		leastKey string
+		least    *ssCounter
+	)
+	for key, c := range t.counts {
+		if least == nil || c.count < least.count {
+			leastKey, least = key, c
+		}
+	}
+	delete(t.counts, leastKey)
+	t.counts[v] = &ssCounter{count: least.count + 1, err: least.count}
+	return OtherLabel
+}
+
+// refresh swaps the candidates surely seen more often than the least frequent
+// values reported, so the values reported only change for more frequent ones.
+func (t *topK) refresh() {
+	candidates := make([]string, 0, len(t.counts))
+	for key := range t.counts {
+		candidates = append(candidates, key)
+	}
+	guaranteed := func(key string) uint64 { return t.counts[key].count - t.counts[key].err }
+	sort.Slice(candidates, func(i, j int) bool { return guaranteed(candidates[i]) > guaranteed(candidates[j]) })
+	members := make([]string, 0, len(t.top))
+	for key := range t.top {
+		members = append(members, key)
+	}
+	sort.Slice(members, func(i, j int) bool { return t.top[members[i]] < t.top[members[j]] })
+	for i, key := range candidates {
+		if i >= len(members) || guaranteed(key) <= t.top[members[i]] {
+			break
+		}
+		evicted := members[i]
+		t.counts[evicted] = &ssCounter{count: t.top[evicted]}
+		delete(t.top, evicted)
+		t.top[key] = guaranteed(key)
+		delete(t.counts, key)
+	}
+}
@@ -28,11 +28,33 @@ func WithSeconds(c metrics.Observer) Option {
 	}
 }
 
+// WithLabels with extra labels appended to the label values of both metrics,
+// the metric vectors must declare them after the builtin labels.
+// Wrap high cardinality labels such as tenants with TopK.
+func WithLabels(fns ...LabelFunc) Option {
+	return func(o *options) {
+		o.labels = fns
+	}
+}
+
 type options struct {
-	// counter: <client/server>_requests_code_total{kind, operation, code, reason}
+	// counter: <client/server>_requests_code_total{kind, operation, code, reason, labels...}
 	requests metrics.Counter
-	// histogram: <client/server>_requests_seconds_bucket{kind, operation}
+	// histogram: <client/server>_requests_seconds_bucket{kind, operation, labels...}
 	seconds metrics.Observer
+	labels  []LabelFunc
+}
+
+// labelValues returns the values of the extra labels, called once per request.
+func (o *options) labelValues(ctx context.Context) []string {
+	if len(o.labels) == 0 {
+		return nil
+	}
+	lvs := make([]string, 0, len(o.labels))
+	for _, fn := range o.labels {
+		lvs = append(lvs, fn(ctx))
+	}
+	return lvs
 }
 
 // Server is middleware server-side metrics.
@@ -59,11 +81,12 @@ func Server(opts ...Option) middleware.Middleware {
 				code = int(se.Code)
 				reason = se.Reason
 			}
+			labels := op.labelValues(ctx)
 			if op.requests != nil {
-				op.requests.With(kind, operation, strconv.Itoa(code), reason).Inc()
+				op.requests.With(append([]string{kind, operation, strconv.Itoa(code), reason}, labels...)...).Inc()
 			}
 			if op.seconds != nil {
-				op.seconds.With(kind, operation).Observe(time.Since(startTime).Seconds())
+				op.seconds.With(append([]string{kind, operation}, labels...)...).Observe(time.Since(startTime).Seconds())
 			}
 			return reply, err
 		}
@@ -94,11 +117,12 @@ func Client(opts ...Option) middleware.Middleware {
 				code = int(se.Code)
 				reason = se.Reason
 			}
+			labels := op.labelValues(ctx)
 			if op.requests != nil {
-				op.requests.With(kind, operation, strconv.Itoa(code), reason).Inc()
+				op.requests.With(append([]string{kind, operation, strconv.Itoa(code), reason}, labels...)...).Inc()
 			}
 			if op.seconds != nil {
-				op.seconds.With(kind, operation).Observe(time.Since(startTime).Seconds())
+				op.seconds.With(append([]string{kind, operation}, labels...)...).Observe(time.Since(startTime).Seconds())
 			}
 			return reply, err
 		}
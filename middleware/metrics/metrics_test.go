@@ -2,7 +2,11 @@ package metrics
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"testing"
+
+	"github.com/go-kratos/kratos/v2/metrics"
 )
 
 func TestMetrics(t *testing.T) {
@@ -19,3 +23,94 @@ func TestMetrics(t *testing.T) {
 		t.Errorf("expect %v, got %v", nil, err)
 	}
 }
+
+type tenantKey struct{}
+
+type counter struct {
+	lvs []string
+}
+
+func (c *counter) With(lvs ...string) metrics.Counter { c.lvs = lvs; return c }
+func (c *counter) Inc()                               {}
+func (c *counter) Add(delta float64)                  {}
+
+type observer struct {
+	lvs []string
+}
+
+func (o *observer) With(lvs ...string) metrics.Observer { o.lvs = lvs; return o }
+func (o *observer) Observe(float64)                     {}
+
+func TestLabels(t *testing.T) {
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+	var calls int
+	tenant := func(ctx context.Context) string {
+		calls++
+		v, _ := ctx.Value(tenantKey{}).(string)
+		return v
+	}
+	c, o := &counter{}, &observer{}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	if _, err := Server(WithRequests(c), WithSeconds(o), WithLabels(tenant))(next)(ctx, "req"); err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"", "", "0", "", "acme"}
+	if !reflect.DeepEqual(expect, c.lvs) {
+		t.Errorf("expect %v, got %v", expect, c.lvs)
+	}
+	if expect = []string{"", "", "acme"}; !reflect.DeepEqual(expect, o.lvs) {
+		t.Errorf("expect %v, got %v", expect, o.lvs)
+	}
+	if calls != 1 {
+		t.Errorf("expect the labels computed once, got %d calls", calls)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	var v string
+	fn := TopK(func(ctx context.Context) string { return v }, 2)
+	for i := 0; i < 1000; i++ {
+		v = []string{"a", "a", "a", "b", "b", fmt.Sprintf("rare-%d", i)}[i%6]
+		fn(context.Background())
+	}
+	for _, test := range []struct{ in, out string }{{"a", "a"}, {"b", "b"}, {"rare-1", OtherLabel}} {
+		v = test.in
+		if got := fn(context.Background()); got != test.out {
+			t.Errorf("expect %s, got %s", test.out, got)
+		}
+	}
+}
+
+func TestTopK_NoCapacity(t *testing.T) {
+	for _, k := range []int{0, -1} {
+		fn := TopK(func(ctx context.Context) string { return "a" }, k)
+		for i := 0; i < 2; i++ {
+			if got := fn(context.Background()); got != OtherLabel {
+				t.Errorf("k=%d: expect %s, got %s", k, OtherLabel, got)
+			}
+		}
+	}
+}
+
+func TestTopK_Cardinality(t *testing.T) {
+	var v string
+	fn := TopK(func(ctx context.Context) string { return v }, 5)
+	emitted := make(map[string]struct{})
+	for i := 0; i < 100000; i++ {
+		v = fmt.Sprintf("unique-%d", i)
+		emitted[fn(context.Background())] = struct{}{}
+	}
+	if len(emitted) > 6 {
+		t.Errorf("expect at most 6 distinct labels, got %d", len(emitted))
+	}
+	// a frequent value still enters the top K.
+	v = "hot"
+	for i := 0; i < 1000; i++ {
+		fn(context.Background())
+	}
+	if got := fn(context.Background()); got != "hot" {
+		t.Errorf("expect the frequent value, got %s", got)
+	}
+}
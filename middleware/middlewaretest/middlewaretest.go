@@ -0,0 +1,254 @@
+// Package middlewaretest provides utilities for middleware testing: fake
+// transports, request contexts, stub handlers and assertions.
+//
+//	ctx, tr := middlewaretest.NewServerContext(context.Background(),
+//		middlewaretest.WithOperation("/helloworld.Greeter/SayHello"),
+//		middlewaretest.WithHeader("Accept-Language", "de-DE"),
+//	)
+//	_, err := localize.Server(bundle)(middlewaretest.Echo)(ctx, nil)
+//	middlewaretest.AssertReason(t, err, "")
+//	middlewaretest.AssertReplyHeader(t, tr, "Content-Language", "de")
+package middlewaretest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+var (
+	_ transport.Transporter = (*Transport)(nil)
+	_ khttp.Transporter     = (*HTTPTransport)(nil)
+)
+
+// Header is a transport.Header backed by an http.Header.
+type Header http.Header
+
+// NewHeader returns a header with the key value pairs.
+func NewHeader(kv ...string) Header {
+	h := Header{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		http.Header(h).Add(kv[i], kv[i+1])
+	}
+	return h
+}
+
+// Get returns the value associated with the passed key.
+func (h Header) Get(key string) string { return http.Header(h).Get(key) }
+
+// Set stores the key-value pair.
+func (h Header) Set(key string, value string) { http.Header(h).Set(key, value) }
+
+// Keys lists the keys stored in this carrier.
+func (h Header) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Transport is a fake transport.Transporter.
+type Transport struct {
+	kind        transport.Kind
+	endpoint    string
+	operation   string
+	reqHeader   Header
+	replyHeader Header
+}
+
+// Kind returns the transport kind.
+func (tr *Transport) Kind() transport.Kind { return tr.kind }
+
+// Endpoint returns the transport endpoint.
+func (tr *Transport) Endpoint() string { return tr.endpoint }
+
+// Operation returns the transport operation.
+func (tr *Transport) Operation() string { return tr.operation }
+
+// RequestHeader returns the request header.
+func (tr *Transport) RequestHeader() transport.Header { return tr.reqHeader }
+
+// ReplyHeader returns the reply header.
+func (tr *Transport) ReplyHeader() transport.Header { return tr.replyHeader }
+
+// HTTPTransport is a fake khttp.Transporter.
+type HTTPTransport struct {
+	Transport
+	request      *http.Request
+	pathTemplate string
+}
+
+// Request returns the HTTP request.
+func (tr *HTTPTransport) Request() *http.Request { return tr.request }
+
+// PathTemplate returns the http path template.
+func (tr *HTTPTransport) PathTemplate() string { return tr.pathTemplate }
+
+// Option is a request context option.
+type Option func(*options)
+
+type options struct {
+	tr       Transport
+	request  *http.Request
+	template string
+	md       metadata.Metadata
+}
+
+// WithKind with the transport kind, default is gRPC.
+func WithKind(kind transport.Kind) Option {
+	return func(o *options) {
+		o.tr.kind = kind
+	}
+}
+
+// WithEndpoint with the transport endpoint.
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.tr.endpoint = endpoint
+	}
+}
+
+// WithOperation with the transport operation, default is "/test.Service/Method".
+func WithOperation(operation string) Option {
+	return func(o *options) {
+		o.tr.operation = operation
+	}
+}
+
+// WithHeader adds a request header.
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		http.Header(o.tr.reqHeader).Add(key, value)
+	}
+}
+
+// WithMetadata adds a metadata pair, e.g. "x-md-global-uid".
+func WithMetadata(key, value string) Option {
+	return func(o *options) {
+		if o.md == nil {
+			o.md = metadata.Metadata{}
+		}
+		o.md.Set(key, value)
+	}
+}
+
+// WithRequest makes the transport an HTTPTransport of the request and the path template,
+// the headers of the request are added to the request header.
+func WithRequest(req *http.Request, pathTemplate string) Option {
+	return func(o *options) {
+		o.tr.kind = transport.KindHTTP
+		o.request = req
+		o.template = pathTemplate
+	}
+}
+
+func newTransport(opts []Option) (transport.Transporter, *options) {
+	o := &options{tr: Transport{
+		kind:        transport.KindGRPC,
+		operation:   "/test.Service/Method",
+		reqHeader:   Header{},
+		replyHeader: Header{},
+	}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.request == nil {
+		return &o.tr, o
+	}
+	for k, vs := range o.request.Header {
+		for _, v := range vs {
+			http.Header(o.tr.reqHeader).Add(k, v)
+		}
+	}
+	return &HTTPTransport{Transport: o.tr, request: o.request, pathTemplate: o.template}, o
+}
+
+// NewServerContext returns a server request context of a fake transport.
+func NewServerContext(ctx context.Context, opts ...Option) (context.Context, transport.Transporter) {
+	tr, o := newTransport(opts)
+	ctx = transport.NewServerContext(ctx, tr)
+	if o.md != nil {
+		ctx = metadata.NewServerContext(ctx, o.md)
+	}
+	return ctx, tr
+}
+
+// NewClientContext returns a client request context of a fake transport.
+func NewClientContext(ctx context.Context, opts ...Option) (context.Context, transport.Transporter) {
+	tr, o := newTransport(opts)
+	ctx = transport.NewClientContext(ctx, tr)
+	if o.md != nil {
+		ctx = metadata.NewClientContext(ctx, o.md)
+	}
+	return ctx, tr
+}
+
+// Echo is a handler replying the request.
+func Echo(_ context.Context, req interface{}) (interface{}, error) {
+	return req, nil
+}
+
+// Reply returns a handler replying reply and err.
+func Reply(reply interface{}, err error) middleware.Handler {
+	return func(context.Context, interface{}) (interface{}, error) {
+		return reply, err
+	}
+}
+
+// Recorder is a handler recording its calls.
+type Recorder struct {
+	// Handler is called by the recorder, default is Echo.
+	Handler middleware.Handler
+	// Calls is the number of calls.
+	Calls int
+	// Ctx and Req are the arguments of the last call.
+	Ctx context.Context
+	Req interface{}
+}
+
+// Handle records the call and calls the handler.
+func (r *Recorder) Handle(ctx context.Context, req interface{}) (interface{}, error) {
+	r.Calls++
+	r.Ctx, r.Req = ctx, req
+	if r.Handler == nil {
+		return Echo(ctx, req)
+	}
+	return r.Handler(ctx, req)
+}
+
+// AssertReason asserts that err has the reason, or is nil if reason is empty.
+func AssertReason(t testing.TB, err error, reason string) {
+	t.Helper()
+	if reason == "" {
+		if err != nil {
+			t.Errorf("expect no error, got %v", err)
+		}
+		return
+	}
+	if got := errors.Reason(err); got != reason {
+		t.Errorf("expect reason %s, got %v", reason, err)
+	}
+}
+
+// AssertCode asserts that err has the code, 200 for nil.
+func AssertCode(t testing.TB, err error, code int) {
+	t.Helper()
+	if got := errors.Code(err); got != code {
+		t.Errorf("expect code %d, got %d: %v", code, got, err)
+	}
+}
+
+// AssertReplyHeader asserts that the reply header key of tr is value.
+func AssertReplyHeader(t testing.TB, tr transport.Transporter, key, value string) {
+	t.Helper()
+	if got := tr.ReplyHeader().Get(key); got != value {
+		t.Errorf("expect reply header %s: %q, got %q", key, value, got)
+	}
+}
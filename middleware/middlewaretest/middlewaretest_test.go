@@ -0,0 +1,76 @@
+package middlewaretest
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// auth rejects the requests without the X-API-Key header and echoes the uid metadata.
+func auth(handler middleware.Handler) middleware.Handler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		tr, ok := transport.FromServerContext(ctx)
+		if !ok || tr.RequestHeader().Get("X-API-Key") == "" {
+			return nil, errors.Unauthorized("UNAUTHORIZED", "missing api key")
+		}
+		if md, ok := metadata.FromServerContext(ctx); ok {
+			tr.ReplyHeader().Set("X-Uid", md.Get("x-md-global-uid"))
+		}
+		return handler(ctx, req)
+	}
+}
+
+func TestServerContext(t *testing.T) {
+	ctx, tr := NewServerContext(context.Background(),
+		WithOperation("/helloworld.Greeter/SayHello"),
+		WithHeader("X-API-Key", "secret"),
+		WithMetadata("x-md-global-uid", "42"),
+	)
+	rec := &Recorder{}
+	reply, err := auth(rec.Handle)(ctx, "hello")
+	AssertReason(t, err, "")
+	AssertCode(t, err, 200)
+	AssertReplyHeader(t, tr, "X-Uid", "42")
+	if reply != "hello" || rec.Calls != 1 || rec.Req != "hello" {
+		t.Errorf("unexpected reply %v, recorder %+v", reply, rec)
+	}
+	if tr.Kind() != transport.KindGRPC || tr.Operation() != "/helloworld.Greeter/SayHello" {
+		t.Errorf("unexpected transport %+v", tr)
+	}
+
+	ctx, _ = NewServerContext(context.Background())
+	_, err = auth(Reply("unreachable", nil))(ctx, nil)
+	AssertReason(t, err, "UNAUTHORIZED")
+	AssertCode(t, err, 401)
+}
+
+func TestHTTPRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/v1/users/1?lang=de", nil)
+	req.Header.Set("X-API-Key", "secret")
+	ctx, tr := NewServerContext(context.Background(), WithRequest(req, "/v1/users/{id}"))
+	ht, ok := tr.(khttp.Transporter)
+	if !ok {
+		t.Fatal("expect an HTTP transporter")
+	}
+	if ht.Kind() != transport.KindHTTP || ht.Request() != req || ht.PathTemplate() != "/v1/users/{id}" {
+		t.Errorf("unexpected transport %+v", ht)
+	}
+	_, err := auth(Echo)(ctx, nil)
+	AssertReason(t, err, "")
+}
+
+func TestClientContext(t *testing.T) {
+	ctx, _ := NewClientContext(context.Background(), WithMetadata("x-md-global-uid", "42"))
+	if _, ok := transport.FromClientContext(ctx); !ok {
+		t.Error("expect a client transporter")
+	}
+	if md, ok := metadata.FromClientContext(ctx); !ok || md.Get("x-md-global-uid") != "42" {
+		t.Errorf("unexpected metadata %v", md)
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Predicate reports whether a request is selected.
+type Predicate func(ctx context.Context, req interface{}) bool
+
+// If returns a Middleware running m only for the requests selected by p,
+// evaluated per request:
+//
+//	middleware.If(middleware.OperationPrefix("/api.admin."), auth)
+func If(p Predicate, m Middleware) Middleware {
+	return func(handler Handler) Handler {
+		next := m(handler)
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if p(ctx, req) {
+				return next(ctx, req)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Not returns the negation of p.
+func Not(p Predicate) Predicate {
+	return func(ctx context.Context, req interface{}) bool {
+		return !p(ctx, req)
+	}
+}
+
+// OperationPrefix selects the requests of the operations with one of the prefixes.
+func OperationPrefix(prefix ...string) Predicate {
+	return func(ctx context.Context, _ interface{}) bool {
+		tr, ok := transport.FromServerContext(ctx)
+		if !ok {
+			tr, ok = transport.FromClientContext(ctx)
+		}
+		if !ok {
+			return false
+		}
+		for _, p := range prefix {
+			if strings.HasPrefix(tr.Operation(), p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HeaderIs selects the server requests with the header key equal to
+// value, or present if value is empty.
+func HeaderIs(key, value string) Predicate {
+	return func(ctx context.Context, _ interface{}) bool {
+		tr, ok := transport.FromServerContext(ctx)
+		if !ok {
+			return false
+		}
+		v := tr.RequestHeader().Get(key)
+		if value == "" {
+			return v != ""
+		}
+		return v == value
+	}
+}
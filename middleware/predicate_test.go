@@ -0,0 +1,42 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/middlewaretest"
+)
+
+func TestIf(t *testing.T) {
+	var applied bool
+	m := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			applied = true
+			return handler(ctx, req)
+		}
+	}
+	tests := []struct {
+		name string
+		p    middleware.Predicate
+		opt  middlewaretest.Option
+		want bool
+	}{
+		{"prefix", middleware.OperationPrefix("/api.admin."), middlewaretest.WithOperation("/api.admin.v1.Admin/List"), true},
+		{"other prefix", middleware.OperationPrefix("/api.admin."), middlewaretest.WithOperation("/api.user.v1.User/Get"), false},
+		{"not", middleware.Not(middleware.OperationPrefix("/api.admin.")), middlewaretest.WithOperation("/api.user.v1.User/Get"), true},
+		{"header", middleware.HeaderIs("X-Debug", "1"), middlewaretest.WithHeader("X-Debug", "1"), true},
+		{"header present", middleware.HeaderIs("X-Debug", ""), middlewaretest.WithHeader("X-Debug", "0"), true},
+		{"header missing", middleware.HeaderIs("X-Debug", ""), middlewaretest.WithHeader("X-Other", "1"), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			applied = false
+			ctx, _ := middlewaretest.NewServerContext(context.Background(), test.opt)
+			reply, err := middleware.If(test.p, m)(middlewaretest.Echo)(ctx, "reply")
+			if reply != "reply" || err != nil || applied != test.want {
+				t.Errorf("applied %v, want %v", applied, test.want)
+			}
+		})
+	}
+}
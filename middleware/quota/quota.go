@@ -0,0 +1,238 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/monitor"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const reason = "QUOTA_EXCEEDED"
+
+// ErrWrongContext is returned when the middleware isn't used on the server side.
+var ErrWrongContext = errors.Unauthorized("QUOTA", "wrong context for middleware")
+
+// Period is the accounting period of a quota.
+type Period int
+
+const (
+	// Daily quotas reset at midnight.
+	Daily Period = iota
+	// Monthly quotas reset on the first day of the month.
+	Monthly
+)
+
+func (p Period) String() string {
+	if p == Monthly {
+		return "monthly"
+	}
+	return "daily"
+}
+
+// window returns the start and the end of the period containing t.
+func (p Period) window(t time.Time) (time.Time, time.Time) {
+	y, m, d := t.Date()
+	if p == Monthly {
+		start := time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 0, 1)
+}
+
+// Quota is the maximum number of requests of a key during a period.
+type Quota struct {
+	Period Period
+	Limit  int64
+}
+
+// QuotaExceeded returns a quota exceeded error, its metadata contains the
+// period, the limit and the reset time in RFC 3339.
+func QuotaExceeded(q Quota, reset time.Time) *errors.Error {
+	return errors.New(429, reason, fmt.Sprintf("%s quota of %d requests exceeded", q.Period, q.Limit)).WithMetadata(map[string]string{
+		"period": q.Period.String(),
+		"limit":  strconv.FormatInt(q.Limit, 10),
+		"reset":  reset.UTC().Format(time.RFC3339),
+	})
+}
+
+// IsQuotaExceeded reports whether err is a quota exceeded error.
+func IsQuotaExceeded(err error) bool {
+	return errors.Reason(err) == reason
+}
+
+// Option is quota option.
+type Option func(*options)
+
+type options struct {
+	key      func(ctx context.Context) string
+	quotas   func(key string) []Quota
+	store    Store
+	location *time.Location
+	usage    metrics.Gauge
+	label    func(key string) string
+	now      func() time.Time
+
+	monitorOnly bool
+	wouldReject metrics.Counter
+	logger      log.Logger
+}
+
+// WithHeaderKey with the request header carrying the API key, default is X-API-Key.
+func WithHeaderKey(header string) Option {
+	return func(o *options) {
+		o.key = func(ctx context.Context) string {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				return tr.RequestHeader().Get(header)
+			}
+			return ""
+		}
+	}
+}
+
+// WithKey with the func returning the key of a request, e.g. from the auth claims,
+// the requests without key aren't accounted.
+func WithKey(f func(ctx context.Context) string) Option {
+	return func(o *options) {
+		o.key = f
+	}
+}
+
+// WithQuotas with the quotas applied to all keys.
+func WithQuotas(quotas ...Quota) Option {
+	return func(o *options) {
+		o.quotas = func(string) []Quota { return quotas }
+	}
+}
+
+// WithQuotaFunc with the func returning the quotas of a key, e.g. by plan.
+func WithQuotaFunc(f func(key string) []Quota) Option {
+	return func(o *options) {
+		o.quotas = f
+	}
+}
+
+// WithStore with the usage store, default is a MemoryStore.
+func WithStore(s Store) Option {
+	return func(o *options) {
+		o.store = s
+	}
+}
+
+// WithLocation with the location the periods start in, default is UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
+}
+
+// WithUsage with the usage gauge, labeled by the label of the key and period.
+func WithUsage(g metrics.Gauge) Option {
+	return func(o *options) {
+		o.usage = g
+	}
+}
+
+// WithLabel with the func returning the metrics label of a key, e.g. its plan,
+// default is a hash of the key so that the keys aren't exported.
+func WithLabel(f func(key string) string) Option {
+	return func(o *options) {
+		o.label = f
+	}
+}
+
+// WithLogger with the logger of the requests the quota would reject in monitor
+// only mode and of the store errors.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithMonitorOnly accounts the requests exceeding a quota without rejecting
+// them, counting them by the label of the key and period in c, which may be nil.
+func WithMonitorOnly(c metrics.Counter) Option {
+	return func(o *options) {
+		o.monitorOnly = true
+		o.wouldReject = c
+	}
+}
+
+// Server is a server middleware accounting the requests of each key against
+// its quotas, the rejected requests aren't accounted.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{
+		quotas:   func(string) []Quota { return nil },
+		location: time.UTC,
+		label:    monitor.HashKey,
+		now:      time.Now,
+		logger:   log.GetLogger(),
+	}
+	WithHeaderKey("X-API-Key")(o)
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.store == nil {
+		o.store = NewMemoryStore()
+	}
+	reporter := monitor.NewReporter("quota", o.wouldReject, o.logger)
+	helper := log.NewHelper(o.logger)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if _, ok := transport.FromServerContext(ctx); !ok {
+				return nil, ErrWrongContext
+			}
+			key := o.key(ctx)
+			if key == "" {
+				return handler(ctx, req)
+			}
+			now := o.now().In(o.location)
+			type counted struct {
+				key string
+				ttl time.Duration
+			}
+			var incremented []counted
+			// giveBack gives back the request to the quotas it was accounted by
+			giveBack := func() {
+				for _, c := range incremented {
+					if _, err := o.store.Incr(ctx, c.key, -1, c.ttl); err != nil {
+						helper.WithContext(ctx).Errorf("quota: failed to give back a rejected request: %v", err)
+					}
+				}
+			}
+			// the usage is stored by a hash of the key, so that the store doesn't persist it.
+			hashed := monitor.HashKey(key)
+			for _, q := range o.quotas(key) {
+				start, reset := q.Period.window(now)
+				c := counted{key: fmt.Sprintf("%s:%s:%d", hashed, q.Period, start.Unix()), ttl: reset.Sub(now)}
+				used, err := o.store.Incr(ctx, c.key, 1, c.ttl)
+				if err != nil {
+					helper.WithContext(ctx).Errorf("quota: failed to account a request: %v", err)
+					giveBack()
+					return nil, errors.InternalServer("QUOTA", "failed to account the request")
+				}
+				incremented = append(incremented, c)
+				if used > q.Limit && !o.monitorOnly {
+					giveBack()
+					return nil, QuotaExceeded(q, reset)
+				}
+				if o.usage != nil {
+					o.usage.With(o.label(key), q.Period.String()).Set(float64(used))
+				}
+				if used <= q.Limit {
+					continue
+				}
+				label := o.label(key)
+				reporter.Report(ctx, []string{label, q.Period.String()}, "key %s: %s quota of %d requests exceeded", label, q.Period, q.Limit)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package quota
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/monitor"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	reqHeader transport.Header
+}
+
+func (tr *Transport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return "/test.Quota/Call" }
+func (tr *Transport) RequestHeader() transport.Header { return tr.reqHeader }
+func (tr *Transport) ReplyHeader() transport.Header   { return nil }
+
+func withNow(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+func TestServer(t *testing.T) {
+	now := time.Date(2022, 1, 30, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store := NewMemoryStore()
+	store.now = clock
+	h := Server(
+		WithStore(store),
+		WithQuotas(Quota{Period: Daily, Limit: 2}, Quota{Period: Monthly, Limit: 3}),
+		withNow(clock),
+	)(func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil })
+	call := func(key string) error {
+		header := headerCarrier{}
+		header.Set("X-API-Key", key)
+		_, err := h(transport.NewServerContext(context.Background(), &Transport{reqHeader: header}), nil)
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := call("alice"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := call("alice")
+	if !IsQuotaExceeded(err) {
+		t.Fatalf("expect daily quota exceeded, got %v", err)
+	}
+	if md := errors.FromError(err).Metadata; md["period"] != "daily" || md["reset"] != "2022-01-31T00:00:00Z" {
+		t.Errorf("unexpected metadata %v", md)
+	}
+	if err := call("bob"); err != nil {
+		t.Errorf("expect the quotas to be per key, got %v", err)
+	}
+	if err := call(""); err != nil {
+		t.Errorf("expect the requests without key to pass, got %v", err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if err := call("alice"); err != nil {
+		t.Fatalf("expect the daily quota to reset, got %v", err)
+	}
+	err = call("alice")
+	if md := errors.FromError(err).Metadata; md["period"] != "monthly" || md["reset"] != "2022-02-01T00:00:00Z" {
+		t.Errorf("expect monthly quota exceeded, got %v", err)
+	}
+}
+
+type gauge struct {
+	lvs   []string
+	value float64
+}
+
+func (g *gauge) With(lvs ...string) metrics.Gauge { g.lvs = lvs; return g }
+func (g *gauge) Set(value float64)                { g.value = value }
+func (g *gauge) Add(delta float64)                { g.value += delta }
+func (g *gauge) Sub(delta float64)                { g.value -= delta }
+
+func TestServer_Rejected(t *testing.T) {
+	now := time.Date(2022, 1, 30, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store := NewMemoryStore()
+	store.now = clock
+	usage := &gauge{}
+	h := Server(
+		WithStore(store),
+		WithQuotas(Quota{Period: Daily, Limit: 1}, Quota{Period: Monthly, Limit: 2}),
+		WithUsage(usage),
+		withNow(clock),
+	)(func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil })
+	header := headerCarrier{}
+	header.Set("X-API-Key", "alice")
+	ctx := transport.NewServerContext(context.Background(), &Transport{reqHeader: header})
+
+	if _, err := h(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := h(ctx, nil); !IsQuotaExceeded(err) {
+			t.Fatalf("expect daily quota exceeded, got %v", err)
+		}
+	}
+	now = now.Add(24 * time.Hour)
+	if _, err := h(ctx, nil); err != nil {
+		t.Errorf("expect the rejected requests not accounted, got %v", err)
+	}
+	if usage.value != 2 || usage.lvs[0] == "alice" || usage.lvs[0] != monitor.HashKey("alice") {
+		t.Errorf("expect the usage of the hashed key, got %v %v", usage.value, usage.lvs)
+	}
+}
+
+type counter struct {
+	lvs   []string
+	value float64
+}
+
+func (c *counter) With(lvs ...string) metrics.Counter { c.lvs = lvs; return c }
+func (c *counter) Inc()                               { c.value++ }
+func (c *counter) Add(delta float64)                  { c.value += delta }
+
+func TestMonitorOnly(t *testing.T) {
+	var buf bytes.Buffer
+	wouldReject := &counter{}
+	h := Server(
+		WithQuotas(Quota{Period: Daily, Limit: 1}),
+		WithMonitorOnly(wouldReject),
+		WithLogger(log.NewStdLogger(&buf)),
+	)(func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil })
+	header := headerCarrier{}
+	header.Set("X-API-Key", "alice")
+	ctx := transport.NewServerContext(context.Background(), &Transport{reqHeader: header})
+	for i := 0; i < 3; i++ {
+		if _, err := h(ctx, nil); err != nil {
+			t.Fatalf("expect monitor only, got %v", err)
+		}
+	}
+	if wouldReject.value != 2 || wouldReject.lvs[0] != monitor.HashKey("alice") || wouldReject.lvs[1] != "daily" {
+		t.Errorf("would reject %v %v, want 2 [hash(alice) daily]", wouldReject.value, wouldReject.lvs)
+	}
+	if !strings.Contains(buf.String(), "quota: monitor only") {
+		t.Errorf("expect the warning logged with the logger, got %q", buf.String())
+	}
+}
+
+type failingStore struct {
+	keys []string
+	err  error
+}
+
+func (s *failingStore) Incr(_ context.Context, key string, n int64, _ time.Duration) (int64, error) {
+	s.keys = append(s.keys, key)
+	return n, s.err
+}
+
+func TestServer_Store(t *testing.T) {
+	store := &failingStore{}
+	h := Server(
+		WithStore(store),
+		WithQuotas(Quota{Period: Daily, Limit: 1}),
+	)(func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil })
+	header := headerCarrier{}
+	header.Set("X-API-Key", "alice")
+	ctx := transport.NewServerContext(context.Background(), &Transport{reqHeader: header})
+	if _, err := h(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.keys) != 1 || strings.Contains(store.keys[0], "alice") || !strings.HasPrefix(store.keys[0], monitor.HashKey("alice")+":") {
+		t.Errorf("expect the usage stored by the hashed key, got %v", store.keys)
+	}
+
+	store.err = errors.New(500, "REDIS", "redis: connection refused")
+	_, err := h(ctx, nil)
+	if e := errors.FromError(err); e.Code != 500 || strings.Contains(e.Message, "redis") {
+		t.Errorf("expect an internal error without the store error, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// Store stores the usage of the keys.
+type Store interface {
+	// Incr increments the usage counter of key by n, created with ttl, and
+	// returns its new value, n is -1 to give back a rejected request.
+	Incr(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error)
+}
+
+type usage struct {
+	count  int64
+	expire time.Time
+}
+
+// MemoryStore is an in-memory Store, it is only suitable for a single instance.
+type MemoryStore struct {
+	mu     sync.Mutex
+	counts map[string]*usage
+	sweep  time.Time
+	now    func() time.Time
+}
+
+// NewMemoryStore returns an in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counts: make(map[string]*usage),
+		now:    time.Now,
+	}
+}
+
+// Incr increments the usage counter of key by n.
+func (s *MemoryStore) Incr(_ context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.After(s.sweep) {
+		for k, u := range s.counts {
+			if now.After(u.expire) {
+				delete(s.counts, k)
+			}
+		}
+		s.sweep = now.Add(time.Hour)
+	}
+	u, ok := s.counts[key]
+	if !ok || now.After(u.expire) {
+		u = &usage{expire: now.Add(ttl)}
+		s.counts[key] = u
+	}
+	u.count += n
+	return u.count, nil
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/aegis/ratelimit"
+)
+
+var _ ratelimit.Limiter = (*Gradient)(nil)
+
+// GradientOption is gradient limiter option.
+type GradientOption func(*Gradient)
+
+// WithInitialLimit with the initial concurrency limit, default is 20.
+func WithInitialLimit(n int) GradientOption {
+	return func(g *Gradient) {
+		g.limit = float64(n)
+	}
+}
+
+// WithMinLimit with the minimum concurrency limit, at least and by default 1,
+// so that the requests keep sampling the latency.
+func WithMinLimit(n int) GradientOption {
+	return func(g *Gradient) {
+		g.minLimit = float64(n)
+	}
+}
+
+// WithMaxLimit with the maximum concurrency limit, default is 1000.
+func WithMaxLimit(n int) GradientOption {
+	return func(g *Gradient) {
+		g.maxLimit = float64(n)
+	}
+}
+
+// WithSmoothing with the weight of a new limit estimate, default is 0.2.
+func WithSmoothing(s float64) GradientOption {
+	return func(g *Gradient) {
+		g.smoothing = s
+	}
+}
+
+// WithRTTTolerance with the tolerated ratio of the current latency to the
+// long term latency before the limit is reduced, default is 1.5.
+func WithRTTTolerance(t float64) GradientOption {
+	return func(g *Gradient) {
+		g.tolerance = t
+	}
+}
+
+// WithLongWindow with the number of samples the long term latency is averaged over, default is 600.
+func WithLongWindow(n int) GradientOption {
+	return func(g *Gradient) {
+		g.longWindow = float64(n)
+	}
+}
+
+// Gradient is an adaptive concurrency Limiter following the gradient2 algorithm
+// of Netflix concurrency-limits: the limit grows while the latency stays close to
+// its long term average and shrinks as soon as requests start queueing.
+// Unlike bbr it relies on the latency only, not on the CPU usage.
+type Gradient struct {
+	mu         sync.Mutex
+	limit      float64
+	minLimit   float64
+	maxLimit   float64
+	smoothing  float64
+	tolerance  float64
+	longWindow float64
+	longRTT    float64
+	samples    float64
+	inflight   int
+	now        func() time.Time
+}
+
+// NewGradient new a gradient2 adaptive concurrency limiter.
+func NewGradient(opts ...GradientOption) *Gradient {
+	g := &Gradient{
+		limit:      20,
+		minLimit:   1,
+		maxLimit:   1000,
+		smoothing:  0.2,
+		tolerance:  1.5,
+		longWindow: 600,
+		now:        time.Now,
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	g.minLimit = math.Max(1, g.minLimit)
+	g.limit = math.Max(g.minLimit, g.limit)
+	return g
+}
+
+// Allow rejects the request when the in-flight requests reached the limit.
+func (g *Gradient) Allow() (ratelimit.DoneFunc, error) {
+	g.mu.Lock()
+	if g.inflight >= int(g.limit) {
+		g.mu.Unlock()
+		return nil, ratelimit.ErrLimitExceed
+	}
+	g.inflight++
+	inflight := g.inflight
+	g.mu.Unlock()
+	start := g.now()
+	return func(info ratelimit.DoneInfo) {
+		g.onSample(g.now().Sub(start), inflight, info.Err)
+	}, nil
+}
+
+// Limit returns the current concurrency limit.
+func (g *Gradient) Limit() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return int(g.limit)
+}
+
+func (g *Gradient) onSample(rtt time.Duration, inflight int, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.inflight--
+	// the failures are dropped, their latency, e.g. of the fast rejections,
+	// would grow the limit during an outage.
+	shortRTT := float64(rtt)
+	if err != nil || shortRTT <= 0 {
+		return
+	}
+	// the long term latency is a simple average during warmup, an EMA afterwards.
+	if g.samples < g.longWindow {
+		g.samples++
+		g.longRTT += (shortRTT - g.longRTT) / g.samples
+	} else {
+		g.longRTT += (shortRTT - g.longRTT) * 2 / (g.longWindow + 1)
+	}
+	// recover faster from a long period of high latency.
+	if g.longRTT/shortRTT > 2 {
+		g.longRTT *= 0.95
+	}
+	// don't grow the limit while the application doesn't use it.
+	if float64(inflight) < g.limit/2 {
+		return
+	}
+	gradient := math.Max(0.5, math.Min(1, g.tolerance*g.longRTT/shortRTT))
+	limit := g.limit*gradient + math.Sqrt(g.limit)
+	limit = g.limit*(1-g.smoothing) + limit*g.smoothing
+	g.limit = math.Max(g.minLimit, math.Min(g.maxLimit, limit))
+}
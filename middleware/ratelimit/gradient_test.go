@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/aegis/ratelimit"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func TestGradient(t *testing.T) {
+	c := &clock{t: time.Now()}
+	g := NewGradient(WithInitialLimit(10), WithMaxLimit(100))
+	g.now = c.now
+
+	run := func(n int, rtt time.Duration) {
+		for i := 0; i < n; i++ {
+			var dones []ratelimit.DoneFunc
+			for j := 0; j < g.Limit(); j++ {
+				done, err := g.Allow()
+				if err != nil {
+					t.Fatal(err)
+				}
+				dones = append(dones, done)
+			}
+			if _, err := g.Allow(); !errors.Is(err, ratelimit.ErrLimitExceed) {
+				t.Fatalf("expect limit exceeded, got %v", err)
+			}
+			c.t = c.t.Add(rtt)
+			for _, done := range dones {
+				done(ratelimit.DoneInfo{})
+			}
+		}
+	}
+	run(5, 10*time.Millisecond)
+	grown := g.Limit()
+	if grown <= 10 {
+		t.Fatalf("expect the limit to grow at a steady latency, got %d", grown)
+	}
+	run(1, 100*time.Millisecond)
+	if limit := g.Limit(); limit >= grown {
+		t.Fatalf("expect the limit to shrink as the latency grows, got %d (was %d)", limit, grown)
+	}
+}
+
+func TestGradient_MinLimit(t *testing.T) {
+	g := NewGradient(WithInitialLimit(0), WithMinLimit(0))
+	done, err := g.Allow()
+	if err != nil {
+		t.Fatalf("expect a request allowed at the minimum limit, got %v", err)
+	}
+	done(ratelimit.DoneInfo{})
+}
+
+func TestGradient_Errors(t *testing.T) {
+	c := &clock{t: time.Now()}
+	g := NewGradient(WithInitialLimit(10))
+	g.now = c.now
+	for i := 0; i < 10; i++ {
+		var dones []ratelimit.DoneFunc
+		for j := 0; j < g.Limit(); j++ {
+			done, err := g.Allow()
+			if err != nil {
+				t.Fatal(err)
+			}
+			dones = append(dones, done)
+		}
+		c.t = c.t.Add(time.Millisecond)
+		for _, done := range dones {
+			done(ratelimit.DoneInfo{Err: context.DeadlineExceeded})
+		}
+	}
+	if limit := g.Limit(); limit != 10 {
+		t.Errorf("expect the failures not to change the limit, got %d", limit)
+	}
+}
+
+type operationTransport struct {
+	transport.Transporter
+	operation string
+}
+
+func (tr *operationTransport) Operation() string { return tr.operation }
+
+func TestOperationLimiter(t *testing.T) {
+	limiters := make(map[ratelimit.Limiter]struct{})
+	m := Server(WithOperationLimiter(func() ratelimit.Limiter {
+		g := NewGradient(WithInitialLimit(1))
+		limiters[g] = struct{}{}
+		return g
+	}))
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	for _, op := range []string{"/a", "/b", "/a"} {
+		ctx := transport.NewServerContext(context.Background(), &operationTransport{operation: op})
+		if _, err := m(next)(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(limiters) != 2 {
+		t.Fatalf("expect a limiter per operation, got %d", len(limiters))
+	}
+}
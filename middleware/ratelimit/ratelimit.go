@@ -6,7 +6,12 @@ import (
 	"github.com/go-kratos/aegis/ratelimit"
 	"github.com/go-kratos/aegis/ratelimit/bbr"
 	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/group"
+	"github.com/go-kratos/kratos/v2/internal/monitor"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 // ErrLimitExceed is service unavailable due to rate limit exceeded.
@@ -23,24 +28,72 @@ func WithLimiter(limiter ratelimit.Limiter) Option {
 	}
 }
 
+// WithOperationLimiter set a Limiter per operation, created by factory on first use,
+// e.g. an adaptive concurrency limiter for each operation:
+//
+//	ratelimit.WithOperationLimiter(func() aegis.Limiter { return ratelimit.NewGradient() })
+func WithOperationLimiter(factory func() ratelimit.Limiter) Option {
+	return func(o *options) {
+		o.group = group.NewGroup(func() interface{} {
+			return factory()
+		})
+	}
+}
+
+// WithMonitorOnly lets the requests the limiter would reject through,
+// counting them by operation in c, which may be nil.
+func WithMonitorOnly(c metrics.Counter) Option {
+	return func(o *options) {
+		o.monitorOnly = true
+		o.wouldReject = c
+	}
+}
+
+// WithLogger with the logger of the requests the limiter would reject in
+// monitor only mode.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
 type options struct {
 	limiter ratelimit.Limiter
+	group   *group.Group
+	logger  log.Logger
+	// counter: server_requests_would_reject_total{operation}
+	wouldReject metrics.Counter
+	monitorOnly bool
 }
 
 // Server ratelimiter middleware
 func Server(opts ...Option) middleware.Middleware {
 	options := &options{
 		limiter: bbr.NewLimiter(),
+		logger:  log.GetLogger(),
 	}
 	for _, o := range opts {
 		o(options)
 	}
+	reporter := monitor.NewReporter("ratelimit", options.wouldReject, options.logger)
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
-			done, e := options.limiter.Allow()
+			var operation string
+			if info, ok := transport.FromServerContext(ctx); ok {
+				operation = info.Operation()
+			}
+			limiter := options.limiter
+			if options.group != nil {
+				limiter = options.group.Get(operation).(ratelimit.Limiter)
+			}
+			done, e := limiter.Allow()
 			if e != nil {
-				// rejected
-				return nil, ErrLimitExceed
+				if !options.monitorOnly {
+					// rejected
+					return nil, ErrLimitExceed
+				}
+				reporter.Report(ctx, []string{operation}, "%s", operation)
+				return handler(ctx, req)
 			}
 			// allowed
 			reply, err = handler(ctx, req)
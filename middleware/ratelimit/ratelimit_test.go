@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/aegis/ratelimit"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+type rejectLimiter struct{}
+
+func (rejectLimiter) Allow() (ratelimit.DoneFunc, error) { return nil, ratelimit.ErrLimitExceed }
+
+type counter struct {
+	value float64
+}
+
+func (c *counter) With(...string) metrics.Counter { return c }
+func (c *counter) Inc()                           { c.value++ }
+func (c *counter) Add(delta float64)              { c.value += delta }
+
+func TestServer(t *testing.T) {
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	if _, err := Server(WithLimiter(rejectLimiter{}))(next)(context.Background(), nil); !errors.Is(err, ErrLimitExceed) {
+		t.Errorf("expect %v, got %v", ErrLimitExceed, err)
+	}
+
+	var buf bytes.Buffer
+	wouldReject := &counter{}
+	reply, err := Server(WithLimiter(rejectLimiter{}), WithMonitorOnly(wouldReject), WithLogger(log.NewStdLogger(&buf)))(next)(context.Background(), nil)
+	if err != nil || reply != "ok" {
+		t.Errorf("expect monitor only, got %v, %v", reply, err)
+	}
+	if wouldReject.value != 1 {
+		t.Errorf("would reject %v, want 1", wouldReject.value)
+	}
+	if !strings.Contains(buf.String(), "ratelimit: monitor only") {
+		t.Errorf("expect the warning logged with the logger, got %q", buf.String())
+	}
+}
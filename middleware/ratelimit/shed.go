@@ -0,0 +1,197 @@
+package ratelimit
+
+import (
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/aegis/ratelimit"
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+var _ ratelimit.Limiter = (*Shedder)(nil)
+
+// probeInterval is the interval the runtime signals are sampled at.
+const probeInterval = 100 * time.Millisecond
+
+var (
+	// probeMu guards probeRefs and probeStop, the probe runs while a shedder is not stopped.
+	probeMu   sync.Mutex
+	probeRefs int
+	probeStop chan struct{}
+	// schedLatency is the EMA of the scheduling delay of the probe goroutine, in nanoseconds.
+	schedLatency int64
+	// gcPause is the longest GC pause which ended within the last second, in nanoseconds.
+	gcPause int64
+)
+
+// schedDecay is the EMA decay of the scheduling delay.
+const schedDecay = 0.8
+
+func startProbe() {
+	probeMu.Lock()
+	defer probeMu.Unlock()
+	if probeRefs++; probeRefs == 1 {
+		stop := make(chan struct{})
+		probeStop = stop
+		// the signals of a previous probe are stale.
+		atomic.StoreInt64(&schedLatency, 0)
+		atomic.StoreInt64(&gcPause, 0)
+		go func() {
+			ticker := time.NewTicker(probeInterval)
+			defer ticker.Stop()
+			probe(ticker.C, time.Now, stop)
+		}()
+	}
+}
+
+func stopProbe() {
+	probeMu.Lock()
+	defer probeMu.Unlock()
+	if probeRefs--; probeRefs == 0 {
+		close(probeStop)
+	}
+}
+
+// probe samples the runtime signals on each tick until stop is closed. The
+// time is read after the tick is received, since from Go 1.23 on the ticks
+// carry the time they were scheduled at rather than the time they were sent.
+func probe(ticks <-chan time.Time, clock func() time.Time, stop <-chan struct{}) {
+	var stats debug.GCStats
+	expected := clock().Add(probeInterval)
+	for {
+		select {
+		case <-ticks:
+		case <-stop:
+			return
+		}
+		now := clock()
+		delay := now.Sub(expected)
+		if delay < 0 {
+			delay = 0
+		}
+		expected = now.Add(probeInterval)
+		prev := atomic.LoadInt64(&schedLatency)
+		atomic.StoreInt64(&schedLatency, int64(float64(prev)*schedDecay+float64(delay)*(1-schedDecay)))
+
+		debug.ReadGCStats(&stats)
+		var pause time.Duration
+		for i, end := range stats.PauseEnd {
+			if now.Sub(end) > time.Second {
+				break
+			}
+			if stats.Pause[i] > pause {
+				pause = stats.Pause[i]
+			}
+		}
+		atomic.StoreInt64(&gcPause, int64(pause))
+	}
+}
+
+// ShedOption is shedder option.
+type ShedOption func(*Shedder)
+
+// WithSchedLatency with the scheduling latency load is shed above, default is 20ms.
+func WithSchedLatency(d time.Duration) ShedOption {
+	return func(s *Shedder) {
+		s.schedLatency = d
+	}
+}
+
+// WithGCPause with the recent GC pause load is shed above, default is 10ms.
+func WithGCPause(d time.Duration) ShedOption {
+	return func(s *Shedder) {
+		s.gcPause = d
+	}
+}
+
+// WithShedRequests with shed requests counter.
+func WithShedRequests(c metrics.Counter) ShedOption {
+	return func(s *Shedder) {
+		s.requests = c
+	}
+}
+
+// ShedStat contains the runtime signals of the shedder.
+type ShedStat struct {
+	SchedLatency time.Duration
+	GCPause      time.Duration
+	DropRate     float64
+}
+
+// Shedder is a Limiter shedding load while the Go runtime is overloaded,
+// judged by the scheduling latency and the recent GC pauses, it delegates
+// to next otherwise. It reacts earlier than CPU based limiters during GC storms.
+type Shedder struct {
+	next         ratelimit.Limiter
+	schedLatency time.Duration
+	gcPause      time.Duration
+	// counter: server_requests_shed_total
+	requests metrics.Counter
+	signals  func() (time.Duration, time.Duration)
+	stopOnce sync.Once
+}
+
+// NewShedder new a shedder in front of next, next may be nil.
+// The runtime signals are sampled by a goroutine shared by the shedders,
+// which stops once all of them are stopped.
+func NewShedder(next ratelimit.Limiter, opts ...ShedOption) *Shedder {
+	startProbe()
+	s := &Shedder{
+		next:         next,
+		schedLatency: 20 * time.Millisecond,
+		gcPause:      10 * time.Millisecond,
+		signals:      runtimeSignals,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Allow rejects the request with a probability growing with the overload.
+func (s *Shedder) Allow() (ratelimit.DoneFunc, error) {
+	if rate := s.Stat().DropRate; rate > 0 && rand.Float64() < rate {
+		if s.requests != nil {
+			s.requests.Inc()
+		}
+		return nil, ratelimit.ErrLimitExceed
+	}
+	if s.next != nil {
+		return s.next.Allow()
+	}
+	return func(ratelimit.DoneInfo) {}, nil
+}
+
+// Stop stops the shedder sampling the runtime signals.
+func (s *Shedder) Stop() {
+	s.stopOnce.Do(stopProbe)
+}
+
+// Stat returns the current runtime signals and the resulting drop rate.
+func (s *Shedder) Stat() ShedStat {
+	var stat ShedStat
+	stat.SchedLatency, stat.GCPause = s.signals()
+	stat.DropRate = dropRate(stat.SchedLatency, s.schedLatency)
+	if r := dropRate(stat.GCPause, s.gcPause); r > stat.DropRate {
+		stat.DropRate = r
+	}
+	return stat
+}
+
+func runtimeSignals() (time.Duration, time.Duration) {
+	return time.Duration(atomic.LoadInt64(&schedLatency)), time.Duration(atomic.LoadInt64(&gcPause))
+}
+
+// dropRate grows linearly from 0 at threshold to 1 at twice the threshold.
+func dropRate(v, threshold time.Duration) float64 {
+	if threshold <= 0 || v <= threshold {
+		return 0
+	}
+	if r := float64(v-threshold) / float64(threshold); r < 1 {
+		return r
+	}
+	return 1
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/aegis/ratelimit"
+)
+
+func TestDropRate(t *testing.T) {
+	tests := []struct {
+		v, threshold time.Duration
+		rate         float64
+	}{
+		{10 * time.Millisecond, 20 * time.Millisecond, 0},
+		{30 * time.Millisecond, 20 * time.Millisecond, 0.5},
+		{50 * time.Millisecond, 20 * time.Millisecond, 1},
+		{50 * time.Millisecond, 0, 0},
+	}
+	for _, test := range tests {
+		if rate := dropRate(test.v, test.threshold); rate != test.rate {
+			t.Errorf("dropRate(%v, %v): expect %v, got %v", test.v, test.threshold, test.rate, rate)
+		}
+	}
+}
+
+func TestShedder(t *testing.T) {
+	var pause time.Duration
+	s := NewShedder(nil, WithGCPause(time.Millisecond))
+	defer s.Stop()
+	s.signals = func() (time.Duration, time.Duration) { return 0, pause }
+	pause = time.Second
+	if _, err := s.Allow(); !errors.Is(err, ratelimit.ErrLimitExceed) {
+		t.Errorf("expect %v, got %v", ratelimit.ErrLimitExceed, err)
+	}
+	pause = 0
+	done, err := s.Allow()
+	if err != nil {
+		t.Fatalf("expect nil, got %v", err)
+	}
+	done(ratelimit.DoneInfo{})
+}
+
+func TestShedder_Stop(t *testing.T) {
+	s1, s2 := NewShedder(nil), NewShedder(nil)
+	stop := probeStop
+	s1.Stop()
+	s1.Stop()
+	select {
+	case <-stop:
+		t.Fatal("expect the probe to run while a shedder is not stopped")
+	default:
+	}
+	s2.Stop()
+	select {
+	case <-stop:
+	default:
+		t.Fatal("expect the probe to stop once the shedders are stopped")
+	}
+}
+
+func TestProbe(t *testing.T) {
+	start := time.Unix(0, 0)
+	times := []time.Time{start, start.Add(probeInterval + 50*time.Millisecond)}
+	clock := func() time.Time {
+		now := times[0]
+		times = times[1:]
+		return now
+	}
+	ticks, stop, done := make(chan time.Time), make(chan struct{}), make(chan struct{})
+	go func() {
+		probe(ticks, clock, stop)
+		close(done)
+	}()
+	// the tick carries the scheduled time, the latency is measured by the clock.
+	ticks <- start.Add(probeInterval)
+	close(stop)
+	<-done
+	defer atomic.StoreInt64(&schedLatency, 0)
+	if got, want := time.Duration(atomic.LoadInt64(&schedLatency)), 10*time.Millisecond; got != want {
+		t.Errorf("expect the scheduling latency %v, got %v", want, got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/report"
 )
 
 // ErrUnknownRequest is unknown request error.
@@ -19,8 +20,10 @@ type HandlerFunc func(ctx context.Context, req, err interface{}) error
 type Option func(*options)
 
 type options struct {
-	handler HandlerFunc
-	logger  log.Logger
+	handler  HandlerFunc
+	logger   log.Logger
+	reporter report.Reporter
+	request  func(ctx context.Context, req interface{}) interface{}
 }
 
 // WithHandler with recovery handler.
@@ -37,6 +40,22 @@ func WithLogger(logger log.Logger) Option {
 	}
 }
 
+// WithReporter with reporter notified of recovered panics.
+func WithReporter(r report.Reporter) Option {
+	return func(o *options) {
+		o.reporter = r
+	}
+}
+
+// WithReportRequest with the func returning the request attached to the events
+// reported, e.g. redacted with redact.Redact. The requests may carry secrets
+// and personal data, they are not reported by default.
+func WithReportRequest(f func(ctx context.Context, req interface{}) interface{}) Option {
+	return func(o *options) {
+		o.request = f
+	}
+}
+
 // Recovery is a server middleware that recovers from any panics.
 func Recovery(opts ...Option) middleware.Middleware {
 	op := options{
@@ -59,6 +78,18 @@ func Recovery(opts ...Option) middleware.Middleware {
 					logger.WithContext(ctx).Errorf("%v: %+v\n%s\n", rerr, req, buf)
 
 					err = op.handler(ctx, req, rerr)
+					if op.reporter != nil {
+						event := &report.Event{
+							Err:   err,
+							Panic: rerr,
+							Stack: buf,
+							Tags:  report.Tags(ctx),
+						}
+						if op.request != nil {
+							event.Request = op.request(ctx, req)
+						}
+						op.reporter.Report(ctx, event)
+					}
 				}
 			}()
 			return handler(ctx, req)
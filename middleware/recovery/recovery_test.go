@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/report"
 )
 
 func TestOnce(t *testing.T) {
@@ -35,3 +36,30 @@ func TestNotPanic(t *testing.T) {
 		t.Errorf("e isn't nil")
 	}
 }
+
+type reporter struct {
+	events []*report.Event
+}
+
+func (r *reporter) Report(ctx context.Context, event *report.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestReporter(t *testing.T) {
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("panic reason")
+	}
+	r := &reporter{}
+	_, e := Recovery(WithReporter(r))(next)(context.Background(), "panic")
+	if len(r.events) != 1 {
+		t.Fatalf("expect 1 event, but got %d", len(r.events))
+	}
+	if ev := r.events[0]; ev.Panic != "panic reason" || ev.Err != e || len(ev.Stack) == 0 || ev.Request != nil {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	redacted := func(ctx context.Context, req interface{}) interface{} { return "redacted" }
+	_, _ = Recovery(WithReporter(r), WithReportRequest(redacted))(next)(context.Background(), "secret")
+	if ev := r.events[1]; ev.Request != "redacted" {
+		t.Errorf("expect the redacted request reported, got %v", ev.Request)
+	}
+}
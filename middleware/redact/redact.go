@@ -0,0 +1,288 @@
+// Package redact protects the fields of the replies marked as PII with the
+// pii option from the callers without the scopes of their policy:
+//
+//	import "pii/pii.proto";
+//
+//	message User {
+//	  string name = 1;
+//	  string phone = 2 [(pii.pii) = {action: MASK, keep: 4, scopes: ["users.pii"]}];
+//	  string email = 3 [(pii.pii) = {action: REDACT, scopes: ["users.pii"]}];
+//	}
+package redact
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+	"sync"
+
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/go-kratos/kratos/v2/api/pii"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+)
+
+// ScopesFunc returns the scopes of the caller.
+type ScopesFunc func(ctx context.Context) []string
+
+// EncryptFunc encrypts the value of a field.
+type EncryptFunc func(ctx context.Context, field protoreflect.FieldDescriptor, plaintext []byte) ([]byte, error)
+
+// Option is redact option.
+type Option func(*options)
+
+type options struct {
+	scopes  ScopesFunc
+	encrypt EncryptFunc
+}
+
+// WithScopes with the scopes of the caller, default is JWTScopes.
+func WithScopes(f ScopesFunc) Option {
+	return func(o *options) {
+		o.scopes = f
+	}
+}
+
+// WithEncrypter with the encrypter of the ENCRYPT fields, the strings are
+// encoded in base64. Without it, they are redacted.
+func WithEncrypter(f EncryptFunc) Option {
+	return func(o *options) {
+		o.encrypt = f
+	}
+}
+
+// JWTScopes returns the OAuth2 scopes of the JWT claims, the "scope" claim
+// separated by spaces or else the "scp" list.
+func JWTScopes(ctx context.Context) []string {
+	claims, ok := jwt.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	mc, ok := claims.(jwtv4.MapClaims)
+	if !ok {
+		return nil
+	}
+	if scope, ok := mc["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	var scopes []string
+	if scp, ok := mc["scp"].([]interface{}); ok {
+		for _, s := range scp {
+			if s, ok := s.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return scopes
+}
+
+// AESGCM returns an encrypter sealing the values with AES-GCM, the nonce is
+// prepended to the ciphertext. The key is 16, 24 or 32 bytes long.
+func AESGCM(key []byte) (EncryptFunc, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return func(_ context.Context, _ protoreflect.FieldDescriptor, plaintext []byte) ([]byte, error) {
+		nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, err
+		}
+		return aead.Seal(nonce, nonce, plaintext, nil), nil
+	}, nil
+}
+
+// Server is a server middleware redacting the PII fields of the replies for the
+// callers without their scopes, apply it per endpoint with the selector middleware.
+func Server(opts ...Option) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			if err != nil {
+				return reply, err
+			}
+			if m, ok := reply.(proto.Message); ok {
+				return Redact(ctx, m, opts...)
+			}
+			return reply, nil
+		}
+	}
+}
+
+// Redact returns m with the PII fields protected for the caller of ctx, a
+// copy of m if any field is protected, m itself otherwise.
+func Redact(ctx context.Context, m proto.Message, opts ...Option) (proto.Message, error) {
+	o := &options{scopes: JWTScopes}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !hasPII(m.ProtoReflect().Descriptor()) {
+		return m, nil
+	}
+	r := &redactor{ctx: ctx, encrypt: o.encrypt, scopes: make(map[string]struct{})}
+	for _, s := range o.scopes(ctx) {
+		r.scopes[s] = struct{}{}
+	}
+	m = proto.Clone(m)
+	if err := r.message(m.ProtoReflect()); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// piiMessages caches whether a message has PII fields, directly or nested.
+var piiMessages sync.Map
+
+func hasPII(md protoreflect.MessageDescriptor) bool {
+	if v, ok := piiMessages.Load(md.FullName()); ok {
+		return v.(bool)
+	}
+	has := walkPII(md, make(map[protoreflect.FullName]bool))
+	piiMessages.Store(md.FullName(), has)
+	return has
+}
+
+func walkPII(md protoreflect.MessageDescriptor, visited map[protoreflect.FullName]bool) bool {
+	if visited[md.FullName()] {
+		return false
+	}
+	visited[md.FullName()] = true
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.IsMap() {
+			fd = fd.MapValue()
+		}
+		if policyOf(fields.Get(i)) != nil || fd.Message() != nil && walkPII(fd.Message(), visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func policyOf(fd protoreflect.FieldDescriptor) *pii.Policy {
+	if opts := fd.Options(); opts != nil && proto.HasExtension(opts, pii.E_Pii) {
+		return proto.GetExtension(opts, pii.E_Pii).(*pii.Policy)
+	}
+	return nil
+}
+
+type redactor struct {
+	ctx     context.Context
+	encrypt EncryptFunc
+	scopes  map[string]struct{}
+}
+
+func (r *redactor) allowed(p *pii.Policy) bool {
+	for _, s := range p.GetScopes() {
+		if _, ok := r.scopes[s]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) message(m protoreflect.Message) (err error) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if p := policyOf(fd); p != nil {
+			if !r.allowed(p) {
+				err = r.field(m, fd, v, p)
+			}
+			return err == nil
+		}
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+					err = r.message(v.Message())
+					return err == nil
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				for i := 0; i < v.List().Len() && err == nil; i++ {
+					err = r.message(v.List().Get(i).Message())
+				}
+			}
+		case fd.Message() != nil:
+			err = r.message(v.Message())
+		}
+		return err == nil
+	})
+	return err
+}
+
+func (r *redactor) field(m protoreflect.Message, fd protoreflect.FieldDescriptor, v protoreflect.Value, p *pii.Policy) error {
+	kind := fd.Kind()
+	if fd.IsMap() {
+		kind = fd.MapValue().Kind()
+	}
+	action := p.GetAction()
+	if action == pii.Action_MASK && kind != protoreflect.StringKind ||
+		action == pii.Action_ENCRYPT && (r.encrypt == nil || kind != protoreflect.StringKind && kind != protoreflect.BytesKind) {
+		action = pii.Action_REDACT
+	}
+	if action == pii.Action_REDACT {
+		m.Clear(fd)
+		return nil
+	}
+	protect := func(v protoreflect.Value) (protoreflect.Value, error) {
+		if action == pii.Action_MASK {
+			return protoreflect.ValueOfString(mask(v.String(), int(p.GetKeep()))), nil
+		}
+		if kind == protoreflect.BytesKind {
+			b, err := r.encrypt(r.ctx, fd, v.Bytes())
+			return protoreflect.ValueOfBytes(b), err
+		}
+		b, err := r.encrypt(r.ctx, fd, []byte(v.String()))
+		return protoreflect.ValueOfString(base64.StdEncoding.EncodeToString(b)), err
+	}
+	var err error
+	switch {
+	case fd.IsMap():
+		mv := v.Map()
+		mv.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			if v, err = protect(v); err == nil {
+				mv.Set(k, v)
+			}
+			return err == nil
+		})
+	case fd.IsList():
+		lv := v.List()
+		for i := 0; i < lv.Len() && err == nil; i++ {
+			var nv protoreflect.Value
+			if nv, err = protect(lv.Get(i)); err == nil {
+				lv.Set(i, nv)
+			}
+		}
+	default:
+		if v, err = protect(v); err == nil {
+			m.Set(fd, v)
+		}
+	}
+	return err
+}
+
+// mask replaces all but the last keep runes of s with '*', all of them if s is too short.
+func mask(s string, keep int) string {
+	rs := []rune(s)
+	n := len(rs) - keep
+	if n <= 0 {
+		n = len(rs)
+	}
+	for i := 0; i < n; i++ {
+		rs[i] = '*'
+	}
+	return string(rs)
+}
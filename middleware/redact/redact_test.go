@@ -0,0 +1,160 @@
+package redact
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/go-kratos/kratos/v2/api/pii"
+	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+)
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, policy *pii.Policy) *descriptorpb.FieldDescriptorProto {
+	fd := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     typ.Enum(),
+	}
+	if policy != nil {
+		fd.Options = &descriptorpb.FieldOptions{}
+		proto.SetExtension(fd.Options, pii.E_Pii, policy)
+	}
+	return fd
+}
+
+// userDescriptor returns the descriptor of:
+//
+//	message User {
+//	  string name = 1;
+//	  string phone = 2 [(pii.pii) = {action: MASK, keep: 4, scopes: ["users.pii"]}];
+//	  string email = 3 [(pii.pii) = {action: REDACT, scopes: ["users.pii"]}];
+//	  string ssn = 4 [(pii.pii) = {action: ENCRYPT, scopes: ["users.pii"]}];
+//	  int32 age = 5 [(pii.pii) = {action: MASK}];
+//	  repeated string aliases = 6 [(pii.pii) = {action: MASK}];
+//	  User manager = 7;
+//	}
+func userDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	scopes := []string{"users.pii"}
+	aliases := field("aliases", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pii.Policy{Action: pii.Action_MASK})
+	aliases.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	manager := field("manager", 7, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, nil)
+	manager.TypeName = proto.String(".test.User")
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/user.proto"),
+		Package:    proto.String("test"),
+		Dependency: []string{"pii/pii.proto"},
+		Syntax:     proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, nil),
+				field("phone", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pii.Policy{Action: pii.Action_MASK, Keep: 4, Scopes: scopes}),
+				field("email", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pii.Policy{Action: pii.Action_REDACT, Scopes: scopes}),
+				field("ssn", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, &pii.Policy{Action: pii.Action_ENCRYPT, Scopes: scopes}),
+				field("age", 5, descriptorpb.FieldDescriptorProto_TYPE_INT32, &pii.Policy{Action: pii.Action_MASK}),
+				aliases,
+				manager,
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().Get(0)
+}
+
+func newUser(md protoreflect.MessageDescriptor, name string) *dynamicpb.Message {
+	m := dynamicpb.NewMessage(md)
+	fields := md.Fields()
+	m.Set(fields.ByName("name"), protoreflect.ValueOfString(name))
+	m.Set(fields.ByName("phone"), protoreflect.ValueOfString("13812345678"))
+	m.Set(fields.ByName("email"), protoreflect.ValueOfString(name+"@go-kratos.dev"))
+	m.Set(fields.ByName("ssn"), protoreflect.ValueOfString("123-45-6789"))
+	m.Set(fields.ByName("age"), protoreflect.ValueOfInt32(30))
+	aliases := m.Mutable(fields.ByName("aliases")).List()
+	aliases.Append(protoreflect.ValueOfString("k8s"))
+	return m
+}
+
+func TestRedact(t *testing.T) {
+	md := userDescriptor(t)
+	fields := md.Fields()
+	user := newUser(md, "kratos")
+	user.Set(fields.ByName("manager"), protoreflect.ValueOfMessage(newUser(md, "boss")))
+
+	encrypt, err := AESGCM(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := jwt.NewContext(context.Background(), jwtv4.MapClaims{"scope": "users.read"})
+	out, err := Redact(ctx, user, WithEncrypter(encrypt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []protoreflect.Message{out.ProtoReflect(), out.ProtoReflect().Get(fields.ByName("manager")).Message()} {
+		if phone := m.Get(fields.ByName("phone")).String(); phone != "*******5678" {
+			t.Errorf("phone = %s", phone)
+		}
+		if m.Has(fields.ByName("email")) || m.Has(fields.ByName("age")) {
+			t.Error("email or age not redacted")
+		}
+		ssn, err := base64.StdEncoding.DecodeString(m.Get(fields.ByName("ssn")).String())
+		if err != nil || len(ssn) != 12+len("123-45-6789")+16 {
+			t.Errorf("ssn = %s, %v", m.Get(fields.ByName("ssn")), err)
+		}
+		if alias := m.Get(fields.ByName("aliases")).List().Get(0).String(); alias != "***" {
+			t.Errorf("alias = %s", alias)
+		}
+	}
+	if phone := user.Get(fields.ByName("phone")).String(); phone != "13812345678" {
+		t.Errorf("reply modified: phone = %s", phone)
+	}
+
+	// the scope sees the values in clear, except the fields without scopes.
+	ctx = jwt.NewContext(context.Background(), jwtv4.MapClaims{"scp": []interface{}{"users.read", "users.pii"}})
+	reply, err := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return user, nil
+	})(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := reply.(proto.Message).ProtoReflect()
+	if phone := m.Get(fields.ByName("phone")).String(); phone != "13812345678" {
+		t.Errorf("phone = %s", phone)
+	}
+	if ssn := m.Get(fields.ByName("ssn")).String(); ssn != "123-45-6789" {
+		t.Errorf("ssn = %s", ssn)
+	}
+	if m.Has(fields.ByName("age")) {
+		t.Error("age not redacted")
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		s    string
+		keep int
+		want string
+	}{
+		{"13812345678", 4, "*******5678"},
+		{"1234", 4, "****"},
+		{"张三丰", 1, "**丰"},
+		{"", 4, ""},
+	}
+	for _, test := range tests {
+		if got := mask(test.s, test.keep); got != test.want {
+			t.Errorf("mask(%q, %d) = %q, want %q", test.s, test.keep, got, test.want)
+		}
+	}
+}
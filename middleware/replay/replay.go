@@ -0,0 +1,139 @@
+package replay
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// nonceKey holds the key used to carry the request nonce.
+	nonceKey = "X-Nonce"
+	// timestampKey holds the key used to carry the request unix timestamp in seconds.
+	timestampKey = "X-Timestamp"
+
+	// reason holds the error reason.
+	reason = "REPLAY"
+)
+
+var (
+	// ErrMissingNonce is returned when the request carries no nonce.
+	ErrMissingNonce = errors.BadRequest(reason, "nonce is missing")
+	// ErrInvalidTimestamp is returned when the request timestamp is missing or malformed.
+	ErrInvalidTimestamp = errors.BadRequest(reason, "timestamp is missing or invalid")
+	// ErrTimestampSkewed is returned when the request timestamp is outside of the window.
+	ErrTimestampSkewed = errors.Unauthorized(reason, "timestamp is out of the allowed window")
+	// ErrNonceReused is returned when the request nonce has already been seen.
+	ErrNonceReused = errors.Unauthorized(reason, "nonce has already been used")
+	// ErrWrongContext is returned when the middleware isn't used on the server side.
+	ErrWrongContext = errors.Unauthorized(reason, "wrong context for middleware")
+	// ErrStore is returned when the nonce store fails, the store error is logged.
+	ErrStore = errors.InternalServer(reason, "nonce could not be recorded")
+)
+
+// Store records the nonces seen by the server.
+type Store interface {
+	// Add records nonce for ttl, it returns false if the nonce is already recorded.
+	Add(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// Option is replay option.
+type Option func(*options)
+
+type options struct {
+	store        Store
+	window       time.Duration
+	nonceKey     string
+	timestampKey string
+	now          func() time.Time
+	logger       log.Logger
+}
+
+// WithStore with nonce store, default is an in-memory store.
+func WithStore(s Store) Option {
+	return func(o *options) {
+		o.store = s
+	}
+}
+
+// WithWindow with the allowed clock skew between client and server, default is 5 minutes.
+// Nonces are kept for twice the window so a request can not be replayed while its timestamp is valid.
+func WithWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.window = d
+	}
+}
+
+// WithNonceKey with the request header key carrying the nonce, default is X-Nonce.
+func WithNonceKey(key string) Option {
+	return func(o *options) {
+		o.nonceKey = key
+	}
+}
+
+// WithTimestampKey with the request header key carrying the timestamp, default is X-Timestamp.
+func WithTimestampKey(key string) Option {
+	return func(o *options) {
+		o.timestampKey = key
+	}
+}
+
+// WithLogger with the logger of the nonce store errors.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Server is a server middleware rejecting replayed requests.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{
+		window:       5 * time.Minute,
+		nonceKey:     nonceKey,
+		timestampKey: timestampKey,
+		now:          time.Now,
+		logger:       log.GetLogger(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.store == nil {
+		o.store = NewMemoryStore()
+	}
+	helper := log.NewHelper(o.logger)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, ErrWrongContext
+			}
+			header := tr.RequestHeader()
+			nonce := header.Get(o.nonceKey)
+			if nonce == "" {
+				return nil, ErrMissingNonce
+			}
+			ts, err := strconv.ParseInt(header.Get(o.timestampKey), 10, 64)
+			if err != nil {
+				return nil, ErrInvalidTimestamp
+			}
+			skew := o.now().Sub(time.Unix(ts, 0))
+			if skew > o.window || skew < -o.window {
+				return nil, ErrTimestampSkewed
+			}
+			added, err := o.store.Add(ctx, nonce, 2*o.window)
+			if err != nil {
+				helper.WithContext(ctx).Errorf("replay: failed to record a nonce: %v", err)
+				return nil, ErrStore
+			}
+			if !added {
+				return nil, ErrNonceReused
+			}
+			return handler(ctx, req)
+		}
+	}
+}
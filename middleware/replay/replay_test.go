@@ -0,0 +1,121 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	reqHeader transport.Header
+}
+
+func (tr *Transport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return "/test.Replay/Call" }
+func (tr *Transport) RequestHeader() transport.Header { return tr.reqHeader }
+func (tr *Transport) ReplyHeader() transport.Header   { return nil }
+
+func newContext(nonce string, ts time.Time) context.Context {
+	header := headerCarrier{}
+	if nonce != "" {
+		header.Set(nonceKey, nonce)
+	}
+	if !ts.IsZero() {
+		header.Set(timestampKey, strconv.FormatInt(ts.Unix(), 10))
+	}
+	return transport.NewServerContext(context.Background(), &Transport{reqHeader: header})
+}
+
+func TestServer(t *testing.T) {
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "reply", nil
+	}
+	h := Server(WithWindow(time.Minute))(next)
+	now := time.Now()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+	}{
+		{"ok", newContext("n1", now), nil},
+		{"reused", newContext("n1", now), ErrNonceReused},
+		{"missing nonce", newContext("", now), ErrMissingNonce},
+		{"missing timestamp", newContext("n2", time.Time{}), ErrInvalidTimestamp},
+		{"too old", newContext("n3", now.Add(-2*time.Minute)), ErrTimestampSkewed},
+		{"too new", newContext("n4", now.Add(2*time.Minute)), ErrTimestampSkewed},
+		{"wrong context", context.Background(), ErrWrongContext},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := h(test.ctx, "req")
+			if test.err == nil && err != nil {
+				t.Fatalf("expect nil, but got %v", err)
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Fatalf("expect %v, but got %v", test.err, err)
+			}
+		})
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Add(context.Context, string, time.Duration) (bool, error) {
+	return false, stderrors.New("redis: connection refused")
+}
+
+func TestServer_Store(t *testing.T) {
+	var buf bytes.Buffer
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return "reply", nil }
+	h := Server(WithStore(failingStore{}), WithLogger(log.NewStdLogger(&buf)))(next)
+	_, err := h(newContext("n1", time.Now()), "req")
+	if !errors.Is(err, ErrStore) || strings.Contains(err.Error(), "redis") {
+		t.Errorf("expect %v, but got %v", ErrStore, err)
+	}
+	if !strings.Contains(buf.String(), "redis: connection refused") {
+		t.Errorf("expect the store error logged, got %q", buf.String())
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	if ok, _ := s.Add(context.Background(), "n", time.Second); !ok {
+		t.Fatal("expect first add to succeed")
+	}
+	if ok, _ := s.Add(context.Background(), "n", time.Second); ok {
+		t.Fatal("expect second add to fail")
+	}
+	now = now.Add(2 * time.Second)
+	if ok, _ := s.Add(context.Background(), "n", time.Second); !ok {
+		t.Fatal("expect add after expiry to succeed")
+	}
+	if len(s.nonces) != 1 {
+		t.Errorf("expect expired nonces to be swept, but got %d", len(s.nonces))
+	}
+}
@@ -0,0 +1,45 @@
+package replay
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is an in-memory Store, it is only suitable for a single instance.
+type MemoryStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	sweep  time.Time
+	now    func() time.Time
+}
+
+// NewMemoryStore returns an in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		nonces: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Add records nonce for ttl, it returns false if the nonce is already recorded.
+func (s *MemoryStore) Add(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.After(s.sweep) {
+		for k, expire := range s.nonces {
+			if now.After(expire) {
+				delete(s.nonces, k)
+			}
+		}
+		s.sweep = now.Add(ttl)
+	}
+	if expire, ok := s.nonces[nonce]; ok && !now.After(expire) {
+		return false, nil
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
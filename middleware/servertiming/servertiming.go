@@ -0,0 +1,169 @@
+// Package servertiming emits the W3C Server-Timing reply header, so the
+// frontend performance tools attribute the latency of the requests:
+//
+//	Server-Timing: db;dur=3.2;desc="users", downstream;dur=8.1, handler;dur=12.5, middleware;dur=0.4, total;dur=12.9
+//
+// Server goes first in the chain and Handler last, the handlers and the
+// clients of the request add their own metrics.
+package servertiming
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type metric struct {
+	name string
+	desc string
+	dur  time.Duration
+}
+
+// Timing is the metrics of a request.
+type Timing struct {
+	mu      sync.Mutex
+	metrics []*metric
+}
+
+// Add adds d to the metric name, desc is kept from the first add.
+func (t *Timing) Add(name string, d time.Duration, desc string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, m := range t.metrics {
+		if m.name == name {
+			m.dur += d
+			return
+		}
+	}
+	t.metrics = append(t.metrics, &metric{name: name, desc: desc, dur: d})
+}
+
+// Duration returns the duration of the metric name.
+func (t *Timing) Duration(name string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, m := range t.metrics {
+		if m.name == name {
+			return m.dur
+		}
+	}
+	return 0
+}
+
+// String returns the Server-Timing header value.
+func (t *Timing) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var sb strings.Builder
+	for i, m := range t.metrics {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(m.name)
+		sb.WriteString(";dur=")
+		sb.WriteString(strconv.FormatFloat(float64(m.dur)/float64(time.Millisecond), 'f', -1, 64))
+		if m.desc != "" {
+			sb.WriteString(";desc=")
+			sb.WriteString(strconv.Quote(m.desc))
+		}
+	}
+	return sb.String()
+}
+
+type timingKey struct{}
+
+// NewContext returns a new Context that carries the timing.
+func NewContext(ctx context.Context, t *Timing) context.Context {
+	return context.WithValue(ctx, timingKey{}, t)
+}
+
+// FromContext returns the timing of the request of ctx if any.
+func FromContext(ctx context.Context) (*Timing, bool) {
+	t, ok := ctx.Value(timingKey{}).(*Timing)
+	return t, ok
+}
+
+// Add adds d to the metric name of the request of ctx.
+func Add(ctx context.Context, name string, d time.Duration, desc string) {
+	if t, ok := FromContext(ctx); ok {
+		t.Add(name, d, desc)
+	}
+}
+
+// Start starts timing the metric name of the request of ctx, until the returned func is called:
+//
+//	defer servertiming.Start(ctx, "db")()
+func Start(ctx context.Context, name string) func() {
+	start := time.Now()
+	return func() {
+		Add(ctx, name, time.Since(start), "")
+	}
+}
+
+// Option is server timing option.
+type Option func(*options)
+
+type options struct {
+	expose func(ctx context.Context) bool
+}
+
+// WithExpose with the func deciding which requests get the header, e.g. only
+// the internal clients, as the timings reveal the internals. Default is all.
+func WithExpose(f func(ctx context.Context) bool) Option {
+	return func(o *options) {
+		o.expose = f
+	}
+}
+
+// Server is a server middleware timing the request and setting the
+// Server-Timing reply header with the metrics added.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{expose: func(context.Context) bool { return true }}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok || !o.expose(ctx) {
+				return handler(ctx, req)
+			}
+			start := time.Now()
+			t := &Timing{}
+			reply, err := handler(NewContext(ctx, t), req)
+			total := time.Since(start)
+			if h := t.Duration("handler"); h > 0 {
+				t.Add("middleware", total-h, "")
+			}
+			t.Add("total", total, "")
+			tr.ReplyHeader().Set("Server-Timing", t.String())
+			return reply, err
+		}
+	}
+}
+
+// Handler is a server middleware timing the handler, it goes last in the chain.
+func Handler() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			defer Start(ctx, "handler")()
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Client is a client middleware adding the duration of the calls to the
+// "downstream" metric of the request.
+func Client() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			defer Start(ctx, "downstream")()
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package servertiming
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/middlewaretest"
+)
+
+func TestServer(t *testing.T) {
+	call := middleware.Chain(Client())(func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(time.Millisecond)
+		return nil, nil
+	})
+	h := middleware.Chain(Server(), Handler())(func(ctx context.Context, req interface{}) (interface{}, error) {
+		stop := Start(ctx, "db")
+		time.Sleep(time.Millisecond)
+		stop()
+		Add(ctx, "cache", 0, "miss")
+		_, _ = call(ctx, req)
+		_, _ = call(ctx, req)
+		return "reply", nil
+	})
+	ctx, tr := middlewaretest.NewServerContext(context.Background())
+	if reply, err := h(ctx, "req"); reply != "reply" || err != nil {
+		t.Fatalf("got %v %v", reply, err)
+	}
+	header := tr.ReplyHeader().Get("Server-Timing")
+	if !regexp.MustCompile(`^db;dur=[\d.]+, cache;dur=0;desc="miss", downstream;dur=[\d.]+, handler;dur=[\d.]+, middleware;dur=[\d.]+, total;dur=[\d.]+$`).MatchString(header) {
+		t.Errorf("unexpected header %q", header)
+	}
+
+	ctx, tr = middlewaretest.NewServerContext(context.Background())
+	next := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	_, _ = Server(WithExpose(func(context.Context) bool { return false }))(next)(ctx, "req")
+	if header = tr.ReplyHeader().Get("Server-Timing"); header != "" {
+		t.Errorf("expect no header, got %q", header)
+	}
+}
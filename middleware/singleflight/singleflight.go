@@ -0,0 +1,148 @@
+package singleflight
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/proto"
+
+	ic "github.com/go-kratos/kratos/v2/internal/context"
+	kreply "github.com/go-kratos/kratos/v2/internal/reply"
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// KeyFunc returns the key identical requests are coalesced by,
+// the request isn't coalesced if ok is false.
+type KeyFunc func(ctx context.Context, req interface{}) (key string, ok bool)
+
+// Option is singleflight option.
+type Option func(*options)
+
+// WithKey with the request key func, see DefaultKey. The requests of the same
+// key share one reply, so the key must identify the caller, e.g. its
+// credentials, as well as the request.
+func WithKey(f KeyFunc) Option {
+	return func(o *options) {
+		o.key = f
+	}
+}
+
+// WithTimeout with the timeout of the coalesced call, which is detached from
+// the cancellation and the deadline of the callers, 2s by default.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+type options struct {
+	key     KeyFunc
+	timeout time.Duration
+}
+
+// Client is a client middleware coalescing the concurrent identical requests
+// into a single call, its reply or error is shared with all the callers, each
+// of them given its own copy of the reply.
+// Only use it for idempotent operations, e.g. composed with selector, after the
+// middlewares setting the credentials so that they are part of the key:
+//
+//	selector.Client(singleflight.Client()).Prefix("/helloworld.Greeter/Get").Build()
+func Client(opts ...Option) middleware.Middleware {
+	o := &options{
+		key:     DefaultKey,
+		timeout: 2 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var g singleflight.Group
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			key, ok := o.key(ctx, req)
+			if !ok {
+				return handler(ctx, req)
+			}
+			ch := g.DoChan(key, func() (interface{}, error) {
+				// the callers may be canceled or time out on their own
+				ctx, cancel := context.WithTimeout(ic.Detach(ctx), o.timeout)
+				defer cancel()
+				// the shared call decodes into a private reply, not the reply
+				// of the caller it was started by, which may give up on it.
+				if reply, ok := kreply.FromContext(ctx); ok {
+					ctx = kreply.NewContext(ctx, kreply.New(reply))
+				}
+				return handler(ctx, req)
+			})
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case res := <-ch:
+				// each caller owns its reply
+				if m, ok := res.Val.(proto.Message); ok {
+					return proto.Clone(m), res.Err
+				}
+				return res.Val, res.Err
+			}
+		}
+	}
+}
+
+// DefaultKey returns a SHA-256 of the operation, the request header, e.g. the
+// Authorization, the client metadata and the request as key, proto requests
+// are marshaled deterministically, the others as JSON. HTTP requests other
+// than GET and HEAD aren't coalesced.
+func DefaultKey(ctx context.Context, req interface{}) (string, bool) {
+	info, ok := transport.FromClientContext(ctx)
+	if !ok {
+		return "", false
+	}
+	h := sha256.New()
+	h.Write([]byte(info.Operation()))
+	if ht, ok := info.(khttp.Transporter); ok {
+		r := ht.Request()
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			return "", false
+		}
+		h.Write([]byte("\x00" + r.Method + " " + r.URL.String()))
+	}
+	header := info.RequestHeader()
+	keys := header.Keys()
+	sort.Strings(keys)
+	for _, k := range keys {
+		h.Write([]byte("\x00" + k + ":" + header.Get(k)))
+	}
+	if md, ok := metadata.FromClientContext(ctx); ok {
+		keys = keys[:0]
+		for k := range md {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			h.Write([]byte("\x00" + k + "=" + md[k]))
+		}
+	}
+	h.Write([]byte{0})
+	var (
+		b   []byte
+		err error
+	)
+	if m, ok := req.(proto.Message); ok {
+		b, err = proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	} else {
+		b, err = json.Marshal(req)
+	}
+	if err != nil {
+		return "", false
+	}
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), true
+}
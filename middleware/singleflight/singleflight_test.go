@@ -0,0 +1,173 @@
+package singleflight
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	kreply "github.com/go-kratos/kratos/v2/internal/reply"
+	"github.com/go-kratos/kratos/v2/metadata"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier map[string]string
+
+func (hc headerCarrier) Get(key string) string      { return hc[key] }
+func (hc headerCarrier) Set(key string, val string) { hc[key] = val }
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	transport.Transporter
+	header headerCarrier
+}
+
+func (tr *Transport) Operation() string               { return "/test.Cache/Get" }
+func (tr *Transport) RequestHeader() transport.Header { return tr.header }
+
+func TestClient(t *testing.T) {
+	var (
+		calls   int32
+		release = make(chan struct{})
+	)
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return wrapperspb.String("value"), nil
+	}
+	h := Client()(next)
+	ctx := transport.NewClientContext(context.Background(), &Transport{header: headerCarrier{}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply, err := h(ctx, wrapperspb.String("key"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if v := reply.(*wrapperspb.StringValue).Value; v != "value" {
+				t.Errorf("expect value, got %s", v)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expect 1 upstream call, got %d", n)
+	}
+
+	k1, _ := DefaultKey(ctx, wrapperspb.String("a"))
+	k2, _ := DefaultKey(ctx, wrapperspb.String("b"))
+	if k1 == k2 {
+		t.Error("expect different keys for different requests")
+	}
+
+	alice := transport.NewClientContext(context.Background(), &Transport{header: headerCarrier{"authorization": "Bearer alice"}})
+	bob := transport.NewClientContext(context.Background(), &Transport{header: headerCarrier{"authorization": "Bearer bob"}})
+	k1, _ = DefaultKey(alice, wrapperspb.String("a"))
+	k2, _ = DefaultKey(bob, wrapperspb.String("a"))
+	if k1 == k2 {
+		t.Error("expect different keys for different credentials")
+	}
+	k1, _ = DefaultKey(metadata.NewClientContext(ctx, metadata.New(map[string]string{"x-md-tenant": "a"})), wrapperspb.String("a"))
+	k2, _ = DefaultKey(metadata.NewClientContext(ctx, metadata.New(map[string]string{"x-md-tenant": "b"})), wrapperspb.String("a"))
+	if k1 == k2 {
+		t.Error("expect different keys for different metadata")
+	}
+}
+
+func TestClient_Detached(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return wrapperspb.String("value"), nil
+	}
+	h := Client()(next)
+	ctx := transport.NewClientContext(context.Background(), &Transport{header: headerCarrier{}})
+	leader, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		_, err := h(leader, wrapperspb.String("key"))
+		done <- err
+	}()
+	<-started
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := h(ctx, wrapperspb.String("key"))
+		waiter <- err
+	}()
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expect the leader canceled, got %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	if err := <-waiter; err != nil {
+		t.Errorf("expect the waiter not canceled with the leader, got %v", err)
+	}
+}
+
+func TestClient_PrivateReply(t *testing.T) {
+	release := make(chan struct{})
+	// next decodes into the reply of the context, like the transports.
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-release
+		reply, _ := kreply.FromContext(ctx)
+		reply.(*wrapperspb.StringValue).Value = "value"
+		return reply, nil
+	}
+	h := Client()(next)
+	ctx := transport.NewClientContext(context.Background(), &Transport{header: headerCarrier{}})
+
+	replies := make([]*wrapperspb.StringValue, 2)
+	var wg sync.WaitGroup
+	for i := range replies {
+		replies[i] = &wrapperspb.StringValue{}
+		wg.Add(1)
+		go func(reply *wrapperspb.StringValue) {
+			defer wg.Done()
+			out, err := h(kreply.NewContext(ctx, reply), wrapperspb.String("key"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if out == reply {
+				t.Error("expect the shared call not to decode into the reply of a caller")
+			}
+			// the caller mutates its reply while the other reads its own
+			got := out.(*wrapperspb.StringValue)
+			if got.Value != "value" {
+				t.Errorf("expect value, got %s", got.Value)
+			}
+			got.Value = "mutated"
+		}(replies[i])
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	for _, reply := range replies {
+		if reply.Value != "" {
+			t.Errorf("expect the replies of the callers untouched, got %s", reply.Value)
+		}
+	}
+}
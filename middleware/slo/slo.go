@@ -0,0 +1,212 @@
+// Package slo tracks per-operation service level objectives and computes
+// the error budget burn rate over a sliding window. A burn rate of 1 means
+// the budget is consumed exactly at the rate allowed by the objective.
+package slo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Objective is a service level objective.
+type Objective struct {
+	// Target is the ratio of good requests, e.g. 0.999.
+	Target float64
+	// Latency counts slower requests as bad, zero disables the latency objective.
+	Latency time.Duration
+}
+
+// AlertFunc is called when the burn rate of an operation rises above the alert threshold.
+type AlertFunc func(operation string, burnRate float64)
+
+// Option is slo option.
+type Option func(*SLO)
+
+// WithObjective with the default objective, default is 99.9% of requests without server errors.
+func WithObjective(obj Objective) Option {
+	return func(s *SLO) {
+		s.objective = obj
+	}
+}
+
+// WithOperationObjective with the objective of an operation.
+func WithOperationObjective(operation string, obj Objective) Option {
+	return func(s *SLO) {
+		s.objectives[operation] = obj
+	}
+}
+
+// WithWindow with the sliding window the burn rate is computed over, default is 1 hour.
+func WithWindow(d time.Duration) Option {
+	return func(s *SLO) {
+		s.window = d
+	}
+}
+
+// WithBurnRate with burn rate gauge.
+func WithBurnRate(g metrics.Gauge) Option {
+	return func(s *SLO) {
+		s.burnRate = g
+	}
+}
+
+// WithAlert with the alert called once each time the burn rate rises above threshold.
+func WithAlert(threshold float64, fn AlertFunc) Option {
+	return func(s *SLO) {
+		s.threshold = threshold
+		s.alert = fn
+	}
+}
+
+// SLO tracks the error budget of operations.
+type SLO struct {
+	objective  Objective
+	objectives map[string]Objective
+	window     time.Duration
+	buckets    int
+	threshold  float64
+	alert      AlertFunc
+	// gauge: server_requests_slo_burn_rate{kind, operation}
+	burnRate metrics.Gauge
+	now      func() time.Time
+
+	mu       sync.Mutex
+	counters map[string]*counter
+}
+
+// New returns a SLO tracker.
+func New(opts ...Option) *SLO {
+	s := &SLO{
+		objective:  Objective{Target: 0.999},
+		objectives: make(map[string]Objective),
+		window:     time.Hour,
+		buckets:    60,
+		now:        time.Now,
+		counters:   make(map[string]*counter),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Server is a server middleware recording requests against their objective.
+func (s *SLO) Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var (
+				kind      string
+				operation string
+			)
+			startTime := s.now()
+			if info, ok := transport.FromServerContext(ctx); ok {
+				kind = info.Kind().String()
+				operation = info.Operation()
+			}
+			reply, err := handler(ctx, req)
+			obj := s.objectiveOf(operation)
+			bad := err != nil && errors.FromError(err).Code >= 500
+			if obj.Latency > 0 && s.now().Sub(startTime) > obj.Latency {
+				bad = true
+			}
+			s.record(kind, operation, obj, bad)
+			return reply, err
+		}
+	}
+}
+
+// BurnRate returns the current error budget burn rate of operation.
+func (s *SLO) BurnRate(operation string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.counters[operation]
+	if !ok {
+		return 0
+	}
+	return c.burnRate(s.objectiveOf(operation), s.bucketOf(s.now()))
+}
+
+func (s *SLO) objectiveOf(operation string) Objective {
+	if obj, ok := s.objectives[operation]; ok {
+		return obj
+	}
+	return s.objective
+}
+
+func (s *SLO) bucketOf(t time.Time) int64 {
+	d := int64(s.window) / int64(s.buckets)
+	if d <= 0 {
+		d = 1
+	}
+	return t.UnixNano() / d
+}
+
+func (s *SLO) record(kind, operation string, obj Objective, bad bool) {
+	s.mu.Lock()
+	c, ok := s.counters[operation]
+	if !ok {
+		c = newCounter(s.buckets)
+		s.counters[operation] = c
+	}
+	now := s.bucketOf(s.now())
+	c.add(now, bad)
+	rate := c.burnRate(obj, now)
+	fire := s.alert != nil && rate > s.threshold && !c.alerting
+	c.alerting = rate > s.threshold
+	s.mu.Unlock()
+
+	if s.burnRate != nil {
+		s.burnRate.With(kind, operation).Set(rate)
+	}
+	if fire {
+		s.alert(operation, rate)
+	}
+}
+
+type bucket struct {
+	id    int64
+	total float64
+	bad   float64
+}
+
+// counter counts requests in a ring of buckets covering the window.
+type counter struct {
+	buckets  []bucket
+	alerting bool
+}
+
+func newCounter(n int) *counter {
+	return &counter{buckets: make([]bucket, n)}
+}
+
+func (c *counter) add(now int64, bad bool) {
+	b := &c.buckets[now%int64(len(c.buckets))]
+	if b.id != now {
+		*b = bucket{id: now}
+	}
+	b.total++
+	if bad {
+		b.bad++
+	}
+}
+
+func (c *counter) burnRate(obj Objective, now int64) float64 {
+	var total, bad float64
+	for _, b := range c.buckets {
+		if now-b.id < int64(len(c.buckets)) {
+			total += b.total
+			bad += b.bad
+		}
+	}
+	budget := 1 - obj.Target
+	if total == 0 || budget <= 0 {
+		return 0
+	}
+	return bad / total / budget
+}
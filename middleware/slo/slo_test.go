@@ -0,0 +1,75 @@
+package slo
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type Transport struct {
+	operation string
+}
+
+func (tr *Transport) Kind() transport.Kind            { return transport.KindGRPC }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return tr.operation }
+func (tr *Transport) RequestHeader() transport.Header { return nil }
+func (tr *Transport) ReplyHeader() transport.Header   { return nil }
+
+type gauge struct {
+	values map[string]float64
+	lvs    []string
+}
+
+func (g *gauge) With(lvs ...string) metrics.Gauge { return &gauge{values: g.values, lvs: lvs} }
+func (g *gauge) Set(value float64)                { g.values[g.lvs[1]] = value }
+func (g *gauge) Add(delta float64)                { g.values[g.lvs[1]] += delta }
+func (g *gauge) Sub(delta float64)                { g.values[g.lvs[1]] -= delta }
+
+func TestSLO(t *testing.T) {
+	now := time.Unix(0, 0)
+	g := &gauge{values: make(map[string]float64)}
+	var alerts []string
+	s := New(
+		WithObjective(Objective{Target: 0.9}),
+		WithWindow(time.Minute),
+		WithBurnRate(g),
+		WithAlert(2, func(operation string, burnRate float64) { alerts = append(alerts, operation) }),
+	)
+	s.now = func() time.Time { return now }
+
+	call := func(op string, err error) {
+		ctx := transport.NewServerContext(context.Background(), &Transport{operation: op})
+		_, _ = s.Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, err
+		})(ctx, "req")
+	}
+	for i := 0; i < 8; i++ {
+		call("/test.SLO/Call", nil)
+	}
+	call("/test.SLO/Call", errors.BadRequest("BAD", "client errors do not burn the budget"))
+	call("/test.SLO/Call", errors.InternalServer("ERR", "server error"))
+	if rate := s.BurnRate("/test.SLO/Call"); math.Abs(rate-1) > 1e-9 {
+		t.Errorf("expect burn rate 1, but got %v", rate)
+	}
+	for i := 0; i < 5; i++ {
+		call("/test.SLO/Call", errors.InternalServer("ERR", "server error"))
+	}
+	if len(alerts) != 1 {
+		t.Errorf("expect 1 alert, but got %v", alerts)
+	}
+	if g.values["/test.SLO/Call"] < 2 {
+		t.Errorf("unexpected burn rate gauge: %v", g.values)
+	}
+
+	// requests fall out of the window.
+	now = now.Add(2 * time.Minute)
+	if rate := s.BurnRate("/test.SLO/Call"); rate != 0 {
+		t.Errorf("expect burn rate 0, but got %v", rate)
+	}
+}
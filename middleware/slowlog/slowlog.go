@@ -0,0 +1,86 @@
+package slowlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Option is slow log option.
+type Option func(*options)
+
+type options struct {
+	threshold  time.Duration
+	operations map[string]time.Duration
+}
+
+// WithThreshold with the default latency threshold, default is 1s.
+func WithThreshold(d time.Duration) Option {
+	return func(o *options) {
+		o.threshold = d
+	}
+}
+
+// WithOperationThreshold with the latency threshold of an operation,
+// a zero duration disables slow logging for the operation.
+func WithOperationThreshold(operation string, d time.Duration) Option {
+	return func(o *options) {
+		o.operations[operation] = d
+	}
+}
+
+// Server is a server middleware logging requests exceeding their latency threshold.
+func Server(logger log.Logger, opts ...Option) middleware.Middleware {
+	o := &options{
+		threshold:  time.Second,
+		operations: make(map[string]time.Duration),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			var (
+				kind      string
+				operation string
+			)
+			startTime := time.Now()
+			if info, ok := transport.FromServerContext(ctx); ok {
+				kind = info.Kind().String()
+				operation = info.Operation()
+			}
+			reply, err = handler(ctx, req)
+			threshold, ok := o.operations[operation]
+			if !ok {
+				threshold = o.threshold
+			}
+			latency := time.Since(startTime)
+			if threshold <= 0 || latency < threshold {
+				return
+			}
+			var (
+				code   int32
+				reason string
+			)
+			if se := errors.FromError(err); se != nil {
+				code = se.Code
+				reason = se.Reason
+			}
+			_ = log.WithContext(ctx, logger).Log(log.LevelWarn,
+				"kind", "server",
+				"component", kind,
+				"operation", operation,
+				"code", code,
+				"reason", reason,
+				"latency", latency.Seconds(),
+				"threshold", threshold.Seconds(),
+				"msg", "slow request",
+			)
+			return
+		}
+	}
+}
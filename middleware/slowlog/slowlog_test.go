@@ -0,0 +1,45 @@
+package slowlog
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type Transport struct {
+	operation string
+}
+
+func (tr *Transport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return tr.operation }
+func (tr *Transport) RequestHeader() transport.Header { return nil }
+func (tr *Transport) ReplyHeader() transport.Header   { return nil }
+
+func TestServer(t *testing.T) {
+	buf := new(bytes.Buffer)
+	m := Server(log.NewStdLogger(buf),
+		WithThreshold(time.Hour),
+		WithOperationThreshold("/test.Slow/Slow", time.Millisecond),
+		WithOperationThreshold("/test.Slow/Ignored", 0),
+	)
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(2 * time.Millisecond)
+		return req, nil
+	}
+	for _, op := range []string{"/test.Slow/Slow", "/test.Slow/Fast", "/test.Slow/Ignored"} {
+		ctx := transport.NewServerContext(context.Background(), &Transport{operation: op})
+		if _, err := m(next)(ctx, "req"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := buf.String()
+	if strings.Count(out, "slow request") != 1 || !strings.Contains(out, "operation=/test.Slow/Slow") {
+		t.Errorf("unexpected slow log: %s", out)
+	}
+}
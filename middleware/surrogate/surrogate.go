@@ -0,0 +1,147 @@
+// Package surrogate tags the responses with surrogate keys, so that a CDN
+// caching them can purge all the responses of a tag at once:
+//
+//	func (s *Service) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+//		surrogate.AddKeys(ctx, "product/"+req.Id)
+//		...
+//	}
+//
+//	func (s *Service) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.Product, error) {
+//		...
+//		return p, s.purger.Purge(ctx, "product/"+req.Id)
+//	}
+package surrogate
+
+import (
+	"context"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Purger purges the cached responses tagged with the keys, e.g. from a CDN.
+type Purger interface {
+	Purge(ctx context.Context, keys ...string) error
+}
+
+// Option is surrogate option.
+type Option func(*options)
+
+type options struct {
+	header    string
+	separator string
+}
+
+// WithHeader with the header the keys are set in and their separator,
+// default is "Surrogate-Key" separated by spaces, e.g. Cloudflare uses
+// "Cache-Tag" separated by commas.
+func WithHeader(key, separator string) Option {
+	return func(o *options) {
+		o.header = key
+		o.separator = separator
+	}
+}
+
+type keysKey struct{}
+
+type tags struct {
+	mu   sync.Mutex
+	keys []string
+	vary []string
+}
+
+func add(list []string, values []string, fold bool) []string {
+	for _, v := range values {
+		found := false
+		for _, e := range list {
+			if e == v || fold && strings.EqualFold(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// varyHeader merges the headers into the Vary header value, a "*" varying by
+// everything wins.
+func varyHeader(value string, headers []string) string {
+	var vary []string
+	for _, h := range append(strings.Split(value, ","), headers...) {
+		if h = strings.TrimSpace(h); h == "" {
+			continue
+		}
+		if h == "*" {
+			return "*"
+		}
+		vary = add(vary, []string{textproto.CanonicalMIMEHeaderKey(h)}, true)
+	}
+	return strings.Join(vary, ", ")
+}
+
+// AddKeys tags the response of the request with the surrogate keys,
+// it is a no-op if the request isn't handled by the Server middleware.
+func AddKeys(ctx context.Context, keys ...string) {
+	if t, ok := ctx.Value(keysKey{}).(*tags); ok {
+		t.mu.Lock()
+		t.keys = add(t.keys, keys, false)
+		t.mu.Unlock()
+	}
+}
+
+// AddVary adds the request headers the response varies by to its Vary header,
+// so that a cache keeps a response per value of the headers.
+func AddVary(ctx context.Context, headers ...string) {
+	if t, ok := ctx.Value(keysKey{}).(*tags); ok {
+		t.mu.Lock()
+		t.vary = add(t.vary, headers, true)
+		t.mu.Unlock()
+	}
+}
+
+// Keys returns the surrogate keys the response of the request is tagged with.
+func Keys(ctx context.Context) []string {
+	if t, ok := ctx.Value(keysKey{}).(*tags); ok {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return append([]string(nil), t.keys...)
+	}
+	return nil
+}
+
+// Server is a server middleware setting the surrogate keys and the Vary
+// headers added by the handler in the reply header, unless it fails.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{
+		header:    "Surrogate-Key",
+		separator: " ",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			t := &tags{}
+			reply, err := handler(context.WithValue(ctx, keysKey{}, t), req)
+			if tr, ok := transport.FromServerContext(ctx); ok && err == nil {
+				t.mu.Lock()
+				defer t.mu.Unlock()
+				if len(t.keys) > 0 {
+					tr.ReplyHeader().Set(o.header, strings.Join(t.keys, o.separator))
+				}
+				if len(t.vary) > 0 {
+					if vary := varyHeader(tr.ReplyHeader().Get("Vary"), t.vary); vary != "" {
+						tr.ReplyHeader().Set("Vary", vary)
+					}
+				}
+			}
+			return reply, err
+		}
+	}
+}
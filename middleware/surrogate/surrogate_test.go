@@ -0,0 +1,93 @@
+package surrogate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	replyHeader transport.Header
+}
+
+func (tr *Transport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return "/test.Product/Get" }
+func (tr *Transport) RequestHeader() transport.Header { return headerCarrier{} }
+func (tr *Transport) ReplyHeader() transport.Header   { return tr.replyHeader }
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		err    error
+		header http.Header
+	}{
+		{"default", nil, nil, http.Header{
+			"Surrogate-Key": {"product/1 products"},
+			"Vary":          {"Accept-Encoding, Accept-Language"},
+		}},
+		{"cloudflare", []Option{WithHeader("Cache-Tag", ",")}, nil, http.Header{
+			"Cache-Tag": {"product/1,products"},
+			"Vary":      {"Accept-Encoding, Accept-Language"},
+		}},
+		{"error", nil, errors.New("not found"), http.Header{
+			"Vary": {"Accept-Encoding"},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{"Vary": {"Accept-Encoding"}}
+			ctx := transport.NewServerContext(context.Background(), &Transport{replyHeader: headerCarrier(header)})
+			_, _ = Server(test.opts...)(func(ctx context.Context, req interface{}) (interface{}, error) {
+				AddKeys(ctx, "product/1", "products")
+				AddKeys(ctx, "products")
+				AddVary(ctx, "accept-language", "Accept-Encoding")
+				if keys := Keys(ctx); !reflect.DeepEqual(keys, []string{"product/1", "products"}) {
+					t.Errorf("keys = %v", keys)
+				}
+				return nil, test.err
+			})(ctx, nil)
+			if !reflect.DeepEqual(header, test.header) {
+				t.Errorf("header = %v, want %v", header, test.header)
+			}
+		})
+	}
+}
+
+func TestVaryHeader(t *testing.T) {
+	tests := []struct {
+		value   string
+		headers []string
+		want    string
+	}{
+		{"", []string{"accept-language"}, "Accept-Language"},
+		{"Accept-Encoding,  accept-language ", []string{" Accept-Language", "Origin"}, "Accept-Encoding, Accept-Language, Origin"},
+		{"*", []string{"Accept-Language"}, "*"},
+		{"Accept-Encoding", []string{"*"}, "*"},
+		{"", []string{" "}, ""},
+	}
+	for _, test := range tests {
+		if got := varyHeader(test.value, test.headers); got != test.want {
+			t.Errorf("varyHeader(%q, %q) = %q, want %q", test.value, test.headers, got, test.want)
+		}
+	}
+}
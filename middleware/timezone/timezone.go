@@ -0,0 +1,199 @@
+package timezone
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// reason is the error reason of an invalid time zone.
+const reason = "INVALID_TIMEZONE"
+
+// Option is timezone option.
+type Option func(*options)
+
+type options struct {
+	header   string
+	claim    string
+	fallback *time.Location
+}
+
+// WithHeader with the request header of the client time zone, default is "X-Timezone".
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// WithClaim with the JWT claim of the user time zone, default is the OpenID "zoneinfo".
+func WithClaim(name string) Option {
+	return func(o *options) {
+		o.claim = name
+	}
+}
+
+// WithDefault with the time zone of the requests without one, default is UTC.
+func WithDefault(loc *time.Location) Option {
+	return func(o *options) {
+		o.fallback = loc
+	}
+}
+
+// Server is a server middleware putting the time zone of the request in the
+// context, from the request header or else the JWT claim, either an IANA
+// name like "Asia/Shanghai" or a UTC offset like "+08:00". An invalid time
+// zone in the header is rejected.
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{
+		header:   "X-Timezone",
+		claim:    "zoneinfo",
+		fallback: time.UTC,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			loc := o.fallback
+			if name := o.claimOf(ctx); name != "" {
+				if l, err := Parse(name); err == nil {
+					loc = l
+				}
+			}
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if name := tr.RequestHeader().Get(o.header); name != "" {
+					l, err := Parse(name)
+					if err != nil {
+						return nil, errors.BadRequest(reason, err.Error())
+					}
+					loc = l
+				}
+			}
+			return handler(NewContext(ctx, loc), req)
+		}
+	}
+}
+
+func (o *options) claimOf(ctx context.Context) string {
+	claims, ok := jwt.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if mc, ok := claims.(jwtv4.MapClaims); ok {
+		name, _ := mc[o.claim].(string)
+		return name
+	}
+	return ""
+}
+
+// maxLocations bounds the locations cached, more than the IANA time zones.
+const maxLocations = 1024
+
+var locations = struct {
+	sync.RWMutex
+	m map[string]*time.Location
+}{m: make(map[string]*time.Location)}
+
+// loadLocation loads the IANA time zone name, caching the known ones since
+// time.LoadLocation reads the zoneinfo database on every call.
+func loadLocation(name string) (*time.Location, error) {
+	locations.RLock()
+	loc, ok := locations.m[name]
+	locations.RUnlock()
+	if ok {
+		return loc, nil
+	}
+	if name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	locations.Lock()
+	if len(locations.m) < maxLocations {
+		locations.m[name] = loc
+	}
+	locations.Unlock()
+	return loc, nil
+}
+
+// Parse parses a time zone, either an IANA name or a UTC offset like "+08:00", "-0530" or "UTC+8".
+func Parse(name string) (*time.Location, error) {
+	offset := strings.TrimPrefix(strings.TrimPrefix(name, "UTC"), "GMT")
+	if offset == "" || offset[0] != '+' && offset[0] != '-' {
+		return loadLocation(name)
+	}
+	hm := strings.Replace(offset[1:], ":", "", 1)
+	for i := 0; i < len(hm); i++ {
+		if hm[i] < '0' || hm[i] > '9' {
+			return nil, fmt.Errorf("invalid time zone offset %q", name)
+		}
+	}
+	var h, m int
+	var err error
+	switch len(hm) {
+	case 1, 2:
+		h, err = strconv.Atoi(hm)
+	case 3, 4:
+		if h, err = strconv.Atoi(hm[:len(hm)-2]); err == nil {
+			m, err = strconv.Atoi(hm[len(hm)-2:])
+		}
+	default:
+		err = fmt.Errorf("invalid offset")
+	}
+	if err != nil || h > 14 || m > 59 {
+		return nil, fmt.Errorf("invalid time zone offset %q", name)
+	}
+	seconds := (h*60 + m) * 60
+	if offset[0] == '-' {
+		seconds = -seconds
+	}
+	if seconds == 0 {
+		return time.UTC, nil
+	}
+	return time.FixedZone(name, seconds), nil
+}
+
+type locationKey struct{}
+
+// NewContext returns a new context with the time zone.
+func NewContext(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// FromContext returns the time zone of the context, UTC if it has none.
+func FromContext(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok {
+		return loc
+	}
+	return time.UTC
+}
+
+// In returns t in the time zone of the context.
+func In(ctx context.Context, t time.Time) time.Time {
+	return t.In(FromContext(ctx))
+}
+
+// Format formats t in the time zone of the context.
+func Format(ctx context.Context, t time.Time, layout string) string {
+	return In(ctx, t).Format(layout)
+}
+
+// StartOfDay returns the start of the day of t in the time zone of the context,
+// e.g. to bucket reports by local day.
+func StartOfDay(ctx context.Context, t time.Time) time.Time {
+	t = In(ctx, t)
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,131 @@
+package timezone
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware/auth/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type Transport struct {
+	reqHeader transport.Header
+}
+
+func (tr *Transport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *Transport) Endpoint() string                { return "" }
+func (tr *Transport) Operation() string               { return "/test.Report/Daily" }
+func (tr *Transport) RequestHeader() transport.Header { return tr.reqHeader }
+func (tr *Transport) ReplyHeader() transport.Header   { return headerCarrier{} }
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		err    bool
+	}{
+		{"Asia/Shanghai", 8 * 3600, false},
+		{"UTC", 0, false},
+		{"+08:00", 8 * 3600, false},
+		{"-0530", -(5*3600 + 30*60), false},
+		{"UTC+8", 8 * 3600, false},
+		{"GMT-3", -3 * 3600, false},
+		{"+5:45", 5*3600 + 45*60, false},
+		{"Mars/Olympus", 0, true},
+		{"Local", 0, true},
+		{"+25:00", 0, true},
+		{"+8:0", 0, true},
+		{"UTC++5", 0, true},
+		{"+-5", 0, true},
+		{"+ 5", 0, true},
+		{"+5:+3", 0, true},
+	}
+	at := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		loc, err := Parse(test.name)
+		if (err != nil) != test.err {
+			t.Errorf("%s: err = %v", test.name, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if _, offset := at.In(loc).Zone(); offset != test.offset {
+			t.Errorf("%s: offset = %d, want %d", test.name, offset, test.offset)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		claim  string
+		zone   string
+		reason string
+	}{
+		{"default", "", "", "UTC", ""},
+		{"claim", "", "Europe/Paris", "Europe/Paris", ""},
+		{"header", "Asia/Tokyo", "Europe/Paris", "Asia/Tokyo", ""},
+		{"invalid header", "Mars/Olympus", "", "", reason},
+		{"invalid claim", "", "Mars/Olympus", "UTC", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := transport.NewServerContext(context.Background(), &Transport{
+				reqHeader: headerCarrier(http.Header{"X-Timezone": {test.header}}),
+			})
+			if test.claim != "" {
+				ctx = jwt.NewContext(ctx, jwtv4.MapClaims{"zoneinfo": test.claim})
+			}
+			var zone string
+			_, err := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+				zone = FromContext(ctx).String()
+				return nil, nil
+			})(ctx, nil)
+			if errors.Reason(err) != test.reason {
+				t.Fatalf("err = %v, want reason %s", err, test.reason)
+			}
+			if zone != test.zone {
+				t.Errorf("zone = %s, want %s", zone, test.zone)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	loc, _ := Parse("Asia/Shanghai")
+	if cached, _ := Parse("Asia/Shanghai"); cached != loc {
+		t.Error("expect the location cached")
+	}
+	ctx := NewContext(context.Background(), loc)
+	at := time.Date(2022, 1, 1, 20, 30, 0, 0, time.UTC)
+	if s := Format(ctx, at, "2006-01-02 15:04"); s != "2022-01-02 04:30" {
+		t.Errorf("Format = %s", s)
+	}
+	if day := StartOfDay(ctx, at); !day.Equal(time.Date(2022, 1, 1, 16, 0, 0, 0, time.UTC)) {
+		t.Errorf("StartOfDay = %v", day)
+	}
+	if loc := FromContext(context.Background()); loc != time.UTC {
+		t.Errorf("FromContext = %v, want UTC", loc)
+	}
+}
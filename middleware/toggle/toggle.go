@@ -0,0 +1,151 @@
+// Package toggle provides the named runtime switches of the middlewares, e.g.
+// turning the payload logging middleware on for 10 minutes without restarting:
+//
+//	toggles := toggle.New()
+//	if err := toggles.Watch(c, "middleware.toggles"); err != nil {
+//		return err
+//	}
+//	http.Middleware(toggles.Conditional("payload_logging", logging.Server(logger)))
+package toggle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// Toggles are named runtime switches of middleware.
+type Toggles struct {
+	mu    sync.RWMutex
+	until map[string]time.Time
+	now   func() time.Time
+}
+
+// New returns toggles which are all disabled.
+func New() *Toggles {
+	return &Toggles{until: make(map[string]time.Time), now: time.Now}
+}
+
+// Enable enables the toggle name for d, forever if d <= 0.
+func (t *Toggles) Enable(name string, d time.Duration) {
+	var until time.Time
+	if d > 0 {
+		until = t.now().Add(d)
+	}
+	t.mu.Lock()
+	t.until[name] = until
+	t.mu.Unlock()
+}
+
+// Disable disables the toggle name.
+func (t *Toggles) Disable(name string) {
+	t.mu.Lock()
+	delete(t.until, name)
+	t.mu.Unlock()
+}
+
+// Enabled reports whether the toggle name is enabled.
+func (t *Toggles) Enabled(name string) bool {
+	t.mu.RLock()
+	until, ok := t.until[name]
+	t.mu.RUnlock()
+	return ok && (until.IsZero() || t.now().Before(until))
+}
+
+// Conditional returns a Middleware running m only while the toggle name is enabled.
+func (t *Toggles) Conditional(name string, m middleware.Middleware) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		next := m(handler)
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if t.Enabled(name) {
+				return next(ctx, req)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Watch drives the toggles by the config key, a map from the toggle names to
+// either a bool or the duration they are enabled for:
+//
+//	middleware:
+//	  toggles:
+//	    auth: true
+//	    payload_logging: 10m
+//
+// A toggle missing from the map is disabled, a duration starts when its value
+// changes. The toggles are validated before any is applied, the invalid ones
+// are logged and the previous toggles kept. A missing key disables the
+// toggles, it is only watched if present.
+func (t *Toggles) Watch(c config.Config, key string) error {
+	applied := make(map[string]string)
+	apply := func(v config.Value) error {
+		values, err := v.Map()
+		if err != nil {
+			return err
+		}
+		toggles := make(map[string]string, len(values))
+		enable := make(map[string]time.Duration, len(values))
+		for name, v := range values {
+			s, err := v.String()
+			if err != nil {
+				return fmt.Errorf("middleware toggle %s: %w", name, err)
+			}
+			toggles[name] = s
+			if enabled, err := strconv.ParseBool(s); err == nil {
+				if enabled {
+					enable[name] = 0
+				}
+				continue
+			}
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("middleware toggle %s: %q is neither a bool nor a duration", name, s)
+			}
+			enable[name] = d
+		}
+		now := t.now()
+		t.mu.Lock()
+		for name, s := range toggles {
+			if applied[name] == s {
+				continue
+			}
+			d, ok := enable[name]
+			switch {
+			case !ok:
+				delete(t.until, name)
+			case d > 0:
+				t.until[name] = now.Add(d)
+			default:
+				t.until[name] = time.Time{}
+			}
+		}
+		for name := range applied {
+			if _, ok := toggles[name]; !ok {
+				delete(t.until, name)
+			}
+		}
+		t.mu.Unlock()
+		applied = toggles
+		return nil
+	}
+	v := c.Value(key)
+	if err := apply(v); err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return c.Watch(key, func(_ string, v config.Value) {
+		if err := apply(v); err != nil {
+			log.Errorf("failed to apply middleware toggles: %v", err)
+		}
+	})
+}
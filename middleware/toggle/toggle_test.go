@@ -0,0 +1,123 @@
+package toggle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+func TestConditional(t *testing.T) {
+	now := time.Now()
+	toggles := New()
+	toggles.now = func() time.Time { return now }
+	var calls int
+	m := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			return handler(ctx, req)
+		}
+	}
+	h := toggles.Conditional("payload", m)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	})
+
+	_, _ = h(context.Background(), nil)
+	toggles.Enable("payload", 10*time.Minute)
+	_, _ = h(context.Background(), nil)
+	now = now.Add(10 * time.Minute)
+	_, _ = h(context.Background(), nil)
+	toggles.Enable("payload", 0)
+	_, _ = h(context.Background(), nil)
+	toggles.Disable("payload")
+	_, _ = h(context.Background(), nil)
+	if calls != 2 {
+		t.Errorf("middleware called %d times, want 2", calls)
+	}
+}
+
+type testSource struct {
+	kvs chan []*config.KeyValue
+	kv  *config.KeyValue
+}
+
+func (s *testSource) Load() ([]*config.KeyValue, error) { return []*config.KeyValue{s.kv}, nil }
+func (s *testSource) Watch() (config.Watcher, error)    { return s, nil }
+func (s *testSource) Stop() error                       { return nil }
+
+func (s *testSource) Next() ([]*config.KeyValue, error) {
+	kvs, ok := <-s.kvs
+	if !ok {
+		return nil, context.Canceled
+	}
+	return kvs, nil
+}
+
+func TestTogglesWatch(t *testing.T) {
+	source := &testSource{
+		kvs: make(chan []*config.KeyValue),
+		kv:  &config.KeyValue{Key: "test", Format: "json", Value: []byte(`{"toggles":{"auth":true,"payload":"10m","off":false}}`)},
+	}
+	c := config.New(config.WithSource(source))
+	defer close(source.kvs)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	toggles := New()
+	if err := toggles.Watch(c, "toggles"); err != nil {
+		t.Fatal(err)
+	}
+	if !toggles.Enabled("auth") || !toggles.Enabled("payload") || toggles.Enabled("off") {
+		t.Fatal("toggles not applied")
+	}
+
+	source.kvs <- []*config.KeyValue{{Key: "test", Format: "json", Value: []byte(`{"toggles":{"auth":false,"payload":"10m","off":true}}`)}}
+	deadline := time.Now().Add(time.Second)
+	for toggles.Enabled("auth") || !toggles.Enabled("off") {
+		if time.Now().After(deadline) {
+			t.Fatal("toggles not updated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !toggles.Enabled("payload") {
+		t.Error("payload toggle disabled")
+	}
+}
+
+func TestTogglesWatch_Invalid(t *testing.T) {
+	source := &testSource{
+		kvs: make(chan []*config.KeyValue),
+		kv:  &config.KeyValue{Key: "test", Format: "json", Value: []byte(`{"toggles":{"auth":true,"off":false}}`)},
+	}
+	c := config.New(config.WithSource(source))
+	defer close(source.kvs)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	toggles := New()
+	if err := toggles.Watch(c, "missing"); err != nil {
+		t.Fatalf("expect the missing key to disable the toggles, got %v", err)
+	}
+	if err := toggles.Watch(c, "toggles"); err != nil {
+		t.Fatal(err)
+	}
+	// the invalid toggles are rejected as a whole
+	source.kvs <- []*config.KeyValue{{Key: "test", Format: "json", Value: []byte(`{"toggles":{"auth":false,"off":true,"payload":"soon"}}`)}}
+	time.Sleep(50 * time.Millisecond)
+	if !toggles.Enabled("auth") || toggles.Enabled("off") {
+		t.Error("expect the invalid toggles not applied")
+	}
+	source.kvs <- []*config.KeyValue{{Key: "test", Format: "json", Value: []byte(`{"toggles":{"auth":true,"off":false,"payload":"10m","last":true}}`)}}
+	deadline := time.Now().Add(time.Second)
+	for !toggles.Enabled("last") {
+		if time.Now().After(deadline) {
+			t.Fatal("toggles not updated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !toggles.Enabled("auth") || !toggles.Enabled("payload") {
+		t.Error("expect the valid toggles applied")
+	}
+}
@@ -0,0 +1,147 @@
+package sqlcommenter
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+// Wrap returns a driver commenting the statements of d, to be registered:
+//
+//	sql.Register("mysql-commented", sqlcommenter.Wrap(&mysql.MySQLDriver{}, sqlcommenter.WithDriver("mysql")))
+//
+// The prepared statements carry the attributes of the context they are
+// prepared with.
+func Wrap(d driver.Driver, opts ...Option) driver.Driver {
+	return &wrappedDriver{Driver: d, opts: newOptions(opts)}
+}
+
+// WrapConnector returns a connector commenting the statements of c, to be opened with sql.OpenDB.
+func WrapConnector(c driver.Connector, opts ...Option) driver.Connector {
+	return &connector{Connector: c, driver: Wrap(c.Driver(), opts...), opts: newOptions(opts)}
+}
+
+type wrappedDriver struct {
+	driver.Driver
+	opts *options
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, opts: d.opts}, nil
+}
+
+func (d *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &connector{Connector: c, driver: d, opts: d.opts}, nil
+	}
+	return &dsnConnector{name: name, driver: d}, nil
+}
+
+type connector struct {
+	driver.Connector
+	driver driver.Driver
+	opts   *options
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn, opts: c.opts}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector is the connector of the drivers without one.
+type dsnConnector struct {
+	name   string
+	driver *wrappedDriver
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// conn comments the statements of the wrapped conn, the optional interfaces
+// it doesn't implement fall back to the database/sql defaults.
+type conn struct {
+	driver.Conn
+	opts *options
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query = c.opts.comment(ctx, query)
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return e.ExecContext(ctx, c.opts.comment(ctx, query), args)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return q.QueryContext(ctx, c.opts.comment(ctx, query), args)
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("sqlcommenter: the driver doesn't support the transaction options")
+	}
+	return c.Conn.Begin() //nolint:staticcheck
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(v *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
@@ -0,0 +1,133 @@
+// Package sqlcommenter appends the trace context and the operation of the
+// requests to the SQL statements as sqlcommenter comments, so the database
+// observability tools correlate the slow queries with the traces:
+//
+//	SELECT * FROM users /*framework='kratos',route='%2Fapi.user.v1.User%2FGetUser',traceparent='00-...-01'*/
+//
+// The statements of a context traced by the tracing middleware carry its span.
+package sqlcommenter
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Option is sqlcommenter option.
+type Option func(*options)
+
+type options struct {
+	application string
+	driver      string
+	attrs       func(ctx context.Context) map[string]string
+}
+
+// WithApplication with the application name of the comments.
+func WithApplication(name string) Option {
+	return func(o *options) {
+		o.application = name
+	}
+}
+
+// WithDriver with the database driver name of the comments, e.g. "mysql".
+func WithDriver(name string) Option {
+	return func(o *options) {
+		o.driver = name
+	}
+}
+
+// WithAttributes with extra attributes of the comments, e.g. the tenant.
+func WithAttributes(f func(ctx context.Context) map[string]string) Option {
+	return func(o *options) {
+		o.attrs = f
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Comment returns query with the attributes of ctx appended as a comment,
+// the query is returned as is if it has a comment already.
+func Comment(ctx context.Context, query string, opts ...Option) string {
+	return newOptions(opts).comment(ctx, query)
+}
+
+func (o *options) comment(ctx context.Context, query string) string {
+	if query == "" || strings.Contains(query, "/*") || strings.Contains(query, "--") {
+		return query
+	}
+	attrs := make(map[string]string)
+	if o.attrs != nil {
+		for k, v := range o.attrs(ctx) {
+			attrs[k] = v
+		}
+	}
+	attrs["framework"] = "kratos"
+	if o.application != "" {
+		attrs["application"] = o.application
+	}
+	if o.driver != "" {
+		attrs["db_driver"] = o.driver
+	}
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		attrs["route"] = tr.Operation()
+		if ht, ok := tr.(khttp.Transporter); ok && ht.PathTemplate() != "" {
+			attrs["action"] = tr.Operation()
+			attrs["route"] = ht.PathTemplate()
+		}
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	for k, v := range carrier {
+		attrs[k] = v
+	}
+	return appendComment(query, attrs)
+}
+
+// appendComment serializes attrs as the sqlcommenter specification, sorted
+// and URL encoded, before the trailing semicolon of query.
+func appendComment(query string, attrs map[string]string) string {
+	keys := make([]string, 0, len(attrs))
+	for k, v := range attrs {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return query
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	trimmed := strings.TrimRight(query, "; \t\n")
+	sb.WriteString(trimmed)
+	sb.WriteString(" /*")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(escape(k))
+		sb.WriteString("='")
+		sb.WriteString(escape(attrs[k]))
+		sb.WriteByte('\'')
+	}
+	sb.WriteString("*/")
+	if strings.HasSuffix(strings.TrimRight(query, " \t\n"), ";") {
+		sb.WriteByte(';')
+	}
+	return sb.String()
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
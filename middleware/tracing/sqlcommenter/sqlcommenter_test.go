@@ -0,0 +1,106 @@
+package sqlcommenter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/go-kratos/kratos/v2/middleware/middlewaretest"
+)
+
+func TestComment(t *testing.T) {
+	ctx, _ := middlewaretest.NewServerContext(context.Background(), middlewaretest.WithOperation("/api.user.v1.User/GetUser"))
+	ctx, span := tracesdk.NewTracerProvider().Tracer("test").Start(ctx, "GetUser")
+	defer span.End()
+	got := Comment(ctx, "SELECT * FROM users;", WithApplication("user's"))
+	want := "SELECT * FROM users /*application='user%27s',framework='kratos',route='%2Fapi.user.v1.User%2FGetUser',traceparent='00-" +
+		span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01'*/;"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/users/1", nil)
+	ctx, _ = middlewaretest.NewServerContext(context.Background(), middlewaretest.WithOperation("/api.user.v1.User/GetUser"), middlewaretest.WithRequest(req, "/users/{id}"))
+	if got = Comment(ctx, "SELECT 1"); got != "SELECT 1 /*action='%2Fapi.user.v1.User%2FGetUser',framework='kratos',route='%2Fusers%2F%7Bid%7D'*/" {
+		t.Errorf("got %s", got)
+	}
+	if got = Comment(ctx, "SELECT 1 /* hint */"); got != "SELECT 1 /* hint */" {
+		t.Errorf("expect the commented query unchanged, got %s", got)
+	}
+}
+
+// fakeDriver records the statements, it implements ExecerContext but not QueryerContext.
+type fakeDriver struct{ queries []string }
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.queries = append(c.d.queries, query)
+	return fakeStmt{}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.d.queries = append(c.d.queries, query)
+	return driver.RowsAffected(1), nil
+}
+
+type (
+	fakeStmt struct{}
+	fakeRows struct{}
+	fakeTx   struct{}
+)
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return fakeRows{}, nil }
+func (fakeRows) Columns() []string                          { return nil }
+func (fakeRows) Close() error                               { return nil }
+func (fakeRows) Next([]driver.Value) error                  { return io.EOF }
+func (fakeTx) Commit() error                                { return nil }
+func (fakeTx) Rollback() error                              { return nil }
+
+func TestWrap(t *testing.T) {
+	d := &fakeDriver{}
+	sql.Register("sqlcommenter-fake", Wrap(d, WithDriver("fake")))
+	db, err := sql.Open("sqlcommenter-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx, _ := middlewaretest.NewServerContext(context.Background(), middlewaretest.WithOperation("/test"))
+	if _, err = db.ExecContext(ctx, "UPDATE users SET age = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT * FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if len(d.queries) != 2 {
+		t.Fatalf("unexpected queries %v", d.queries)
+	}
+	for _, q := range d.queries {
+		if !strings.HasSuffix(q, "/*db_driver='fake',framework='kratos',route='%2Ftest'*/") {
+			t.Errorf("expect the query commented, got %s", q)
+		}
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = tx.Rollback()
+	if _, err = db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true}); err == nil {
+		t.Error("expect the read only transaction unsupported")
+	}
+}
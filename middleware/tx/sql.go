@@ -0,0 +1,61 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+type sqlKey struct{}
+
+// FromContext returns the SQL transaction in ctx.
+func FromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(sqlKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// DB returns the SQL transaction in ctx, or db outside of a transaction,
+// so the repositories work either way:
+//
+//	_, err := tx.DB(ctx, r.db).ExecContext(ctx, "UPDATE ...")
+func DB(ctx context.Context, db Querier) Querier {
+	if tx, ok := FromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+type sqlManager struct {
+	db   *sql.DB
+	opts *sql.TxOptions
+}
+
+// NewSQL returns a database/sql transaction manager, opts may be nil. The
+// operations called with a transaction in their context join it.
+func NewSQL(db *sql.DB, opts *sql.TxOptions) TxManager {
+	return &sqlManager{db: db, opts: opts}
+}
+
+func (m *sqlManager) Begin(ctx context.Context) (context.Context, Tx, error) {
+	if _, ok := FromContext(ctx); ok {
+		return ctx, nopTx{}, nil
+	}
+	tx, err := m.db.BeginTx(ctx, m.opts)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return context.WithValue(ctx, sqlKey{}, tx), tx, nil
+}
+
+// nopTx is the transaction of the operations joining an outer one.
+type nopTx struct{}
+
+func (nopTx) Commit() error   { return nil }
+func (nopTx) Rollback() error { return nil }
@@ -0,0 +1,98 @@
+// Package tx runs the handlers of the selected operations in transactions,
+// committed when the handler succeeds and rolled back when it fails or panics,
+// e.g. composed with selector:
+//
+//	selector.Server(tx.Server(tx.NewSQL(db, nil))).Prefix("/helloworld.Greeter/Update").Build()
+//
+// The handlers and the repositories get the transaction from the context.
+package tx
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+const reason = "TRANSACTION"
+
+// Tx is a transaction.
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// TxManager begins the transactions.
+type TxManager interface {
+	// Begin begins a transaction, the returned context carries it.
+	Begin(ctx context.Context) (context.Context, Tx, error)
+}
+
+// Option is tx option.
+type Option func(*options)
+
+type options struct {
+	rollback func(err error) bool
+	logger   log.Logger
+}
+
+// WithRollback with the func reporting whether the transaction is rolled back
+// on the error returned by the handler, default is any error.
+func WithRollback(f func(err error) bool) Option {
+	return func(o *options) {
+		o.rollback = f
+	}
+}
+
+// WithLogger with the logger of the transaction errors, which are not returned
+// to the clients.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Server is a server middleware running the handler in a transaction of m.
+func Server(m TxManager, opts ...Option) middleware.Middleware {
+	o := &options{
+		rollback: func(error) bool { return true },
+		logger:   log.GetLogger(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	logger := log.NewHelper(o.logger)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			ctx, tx, err := m.Begin(ctx)
+			if err != nil {
+				logger.WithContext(ctx).Errorw("msg", "failed to begin transaction", "error", err)
+				return nil, errors.ServiceUnavailable(reason, "failed to begin transaction")
+			}
+			defer func() {
+				if p := recover(); p != nil {
+					_ = tx.Rollback()
+					panic(p)
+				}
+			}()
+			reply, err = handler(ctx, req)
+			if err != nil {
+				if o.rollback(err) {
+					_ = tx.Rollback()
+					return nil, err
+				}
+				if cerr := tx.Commit(); cerr != nil {
+					logger.WithContext(ctx).Errorw("msg", "failed to commit transaction", "error", cerr)
+					return nil, errors.InternalServer(reason, "failed to commit transaction")
+				}
+				return nil, err
+			}
+			if err = tx.Commit(); err != nil {
+				logger.WithContext(ctx).Errorw("msg", "failed to commit transaction", "error", err)
+				return nil, errors.InternalServer(reason, "failed to commit transaction")
+			}
+			return reply, nil
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+)
+
+// testDriver records the statements and the transactions of its connections.
+type testDriver struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (d *testDriver) record(s string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, s)
+}
+
+func (d *testDriver) Log() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return strings.Join(d.log, ",")
+}
+
+func (d *testDriver) Open(name string) (driver.Conn, error) { return &testConn{d: d}, nil }
+
+type testConn struct{ d *testDriver }
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{c: c, query: query}, nil
+}
+func (c *testConn) Close() error              { return nil }
+func (c *testConn) Begin() (driver.Tx, error) { c.d.record("begin"); return c, nil }
+func (c *testConn) Commit() error             { c.d.record("commit"); return nil }
+func (c *testConn) Rollback() error           { c.d.record("rollback"); return nil }
+
+type testStmt struct {
+	c     *testConn
+	query string
+}
+
+func (s *testStmt) Close() error  { return nil }
+func (s *testStmt) NumInput() int { return -1 }
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.d.record(s.query)
+	return driver.RowsAffected(1), nil
+}
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+var testDrv = &testDriver{}
+
+func init() {
+	sql.Register("txtest", testDrv)
+}
+
+func TestServer(t *testing.T) {
+	db, err := sql.Open("txtest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	m := NewSQL(db, nil)
+	errFailed := errors.New("failed")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if _, ok := FromContext(ctx); !ok {
+			t.Error("expect a transaction in the context")
+		}
+		if _, err := DB(ctx, db).ExecContext(ctx, "update"); err != nil {
+			return nil, err
+		}
+		if req == "fail" {
+			return nil, errFailed
+		}
+		if req == "panic" {
+			panic("panic")
+		}
+		// nested operations join the transaction.
+		return Server(m)(func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil })(ctx, req)
+	}
+	tests := []struct {
+		req  string
+		err  error
+		opts []Option
+		log  string
+	}{
+		{req: "ok", log: "begin,update,commit"},
+		{req: "fail", err: errFailed, log: "begin,update,rollback"},
+		{req: "fail", err: errFailed, opts: []Option{WithRollback(func(error) bool { return false })}, log: "begin,update,commit"},
+	}
+	for _, test := range tests {
+		testDrv.log = nil
+		_, err := Server(m, test.opts...)(handler)(context.Background(), test.req)
+		if !errors.Is(err, test.err) {
+			t.Errorf("got error %v, want %v", err, test.err)
+		}
+		if got := testDrv.Log(); got != test.log {
+			t.Errorf("got %s, want %s", got, test.log)
+		}
+	}
+
+	testDrv.log = nil
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expect the panic to be propagated")
+			}
+		}()
+		_, _ = Server(m)(handler)(context.Background(), "panic")
+	}()
+	if got := testDrv.Log(); got != "begin,update,rollback" {
+		t.Errorf("got %s, want the transaction rolled back on panic", got)
+	}
+}
+
+type failingManager struct{ err error }
+
+func (m failingManager) Begin(ctx context.Context) (context.Context, Tx, error) {
+	return ctx, nil, m.err
+}
+
+func TestServer_BeginError(t *testing.T) {
+	m := failingManager{err: errors.New("dial tcp 10.0.0.1:5432: password authentication failed")}
+	_, err := Server(m)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})(context.Background(), "ok")
+	e := kerrors.FromError(err)
+	if e.Code != 503 || e.Reason != reason || strings.Contains(e.Message, "password") {
+		t.Errorf("expect a generic error, got %v", err)
+	}
+}
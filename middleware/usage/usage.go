@@ -0,0 +1,300 @@
+// Package usage accounts the requests and the bytes of the requests and the
+// replies per key, e.g. the API key or the tenant, and operation, and flushes
+// the usage records periodically to a sink for metering and billing. The
+// bytes are the sizes of the messages, see WithSize, not of their encoding on
+// the wire, so they are measured the same way for both directions and for
+// both the HTTP and the gRPC transports. The Meter is a transport.Server
+// flushing the usage when the app stops:
+//
+//	meter := usage.New(usage.NewWriterSink(f))
+//	httpSrv := http.NewServer(http.Middleware(meter.Server()))
+//	app := kratos.New(kratos.Server(httpSrv, meter))
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/internal/monitor"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var _ transport.Server = (*Meter)(nil)
+
+// maxPending is the maximum number of records kept while the sink fails.
+const maxPending = 10000
+
+// OtherKey is the key the usage of the keys over the WithMaxKeys limit is
+// accounted by.
+const OtherKey = "other"
+
+// Record is the usage of a key and an operation during a period.
+type Record struct {
+	Key          string    `json:"key"`
+	Operation    string    `json:"operation"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Requests     int64     `json:"requests"`
+	RequestBytes int64     `json:"request_bytes"`
+	ReplyBytes   int64     `json:"reply_bytes"`
+}
+
+// Sink writes the usage records, e.g. to Kafka or a database.
+type Sink interface {
+	Write(ctx context.Context, records []Record) error
+}
+
+// SinkFunc is a func Sink.
+type SinkFunc func(ctx context.Context, records []Record) error
+
+// Write calls f.
+func (f SinkFunc) Write(ctx context.Context, records []Record) error {
+	return f(ctx, records)
+}
+
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a sink writing the records as JSON lines to w, e.g. a
+// file shipped by a log collector.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+func (s *writerSink) Write(ctx context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc := json.NewEncoder(s.w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Option is meter option.
+type Option func(*Meter)
+
+// WithHeaderKey with the request header carrying the key, default is X-API-Key.
+// The records carry a hash of the header, so that the sinks don't store it,
+// but the header isn't authenticated, use WithKey to account the validated
+// callers only.
+func WithHeaderKey(header string) Option {
+	return func(m *Meter) {
+		m.key = func(ctx context.Context) string {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if v := tr.RequestHeader().Get(header); v != "" {
+					return monitor.HashKey(v)
+				}
+			}
+			return ""
+		}
+	}
+}
+
+// WithKey with the func returning the key of a request, e.g. the tenant from
+// the auth claims, the requests without key aren't accounted. The key is
+// written to the sink as it is, so it must not be a secret.
+func WithKey(f func(ctx context.Context) string) Option {
+	return func(m *Meter) {
+		m.key = f
+	}
+}
+
+// WithSize with the func returning the size in bytes of a request or a reply,
+// default is Size.
+func WithSize(f func(v interface{}) int) Option {
+	return func(m *Meter) {
+		m.size = f
+	}
+}
+
+// WithMaxKeys with the maximum number of keys accounted separately during an
+// interval, the usage of the other keys is accounted by OtherKey, default is 10000.
+func WithMaxKeys(n int) Option {
+	return func(m *Meter) {
+		m.maxKeys = n
+	}
+}
+
+// WithInterval with the flush interval, default is 1m.
+func WithInterval(d time.Duration) Option {
+	return func(m *Meter) {
+		m.interval = d
+	}
+}
+
+type recordKey struct {
+	key       string
+	operation string
+}
+
+// Meter accumulates the usage and flushes it to a sink.
+type Meter struct {
+	sink     Sink
+	key      func(ctx context.Context) string
+	size     func(v interface{}) int
+	interval time.Duration
+	maxKeys  int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	start   time.Time
+	keys    map[string]struct{}
+	records map[recordKey]*Record
+	pending []Record
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New new a meter flushing to sink.
+func New(sink Sink, opts ...Option) *Meter {
+	m := &Meter{
+		sink:     sink,
+		size:     Size,
+		interval: time.Minute,
+		maxKeys:  10000,
+		now:      time.Now,
+		keys:     make(map[string]struct{}),
+		records:  make(map[recordKey]*Record),
+	}
+	WithHeaderKey("X-API-Key")(m)
+	for _, o := range opts {
+		o(m)
+	}
+	m.start = m.now()
+	return m
+}
+
+// Size returns the size of the proto messages and of the byte slices, 0 otherwise.
+func Size(v interface{}) int {
+	switch v := v.(type) {
+	case proto.Message:
+		return proto.Size(v)
+	case []byte:
+		return len(v)
+	}
+	return 0
+}
+
+// Server is a server middleware accounting the usage of the requests.
+func (m *Meter) Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			key := m.key(ctx)
+			if key == "" {
+				return handler(ctx, req)
+			}
+			reqBytes := int64(m.size(req))
+			reply, err := handler(ctx, req)
+			var replyBytes int64
+			if err == nil {
+				replyBytes = int64(m.size(reply))
+			}
+			m.add(recordKey{key: key, operation: tr.Operation()}, reqBytes, replyBytes)
+			return reply, err
+		}
+	}
+}
+
+func (m *Meter) add(k recordKey, reqBytes, replyBytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.keys[k.key]; !ok {
+		if len(m.keys) >= m.maxKeys {
+			k.key = OtherKey
+		} else {
+			m.keys[k.key] = struct{}{}
+		}
+	}
+	r, ok := m.records[k]
+	if !ok {
+		r = &Record{Key: k.key, Operation: k.operation}
+		m.records[k] = r
+	}
+	r.Requests++
+	r.RequestBytes += reqBytes
+	r.ReplyBytes += replyBytes
+}
+
+// Flush writes the usage accumulated since the last flush to the sink, the
+// records failing to be written are retried on the next flush, the oldest
+// are dropped past 10000 records.
+func (m *Meter) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	now := m.now()
+	records := m.pending
+	for _, r := range m.records {
+		r.Start, r.End = m.start, now
+		records = append(records, *r)
+	}
+	m.keys = make(map[string]struct{})
+	m.records = make(map[recordKey]*Record)
+	m.pending = nil
+	m.start = now
+	m.mu.Unlock()
+	if len(records) == 0 {
+		return nil
+	}
+	if err := m.sink.Write(ctx, records); err != nil {
+		m.mu.Lock()
+		m.pending = append(records, m.pending...)
+		if n := len(m.pending) - maxPending; n > 0 {
+			log.Errorf("usage: dropped %d records failing to be written", n)
+			m.pending = m.pending[n:]
+		}
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Start flushes the usage every interval until the meter stops.
+func (m *Meter) Start(ctx context.Context) error {
+	m.mu.Lock()
+	m.stop, m.done = make(chan struct{}), make(chan struct{})
+	stop, done := m.stop, m.done
+	m.mu.Unlock()
+	defer close(done)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := m.Flush(ctx); err != nil {
+				log.Errorf("usage: failed to flush: %v", err)
+			}
+		}
+	}
+}
+
+// Stop stops the periodic flushes and flushes the remaining usage.
+func (m *Meter) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	stop, done := m.stop, m.done
+	m.stop = nil
+	m.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	return m.Flush(ctx)
+}
@@ -0,0 +1,108 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/internal/monitor"
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/middleware/middlewaretest"
+)
+
+func TestMeter(t *testing.T) {
+	var (
+		written []Record
+		fail    = true
+	)
+	sink := SinkFunc(func(ctx context.Context, records []Record) error {
+		if fail {
+			return errors.New("unavailable")
+		}
+		written = append(written, records...)
+		return nil
+	})
+	m := New(sink)
+	h := m.Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.HelloReply{Message: "Hello " + req.(*pb.HelloRequest).Name}, nil
+	})
+	call := func(key, operation string) {
+		ctx, _ := middlewaretest.NewServerContext(context.Background(),
+			middlewaretest.WithOperation(operation), middlewaretest.WithHeader("X-API-Key", key))
+		if _, err := h(ctx, &pb.HelloRequest{Name: "kratos"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	call("alice", "/helloworld.Greeter/SayHello")
+	call("alice", "/helloworld.Greeter/SayHello")
+	call("bob", "/helloworld.Greeter/SayHello")
+	call("", "/helloworld.Greeter/SayHello")
+
+	if err := m.Flush(context.Background()); err == nil {
+		t.Fatal("expect the sink to fail")
+	}
+	call("alice", "/helloworld.Greeter/SayHello")
+	fail = false
+	if err := m.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var alice Record
+	for _, r := range written {
+		if r.Key == monitor.HashKey("alice") {
+			alice.Requests += r.Requests
+			alice.RequestBytes += r.RequestBytes
+			alice.ReplyBytes += r.ReplyBytes
+		}
+	}
+	if len(written) != 3 || alice.Requests != 3 || alice.RequestBytes != 24 || alice.ReplyBytes != 42 {
+		t.Errorf("got %d records, alice %+v", len(written), alice)
+	}
+}
+
+func TestMaxKeys(t *testing.T) {
+	var written []Record
+	m := New(SinkFunc(func(ctx context.Context, records []Record) error {
+		written = append(written, records...)
+		return nil
+	}), WithMaxKeys(2))
+	for _, key := range []string{"a", "b", "c", "d", "a"} {
+		ctx, _ := middlewaretest.NewServerContext(context.Background(), middlewaretest.WithHeader("X-API-Key", key))
+		_, _ = m.Server()(middlewaretest.Echo)(ctx, []byte("hello"))
+	}
+	if err := m.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	requests := map[string]int64{}
+	for _, r := range written {
+		if r.Key == "a" || r.Key == "b" {
+			t.Errorf("expect the keys hashed, got %s", r.Key)
+		}
+		requests[r.Key] += r.Requests
+	}
+	if len(requests) != 3 || requests[monitor.HashKey("a")] != 2 || requests[OtherKey] != 2 {
+		t.Errorf("expect 2 keys and the others, got %v", requests)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	var buf bytes.Buffer
+	m := New(NewWriterSink(&buf), WithInterval(10*time.Millisecond), WithKey(func(context.Context) string { return "tenant" }))
+	go func() { _ = m.Start(context.Background()) }()
+	time.Sleep(5 * time.Millisecond)
+	ctx, _ := middlewaretest.NewServerContext(context.Background())
+	_, _ = m.Server()(middlewaretest.Echo)(ctx, []byte("hello"))
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var r Record
+	if err := json.NewDecoder(strings.NewReader(buf.String())).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Key != "tenant" || r.Requests != 1 || r.RequestBytes != 5 || r.ReplyBytes != 5 || !r.End.After(r.Start) {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
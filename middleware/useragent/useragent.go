@@ -0,0 +1,228 @@
+// Package useragent parses the User-Agent of the requests into the device
+// info, e.g. for targeting and analytics.
+package useragent
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Platform is the operating system of a device.
+type Platform string
+
+// Platforms.
+const (
+	Unknown  Platform = ""
+	IOS      Platform = "ios"
+	Android  Platform = "android"
+	Windows  Platform = "windows"
+	MacOS    Platform = "macos"
+	ChromeOS Platform = "chromeos"
+	Linux    Platform = "linux"
+)
+
+// Device is the device info of a User-Agent.
+type Device struct {
+	UserAgent string
+	Platform  Platform
+	// OSVersion is the version of the platform, e.g. "15.4".
+	OSVersion string
+	// App and AppVersion are the product token of a native app, e.g. "MyApp/2.3.1".
+	App        string
+	AppVersion string
+	// Browser is the name of the browser, e.g. "Chrome".
+	Browser string
+	Mobile  bool
+	Bot     bool
+}
+
+var (
+	defaultBots = []string{"bot", "crawl", "spider", "slurp", "headless", "curl/", "wget/", "python-requests", "go-http-client"}
+
+	productRe   = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)`)
+	iosRe       = regexp.MustCompile(`(?:iPhone |CPU |iOS |iPadOS )(?:OS )?(\d+[_.\d]*)`)
+	androidRe   = regexp.MustCompile(`Android[ /]?(\d+[.\d]*)?`)
+	windowsRe   = regexp.MustCompile(`Windows NT (\d+[.\d]*)`)
+	macosRe     = regexp.MustCompile(`Mac OS X (\d+[_.\d]*)`)
+	browserList = []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"EdgA/", "Edge"},
+		{"EdgiOS/", "Edge"},
+		{"OPR/", "Opera"},
+		{"SamsungBrowser/", "Samsung Internet"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Version/", "Safari"},
+	}
+)
+
+// Option is useragent option.
+type Option func(*options)
+
+type options struct {
+	header    string
+	apps      []string
+	bots      []string
+	cacheSize int
+}
+
+// WithHeader with the request header of the User-Agent, default is "User-Agent".
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// WithApps with the product names of the native apps, which are searched
+// anywhere in the User-Agent, e.g. in the ones of the web views. By default
+// the app is the first product token unless it's "Mozilla".
+func WithApps(names ...string) Option {
+	return func(o *options) {
+		o.apps = names
+	}
+}
+
+// WithBots with the case insensitive substrings of the User-Agents of the
+// bots, added to the default ones like "bot", "spider" and "curl/".
+func WithBots(patterns ...string) Option {
+	return func(o *options) {
+		for _, p := range patterns {
+			o.bots = append(o.bots, strings.ToLower(p))
+		}
+	}
+}
+
+// WithCacheSize with the number of the parsed User-Agents cached, default is 1024.
+func WithCacheSize(size int) Option {
+	return func(o *options) {
+		o.cacheSize = size
+	}
+}
+
+// Parser parses the User-Agents, caching the devices per User-Agent.
+type Parser struct {
+	opts  *options
+	cache *lru
+}
+
+// NewParser new a User-Agent parser.
+func NewParser(opts ...Option) *Parser {
+	o := &options{
+		header:    "User-Agent",
+		bots:      append([]string(nil), defaultBots...),
+		cacheSize: 1024,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Parser{opts: o, cache: newLRU(o.cacheSize)}
+}
+
+// Parse parses ua, the device returned is shared and must not be modified.
+func (p *Parser) Parse(ua string) *Device {
+	if d, ok := p.cache.get(ua); ok {
+		return d
+	}
+	d := p.parse(ua)
+	p.cache.add(ua, d)
+	return d
+}
+
+func (p *Parser) parse(ua string) *Device {
+	d := &Device{UserAgent: ua}
+	lower := strings.ToLower(ua)
+	for _, bot := range p.opts.bots {
+		if strings.Contains(lower, bot) {
+			d.Bot = true
+			break
+		}
+	}
+	switch {
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"), strings.Contains(ua, "iPod"), strings.Contains(ua, "iOS"):
+		d.Platform, d.OSVersion = IOS, submatch(iosRe, ua)
+		d.Mobile = !strings.Contains(ua, "iPad")
+	case strings.Contains(ua, "Android"):
+		d.Platform, d.OSVersion = Android, submatch(androidRe, ua)
+		d.Mobile = strings.Contains(ua, "Mobile") || !strings.Contains(ua, "Mozilla/")
+	case strings.Contains(ua, "Windows"):
+		d.Platform, d.OSVersion = Windows, submatch(windowsRe, ua)
+	case strings.Contains(ua, "Macintosh"), strings.Contains(ua, "Mac OS X"):
+		d.Platform, d.OSVersion = MacOS, submatch(macosRe, ua)
+	case strings.Contains(ua, "CrOS"):
+		d.Platform = ChromeOS
+	case strings.Contains(ua, "Linux"):
+		d.Platform = Linux
+	}
+	if strings.HasPrefix(ua, "Mozilla/") {
+		for _, b := range browserList {
+			if strings.Contains(ua, b.token) {
+				d.Browser = b.name
+				break
+			}
+		}
+	}
+	d.App, d.AppVersion = p.app(ua)
+	return d
+}
+
+// app returns the product token of the native app.
+func (p *Parser) app(ua string) (string, string) {
+	if len(p.opts.apps) == 0 {
+		if m := productRe.FindStringSubmatch(ua); m != nil && m[1] != "Mozilla" {
+			return m[1], m[2]
+		}
+		return "", ""
+	}
+	for _, name := range p.opts.apps {
+		i := strings.Index(ua, name+"/")
+		if i < 0 {
+			continue
+		}
+		if m := productRe.FindStringSubmatch(ua[i:]); m != nil {
+			return m[1], m[2]
+		}
+	}
+	return "", ""
+}
+
+func submatch(re *regexp.Regexp, s string) string {
+	if m := re.FindStringSubmatch(s); len(m) > 1 {
+		return strings.ReplaceAll(m[1], "_", ".")
+	}
+	return ""
+}
+
+// Server is a server middleware parsing the User-Agent of the requests and
+// putting the device in the context.
+func Server(opts ...Option) middleware.Middleware {
+	p := NewParser(opts...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				if ua := tr.RequestHeader().Get(p.opts.header); ua != "" {
+					ctx = NewContext(ctx, p.Parse(ua))
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+type deviceKey struct{}
+
+// NewContext returns a new context with the device of the client.
+func NewContext(ctx context.Context, d *Device) context.Context {
+	return context.WithValue(ctx, deviceKey{}, d)
+}
+
+// FromContext returns the device of the client in the context.
+func FromContext(ctx context.Context) (*Device, bool) {
+	d, ok := ctx.Value(deviceKey{}).(*Device)
+	return d, ok
+}
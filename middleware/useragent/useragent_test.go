@@ -0,0 +1,88 @@
+package useragent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware/middlewaretest"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ua     string
+		expect Device
+	}{
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 15_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.4 Mobile/15E148 Safari/604.1",
+			Device{Platform: IOS, OSVersion: "15.4", Browser: "Safari", Mobile: true},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 12; Pixel 6) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/100.0.4896.88 Mobile Safari/537.36",
+			Device{Platform: Android, OSVersion: "12", Browser: "Chrome", Mobile: true},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/100.0.4896.127 Safari/537.36 Edg/100.0.1185.50",
+			Device{Platform: Windows, OSVersion: "10.0", Browser: "Edge"},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.4 Safari/605.1.15",
+			Device{Platform: MacOS, OSVersion: "10.15.7", Browser: "Safari"},
+		},
+		{
+			"MyApp/2.3.1 (iPad; iOS 15.4; Scale/2.00)",
+			Device{Platform: IOS, OSVersion: "15.4", App: "MyApp", AppVersion: "2.3.1"},
+		},
+		{
+			"okhttp/4.9.3",
+			Device{App: "okhttp", AppVersion: "4.9.3"},
+		},
+		{
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			Device{Bot: true},
+		},
+		{
+			"curl/7.79.1",
+			Device{App: "curl", AppVersion: "7.79.1", Bot: true},
+		},
+	}
+	p := NewParser()
+	for _, test := range tests {
+		test.expect.UserAgent = test.ua
+		if d := p.Parse(test.ua); *d != test.expect {
+			t.Errorf("parse %q: expect %+v, got %+v", test.ua, test.expect, *d)
+		}
+	}
+}
+
+func TestApps(t *testing.T) {
+	ua := "Mozilla/5.0 (Linux; Android 11; SM-G991B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0 Mobile Safari/537.36 MyApp/3.0.0"
+	d := NewParser(WithApps("MyApp"), WithBots("SM-G991B")).Parse(ua)
+	if d.App != "MyApp" || d.AppVersion != "3.0.0" || !d.Bot {
+		t.Errorf("unexpected device %+v", d)
+	}
+}
+
+func TestCache(t *testing.T) {
+	p := NewParser(WithCacheSize(1))
+	a := p.Parse("okhttp/4.9.3")
+	if p.Parse("okhttp/4.9.3") != a {
+		t.Error("expect the cached device")
+	}
+	p.Parse("curl/7.79.1")
+	if p.Parse("okhttp/4.9.3") == a {
+		t.Error("expect the device evicted")
+	}
+}
+
+func TestServer(t *testing.T) {
+	ctx, _ := middlewaretest.NewServerContext(context.Background(), middlewaretest.WithHeader("User-Agent", "okhttp/4.9.3"))
+	_, err := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if d, ok := FromContext(ctx); !ok || d.App != "okhttp" {
+			t.Errorf("unexpected device %+v", d)
+		}
+		return req, nil
+	})(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,25 +2,120 @@ package validate
 
 import (
 	"context"
+	"strings"
 
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 type validator interface {
 	Validate() error
 }
 
-// Validator is a validator middleware.
+// allValidator is implemented by the messages of protoc-gen-validate v0.6.2+,
+// reporting all the invalid fields at once.
+type allValidator interface {
+	ValidateAll() error
+}
+
+// fieldError is implemented by the field errors of protoc-gen-validate.
+type fieldError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// multiError is implemented by the errors of ValidateAll.
+type multiError interface {
+	AllErrors() []error
+}
+
+// Validator is a validator middleware. The invalid requests are rejected with
+// a bad request, its metadata maps the JSON path of each invalid field, e.g.
+// "address.zipCode", to the reason.
 func Validator() middleware.Middleware {
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
-			if v, ok := req.(validator); ok {
-				if err := v.Validate(); err != nil {
-					return nil, errors.BadRequest("VALIDATOR", err.Error())
+			var verr error
+			if v, ok := req.(allValidator); ok {
+				verr = v.ValidateAll()
+			} else if v, ok := req.(validator); ok {
+				verr = v.Validate()
+			}
+			if verr != nil {
+				e := errors.BadRequest("VALIDATOR", verr.Error())
+				var md protoreflect.MessageDescriptor
+				if m, ok := req.(proto.Message); ok {
+					md = m.ProtoReflect().Descriptor()
+				}
+				if fields := fieldErrors(verr, md, "", nil); len(fields) > 0 {
+					e = e.WithMetadata(fields)
 				}
+				return nil, e
 			}
 			return handler(ctx, req)
 		}
 	}
 }
+
+// fieldErrors collects the reasons of the invalid fields of err in the message
+// described by md, the nested messages prefixed by the path of their field.
+func fieldErrors(err error, md protoreflect.MessageDescriptor, prefix string, fields map[string]string) map[string]string {
+	switch e := err.(type) {
+	case multiError:
+		for _, err := range e.AllErrors() {
+			fields = fieldErrors(err, md, prefix, fields)
+		}
+	case fieldError:
+		name, fd := jsonName(md, e.Field())
+		path := prefix + name
+		if cause := e.Cause(); cause != nil {
+			var nested protoreflect.MessageDescriptor
+			if fd != nil {
+				nested = fd.Message()
+				if fd.IsMap() {
+					nested = fd.MapValue().Message()
+				}
+			}
+			if _, ok := cause.(fieldError); ok {
+				return fieldErrors(cause, nested, path+".", fields)
+			}
+			if _, ok := cause.(multiError); ok {
+				return fieldErrors(cause, nested, path+".", fields)
+			}
+		}
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+		fields[path] = e.Reason()
+	}
+	return fields
+}
+
+// jsonName returns the JSON name of a field named by PGV in CamelCase, e.g.
+// "FirstName", along with its descriptor, the index of a repeated field like
+// "Items[0]" is kept. It falls back to lowering the first letter if md has no
+// such field.
+func jsonName(md protoreflect.MessageDescriptor, field string) (string, protoreflect.FieldDescriptor) {
+	name, index := field, ""
+	if i := strings.IndexByte(field, '['); i >= 0 {
+		name, index = field[:i], field[i:]
+	}
+	if md != nil {
+		key := strings.ToLower(name)
+		fds := md.Fields()
+		for i := 0; i < fds.Len(); i++ {
+			fd := fds.Get(i)
+			if strings.ToLower(strings.ReplaceAll(string(fd.Name()), "_", "")) == key {
+				return fd.JSONName() + index, fd
+			}
+		}
+	}
+	if name == "" {
+		return field, nil
+	}
+	return strings.ToLower(name[:1]) + name[1:] + index, nil
+}
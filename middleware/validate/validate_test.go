@@ -3,9 +3,11 @@ package validate
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/testdata/complex"
 	"github.com/go-kratos/kratos/v2/middleware"
 )
 
@@ -41,3 +43,70 @@ func TestTable(t *testing.T) {
 		})
 	}
 }
+
+// fieldErr is a protoc-gen-validate field error.
+type fieldErr struct {
+	field  string
+	reason string
+	cause  error
+}
+
+func (e fieldErr) Field() string  { return e.field }
+func (e fieldErr) Reason() string { return e.reason }
+func (e fieldErr) Cause() error   { return e.cause }
+func (e fieldErr) Error() string  { return "invalid " + e.field + ": " + e.reason }
+
+type multiErr []error
+
+func (m multiErr) Error() string      { return fmt.Sprintf("%d errors", len(m)) }
+func (m multiErr) AllErrors() []error { return m }
+
+type user struct{}
+
+func (user) Validate() error { return fieldErr{field: "Name", reason: "value is required"} }
+
+func (user) ValidateAll() error {
+	return multiErr{
+		fieldErr{field: "Name", reason: "value is required"},
+		fieldErr{field: "Address", reason: "embedded message failed validation", cause: fieldErr{field: "ZipCode", reason: "value length must be 5 runes"}},
+	}
+}
+
+func TestFieldMetadata(t *testing.T) {
+	var mock middleware.Handler = func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	_, err := Validator()(mock)(context.Background(), user{})
+	e := errors.FromError(err)
+	if !errors.IsBadRequest(err) || len(e.Metadata) != 2 {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if e.Metadata["name"] != "value is required" || e.Metadata["address.zipCode"] != "value length must be 5 runes" {
+		t.Errorf("unexpected metadata %v", e.Metadata)
+	}
+}
+
+// complexRequest is a proto request whose fields have custom JSON names.
+type complexRequest struct {
+	*complex.Complex
+}
+
+func (complexRequest) Validate() error {
+	return multiErr{
+		fieldErr{field: "NoOne", reason: "value is required"},
+		fieldErr{field: "Simple", reason: "embedded message failed validation", cause: fieldErr{field: "Component", reason: "value is required"}},
+		fieldErr{field: "Simples[1]", reason: "value is required"},
+	}
+}
+
+func TestFieldMetadata_JSONName(t *testing.T) {
+	var mock middleware.Handler = func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	_, err := Validator()(mock)(context.Background(), complexRequest{&complex.Complex{}})
+	e := errors.FromError(err)
+	want := map[string]string{
+		"numberOne":             "value is required",
+		"very_simple.component": "value is required",
+		"simples[1]":            "value is required",
+	}
+	if !reflect.DeepEqual(e.Metadata, want) {
+		t.Errorf("expect metadata %v, got %v", want, e.Metadata)
+	}
+}
@@ -0,0 +1,346 @@
+// Package operations implements the google.longrunning operations: handlers
+// start slow tasks with Manager.Start and return the operation, the clients
+// poll it with the Operations service implemented by Manager:
+//
+//	paginator, err := pagination.New(secret)
+//	ops := operations.New(operations.NewMemoryStore(), operations.WithPaginator(paginator))
+//	longrunning.RegisterOperationsServer(grpcSrv, ops)
+//	app := kratos.New(kratos.BeforeStop(ops.Stop), ...)
+//
+//	func (s *Service) Export(ctx context.Context, req *pb.ExportRequest) (*longrunning.Operation, error) {
+//		return s.ops.Start(ctx, nil, func(ctx context.Context, update operations.UpdateFunc) (proto.Message, error) {
+//			return s.export(ctx, req)
+//		})
+//	}
+package operations
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/filtering"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/pagination"
+)
+
+var _ longrunning.OperationsServer = (*Manager)(nil)
+
+var (
+	// ErrNotFound is returned when an operation doesn't exist.
+	ErrNotFound = errors.NotFound("OPERATION_NOT_FOUND", "operation not found")
+	// ErrStopped is returned when an operation is started once the manager is stopped.
+	ErrStopped = errors.ServiceUnavailable("OPERATION_STOPPED", "operations manager stopped")
+	// ErrNoPaginator is returned by ListOperations when no paginator is configured.
+	ErrNoPaginator = errors.InternalServer("OPERATION_NO_PAGINATOR", "operations: no paginator, see WithPaginator")
+)
+
+// UpdateFunc updates the metadata of the running operation, e.g. its progress.
+type UpdateFunc func(metadata proto.Message)
+
+// Task is a slow task run by an operation, its reply is the operation response.
+type Task func(ctx context.Context, update UpdateFunc) (proto.Message, error)
+
+// Option is operations manager option.
+type Option func(*Manager)
+
+// WithPrefix with the prefix of the operation names, default is "operations/".
+func WithPrefix(prefix string) Option {
+	return func(m *Manager) {
+		m.prefix = prefix
+	}
+}
+
+// WithPaginator with the paginator of ListOperations, required to list the
+// operations, its secret must be shared by the instances serving them.
+func WithPaginator(p *pagination.Paginator) Option {
+	return func(m *Manager) {
+		m.paginator = p
+	}
+}
+
+// WithPollInterval with the interval WaitOperation polls the operations run by other instances, default is 1s.
+func WithPollInterval(d time.Duration) Option {
+	return func(m *Manager) {
+		m.interval = d
+	}
+}
+
+// WithLogger with the logger of the operation store errors.
+func WithLogger(logger log.Logger) Option {
+	return func(m *Manager) {
+		m.log = log.NewHelper(logger)
+	}
+}
+
+type running struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Manager runs the operations and implements the Operations service.
+type Manager struct {
+	longrunning.UnimplementedOperationsServer
+
+	store     Store
+	prefix    string
+	paginator *pagination.Paginator
+	interval  time.Duration
+	log       *log.Helper
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	running map[string]*running
+	stopped bool
+}
+
+// New new an operations manager storing the operations in store.
+func New(store Store, opts ...Option) *Manager {
+	m := &Manager{
+		store:    store,
+		prefix:   "operations/",
+		interval: time.Second,
+		log:      log.NewHelper(log.GetLogger()),
+		running:  make(map[string]*running),
+	}
+	for _, o := range opts {
+		o(m)
+	}
+	// the tasks outlive the requests, they are canceled by Stop.
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	return m
+}
+
+// Start starts an operation running task in the background and returns it,
+// metadata is the initial metadata of the operation and may be nil.
+func (m *Manager) Start(ctx context.Context, metadata proto.Message, task Task) (*longrunning.Operation, error) {
+	op := &longrunning.Operation{Name: m.prefix + uuid.NewString()}
+	if metadata != nil {
+		md, err := anypb.New(metadata)
+		if err != nil {
+			return nil, err
+		}
+		op.Metadata = md
+	}
+	// the task is registered with the check, so that Stop waits for it
+	// as soon as it writes to the store.
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil, ErrStopped
+	}
+	taskCtx, cancel := context.WithCancel(m.ctx)
+	r := &running{cancel: cancel, done: make(chan struct{})}
+	m.running[op.Name] = r
+	m.mu.Unlock()
+	if err := m.store.Put(ctx, op); err != nil {
+		m.mu.Lock()
+		delete(m.running, op.Name)
+		m.mu.Unlock()
+		cancel()
+		close(r.done)
+		return nil, err
+	}
+
+	started := proto.Clone(op).(*longrunning.Operation)
+	go func() {
+		defer close(r.done)
+		defer cancel()
+		var mu sync.Mutex
+		update := func(metadata proto.Message) {
+			md, err := anypb.New(metadata)
+			if err != nil {
+				m.log.Errorf("operations: %s metadata: %v", op.Name, err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			op.Metadata = md
+			m.put(op)
+		}
+		reply, err := task(taskCtx, update)
+		mu.Lock()
+		defer mu.Unlock()
+		op.Done = true
+		switch {
+		case taskCtx.Err() == context.Canceled:
+			op.Result = &longrunning.Operation_Error{Error: status.New(codes.Canceled, "operation canceled").Proto()}
+		case err != nil:
+			op.Result = &longrunning.Operation_Error{Error: status.Convert(err).Proto()}
+		default:
+			res, err := anypb.New(reply)
+			if err != nil {
+				op.Result = &longrunning.Operation_Error{Error: status.Convert(err).Proto()}
+			} else {
+				op.Result = &longrunning.Operation_Response{Response: res}
+			}
+		}
+		m.put(op)
+		m.mu.Lock()
+		delete(m.running, op.Name)
+		m.mu.Unlock()
+	}()
+	return started, nil
+}
+
+func (m *Manager) put(op *longrunning.Operation) {
+	if err := m.store.Put(context.Background(), op); err != nil {
+		m.log.Errorf("operations: put %s: %v", op.Name, err)
+	}
+}
+
+// GetOperation gets the latest state of an operation.
+func (m *Manager) GetOperation(ctx context.Context, req *longrunning.GetOperationRequest) (*longrunning.Operation, error) {
+	return m.store.Get(ctx, req.Name)
+}
+
+// ListOperations lists the operations under the name of the request, filtered by
+// done, e.g. "done = false".
+func (m *Manager) ListOperations(ctx context.Context, req *longrunning.ListOperationsRequest) (*longrunning.ListOperationsResponse, error) {
+	if m.paginator == nil {
+		return nil, ErrNoPaginator
+	}
+	done, err := doneFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	page, err := m.paginator.Page(req, req.Name, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	prefix := req.Name
+	if prefix != "" && prefix[len(prefix)-1] != '/' {
+		prefix += "/"
+	}
+	res := &longrunning.ListOperationsResponse{}
+	offset := page.Offset
+	for len(res.Operations) < int(page.Size) {
+		ops, err := m.store.List(ctx, prefix, offset, page.Size)
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range ops {
+			offset++
+			if done == nil || op.Done == *done {
+				res.Operations = append(res.Operations, op)
+				if len(res.Operations) == int(page.Size) {
+					break
+				}
+			}
+		}
+		if len(ops) < int(page.Size) {
+			break
+		}
+	}
+	page.Offset = offset - int64(len(res.Operations))
+	res.NextPageToken = m.paginator.NextPageToken(page, len(res.Operations), "")
+	return res, nil
+}
+
+// doneFilter parses the filters supported by ListOperations: "", "done = true" and "done = false".
+func doneFilter(filter string) (*bool, error) {
+	expr, err := filtering.Parse(filter)
+	if err != nil || expr == nil {
+		return nil, err
+	}
+	if r, ok := expr.(*filtering.Restriction); ok && r.Comparator == "=" && r.Comparable.String() == "done" && r.Arg != nil {
+		switch r.Arg.String() {
+		case "true":
+			done := true
+			return &done, nil
+		case "false":
+			done := false
+			return &done, nil
+		}
+	}
+	return nil, filtering.InvalidFilter("unsupported filter %q, only done = true|false is", filter)
+}
+
+// DeleteOperation deletes an operation, it doesn't cancel it.
+func (m *Manager) DeleteOperation(ctx context.Context, req *longrunning.DeleteOperationRequest) (*emptypb.Empty, error) {
+	if err := m.store.Delete(ctx, req.Name); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// CancelOperation cancels an operation run by this instance.
+func (m *Manager) CancelOperation(ctx context.Context, req *longrunning.CancelOperationRequest) (*emptypb.Empty, error) {
+	m.mu.Lock()
+	r, ok := m.running[req.Name]
+	m.mu.Unlock()
+	if ok {
+		r.cancel()
+		return &emptypb.Empty{}, nil
+	}
+	if _, err := m.store.Get(ctx, req.Name); err != nil {
+		return nil, err
+	}
+	// done, or run by another instance.
+	return &emptypb.Empty{}, nil
+}
+
+// WaitOperation waits until an operation is done or the timeout of the request, at most the deadline of ctx.
+func (m *Manager) WaitOperation(ctx context.Context, req *longrunning.WaitOperationRequest) (*longrunning.Operation, error) {
+	if d := req.Timeout.AsDuration(); req.Timeout != nil && d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	for {
+		op, err := m.store.Get(ctx, req.Name)
+		if err != nil || op.Done {
+			return op, err
+		}
+		m.mu.Lock()
+		r, ok := m.running[req.Name]
+		m.mu.Unlock()
+		wait := time.After(m.interval)
+		if ok {
+			select {
+			case <-r.done:
+			case <-wait:
+			case <-ctx.Done():
+				return op, nil
+			}
+			continue
+		}
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return op, nil
+		}
+	}
+}
+
+// Stop cancels the running operations and waits until they are done and
+// stored, at most the deadline of ctx, the operations started afterwards fail.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	m.stopped = true
+	running := make([]*running, 0, len(m.running))
+	for _, r := range m.running {
+		running = append(running, r)
+	}
+	m.mu.Unlock()
+	m.cancel()
+	for _, r := range running {
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
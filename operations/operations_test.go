@@ -0,0 +1,158 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/pagination"
+)
+
+func TestOperation(t *testing.T) {
+	ctx := context.Background()
+	m := New(NewMemoryStore())
+	release := make(chan struct{})
+	op, err := m.Start(ctx, wrapperspb.Int32(0), func(ctx context.Context, update UpdateFunc) (proto.Message, error) {
+		update(wrapperspb.Int32(50))
+		<-release
+		return wrapperspb.String("done"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Done {
+		t.Fatal("operation done before the task")
+	}
+	close(release)
+	op, err = m.WaitOperation(ctx, &longrunning.WaitOperationRequest{Name: op.Name, Timeout: durationpb.New(time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !op.Done {
+		t.Fatal("operation not done")
+	}
+	res := new(wrapperspb.StringValue)
+	if err := op.GetResponse().UnmarshalTo(res); err != nil || res.Value != "done" {
+		t.Errorf("response = %v, %v", res, err)
+	}
+	md := new(wrapperspb.Int32Value)
+	if err := op.GetMetadata().UnmarshalTo(md); err != nil || md.Value != 50 {
+		t.Errorf("metadata = %v, %v", md, err)
+	}
+}
+
+func TestOperationError(t *testing.T) {
+	ctx := context.Background()
+	m := New(NewMemoryStore())
+	op, _ := m.Start(ctx, nil, func(ctx context.Context, update UpdateFunc) (proto.Message, error) {
+		return nil, kerrors.BadRequest("BAD", "bad")
+	})
+	op, _ = m.WaitOperation(ctx, &longrunning.WaitOperationRequest{Name: op.Name})
+	if codes.Code(op.GetError().GetCode()) != codes.InvalidArgument {
+		t.Errorf("error = %v", op.GetError())
+	}
+}
+
+func TestCancelOperation(t *testing.T) {
+	ctx := context.Background()
+	m := New(NewMemoryStore())
+	op, _ := m.Start(ctx, nil, func(ctx context.Context, update UpdateFunc) (proto.Message, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if _, err := m.CancelOperation(ctx, &longrunning.CancelOperationRequest{Name: op.Name}); err != nil {
+		t.Fatal(err)
+	}
+	op, _ = m.WaitOperation(ctx, &longrunning.WaitOperationRequest{Name: op.Name})
+	if codes.Code(op.GetError().GetCode()) != codes.Canceled {
+		t.Errorf("error = %v", op.GetError())
+	}
+	if _, err := m.DeleteOperation(ctx, &longrunning.DeleteOperationRequest{Name: op.Name}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.GetOperation(ctx, &longrunning.GetOperationRequest{Name: op.Name}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get deleted operation: %v", err)
+	}
+}
+
+func TestListOperations(t *testing.T) {
+	ctx := context.Background()
+	p, err := pagination.New([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(NewMemoryStore(), WithPaginator(p))
+	release := make(chan struct{})
+	defer close(release)
+	for i := 0; i < 5; i++ {
+		task := func(ctx context.Context, update UpdateFunc) (proto.Message, error) {
+			return wrapperspb.Bool(true), nil
+		}
+		if i%2 == 0 {
+			task = func(ctx context.Context, update UpdateFunc) (proto.Message, error) {
+				<-release
+				return wrapperspb.Bool(true), nil
+			}
+		}
+		op, _ := m.Start(ctx, nil, task)
+		if i%2 == 1 {
+			_, _ = m.WaitOperation(ctx, &longrunning.WaitOperationRequest{Name: op.Name})
+		}
+	}
+
+	var (
+		names []string
+		token string
+	)
+	for {
+		res, err := m.ListOperations(ctx, &longrunning.ListOperationsRequest{Name: "operations", Filter: "done = false", PageSize: 2, PageToken: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, op := range res.Operations {
+			if op.Done {
+				t.Errorf("%s is done", op.Name)
+			}
+			names = append(names, op.Name)
+		}
+		if token = res.NextPageToken; token == "" {
+			break
+		}
+	}
+	if len(names) != 3 {
+		t.Errorf("listed %d running operations, want 3", len(names))
+	}
+	if _, err := m.ListOperations(ctx, &longrunning.ListOperationsRequest{Name: "operations", Filter: "name = x"}); err == nil {
+		t.Error("unsupported filter accepted")
+	}
+}
+
+func TestStop(t *testing.T) {
+	ctx := context.Background()
+	m := New(NewMemoryStore())
+	op, _ := m.Start(ctx, nil, func(ctx context.Context, update UpdateFunc) (proto.Message, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err := m.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	op, _ = m.GetOperation(ctx, &longrunning.GetOperationRequest{Name: op.Name})
+	if !op.Done || codes.Code(op.GetError().GetCode()) != codes.Canceled {
+		t.Errorf("expect the operation canceled once stopped, got %v", op)
+	}
+	if _, err := m.Start(ctx, nil, nil); !errors.Is(err, ErrStopped) {
+		t.Errorf("expect %v, got %v", ErrStopped, err)
+	}
+	if _, err := m.ListOperations(ctx, &longrunning.ListOperationsRequest{Name: "operations"}); !errors.Is(err, ErrNoPaginator) {
+		t.Errorf("expect %v, got %v", ErrNoPaginator, err)
+	}
+}
@@ -0,0 +1,92 @@
+package operations
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// Store stores the operations.
+type Store interface {
+	// Put creates or updates an operation.
+	Put(ctx context.Context, op *longrunning.Operation) error
+	// Get returns the operation, or ErrNotFound.
+	Get(ctx context.Context, name string) (*longrunning.Operation, error)
+	// List returns at most limit operations whose name starts with prefix,
+	// in name order after skipping offset of them.
+	List(ctx context.Context, prefix string, offset int64, limit int32) ([]*longrunning.Operation, error)
+	// Delete deletes the operation, or returns ErrNotFound.
+	Delete(ctx context.Context, name string) error
+}
+
+// MemoryStore is an in-memory Store, it is only suitable for a single instance.
+type MemoryStore struct {
+	mu  sync.RWMutex
+	ops map[string]*longrunning.Operation
+}
+
+// NewMemoryStore returns an in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{ops: make(map[string]*longrunning.Operation)}
+}
+
+// Put creates or updates an operation.
+func (s *MemoryStore) Put(_ context.Context, op *longrunning.Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ops[op.Name] = proto.Clone(op).(*longrunning.Operation)
+	return nil
+}
+
+// Get returns the operation.
+func (s *MemoryStore) Get(_ context.Context, name string) (*longrunning.Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	op, ok := s.ops[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(op).(*longrunning.Operation), nil
+}
+
+// List returns the operations whose name starts with prefix.
+func (s *MemoryStore) List(_ context.Context, prefix string, offset int64, limit int32) ([]*longrunning.Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.ops))
+	for name := range s.ops {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if offset >= int64(len(names)) {
+		return nil, nil
+	}
+	names = names[offset:]
+	if int(limit) < len(names) {
+		names = names[:limit]
+	}
+	ops := make([]*longrunning.Operation, len(names))
+	for i, name := range names {
+		ops[i] = proto.Clone(s.ops[name]).(*longrunning.Operation)
+	}
+	return ops, nil
+}
+
+// Delete deletes the operation.
+func (s *MemoryStore) Delete(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ops[name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.ops, name)
+	return nil
+}
@@ -6,8 +6,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/guard"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/report"
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
@@ -30,6 +32,14 @@ type options struct {
 	registrarTimeout time.Duration
 	stopTimeout      time.Duration
 	servers          []transport.Server
+	tracker          *transport.Tracker
+	guard            *guard.Guard
+	reporter         report.Reporter
+
+	beforeStart []func(context.Context) error
+	beforeStop  []func(context.Context) error
+	afterStart  []func(context.Context) error
+	afterStop   []func(context.Context) error
 }
 
 // ID with service id.
@@ -74,6 +84,18 @@ func Server(srv ...transport.Server) Option {
 	return func(o *options) { o.servers = srv }
 }
 
+// Clients with the tracker of the clients closed after the servers stop,
+// see the WithTracker options of the transport clients.
+func Clients(t *transport.Tracker) Option {
+	return func(o *options) { o.tracker = t }
+}
+
+// Guard with the guard checking the hazards of the servers and the clients
+// before the servers start.
+func Guard(g *guard.Guard) Option {
+	return func(o *options) { o.guard = g }
+}
+
 // Signal with exit signals.
 func Signal(sigs ...os.Signal) Option {
 	return func(o *options) { o.sigs = sigs }
@@ -93,3 +115,38 @@ func RegistrarTimeout(t time.Duration) Option {
 func StopTimeout(t time.Duration) Option {
 	return func(o *options) { o.stopTimeout = t }
 }
+
+// Reporter with reporter notified of application errors.
+func Reporter(r report.Reporter) Option {
+	return func(o *options) { o.reporter = r }
+}
+
+// BeforeStart run funcs before the servers start, an error aborts the run.
+func BeforeStart(fn func(context.Context) error) Option {
+	return func(o *options) {
+		o.beforeStart = append(o.beforeStart, fn)
+	}
+}
+
+// AfterStart run funcs after the servers start and the instance is registered,
+// an error stops the app.
+func AfterStart(fn func(context.Context) error) Option {
+	return func(o *options) {
+		o.afterStart = append(o.afterStart, fn)
+	}
+}
+
+// BeforeStop run funcs before the instance is deregistered and the servers
+// stop, once even if the app is stopped again, the errors are logged.
+func BeforeStop(fn func(context.Context) error) Option {
+	return func(o *options) {
+		o.beforeStop = append(o.beforeStop, fn)
+	}
+}
+
+// AfterStop run funcs after the servers stop and the clients are closed.
+func AfterStop(fn func(context.Context) error) Option {
+	return func(o *options) {
+		o.afterStop = append(o.afterStop, fn)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	xlog "github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/report"
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
@@ -143,3 +144,16 @@ func TestRegistrarTimeout(t *testing.T) {
 		t.Fatal("o.registrarTimeout is not equal to v")
 	}
 }
+
+type mockReporter struct{}
+
+func (m *mockReporter) Report(ctx context.Context, event *report.Event) {}
+
+func TestReporter(t *testing.T) {
+	o := &options{}
+	v := &mockReporter{}
+	Reporter(v)(o)
+	if !reflect.DeepEqual(v, o.reporter) {
+		t.Fatal("o.reporter is not equal to v")
+	}
+}
@@ -0,0 +1,206 @@
+// Package pagination implements the pagination of the list endpoints
+// following AIP-158: page sizes are clamped and page tokens are opaque,
+// signed with HMAC and bound to the other parameters of the request.
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrInvalidPageToken is returned when a page token is malformed, tampered,
+// expired or used with different request parameters.
+var ErrInvalidPageToken = errors.BadRequest("INVALID_PAGE_TOKEN", "page token is invalid")
+
+// ErrShortSecret is returned by New when the secret is shorter than 16 bytes,
+// the page tokens signed with it could be forged.
+var ErrShortSecret = stderrors.New("pagination: the secret must be at least 16 bytes")
+
+// ListRequest is implemented by the generated list requests.
+type ListRequest interface {
+	GetPageSize() int32
+	GetPageToken() string
+}
+
+// ListResponse is implemented by the generated list responses, which have a
+// next_page_token string field.
+type ListResponse interface {
+	proto.Message
+	GetNextPageToken() string
+}
+
+// Page is the page requested.
+type Page struct {
+	// Size is the clamped page size.
+	Size int32
+	// Offset is the number of items already listed.
+	Offset int64
+	// Key is the key of the last item listed, for keyset pagination.
+	Key string
+
+	query string
+}
+
+// Option is paginator option.
+type Option func(*Paginator)
+
+// WithDefaultPageSize with the page size used when the request has none, default is 20.
+func WithDefaultPageSize(size int32) Option {
+	return func(p *Paginator) {
+		p.defaultSize = size
+	}
+}
+
+// WithMaxPageSize with the maximum page size, default is 100.
+func WithMaxPageSize(size int32) Option {
+	return func(p *Paginator) {
+		p.maxSize = size
+	}
+}
+
+// WithTTL with the validity of the page tokens, default is 0, no expiry.
+func WithTTL(ttl time.Duration) Option {
+	return func(p *Paginator) {
+		p.ttl = ttl
+	}
+}
+
+// Paginator parses list requests and builds next page tokens.
+type Paginator struct {
+	secret      []byte
+	defaultSize int32
+	maxSize     int32
+	ttl         time.Duration
+	now         func() time.Time
+}
+
+// New new a paginator signing the page tokens with secret of at least 16
+// random bytes.
+func New(secret []byte, opts ...Option) (*Paginator, error) {
+	if len(secret) < 16 {
+		return nil, ErrShortSecret
+	}
+	p := &Paginator{
+		secret:      secret,
+		defaultSize: 20,
+		maxSize:     100,
+		now:         time.Now,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p, nil
+}
+
+// PageSize clamps size between 1 and the maximum page size, 0 is the default page size.
+func (p *Paginator) PageSize(size int32) int32 {
+	switch {
+	case size <= 0:
+		return p.defaultSize
+	case size > p.maxSize:
+		return p.maxSize
+	}
+	return size
+}
+
+// Page returns the page requested by req, query are the other parameters of
+// the request, e.g. the filter and the order, which the page token is bound to.
+func (p *Paginator) Page(req ListRequest, query ...string) (Page, error) {
+	page := Page{Size: p.PageSize(req.GetPageSize()), query: strings.Join(query, "\x00")}
+	token := req.GetPageToken()
+	if token == "" {
+		return page, nil
+	}
+	c, err := p.decode(token)
+	if err != nil || c.Query != checksum(page.query) {
+		return Page{}, ErrInvalidPageToken
+	}
+	if c.Expire > 0 && p.now().Unix() > c.Expire {
+		return Page{}, ErrInvalidPageToken
+	}
+	page.Offset, page.Key = c.Offset, c.Key
+	return page, nil
+}
+
+// NextPageToken returns the token of the page following page, count is the
+// number of items listed in page and key the key of the last one. It returns
+// an empty token when page is the last one.
+func (p *Paginator) NextPageToken(page Page, count int, key string) string {
+	if count < int(page.Size) {
+		return ""
+	}
+	c := cursor{Offset: page.Offset + int64(count), Key: key, Query: checksum(page.query)}
+	if p.ttl > 0 {
+		c.Expire = p.now().Add(p.ttl).Unix()
+	}
+	return p.encode(c)
+}
+
+// SetNextPageToken sets the next_page_token of res to the token of the page
+// following page, see NextPageToken.
+func (p *Paginator) SetNextPageToken(res ListResponse, page Page, count int, key string) error {
+	m := res.ProtoReflect()
+	fd := m.Descriptor().Fields().ByName("next_page_token")
+	if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+		return fmt.Errorf("pagination: %s has no next_page_token string field", m.Descriptor().FullName())
+	}
+	m.Set(fd, protoreflect.ValueOfString(p.NextPageToken(page, count, key)))
+	return nil
+}
+
+type cursor struct {
+	Offset int64  `json:"o,omitempty"`
+	Key    string `json:"k,omitempty"`
+	Query  string `json:"q,omitempty"`
+	Expire int64  `json:"e,omitempty"`
+}
+
+func (p *Paginator) encode(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(p.sign(data))
+}
+
+func (p *Paginator) decode(token string) (c cursor, err error) {
+	i := strings.IndexByte(token, '.')
+	if i < 0 {
+		return c, ErrInvalidPageToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token[:i])
+	if err != nil {
+		return c, err
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil {
+		return c, err
+	}
+	if !hmac.Equal(mac, p.sign(data)) {
+		return c, ErrInvalidPageToken
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+func (p *Paginator) sign(data []byte) []byte {
+	h := hmac.New(sha256.New, p.secret)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func checksum(query string) string {
+	if query == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(query))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
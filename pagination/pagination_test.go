@@ -0,0 +1,114 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/longrunning"
+)
+
+type listRequest struct {
+	size  int32
+	token string
+}
+
+func (r *listRequest) GetPageSize() int32   { return r.size }
+func (r *listRequest) GetPageToken() string { return r.token }
+
+var secret = []byte("0123456789abcdef")
+
+func newPaginator(t *testing.T, opts ...Option) *Paginator {
+	t.Helper()
+	p, err := New(secret, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestNew_ShortSecret(t *testing.T) {
+	for _, short := range [][]byte{nil, {}, []byte("secret")} {
+		if _, err := New(short); err != ErrShortSecret {
+			t.Errorf("%q: expect %v, got %v", short, ErrShortSecret, err)
+		}
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	p := newPaginator(t, WithDefaultPageSize(10), WithMaxPageSize(50))
+	for size, expect := range map[int32]int32{0: 10, -1: 10, 20: 20, 100: 50} {
+		if got := p.PageSize(size); got != expect {
+			t.Errorf("PageSize(%d): expect %d, got %d", size, expect, got)
+		}
+	}
+}
+
+func TestPaginator(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	p := newPaginator(t, WithTTL(time.Hour))
+	p.now = func() time.Time { return now }
+
+	page, err := p.Page(&listRequest{size: 2}, "name=foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := p.NextPageToken(page, 2, "k2")
+	if token == "" {
+		t.Fatal("expect a next page token")
+	}
+	next, err := p.Page(&listRequest{size: 2, token: token}, "name=foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Offset != 2 || next.Key != "k2" {
+		t.Errorf("unexpected page %+v", next)
+	}
+	if p.NextPageToken(next, 1, "k3") != "" {
+		t.Error("expect no token after the last page")
+	}
+
+	invalid := []struct {
+		name  string
+		token string
+		query string
+	}{
+		{"tampered", token[:len(token)-2] + "xx", "name=foo"},
+		{"other query", token, "name=bar"},
+		{"malformed", "token", "name=foo"},
+	}
+	for _, test := range invalid {
+		if _, err := p.Page(&listRequest{token: test.token}, test.query); err != ErrInvalidPageToken {
+			t.Errorf("%s: expect invalid page token, got %v", test.name, err)
+		}
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := p.Page(&listRequest{token: token}, "name=foo"); err != ErrInvalidPageToken {
+		t.Errorf("expired: expect invalid page token, got %v", err)
+	}
+}
+
+type noToken struct {
+	*longrunning.Operation
+}
+
+func (noToken) GetNextPageToken() string { return "" }
+
+func TestSetNextPageToken(t *testing.T) {
+	p := newPaginator(t)
+	req := &longrunning.ListOperationsRequest{PageSize: 2}
+	page, err := p.Page(req, req.Filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := &longrunning.ListOperationsResponse{}
+	if err = p.SetNextPageToken(res, page, 2, ""); err != nil {
+		t.Fatal(err)
+	}
+	req.PageToken = res.NextPageToken
+	if next, err := p.Page(req, req.Filter); err != nil || next.Offset != 2 {
+		t.Errorf("got %+v %v, want the next page", next, err)
+	}
+	if err = p.SetNextPageToken(noToken{&longrunning.Operation{}}, page, 2, ""); err == nil {
+		t.Error("expect an error without a next_page_token field")
+	}
+}
@@ -0,0 +1,147 @@
+// Package plugins lets platform teams inject company-standard middleware and
+// servers into services. Plugins register themselves in their init, either
+// compiled in with a blank import or loaded at runtime from Go plugins (.so):
+//
+//	func init() {
+//		plugins.RegisterMiddleware("audit", audit.Server)
+//	}
+//
+// Services then only depend on this package:
+//
+//	_ = plugins.LoadEnv()
+//	srv := http.NewServer(http.Middleware(plugins.Middleware()...))
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// EnvKey is the environment variable listing the plugins loaded by LoadEnv,
+// separated by the OS path list separator.
+const EnvKey = "KRATOS_PLUGINS"
+
+// ServerFactory creates a server added to the app.
+type ServerFactory func() (transport.Server, error)
+
+type registered struct {
+	name    string
+	factory interface{}
+}
+
+var (
+	mu          sync.RWMutex
+	middlewares []registered
+	servers     []registered
+)
+
+// RegisterMiddleware registers a middleware factory under name, the middleware
+// are returned in their registration order. Registering a name twice replaces it.
+func RegisterMiddleware(name string, factory func() middleware.Middleware) {
+	if factory == nil {
+		panic("plugins: cannot register a nil middleware factory")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	middlewares = register(middlewares, name, factory)
+}
+
+// RegisterServer registers a server factory under name. Registering a name twice replaces it.
+func RegisterServer(name string, factory ServerFactory) {
+	if factory == nil {
+		panic("plugins: cannot register a nil server factory")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	servers = register(servers, name, factory)
+}
+
+func register(rs []registered, name string, factory interface{}) []registered {
+	for i, r := range rs {
+		if r.name == name {
+			rs[i].factory = factory
+			return rs
+		}
+	}
+	return append(rs, registered{name: name, factory: factory})
+}
+
+// Names returns the names of the registered middleware and servers.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(middlewares)+len(servers))
+	for _, r := range middlewares {
+		names = append(names, r.name)
+	}
+	for _, r := range servers {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// Middleware returns new instances of the registered middleware.
+func Middleware() []middleware.Middleware {
+	mu.RLock()
+	defer mu.RUnlock()
+	ms := make([]middleware.Middleware, 0, len(middlewares))
+	for _, r := range middlewares {
+		ms = append(ms, r.factory.(func() middleware.Middleware)())
+	}
+	return ms
+}
+
+// Servers returns new instances of the registered servers,
+// pass them to the app with kratos.Server.
+func Servers() ([]transport.Server, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	srvs := make([]transport.Server, 0, len(servers))
+	for _, r := range servers {
+		srv, err := r.factory.(ServerFactory)()
+		if err != nil {
+			return nil, fmt.Errorf("plugins: server %s: %w", r.name, err)
+		}
+		srvs = append(srvs, srv)
+	}
+	return srvs, nil
+}
+
+// Load opens the Go plugins at paths, they register themselves in their init.
+// A directory loads all the *.so files it contains.
+func Load(paths ...string) error {
+	for _, path := range paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		files := []string{path}
+		if fi.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(path, "*.so")); err != nil {
+				return err
+			}
+		}
+		for _, file := range files {
+			if _, err := plugin.Open(file); err != nil {
+				return fmt.Errorf("plugins: load %s: %w", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadEnv loads the Go plugins listed in the EnvKey environment variable.
+func LoadEnv() error {
+	v := os.Getenv(EnvKey)
+	if v == "" {
+		return nil
+	}
+	return Load(strings.Split(v, string(os.PathListSeparator))...)
+}
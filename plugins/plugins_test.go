@@ -0,0 +1,57 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func tag(name string) func() middleware.Middleware {
+	return func() middleware.Middleware {
+		return func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				return handler(ctx, req.(string)+name)
+			}
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	RegisterMiddleware("a", tag("x"))
+	RegisterMiddleware("b", tag("b"))
+	RegisterMiddleware("a", tag("a"))
+	RegisterServer("admin", func() (transport.Server, error) { return nil, errors.New("no port") })
+	defer func() { middlewares, servers = nil, nil }()
+
+	if names := Names(); !reflect.DeepEqual(names, []string{"a", "b", "admin"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+	h := middleware.Chain(Middleware()...)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	})
+	reply, _ := h(context.Background(), "")
+	if reply != "ab" {
+		t.Errorf("expect ab, got %v", reply)
+	}
+	if _, err := Servers(); err == nil {
+		t.Error("expect the server factory error")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if err := Load(t.TempDir()); err != nil {
+		t.Errorf("expect an empty directory to load, got %v", err)
+	}
+	if err := Load("not_found.so"); err == nil {
+		t.Error("expect an error loading a missing plugin")
+	}
+	os.Setenv(EnvKey, "")
+	if err := LoadEnv(); err != nil {
+		t.Error(err)
+	}
+}
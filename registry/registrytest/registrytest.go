@@ -0,0 +1,277 @@
+// Package registrytest provides the conformance suite of the registries, run
+// by the tests of each registry:
+//
+//	func TestConformance(t *testing.T) {
+//		r := etcd.New(client)
+//		registrytest.Conformance(t, r, registry.Resilient(r))
+//	}
+package registrytest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// Timeout is the time the instances are expected to be watched within.
+var Timeout = 10 * time.Second
+
+var seq int64
+
+// Conformance runs the conformance suite, r and d of the same registry:
+//
+//   - Register: the registered instances are resolved by GetService and
+//     watched by Watch once changed, the watchers are stopped by Stop.
+//   - Registered: the instances registered before Watch are returned by the
+//     first Next.
+//   - Restart: an instance registered again, e.g. restarted with another
+//     endpoint, is updated in place.
+//   - Concurrency: the instances registered and deregistered concurrently are
+//     watched by all the watchers.
+func Conformance(t *testing.T, r registry.Registrar, d registry.Discovery) {
+	t.Run("Register", func(t *testing.T) { testRegister(t, r, d) })
+	t.Run("Registered", func(t *testing.T) { testRegistered(t, r, d) })
+	t.Run("Restart", func(t *testing.T) { testRestart(t, r, d) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, r, d) })
+}
+
+// Service returns a unique service name for the tests.
+func Service() string {
+	return fmt.Sprintf("registrytest-%d-%d", time.Now().UnixNano(), atomic.AddInt64(&seq, 1))
+}
+
+// Instance returns the instance id of the service with an endpoint of port.
+func Instance(name, id string, port int) *registry.ServiceInstance {
+	return &registry.ServiceInstance{
+		ID:        id,
+		Name:      name,
+		Version:   "v1.0.0",
+		Metadata:  map[string]string{"zone": "zone-" + id},
+		Endpoints: []string{"grpc://127.0.0.1:" + strconv.Itoa(port)},
+	}
+}
+
+func testRegister(t *testing.T, r registry.Registrar, d registry.Discovery) {
+	ctx := context.Background()
+	name := Service()
+	ins1, ins2 := Instance(name, "1", 9000), Instance(name, "2", 9001)
+
+	w, err := d.Watch(ctx, name)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if err = r.Register(ctx, ins1); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	Next(t, w, "1")
+	if err = r.Register(ctx, ins2); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	Next(t, w, "1", "2")
+	if err = r.Deregister(ctx, ins1); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	Next(t, w, "2")
+
+	services, err := d.GetService(ctx, name)
+	if err != nil {
+		t.Fatalf("GetService: %v", err)
+	}
+	if got := instanceIDs(services); len(got) != 1 || got[0] != "2" {
+		t.Fatalf("GetService: expect instances [2], got %v", got)
+	}
+	if s := services[0]; s.Name != name || s.Version != ins2.Version || s.Metadata["zone"] != ins2.Metadata["zone"] || fmt.Sprint(s.Endpoints) != fmt.Sprint(ins2.Endpoints) {
+		t.Fatalf("GetService: expect instance %+v, got %+v", ins2, s)
+	}
+
+	if err = r.Deregister(ctx, ins2); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	if err = w.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.Next()
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		if err == nil {
+			t.Fatal("Next: expect an error once stopped")
+		}
+	case <-time.After(Timeout):
+		t.Fatal("Next: expect to return once stopped")
+	}
+}
+
+func testRegistered(t *testing.T, r registry.Registrar, d registry.Discovery) {
+	ctx := context.Background()
+	name := Service()
+	ins := Instance(name, "1", 9000)
+	if err := r.Register(ctx, ins); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	defer r.Deregister(ctx, ins) //nolint:errcheck
+	eventually(t, func() bool {
+		services, err := d.GetService(ctx, name)
+		return err == nil && len(services) == 1
+	}, "GetService: expect the registered instance")
+
+	w, err := d.Watch(ctx, name)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer w.Stop() //nolint:errcheck
+	Next(t, w, "1")
+}
+
+func testRestart(t *testing.T, r registry.Registrar, d registry.Discovery) {
+	ctx := context.Background()
+	name := Service()
+	w, err := d.Watch(ctx, name)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer w.Stop() //nolint:errcheck
+
+	ins := Instance(name, "1", 9000)
+	if err = r.Register(ctx, ins); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	Next(t, w, "1")
+
+	restarted := Instance(name, "1", 9100)
+	if err = r.Register(ctx, restarted); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	deadline := time.Now().Add(Timeout)
+	for {
+		services := Next(t, w, "1")
+		if services[0].Endpoints[0] == restarted.Endpoints[0] {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Next: expect the restarted instance %v, got %v", restarted.Endpoints, services[0].Endpoints)
+		}
+	}
+
+	// a restart deregisters the instance before registering it again
+	if err = r.Deregister(ctx, restarted); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	if err = r.Register(ctx, ins); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	defer r.Deregister(ctx, ins) //nolint:errcheck
+	eventually(t, func() bool {
+		services, err := d.GetService(ctx, name)
+		return err == nil && len(services) == 1 && services[0].Endpoints[0] == ins.Endpoints[0]
+	}, "GetService: expect the instance registered again")
+}
+
+func testConcurrency(t *testing.T, r registry.Registrar, d registry.Discovery) {
+	const n = 8
+	ctx := context.Background()
+	name := Service()
+	watchers := make([]registry.Watcher, 3)
+	for i := range watchers {
+		w, err := d.Watch(ctx, name)
+		if err != nil {
+			t.Fatalf("Watch: %v", err)
+		}
+		defer w.Stop() //nolint:errcheck
+		watchers[i] = w
+	}
+
+	ids := make([]string, n)
+	instances := make([]*registry.ServiceInstance, n)
+	for i := range instances {
+		ids[i] = strconv.Itoa(i)
+		instances[i] = Instance(name, ids[i], 9000+i)
+	}
+	parallel(t, instances, r.Register)
+	for _, w := range watchers {
+		Next(t, w, ids...)
+	}
+	parallel(t, instances, r.Deregister)
+	eventually(t, func() bool {
+		services, err := d.GetService(ctx, name)
+		return err == nil && len(services) == 0
+	}, "GetService: expect the instances deregistered")
+}
+
+// Next watches w until its instances are of the ids, failing t after Timeout.
+func Next(t *testing.T, w registry.Watcher, ids ...string) []*registry.ServiceInstance {
+	t.Helper()
+	type result struct {
+		services []*registry.ServiceInstance
+		err      error
+	}
+	deadline := time.After(Timeout)
+	sort.Strings(ids)
+	var got []string
+	for {
+		ch := make(chan result, 1)
+		go func() {
+			services, err := w.Next()
+			ch <- result{services, err}
+		}()
+		select {
+		case res := <-ch:
+			if res.err != nil {
+				t.Fatalf("Next: %v", res.err)
+			}
+			if got = instanceIDs(res.services); fmt.Sprint(got) == fmt.Sprint(ids) {
+				return res.services
+			}
+		case <-deadline:
+			t.Fatalf("Next: expect instances %v, got %v", ids, got)
+		}
+	}
+}
+
+func parallel(t *testing.T, instances []*registry.ServiceInstance, fn func(context.Context, *registry.ServiceInstance) error) {
+	t.Helper()
+	var wg sync.WaitGroup
+	errs := make(chan error, len(instances))
+	for _, ins := range instances {
+		wg.Add(1)
+		go func(ins *registry.ServiceInstance) {
+			defer wg.Done()
+			errs <- fn(context.Background(), ins)
+		}(ins)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func eventually(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	for deadline := time.Now().Add(Timeout); !cond(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+	}
+}
+
+func instanceIDs(services []*registry.ServiceInstance) []string {
+	ret := make([]string, 0, len(services))
+	for _, s := range services {
+		ret = append(ret, s.ID)
+	}
+	sort.Strings(ret)
+	return ret
+}
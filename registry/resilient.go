@@ -0,0 +1,236 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// ErrWatcherStopped is returned by Next of a stopped resilient watcher.
+var ErrWatcherStopped = errors.New("registry: watcher stopped")
+
+// rewatchFailures is the count of the failures in a row of a watcher before it
+// is watched again, the transient failures are retried.
+const rewatchFailures = 2
+
+// ResilientOption is a resilient discovery option.
+type ResilientOption func(*resilient)
+
+// WithBackoff with the min and max delay of the re-watches.
+func WithBackoff(minDelay, maxDelay time.Duration) ResilientOption {
+	return func(r *resilient) {
+		r.minDelay, r.maxDelay = minDelay, maxDelay
+	}
+}
+
+// WithLogger with the logger of the watch errors.
+func WithLogger(logger log.Logger) ResilientOption {
+	return func(r *resilient) {
+		r.log = log.NewHelper(logger)
+	}
+}
+
+type resilient struct {
+	Discovery
+	minDelay time.Duration
+	maxDelay time.Duration
+	log      *log.Helper
+}
+
+// Resilient wraps the discovery so that its watchers behave the same across
+// the registries: Next is retried with exponential backoff when the watcher
+// fails, the service is watched again once it keeps failing, e.g. the
+// connection is lost, the full instances are
+// resynced from GetService once watched again, and the updates not changing
+// the instances are suppressed. Next only returns an error once stopped.
+func Resilient(d Discovery, opts ...ResilientOption) Discovery {
+	if r, ok := d.(*resilient); ok {
+		return r
+	}
+	r := &resilient{
+		Discovery: d,
+		minDelay:  100 * time.Millisecond,
+		maxDelay:  10 * time.Second,
+		log:       log.NewHelper(log.GetLogger()),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Watch creates a resilient watcher, the first watch error is returned.
+func (r *resilient) Watch(ctx context.Context, serviceName string) (Watcher, error) {
+	w, err := r.Discovery.Watch(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	rw := &resilientWatcher{resilient: r, name: serviceName, w: w}
+	rw.ctx, rw.cancel = context.WithCancel(ctx)
+	return rw, nil
+}
+
+type resilientWatcher struct {
+	*resilient
+	name   string
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	w         Watcher
+	last      []*ServiceInstance
+	delivered bool
+	resync    bool
+	attempts  int
+	failures  int
+}
+
+// Next returns the first instances, then the instances once they changed.
+func (w *resilientWatcher) Next() ([]*ServiceInstance, error) {
+	for {
+		if w.ctx.Err() != nil {
+			return nil, ErrWatcherStopped
+		}
+		inner, err := w.watcher()
+		if err != nil {
+			w.log.Errorf("[registry] failed to watch service %s: %v", w.name, err)
+			if err = w.backoff(); err != nil {
+				return nil, ErrWatcherStopped
+			}
+			continue
+		}
+		var ins []*ServiceInstance
+		if w.resyncing() {
+			// the resync stays pending until it succeeds.
+			if ins, err = w.GetService(w.ctx, w.name); err != nil {
+				w.log.Errorf("[registry] failed to resync service %s: %v", w.name, err)
+				if err = w.backoff(); err != nil {
+					return nil, ErrWatcherStopped
+				}
+				continue
+			}
+			w.mu.Lock()
+			w.resync = false
+			w.mu.Unlock()
+		} else if ins, err = inner.Next(); err != nil {
+			if w.ctx.Err() != nil {
+				return nil, ErrWatcherStopped
+			}
+			w.log.Errorf("[registry] failed to watch service %s: %v", w.name, err)
+			w.failures++
+			if w.failures >= rewatchFailures {
+				w.failures = 0
+				w.reset(inner)
+			}
+			if err = w.backoff(); err != nil {
+				return nil, ErrWatcherStopped
+			}
+			continue
+		}
+		w.failures = 0
+		w.mu.Lock()
+		w.attempts = 0
+		changed := !w.delivered || !equalInstances(w.last, ins)
+		if changed {
+			w.last, w.delivered = ins, true
+		}
+		w.mu.Unlock()
+		if changed {
+			return ins, nil
+		}
+	}
+}
+
+// watcher returns the watcher, watched again after failed, the instances
+// are then resynced.
+func (w *resilientWatcher) watcher() (Watcher, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.w != nil {
+		return w.w, nil
+	}
+	if err := w.ctx.Err(); err != nil {
+		return nil, err
+	}
+	inner, err := w.Discovery.Watch(w.ctx, w.name)
+	if err != nil {
+		return nil, err
+	}
+	w.w, w.resync = inner, true
+	return inner, nil
+}
+
+// resyncing reports whether the instances should be resynced.
+func (w *resilientWatcher) resyncing() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.resync
+}
+
+func (w *resilientWatcher) reset(inner Watcher) {
+	w.mu.Lock()
+	if w.w == inner {
+		w.w = nil
+	}
+	w.mu.Unlock()
+	_ = inner.Stop()
+}
+
+func (w *resilientWatcher) backoff() error {
+	w.mu.Lock()
+	delay := w.minDelay << w.attempts
+	if delay <= 0 || delay > w.maxDelay {
+		delay = w.maxDelay
+	} else {
+		w.attempts++
+	}
+	w.mu.Unlock()
+	// jitter the delay between [delay/2, delay)
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int63n(half))
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	}
+}
+
+// Stop stops the watcher.
+func (w *resilientWatcher) Stop() error {
+	w.cancel()
+	w.mu.Lock()
+	inner := w.w
+	w.w = nil
+	w.mu.Unlock()
+	if inner != nil {
+		return inner.Stop()
+	}
+	return nil
+}
+
+// equalInstances reports whether a and b have the same instances regardless
+// of the order.
+func equalInstances(a, b []*ServiceInstance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	m := make(map[string]*ServiceInstance, len(a))
+	for _, in := range a {
+		m[in.ID] = in
+	}
+	for _, in := range b {
+		if o, ok := m[in.ID]; !ok || !reflect.DeepEqual(o, in) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,258 @@
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/registry/registrytest"
+)
+
+var errConnLost = errors.New("connection lost")
+
+// memory is a registry of which the watchers are lost on disconnect.
+type memory struct {
+	mu       sync.Mutex
+	services map[string]map[string]*registry.ServiceInstance
+	watchers map[*memoryWatcher]struct{}
+	watches  int
+	down     bool
+	// gets is the number of GetService calls failing, lazy the watchers not
+	// sending the instances when watched.
+	gets int
+	lazy bool
+}
+
+func newMemory() *memory {
+	return &memory{
+		services: make(map[string]map[string]*registry.ServiceInstance),
+		watchers: make(map[*memoryWatcher]struct{}),
+	}
+}
+
+func (m *memory) Register(_ context.Context, s *registry.ServiceInstance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.services[s.Name] == nil {
+		m.services[s.Name] = make(map[string]*registry.ServiceInstance)
+	}
+	m.services[s.Name][s.ID] = s
+	m.notify(s.Name)
+	return nil
+}
+
+func (m *memory) Deregister(_ context.Context, s *registry.ServiceInstance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.services[s.Name], s.ID)
+	m.notify(s.Name)
+	return nil
+}
+
+func (m *memory) GetService(_ context.Context, name string) ([]*registry.ServiceInstance, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return nil, errConnLost
+	}
+	if m.gets > 0 {
+		m.gets--
+		return nil, errConnLost
+	}
+	return m.list(name), nil
+}
+
+func (m *memory) Watch(_ context.Context, name string) (registry.Watcher, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watches++
+	if m.down {
+		return nil, errConnLost
+	}
+	w := &memoryWatcher{name: name, ch: make(chan []*registry.ServiceInstance, 64), lost: make(chan struct{}), stop: make(chan struct{})}
+	m.watchers[w] = struct{}{}
+	if ins := m.list(name); len(ins) > 0 && !m.lazy {
+		w.ch <- ins
+	}
+	return w, nil
+}
+
+// disconnect fails the watchers, up until connect.
+func (m *memory) disconnect() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.down = true
+	for w := range m.watchers {
+		close(w.lost)
+		delete(m.watchers, w)
+	}
+}
+
+func (m *memory) connect() {
+	m.mu.Lock()
+	m.down = false
+	m.mu.Unlock()
+}
+
+// touch notifies the watchers of name without any change.
+func (m *memory) touch(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notify(name)
+}
+
+func (m *memory) list(name string) []*registry.ServiceInstance {
+	ins := make([]*registry.ServiceInstance, 0, len(m.services[name]))
+	for _, s := range m.services[name] {
+		ins = append(ins, s)
+	}
+	return ins
+}
+
+func (m *memory) notify(name string) {
+	if m.down {
+		return
+	}
+	for w := range m.watchers {
+		if w.name == name {
+			w.ch <- m.list(name)
+		}
+	}
+}
+
+type memoryWatcher struct {
+	name string
+	ch   chan []*registry.ServiceInstance
+	lost chan struct{}
+	stop chan struct{}
+	once sync.Once
+}
+
+func (w *memoryWatcher) Next() ([]*registry.ServiceInstance, error) {
+	select {
+	case <-w.stop:
+		return nil, context.Canceled
+	default:
+	}
+	select {
+	case ins := <-w.ch:
+		return ins, nil
+	case <-w.lost:
+		return nil, errConnLost
+	case <-w.stop:
+		return nil, context.Canceled
+	}
+}
+
+func (w *memoryWatcher) Stop() error {
+	w.once.Do(func() { close(w.stop) })
+	return nil
+}
+
+func TestConformance(t *testing.T) {
+	m := newMemory()
+	registrytest.Conformance(t, m, m)
+}
+
+func TestResilient_Conformance(t *testing.T) {
+	m := newMemory()
+	registrytest.Conformance(t, m, registry.Resilient(m))
+}
+
+func TestResilient_Rewatch(t *testing.T) {
+	ctx := context.Background()
+	m := newMemory()
+	d := registry.Resilient(m, registry.WithBackoff(time.Millisecond, 10*time.Millisecond))
+	w, err := d.Watch(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	_ = m.Register(ctx, &registry.ServiceInstance{ID: "1", Name: "user"})
+	registrytest.Next(t, w, "1")
+
+	m.disconnect()
+	_ = m.Register(ctx, &registry.ServiceInstance{ID: "2", Name: "user"})
+	time.AfterFunc(50*time.Millisecond, m.connect)
+	registrytest.Next(t, w, "1", "2")
+	if m.watches < 3 {
+		t.Errorf("expect watched again with backoff, got %d watches", m.watches)
+	}
+}
+
+func TestResilient_Resync(t *testing.T) {
+	ctx := context.Background()
+	m := newMemory()
+	d := registry.Resilient(m, registry.WithBackoff(time.Millisecond, 10*time.Millisecond))
+	w, err := d.Watch(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	_ = m.Register(ctx, &registry.ServiceInstance{ID: "1", Name: "user"})
+	registrytest.Next(t, w, "1")
+
+	m.mu.Lock()
+	m.lazy, m.gets = true, 3
+	m.mu.Unlock()
+	m.disconnect()
+	_ = m.Register(ctx, &registry.ServiceInstance{ID: "2", Name: "user"})
+	time.AfterFunc(20*time.Millisecond, m.connect)
+	// the instances are only resynced, the watchers don't send them.
+	registrytest.Next(t, w, "1", "2")
+}
+
+func TestResilient_Dedup(t *testing.T) {
+	ctx := context.Background()
+	m := newMemory()
+	w, err := registry.Resilient(m).Watch(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	_ = m.Register(ctx, &registry.ServiceInstance{ID: "1", Name: "user"})
+	registrytest.Next(t, w, "1")
+	m.touch("user")
+	m.touch("user")
+	_ = m.Register(ctx, &registry.ServiceInstance{ID: "1", Name: "user", Version: "v2"})
+	ins, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ins) != 1 || ins[0].Version != "v2" {
+		t.Errorf("expect the no-op updates suppressed, got %v", ins)
+	}
+}
+
+func TestResilient_Empty(t *testing.T) {
+	ctx := context.Background()
+	m := newMemory()
+	w, err := registry.Resilient(m).Watch(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	m.touch("user")
+	ins, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ins) != 0 {
+		t.Errorf("expect the first empty instances delivered, got %v", ins)
+	}
+}
+
+func TestResilient_Stop(t *testing.T) {
+	m := newMemory()
+	w, err := registry.Resilient(m).Watch(context.Background(), "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(10*time.Millisecond, func() { _ = w.Stop() })
+	if _, err := w.Next(); !errors.Is(err, registry.ErrWatcherStopped) {
+		t.Errorf("expect the watcher stopped, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package report
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Event is an error or a recovered panic reported to an error tracker.
+type Event struct {
+	// Err is the reported error, for panics it is the error returned to the caller.
+	Err error
+	// Panic is the recovered value, nil if the event is not a panic.
+	Panic interface{}
+	// Stack is the goroutine stack captured where the panic occurred, nil if
+	// the event is not a panic.
+	Stack []byte
+	// Request is the request being handled, if reported, e.g. redacted.
+	Request interface{}
+	// Tags are indexed key/values attached to the event.
+	Tags map[string]string
+}
+
+// Reporter reports errors to an error tracking service such as Sentry or Bugsnag.
+// Implementations must be safe for concurrent use and should not block.
+type Reporter interface {
+	Report(ctx context.Context, event *Event)
+}
+
+// Tags returns the request tags carried by the server transport in ctx.
+func Tags(ctx context.Context) map[string]string {
+	tags := make(map[string]string)
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		tags["kind"] = tr.Kind().String()
+		tags["operation"] = tr.Operation()
+		tags["endpoint"] = tr.Endpoint()
+	}
+	return tags
+}
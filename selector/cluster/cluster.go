@@ -0,0 +1,265 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+const (
+	// Name is cluster balancer name
+	Name = "cluster"
+	// DefaultKey is the node metadata key of the cluster.
+	DefaultKey = "cluster"
+)
+
+var _ selector.Selector = (*Selector)(nil)
+
+// Option is cluster builder option.
+type Option func(o *options)
+
+type options struct {
+	key        string
+	priority   []string
+	threshold  float64
+	decay      float64
+	probeRatio float64
+	failover   metrics.Counter
+	failback   metrics.Counter
+}
+
+// WithKey with the node metadata key of the cluster, default is DefaultKey.
+func WithKey(key string) Option {
+	return func(o *options) {
+		o.key = key
+	}
+}
+
+// WithPriority with the remote clusters preferred on failover, e.g. the nearest first,
+// the other clusters follow by name.
+func WithPriority(clusters ...string) Option {
+	return func(o *options) {
+		o.priority = clusters
+	}
+}
+
+// WithThreshold with the success rate of a cluster below which it's failed over, default is 0.8.
+func WithThreshold(threshold float64) Option {
+	return func(o *options) {
+		o.threshold = threshold
+	}
+}
+
+// WithProbeRatio with the ratio of the requests still sent to the local
+// cluster while failed over, to detect its recovery, default is 0.05.
+func WithProbeRatio(ratio float64) Option {
+	return func(o *options) {
+		o.probeRatio = ratio
+	}
+}
+
+// WithMetrics with the counters of the failovers and the failbacks.
+func WithMetrics(failover, failback metrics.Counter) Option {
+	return func(o *options) {
+		o.failover = failover
+		o.failback = failback
+	}
+}
+
+// Builder is the cluster selector builder.
+type Builder struct {
+	local string
+	next  selector.Builder
+	opts  []Option
+}
+
+// NewBuilder returns a builder of selectors preferring the nodes of the local
+// cluster and failing over to the remote clusters when its success rate drops
+// below the threshold, the nodes of each cluster are selected by next.
+func NewBuilder(local string, next selector.Builder, opts ...Option) selector.Builder {
+	return &Builder{local: local, next: next, opts: opts}
+}
+
+// Build creates a cluster selector.
+func (b *Builder) Build() selector.Selector {
+	o := options{
+		key:        DefaultKey,
+		threshold:  0.8,
+		decay:      0.05,
+		probeRatio: 0.05,
+	}
+	for _, opt := range b.opts {
+		opt(&o)
+	}
+	return &Selector{local: b.local, next: b.next, opts: o}
+}
+
+type cluster struct {
+	name     string
+	selector selector.Selector
+
+	mu      sync.Mutex
+	success float64
+}
+
+// observe updates the success rate EWMA, server errors count as failures.
+func (c *cluster) observe(err error, decay float64) float64 {
+	v := 1.0
+	if err != nil && errors.FromError(err).Code >= 500 {
+		v = 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.success += (v - c.success) * decay
+	return c.success
+}
+
+func (c *cluster) health() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.success
+}
+
+// Selector is a multi-cluster selector.
+type Selector struct {
+	local string
+	next  selector.Builder
+	opts  options
+
+	mu       sync.Mutex
+	clusters atomic.Value // []*cluster, local first then by priority
+	failover int32
+	requests uint64
+}
+
+// Apply groups the nodes by cluster.
+func (s *Selector) Apply(nodes []selector.Node) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := make(map[string][]selector.Node)
+	for _, n := range nodes {
+		name := n.Metadata()[s.opts.key]
+		groups[name] = append(groups[name], n)
+	}
+	old := make(map[string]*cluster)
+	for _, c := range s.load() {
+		old[c.name] = c
+	}
+	clusters := make([]*cluster, 0, len(groups))
+	for name, nodes := range groups {
+		c, ok := old[name]
+		if !ok {
+			c = &cluster{name: name, selector: s.next.Build(), success: 1}
+			if name == s.local {
+				// the local cluster is back with new nodes.
+				s.failback()
+			}
+		}
+		c.selector.Apply(nodes)
+		clusters = append(clusters, c)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		pi, pj := s.rank(clusters[i].name), s.rank(clusters[j].name)
+		if pi != pj {
+			return pi < pj
+		}
+		return clusters[i].name < clusters[j].name
+	})
+	s.clusters.Store(clusters)
+}
+
+func (s *Selector) rank(name string) int {
+	if name == s.local {
+		return -1
+	}
+	for i, p := range s.opts.priority {
+		if p == name {
+			return i
+		}
+	}
+	return len(s.opts.priority)
+}
+
+func (s *Selector) load() []*cluster {
+	clusters, _ := s.clusters.Load().([]*cluster)
+	return clusters
+}
+
+// Select selects a node of the local cluster unless it's failed over, then of the
+// first healthy remote cluster, falling back to the next clusters if none is available.
+func (s *Selector) Select(ctx context.Context, opts ...selector.SelectOption) (selector.Node, selector.DoneFunc, error) {
+	for _, c := range s.candidates() {
+		n, done, err := c.selector.Select(ctx, opts...)
+		if err != nil {
+			continue
+		}
+		return n, s.done(c, done), nil
+	}
+	return nil, nil, selector.ErrNoAvailable
+}
+
+// candidates returns the clusters in the order they are tried.
+func (s *Selector) candidates() []*cluster {
+	clusters := s.load()
+	if len(clusters) == 0 || clusters[0].name != s.local || atomic.LoadInt32(&s.failover) == 0 {
+		return clusters
+	}
+	if clusters[0].health() >= s.opts.threshold {
+		s.failback()
+		return clusters
+	}
+	// probe the local cluster with a fraction of the requests.
+	if s.opts.probeRatio > 0 {
+		if n := atomic.AddUint64(&s.requests, 1); float64(n%1000) < s.opts.probeRatio*1000 {
+			return clusters
+		}
+	}
+	remotes := make([]*cluster, 0, len(clusters))
+	for _, c := range clusters[1:] {
+		if c.health() >= s.opts.threshold {
+			remotes = append(remotes, c)
+		}
+	}
+	for _, c := range clusters[1:] {
+		if c.health() < s.opts.threshold {
+			remotes = append(remotes, c)
+		}
+	}
+	return append(remotes, clusters[0])
+}
+
+func (s *Selector) done(c *cluster, done selector.DoneFunc) selector.DoneFunc {
+	return func(ctx context.Context, di selector.DoneInfo) {
+		if done != nil {
+			done(ctx, di)
+		}
+		health := c.observe(di.Err, s.opts.decay)
+		if c.name != s.local {
+			return
+		}
+		if health < s.opts.threshold {
+			if atomic.CompareAndSwapInt32(&s.failover, 0, 1) && s.opts.failover != nil {
+				s.opts.failover.Inc()
+			}
+		} else {
+			s.failback()
+		}
+	}
+}
+
+// failback resets the failover once the local cluster recovers.
+func (s *Selector) failback() {
+	if atomic.CompareAndSwapInt32(&s.failover, 1, 0) && s.opts.failback != nil {
+		s.opts.failback.Inc()
+	}
+}
+
+// Failover reports whether the local cluster is failed over.
+func (s *Selector) Failover() bool {
+	return atomic.LoadInt32(&s.failover) == 1
+}
@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/go-kratos/kratos/v2/selector/random"
+)
+
+type counter struct{ n int }
+
+func (c *counter) With(...string) metrics.Counter { return c }
+func (c *counter) Inc()                           { c.n++ }
+func (c *counter) Add(float64)                    {}
+
+func node(addr, cluster string) selector.Node {
+	return selector.NewNode(addr, &registry.ServiceInstance{
+		ID:       addr,
+		Metadata: map[string]string{DefaultKey: cluster},
+	})
+}
+
+func TestSelector(t *testing.T) {
+	s := NewBuilder("sh", random.NewBuilder(), WithPriority("bj"), WithProbeRatio(0)).Build().(*Selector)
+	s.Apply([]selector.Node{node("10.0.0.1:80", "sh"), node("10.1.0.1:80", "gz"), node("10.2.0.1:80", "bj")})
+
+	call := func(err error) string {
+		n, done, e := s.Select(context.Background())
+		if e != nil {
+			t.Fatal(e)
+		}
+		done(context.Background(), selector.DoneInfo{Err: err})
+		return n.Metadata()[DefaultKey]
+	}
+	if c := call(nil); c != "sh" {
+		t.Fatalf("expect the local cluster, got %s", c)
+	}
+	for i := 0; i < 10 && !s.Failover(); i++ {
+		call(errors.ServiceUnavailable("UNAVAILABLE", ""))
+	}
+	if !s.Failover() {
+		t.Fatal("expect failover after local errors")
+	}
+	if c := call(nil); c != "bj" {
+		t.Fatalf("expect the priority remote cluster, got %s", c)
+	}
+}
+
+func TestFailback(t *testing.T) {
+	failover, failback := &counter{}, &counter{}
+	s := NewBuilder("sh", random.NewBuilder(), WithProbeRatio(1), WithMetrics(failover, failback)).Build().(*Selector)
+	s.Apply([]selector.Node{node("10.0.0.1:80", "sh"), node("10.1.0.1:80", "gz")})
+	for i := 0; i < 10; i++ {
+		_, done, _ := s.Select(context.Background())
+		done(context.Background(), selector.DoneInfo{Err: errors.InternalServer("INTERNAL", "")})
+	}
+	for i := 0; i < 100 && s.Failover(); i++ {
+		_, done, _ := s.Select(context.Background())
+		done(context.Background(), selector.DoneInfo{})
+	}
+	if s.Failover() || failover.n != 1 || failback.n != 1 {
+		t.Errorf("expect a failover then a failback, got %d failovers, %d failbacks", failover.n, failback.n)
+	}
+}
+
+func TestFailback_Apply(t *testing.T) {
+	failover, failback := &counter{}, &counter{}
+	s := NewBuilder("sh", random.NewBuilder(), WithProbeRatio(0), WithMetrics(failover, failback)).Build().(*Selector)
+	s.Apply([]selector.Node{node("10.0.0.1:80", "sh"), node("10.1.0.1:80", "gz")})
+	for i := 0; i < 10 && !s.Failover(); i++ {
+		_, done, _ := s.Select(context.Background())
+		done(context.Background(), selector.DoneInfo{Err: errors.InternalServer("INTERNAL", "")})
+	}
+	if !s.Failover() {
+		t.Fatal("expect failover after local errors")
+	}
+	// the local nodes are replaced.
+	s.Apply([]selector.Node{node("10.1.0.1:80", "gz")})
+	s.Apply([]selector.Node{node("10.0.0.2:80", "sh"), node("10.1.0.1:80", "gz")})
+	if s.Failover() || failback.n != 1 {
+		t.Fatalf("expect a failback once the local cluster is back, got %d failbacks", failback.n)
+	}
+	if n, _, err := s.Select(context.Background()); err != nil || n.Metadata()[DefaultKey] != "sh" {
+		t.Errorf("expect the local cluster, got %v %v", n, err)
+	}
+}
@@ -0,0 +1,81 @@
+package channelz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// NewHandler new a channelz summary handler, cc is a connection to a gRPC
+// server with the channelz service registered, see grpc.Channelz.
+func NewHandler(cc grpc.ClientConnInterface) http.Handler {
+	client := channelzpb.NewChannelzClient(cc)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := writeSummary(ctx, w, client); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		}
+	})
+}
+
+func writeSummary(ctx context.Context, w io.Writer, client channelzpb.ChannelzClient) error {
+	servers, err := client.GetServers(ctx, &channelzpb.GetServersRequest{})
+	if err != nil {
+		return err
+	}
+	for _, s := range servers.GetServer() {
+		d := s.GetData()
+		fmt.Fprintf(w, "server %d: calls started=%d succeeded=%d failed=%d\n",
+			s.GetRef().GetServerId(), d.GetCallsStarted(), d.GetCallsSucceeded(), d.GetCallsFailed())
+		sockets, err := client.GetServerSockets(ctx, &channelzpb.GetServerSocketsRequest{ServerId: s.GetRef().GetServerId()})
+		if err != nil {
+			return err
+		}
+		for _, ref := range sockets.GetSocketRef() {
+			res, err := client.GetSocket(ctx, &channelzpb.GetSocketRequest{SocketId: ref.GetSocketId()})
+			if err != nil {
+				return err
+			}
+			writeSocket(w, res.GetSocket())
+		}
+	}
+	channels, err := client.GetTopChannels(ctx, &channelzpb.GetTopChannelsRequest{})
+	if err != nil {
+		return err
+	}
+	for _, c := range channels.GetChannel() {
+		d := c.GetData()
+		fmt.Fprintf(w, "channel %d %s: state=%s calls started=%d succeeded=%d failed=%d\n",
+			c.GetRef().GetChannelId(), d.GetTarget(), d.GetState().GetState(),
+			d.GetCallsStarted(), d.GetCallsSucceeded(), d.GetCallsFailed())
+	}
+	return nil
+}
+
+func writeSocket(w io.Writer, s *channelzpb.Socket) {
+	d := s.GetData()
+	fmt.Fprintf(w, "  socket %d %s: streams started=%d succeeded=%d failed=%d messages sent=%d received=%d\n",
+		s.GetRef().GetSocketId(), formatAddress(s.GetRemote()),
+		d.GetStreamsStarted(), d.GetStreamsSucceeded(), d.GetStreamsFailed(),
+		d.GetMessagesSent(), d.GetMessagesReceived())
+}
+
+func formatAddress(addr *channelzpb.Address) string {
+	switch a := addr.GetAddress().(type) {
+	case *channelzpb.Address_TcpipAddress:
+		return net.JoinHostPort(net.IP(a.TcpipAddress.GetIpAddress()).String(), fmt.Sprint(a.TcpipAddress.GetPort()))
+	case *channelzpb.Address_UdsAddress_:
+		return a.UdsAddress.GetFilename()
+	case *channelzpb.Address_OtherAddress_:
+		return a.OtherAddress.GetName()
+	}
+	return "-"
+}
@@ -0,0 +1,43 @@
+package channelz
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+)
+
+func TestHandler(t *testing.T) {
+	srv := grpc.NewServer(grpc.Channelz(true))
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	defer func() {
+		_ = srv.Stop(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := grpc.DialInsecure(context.Background(), grpc.WithEndpoint(e.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w := httptest.NewRecorder()
+	NewHandler(conn).ServeHTTP(w, httptest.NewRequest("GET", "/debug/channelz", nil))
+	body := w.Body.String()
+	if w.Code != 200 {
+		t.Fatalf("expect status 200, got %d: %s", w.Code, body)
+	}
+	for _, s := range []string{"server ", "socket ", "channel "} {
+		if !strings.Contains(body, s) {
+			t.Errorf("expect %q in summary, got:\n%s", s, body)
+		}
+	}
+}
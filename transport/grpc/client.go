@@ -4,9 +4,13 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"time"
 
+	ic "github.com/go-kratos/kratos/v2/internal/context"
+	kreply "github.com/go-kratos/kratos/v2/internal/reply"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
@@ -18,9 +22,12 @@ import (
 	_ "github.com/go-kratos/kratos/v2/transport/grpc/resolver/direct"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	grpcinsecure "google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
 )
 
 // ClientOption is gRPC client option.
@@ -96,6 +103,51 @@ func WithLogger(log log.Logger) ClientOption {
 	}
 }
 
+// WithSerialization with the serialization metrics, the marshal and unmarshal durations
+// and the payload sizes of the messages are observed per operation and recorded as
+// events of the client span, either observer may be nil. It installs a stats handler
+// along with the ones of WithStatsHandler, gRPC accepts a single stats handler per
+// connection so grpc.WithStatsHandler passed to WithOptions would replace them.
+// The calls are forced to the proto codec, which replaces the codec selected by
+// grpc.CallContentSubtype or grpc.ForceCodec.
+func WithSerialization(duration, size metrics.Observer) ClientOption {
+	return func(o *clientOptions) {
+		o.serialization = newSerialization(duration, size)
+	}
+}
+
+// WithStatsHandler with gRPC stats handlers, e.g. of OpenTelemetry.
+func WithStatsHandler(h ...stats.Handler) ClientOption {
+	return func(o *clientOptions) {
+		o.statsHandlers = h
+	}
+}
+
+// WithKeepAlive with the keepalive parameters of the connections, e.g. to
+// detect the broken connections behind the load balancers dropping idle ones.
+func WithKeepAlive(params keepalive.ClientParameters) ClientOption {
+	return func(o *clientOptions) {
+		o.keepalive = &params
+	}
+}
+
+// WithConnectionPool with the number of connections dialed by DialPool and
+// DialInsecurePool, default is 1. Dial and DialInsecure dial a single
+// connection, so they refuse a pool of more than one.
+func WithConnectionPool(size int) ClientOption {
+	return func(o *clientOptions) {
+		o.poolSize = size
+	}
+}
+
+// WithTracker with the tracker of the open clients, e.g. for the app to close
+// them after the servers stop. The connections closed elsewhere are untracked.
+func WithTracker(t *transport.Tracker) ClientOption {
+	return func(o *clientOptions) {
+		o.tracker = t
+	}
+}
+
 // clientOptions is gRPC Client
 type clientOptions struct {
 	endpoint     string
@@ -108,6 +160,12 @@ type clientOptions struct {
 	balancerName string
 	filters      []selector.Filter
 	logger       log.Logger
+	keepalive    *keepalive.ClientParameters
+	poolSize     int
+	tracker      *transport.Tracker
+
+	statsHandlers []stats.Handler
+	serialization *serialization
 }
 
 // Dial returns a GRPC connection.
@@ -121,6 +179,14 @@ func DialInsecure(ctx context.Context, opts ...ClientOption) (*grpc.ClientConn,
 }
 
 func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.ClientConn, error) {
+	options := newClientOptions(opts...)
+	if options.poolSize > 1 {
+		return nil, fmt.Errorf("a pool of %d connections requires DialPool or DialInsecurePool", options.poolSize)
+	}
+	return dialOptions(ctx, insecure, options)
+}
+
+func newClientOptions(opts ...ClientOption) clientOptions {
 	options := clientOptions{
 		timeout:      2000 * time.Millisecond,
 		balancerName: wrr.Name,
@@ -129,8 +195,12 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 	for _, o := range opts {
 		o(&options)
 	}
+	return options
+}
+
+func dialOptions(ctx context.Context, insecure bool, options clientOptions) (*grpc.ClientConn, error) {
 	ints := []grpc.UnaryClientInterceptor{
-		unaryClientInterceptor(options.middleware, options.timeout, options.filters),
+		unaryClientInterceptor(options.middleware, options.timeout, options.filters, options.tlsConf != nil),
 	}
 	if len(options.ints) > 0 {
 		ints = append(ints, options.ints...)
@@ -138,6 +208,7 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 	grpcOpts := []grpc.DialOption{
 		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"LoadBalancingPolicy": "%s"}`, options.balancerName)),
 		grpc.WithChainUnaryInterceptor(ints...),
+		grpc.WithChainStreamInterceptor(streamClientInterceptor()),
 	}
 	if options.discovery != nil {
 		grpcOpts = append(grpcOpts,
@@ -154,22 +225,101 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 	if options.tlsConf != nil {
 		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(credentials.NewTLS(options.tlsConf)))
 	}
+	if options.keepalive != nil {
+		grpcOpts = append(grpcOpts, grpc.WithKeepaliveParams(*options.keepalive))
+	}
+	handlers := statsHandlers(options.statsHandlers)
+	if options.serialization != nil {
+		handlers = append(handlers, options.serialization)
+		grpcOpts = append(grpcOpts,
+			grpc.WithChainUnaryInterceptor(options.serialization.unaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(options.serialization.streamClientInterceptor()),
+		)
+	}
+	if len(handlers) > 0 {
+		grpcOpts = append(grpcOpts, grpc.WithStatsHandler(handlers))
+	}
 	if len(options.grpcOpts) > 0 {
 		grpcOpts = append(grpcOpts, options.grpcOpts...)
 	}
-	return grpc.DialContext(ctx, options.endpoint, grpcOpts...)
+	conn, err := grpc.DialContext(ctx, options.endpoint, grpcOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if options.tracker != nil {
+		untrack := options.tracker.Track(transport.ClientInfo{
+			Kind:     transport.KindGRPC,
+			Target:   options.endpoint,
+			Insecure: insecure,
+		}, trackedConn{conn})
+		go untrackClosed(conn, untrack)
+	}
+	return conn, nil
+}
+
+// untrackClosed untracks the connection once it is closed.
+func untrackClosed(conn *grpc.ClientConn, untrack func()) {
+	for state := conn.GetState(); state != connectivity.Shutdown; state = conn.GetState() {
+		conn.WaitForStateChange(context.Background(), state)
+	}
+	untrack()
+}
+
+// trackedConn is a tracked connection, untracked once closed.
+type trackedConn struct {
+	*grpc.ClientConn
+}
+
+func (c trackedConn) Closed() bool {
+	return c.GetState() == connectivity.Shutdown
 }
 
-func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, filters []selector.Filter) grpc.UnaryClientInterceptor {
+// Unwrap returns the connection, e.g. to find the named client of the tracker.
+func (c trackedConn) Unwrap() io.Closer {
+	return c.ClientConn
+}
+
+// streamClientInterceptor cancels the streams with the request of the strict
+// mode they are opened by, if any.
+func streamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, cancel := ic.MergeRequest(ctx)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		return &requestStream{ClientStream: stream, desc: desc, cancel: cancel}, nil
+	}
+}
+
+// requestStream is a client stream of a request, released once done.
+type requestStream struct {
+	grpc.ClientStream
+	desc   *grpc.StreamDesc
+	cancel context.CancelFunc
+}
+
+func (s *requestStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || !s.desc.ServerStreams {
+		s.cancel()
+	}
+	return err
+}
+
+func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, filters []selector.Filter, secure bool) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel := ic.MergeRequest(ctx)
+		defer cancel()
 		ctx = transport.NewClientContext(ctx, &Transport{
 			endpoint:  cc.Target(),
 			operation: method,
 			reqHeader: headerCarrier{},
 			filters:   filters,
+			secure:    secure,
 		})
 		if timeout > 0 {
-			var cancel context.CancelFunc
 			ctx, cancel = context.WithTimeout(ctx, timeout)
 			defer cancel()
 		}
@@ -183,12 +333,17 @@ func unaryClientInterceptor(ms []middleware.Middleware, timeout time.Duration, f
 				}
 				ctx = grpcmd.AppendToOutgoingContext(ctx, keyvals...)
 			}
-			return reply, invoker(ctx, method, req, reply, cc, opts...)
+			target, _ := kreply.FromContext(ctx)
+			return target, invoker(ctx, method, req, target, cc, opts...)
 		}
 		if len(ms) > 0 {
 			h = middleware.Chain(ms...)(h)
 		}
-		_, err := h(ctx, req)
-		return err
+		out, err := h(kreply.NewContext(ctx, reply), req)
+		if err != nil {
+			return err
+		}
+		kreply.Copy(reply, out)
+		return nil
 	}
 }
@@ -10,7 +10,10 @@ import (
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 func TestWithEndpoint(t *testing.T) {
@@ -88,7 +91,7 @@ func EmptyMiddleware() middleware.Middleware {
 }
 
 func TestUnaryClientInterceptor(t *testing.T) {
-	f := unaryClientInterceptor([]middleware.Middleware{EmptyMiddleware()}, time.Duration(100), nil)
+	f := unaryClientInterceptor([]middleware.Middleware{EmptyMiddleware()}, time.Duration(100), nil, false)
 	req := &struct{}{}
 	resp := &struct{}{}
 
@@ -153,3 +156,43 @@ func TestDialConn(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestUntrackClosed(t *testing.T) {
+	tracker := transport.NewTracker()
+	conn, err := DialInsecure(context.Background(), WithEndpoint("127.0.0.1:0"), WithTracker(tracker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	untracked := make(chan struct{})
+	go untrackClosed(conn, func() { close(untracked) })
+	_ = conn.Close()
+	select {
+	case <-untracked:
+	case <-time.After(time.Second):
+		t.Fatal("expect the closed connection untracked")
+	}
+}
+
+func TestDial_Secure(t *testing.T) {
+	secure := make(chan bool, 1)
+	m := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, _ := transport.FromClientContext(ctx)
+			secure <- tr.(transport.Securer).Secure()
+			return nil, context.Canceled
+		}
+	}
+	conn, err := Dial(context.Background(),
+		WithEndpoint("127.0.0.1:0"),
+		WithMiddleware(m),
+		WithOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.Invoke(context.Background(), "/test.Test/Call", &emptypb.Empty{}, &emptypb.Empty{})
+	if <-secure {
+		t.Error("expect a connection dialed without a TLS config insecure")
+	}
+}
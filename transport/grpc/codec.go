@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/proto"
+)
+
+// MarshalFunc marshals a message.
+type MarshalFunc func(v interface{}) ([]byte, error)
+
+// UnmarshalFunc unmarshals a message.
+type UnmarshalFunc func(data []byte, v interface{}) error
+
+// MarshalInterceptor intercepts the marshaling of the messages sent by the server,
+// e.g. to compress or encrypt the bytes returned by next.
+type MarshalInterceptor func(next MarshalFunc) MarshalFunc
+
+// UnmarshalInterceptor intercepts the unmarshaling of the messages received by the server,
+// e.g. to decrypt the bytes before next, or to validate the message after it.
+type UnmarshalInterceptor func(next UnmarshalFunc) UnmarshalFunc
+
+// codec is the proto codec with the marshal and unmarshal interceptors.
+type codec struct {
+	marshal   MarshalFunc
+	unmarshal UnmarshalFunc
+}
+
+func newCodec(marshalInts []MarshalInterceptor, unmarshalInts []UnmarshalInterceptor) encoding.Codec {
+	base := encoding.GetCodec(proto.Name)
+	c := &codec{
+		marshal:   base.Marshal,
+		unmarshal: base.Unmarshal,
+	}
+	for i := len(marshalInts) - 1; i >= 0; i-- {
+		c.marshal = marshalInts[i](c.marshal)
+	}
+	for i := len(unmarshalInts) - 1; i >= 0; i-- {
+		c.unmarshal = unmarshalInts[i](c.unmarshal)
+	}
+	return c
+}
+
+func (c *codec) Marshal(v interface{}) ([]byte, error) {
+	return c.marshal(v)
+}
+
+func (c *codec) Unmarshal(data []byte, v interface{}) error {
+	return c.unmarshal(data, v)
+}
+
+func (c *codec) Name() string {
+	return proto.Name
+}
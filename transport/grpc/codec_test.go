@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"bytes"
+	"testing"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
+
+func TestCodec(t *testing.T) {
+	var validated bool
+	c := newCodec(
+		[]MarshalInterceptor{func(next MarshalFunc) MarshalFunc {
+			return func(v interface{}) ([]byte, error) {
+				b, err := next(v)
+				return reverse(b), err
+			}
+		}},
+		[]UnmarshalInterceptor{
+			func(next UnmarshalFunc) UnmarshalFunc {
+				return func(data []byte, v interface{}) error {
+					err := next(data, v)
+					validated = err == nil && v.(*pb.HelloRequest).Name == "kratos"
+					return err
+				}
+			},
+			func(next UnmarshalFunc) UnmarshalFunc {
+				return func(data []byte, v interface{}) error {
+					return next(reverse(data), v)
+				}
+			},
+		},
+	)
+	data, err := c.Marshal(&pb.HelloRequest{Name: "kratos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("kratos")) {
+		t.Errorf("expect the marshal interceptor to transform the bytes, got %q", data)
+	}
+	var req pb.HelloRequest
+	if err := c.Unmarshal(data, &req); err != nil {
+		t.Fatal(err)
+	}
+	if !validated {
+		t.Errorf("expect the outermost unmarshal interceptor to see the decoded message, got %v", &req)
+	}
+}
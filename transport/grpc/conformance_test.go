@@ -0,0 +1,52 @@
+package grpc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/transporttest"
+
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
+)
+
+// conformanceServer serves the handler of the conformance suite.
+type conformanceServer struct {
+	pb.UnimplementedGreeterServer
+	h middleware.Handler
+}
+
+func (s *conformanceServer) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	reply, err := s.h(ctx, in.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.HelloReply{Message: reply.(string)}, nil
+}
+
+func TestConformance(t *testing.T) {
+	transporttest.Conformance(t, transporttest.Harness{
+		NewServer: func(h middleware.Handler, m ...middleware.Middleware) (transport.Server, error) {
+			srv := NewServer(Address("127.0.0.1:0"), Middleware(m...))
+			pb.RegisterGreeterServer(srv, &conformanceServer{h: h})
+			return srv, nil
+		},
+		Call: func(ctx context.Context, endpoint *url.URL, req string, m ...middleware.Middleware) (string, transport.Header, error) {
+			conn, err := DialInsecure(ctx, WithEndpoint(endpoint.Host), WithMiddleware(m...))
+			if err != nil {
+				return "", nil, err
+			}
+			defer conn.Close()
+			var md grpcmd.MD
+			reply, err := pb.NewGreeterClient(conn).SayHello(ctx, &pb.HelloRequest{Name: req}, grpc.Header(&md))
+			if err != nil {
+				return "", nil, err
+			}
+			return reply.Message, headerCarrier(md), nil
+		},
+	})
+}
@@ -19,7 +19,7 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 		replyHeader := grpcmd.MD{}
 		ctx = transport.NewServerContext(ctx, &Transport{
 			endpoint:    s.endpoint.String(),
-			operation:   info.FullMethod,
+			operation:   s.operationOf(info.FullMethod),
 			reqHeader:   headerCarrier(md),
 			replyHeader: headerCarrier(replyHeader),
 		})
@@ -27,13 +27,23 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 			ctx, cancel = context.WithTimeout(ctx, s.timeout)
 			defer cancel()
 		}
+		if s.strict {
+			var cancelCalls func()
+			ctx, cancelCalls = ic.WithRequest(ctx)
+			defer s.watch(ctx, s.operationOf(info.FullMethod), cancelCalls)()
+		}
 		h := func(ctx context.Context, req interface{}) (interface{}, error) {
 			return handler(ctx, req)
 		}
+		if ms := s.matcher.Match(info.FullMethod); len(ms) > 0 {
+			h = middleware.Chain(ms...)(h)
+		}
 		if len(s.middleware) > 0 {
 			h = middleware.Chain(s.middleware...)(h)
 		}
+		s.observers.OnRequestStart(ctx)
 		reply, err := h(ctx, req)
+		s.observers.OnRequestEnd(ctx, err)
 		if len(replyHeader) > 0 {
 			_ = grpc.SetHeader(ctx, replyHeader)
 		}
@@ -58,7 +68,9 @@ func (w *wrappedStream) Context() context.Context {
 	return w.ctx
 }
 
-// streamServerInterceptor is a gRPC stream server interceptor
+// streamServerInterceptor is a gRPC stream server interceptor, the middleware
+// is called once per stream with the grpc.ServerStream as the request, which
+// the middlewares inspecting the request must skip, e.g. logging does.
 func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		ctx, cancel := ic.Merge(ss.Context(), s.baseCtx)
@@ -67,17 +79,47 @@ func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
 		replyHeader := grpcmd.MD{}
 		ctx = transport.NewServerContext(ctx, &Transport{
 			endpoint:    s.endpoint.String(),
-			operation:   info.FullMethod,
+			operation:   s.operationOf(info.FullMethod),
 			reqHeader:   headerCarrier(md),
 			replyHeader: headerCarrier(replyHeader),
 		})
 
-		ws := NewWrappedStream(ctx, ss)
+		if s.strict {
+			var cancelCalls func()
+			ctx, cancelCalls = ic.WithRequest(ctx)
+			defer s.watch(ctx, s.operationOf(info.FullMethod), cancelCalls)()
+		}
+		h := func(ctx context.Context, req interface{}) (interface{}, error) {
+			// the middleware may wrap the stream, e.g. to count the messages.
+			stream, ok := req.(grpc.ServerStream)
+			if !ok {
+				stream = ss
+			}
+			return nil, handler(srv, NewWrappedStream(ctx, stream))
+		}
+		// the mounted unary methods run the middleware in mountedInterceptor.
+		if !s.mounts.unary(info.FullMethod) {
+			if ms := s.matcher.Match(info.FullMethod); len(ms) > 0 {
+				h = middleware.Chain(ms...)(h)
+			}
+			if len(s.middleware) > 0 {
+				h = middleware.Chain(s.middleware...)(h)
+			}
+		}
 
-		err := handler(srv, ws)
+		s.observers.OnRequestStart(ctx)
+		_, err := h(ctx, ss)
+		s.observers.OnRequestEnd(ctx, err)
 		if len(replyHeader) > 0 {
 			_ = grpc.SetHeader(ctx, replyHeader)
 		}
 		return err
 	}
 }
+
+func (s *Server) operationOf(fullMethod string) string {
+	if s.operation != nil {
+		return s.operation(fullMethod)
+	}
+	return fullMethod
+}
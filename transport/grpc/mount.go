@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type mountedService struct {
+	impl    interface{}
+	methods map[string]*grpc.MethodDesc
+	streams map[string]*grpc.StreamDesc
+}
+
+// mountRegistrar collects the services registered by a mount.
+type mountRegistrar map[string]*mountedService
+
+func (r mountRegistrar) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	svc := &mountedService{
+		impl:    impl,
+		methods: make(map[string]*grpc.MethodDesc, len(desc.Methods)),
+		streams: make(map[string]*grpc.StreamDesc, len(desc.Streams)),
+	}
+	for i := range desc.Methods {
+		svc.methods[desc.Methods[i].MethodName] = &desc.Methods[i]
+	}
+	for i := range desc.Streams {
+		svc.streams[desc.Streams[i].StreamName] = &desc.Streams[i]
+	}
+	r[desc.ServiceName] = svc
+}
+
+// mountTable is a copy-on-write table of the services mounted at runtime.
+type mountTable struct {
+	mu       sync.Mutex
+	mounts   map[string]mountRegistrar
+	services atomic.Value // map[string]*mountedService
+}
+
+func (t *mountTable) lookup(service string) (*mountedService, bool) {
+	services, _ := t.services.Load().(map[string]*mountedService)
+	svc, ok := services[service]
+	return svc, ok
+}
+
+// unary reports whether fullMethod is a mounted unary method.
+func (t *mountTable) unary(fullMethod string) bool {
+	service, method := splitMethod(fullMethod)
+	svc, ok := t.lookup(service)
+	if !ok {
+		return false
+	}
+	_, ok = svc.methods[method]
+	return ok
+}
+
+func (t *mountTable) update(name string, r mountRegistrar) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mounts == nil {
+		t.mounts = make(map[string]mountRegistrar)
+	}
+	if r != nil {
+		t.mounts[name] = r
+	} else {
+		delete(t.mounts, name)
+	}
+	services := make(map[string]*mountedService)
+	for _, r := range t.mounts {
+		for name, svc := range r {
+			services[name] = svc
+		}
+	}
+	t.services.Store(services)
+}
+
+// Mount registers the services registered by register under name while the
+// server may be running, replacing the services previously mounted under the same name:
+//
+//	srv.Mount("greeter", func(r grpc.ServiceRegistrar) {
+//		v1.RegisterGreeterServer(r, greeter)
+//	})
+//
+// Mounted services are served by the unknown service handler, they aren't
+// listed by the reflection service.
+func (s *Server) Mount(name string, register func(grpc.ServiceRegistrar)) {
+	r := make(mountRegistrar)
+	register(r)
+	s.mounts.update(name, r)
+}
+
+// Unmount removes the services mounted under name.
+func (s *Server) Unmount(name string) {
+	s.mounts.update(name, nil)
+}
+
+// serveMounted is the unknown service handler, the stream interceptors already ran.
+func (s *Server) serveMounted(_ interface{}, stream grpc.ServerStream) error {
+	fullMethod, _ := grpc.MethodFromServerStream(stream)
+	service, method := splitMethod(fullMethod)
+	svc, ok := s.mounts.lookup(service)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown service %v", service)
+	}
+	if desc, ok := svc.streams[method]; ok {
+		return desc.Handler(svc.impl, stream)
+	}
+	desc, ok := svc.methods[method]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %v for service %v", method, service)
+	}
+	ctx := stream.Context()
+	reply, err := desc.Handler(svc.impl, ctx, stream.RecvMsg, s.mountedInterceptor)
+	// the reply header must be sent before the reply.
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		if md, ok := tr.ReplyHeader().(headerCarrier); ok && len(md) > 0 {
+			_ = stream.SetHeader(grpcmd.MD(md))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return stream.SendMsg(reply)
+}
+
+// mountedInterceptor applies the timeout, the middleware and the unary
+// interceptors to a mounted unary method.
+func (s *Server) mountedInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	next := handler
+	for i := len(s.unaryInts) - 1; i >= 0; i-- {
+		in, n := s.unaryInts[i], next
+		next = func(ctx context.Context, req interface{}) (interface{}, error) {
+			return in(ctx, req, info, n)
+		}
+	}
+	h := middleware.Handler(next)
+	if ms := s.matcher.Match(info.FullMethod); len(ms) > 0 {
+		h = middleware.Chain(ms...)(h)
+	}
+	if len(s.middleware) > 0 {
+		h = middleware.Chain(s.middleware...)(h)
+	}
+	return h(ctx, req)
+}
+
+func splitMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return fullMethod, ""
+}
@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/stats"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ stats.Handler = (*connObserver)(nil)
+
+type connInfoKey struct{}
+
+// connObserver is a stats.Handler emitting connection events to observers.
+type connObserver struct {
+	observers transport.Observers
+}
+
+func (h *connObserver) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return context.WithValue(ctx, connInfoKey{}, transport.ConnInfo{
+		Kind:       transport.KindGRPC,
+		LocalAddr:  info.LocalAddr,
+		RemoteAddr: info.RemoteAddr,
+	})
+}
+
+func (h *connObserver) HandleConn(ctx context.Context, s stats.ConnStats) {
+	info, _ := ctx.Value(connInfoKey{}).(transport.ConnInfo)
+	switch s.(type) {
+	case *stats.ConnBegin:
+		h.observers.OnAccept(info)
+	case *stats.ConnEnd:
+		h.observers.OnClose(info)
+	}
+}
+
+func (h *connObserver) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *connObserver) HandleRPC(context.Context, stats.RPCStats) {}
@@ -0,0 +1,55 @@
+package grpc
+
+import (
+	"github.com/go-kratos/kratos/v2/internal/pause"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Pauser = (*Server)(nil)
+
+// PauseMetrics with accept pause metrics, paused is set to 1 while the
+// server is paused and pauses counts the pauses, either may be nil.
+func PauseMetrics(paused metrics.Gauge, pauses metrics.Counter) ServerOption {
+	return func(s *Server) {
+		s.pausedGauge = paused
+		s.pauses = pauses
+	}
+}
+
+// Pause stops accepting new connections, the accepted ones are still served
+// and the new ones wait in the listen backlog until Resume.
+func (s *Server) Pause() {
+	s.gate.Pause()
+}
+
+// Resume resumes accepting new connections.
+func (s *Server) Resume() {
+	s.gate.Resume()
+}
+
+// Paused reports whether the server is paused.
+func (s *Server) Paused() bool {
+	return s.gate.Paused()
+}
+
+func (s *Server) newGate() *pause.Gate {
+	return pause.New(func(paused bool) {
+		if paused {
+			s.log.Info("[gRPC] server paused accepting connections")
+			if s.pauses != nil {
+				s.pauses.Inc()
+			}
+			if s.pausedGauge != nil {
+				s.pausedGauge.Set(1)
+			}
+			s.observers.OnPause(transport.KindGRPC)
+			return
+		}
+		s.log.Info("[gRPC] server resumed accepting connections")
+		if s.pausedGauge != nil {
+			s.pausedGauge.Set(0)
+		}
+		s.observers.OnResume(transport.KindGRPC)
+	})
+}
@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+var _ grpc.ClientConnInterface = (*Pool)(nil)
+
+// Pool is a pool of connections to the same endpoint, the RPCs are spread
+// round-robin across the connections so that the high-QPS callers aren't
+// bottlenecked on the streams of a single HTTP/2 connection.
+type Pool struct {
+	// next is accessed atomically, so it's first to be 64-bit aligned on 32-bit platforms.
+	next  uint64
+	conns []*grpc.ClientConn
+}
+
+// DialPool returns a pool of GRPC connections, see WithConnectionPool.
+func DialPool(ctx context.Context, opts ...ClientOption) (*Pool, error) {
+	return dialPool(ctx, false, opts...)
+}
+
+// DialInsecurePool returns a pool of insecure GRPC connections, see WithConnectionPool.
+func DialInsecurePool(ctx context.Context, opts ...ClientOption) (*Pool, error) {
+	return dialPool(ctx, true, opts...)
+}
+
+func dialPool(ctx context.Context, insecure bool, opts ...ClientOption) (*Pool, error) {
+	options := newClientOptions(opts...)
+	if options.poolSize < 1 {
+		options.poolSize = 1
+	}
+	p := &Pool{conns: make([]*grpc.ClientConn, 0, options.poolSize)}
+	for i := 0; i < options.poolSize; i++ {
+		conn, err := dialOptions(ctx, insecure, options)
+		if err != nil {
+			_ = p.Close()
+			return nil, err
+		}
+		p.conns = append(p.conns, conn)
+	}
+	return p, nil
+}
+
+// Conn returns the next connection of the pool.
+func (p *Pool) Conn() *grpc.ClientConn {
+	n := atomic.AddUint64(&p.next, 1)
+	return p.conns[n%uint64(len(p.conns))]
+}
+
+// Invoke performs a unary RPC on the next connection of the pool.
+func (p *Pool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	return p.Conn().Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream begins a streaming RPC on the next connection of the pool.
+func (p *Pool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.Conn().NewStream(ctx, desc, method, opts...)
+}
+
+// Close closes all the connections of the pool.
+func (p *Pool) Close() error {
+	var err error
+	for _, conn := range p.conns {
+		if e := conn.Close(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+)
+
+func TestPool(t *testing.T) {
+	srv := NewServer()
+	pb.RegisterGreeterServer(srv, &server{})
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() { _ = srv.Stop(context.Background()) }()
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := DialInsecurePool(context.Background(),
+		WithEndpoint(e.Host),
+		WithConnectionPool(3),
+		WithKeepAlive(keepalive.ClientParameters{Time: time.Minute, Timeout: time.Second}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	client := pb.NewGreeterClient(pool)
+	for i := 0; i < 6; i++ {
+		if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(pool.conns) != 3 {
+		t.Fatalf("expect 3 connections, got %d", len(pool.conns))
+	}
+	for i, conn := range pool.conns {
+		if state := conn.GetState(); state != connectivity.Ready {
+			t.Errorf("expect connection %d ready, got %v", i, state)
+		}
+	}
+}
+
+func TestDial_ConnectionPool(t *testing.T) {
+	if _, err := DialInsecure(context.Background(), WithEndpoint("127.0.0.1:0"), WithConnectionPool(3)); err == nil {
+		t.Error("expect an error dialing a pool of connections")
+	}
+}
+
+func TestWithKeepAlive(t *testing.T) {
+	o := &clientOptions{}
+	WithKeepAlive(keepalive.ClientParameters{Time: time.Minute})(o)
+	if o.keepalive == nil || o.keepalive.Time != time.Minute {
+		t.Errorf("expect %v but got %v", time.Minute, o.keepalive)
+	}
+}
@@ -44,10 +44,11 @@ type builder struct {
 	insecure   bool
 }
 
-// NewBuilder creates a builder which is used to factory registry resolvers.
+// NewBuilder creates a builder which is used to factory registry resolvers,
+// the services are watched again after the watchers fail.
 func NewBuilder(d registry.Discovery, opts ...Option) resolver.Builder {
 	b := &builder{
-		discoverer: d,
+		discoverer: registry.Resilient(d),
 		logger:     log.GetLogger(),
 		timeout:    time.Second * 10,
 		insecure:   false,
@@ -33,7 +33,7 @@ func (r *discoveryResolver) watch() {
 		}
 		ins, err := r.w.Next()
 		if err != nil {
-			if errors.Is(err, context.Canceled) {
+			if errors.Is(err, context.Canceled) || errors.Is(err, registry.ErrWatcherStopped) {
 				return
 			}
 			r.log.Errorf("[resolver] Failed to watch discovery endpoint: %v", err)
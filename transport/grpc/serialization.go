@@ -0,0 +1,251 @@
+package grpc
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+var (
+	_ stats.Handler  = (*serialization)(nil)
+	_ encoding.Codec = (*call)(nil)
+)
+
+// maxPendingTimings bounds the timings of the server messages whose payload
+// event is never emitted, e.g. when the message is too large to be sent, the
+// oldest ones are dropped first.
+const maxPendingTimings = 4096
+
+type methodKey struct{}
+
+type callKey struct{}
+
+// serialization records the marshal and unmarshal durations and the payload sizes
+// of the messages as span events and metrics per operation. The codec measures
+// the durations, which are reported by the stats handler along with the payload
+// event of the same message.
+//
+// The client calls carry their own codec in the context of the call, so their
+// timings never mix. The server codec is shared by the calls, its timings are
+// matched by message and each message event follows its codec call.
+type serialization struct {
+	// histogram: {client,server}_serialization_duration_seconds{kind, operation, action}
+	duration metrics.Observer
+	// histogram: {client,server}_serialization_size_bytes{kind, operation, action}
+	size metrics.Observer
+
+	mu      sync.Mutex
+	order   *list.List // of *pendingTiming, oldest first
+	pending map[proto.Message][]*list.Element
+}
+
+type pendingTiming struct {
+	msg proto.Message
+	d   time.Duration
+}
+
+func newSerialization(duration, size metrics.Observer) *serialization {
+	return &serialization{
+		duration: duration,
+		size:     size,
+		order:    list.New(),
+		pending:  make(map[proto.Message][]*list.Element),
+	}
+}
+
+func (s *serialization) marshal(next MarshalFunc) MarshalFunc {
+	return func(v interface{}) ([]byte, error) {
+		start := time.Now()
+		data, err := next(v)
+		if err == nil {
+			s.record(v, time.Since(start))
+		}
+		return data, err
+	}
+}
+
+func (s *serialization) unmarshal(next UnmarshalFunc) UnmarshalFunc {
+	return func(data []byte, v interface{}) error {
+		start := time.Now()
+		err := next(data, v)
+		if err == nil {
+			s.record(v, time.Since(start))
+		}
+		return err
+	}
+}
+
+func (s *serialization) record(v interface{}, d time.Duration) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.order.Len() >= maxPendingTimings {
+		s.remove(s.order.Front())
+	}
+	s.pending[m] = append(s.pending[m], s.order.PushBack(&pendingTiming{msg: m, d: d}))
+}
+
+func (s *serialization) take(v interface{}) (time.Duration, bool) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	es := s.pending[m]
+	if len(es) == 0 {
+		return 0, false
+	}
+	e := es[0]
+	s.remove(e)
+	return e.Value.(*pendingTiming).d, true
+}
+
+func (s *serialization) remove(e *list.Element) {
+	t := s.order.Remove(e).(*pendingTiming)
+	es := s.pending[t.msg]
+	for i := range es {
+		if es[i] == e {
+			es = append(es[:i], es[i+1:]...)
+			break
+		}
+	}
+	if len(es) == 0 {
+		delete(s.pending, t.msg)
+	} else {
+		s.pending[t.msg] = es
+	}
+}
+
+// unaryClientInterceptor runs the client calls with their own codec.
+func (s *serialization) unaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		c := newCall()
+		return invoker(context.WithValue(ctx, callKey{}, c), method, req, reply, cc, append(opts, grpc.ForceCodec(c))...)
+	}
+}
+
+// streamClientInterceptor runs the client streams with their own codec.
+func (s *serialization) streamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		c := newCall()
+		return streamer(context.WithValue(ctx, callKey{}, c), desc, cc, method, append(opts, grpc.ForceCodec(c))...)
+	}
+}
+
+func (s *serialization) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, methodKey{}, info.FullMethodName)
+}
+
+func (s *serialization) HandleRPC(ctx context.Context, rs stats.RPCStats) {
+	switch p := rs.(type) {
+	case *stats.InPayload:
+		s.report(ctx, "unmarshal", p.Payload, p.Length)
+	case *stats.OutPayload:
+		s.report(ctx, "marshal", p.Payload, p.Length)
+	}
+}
+
+func (s *serialization) report(ctx context.Context, action string, msg interface{}, length int) {
+	var (
+		d  time.Duration
+		ok bool
+	)
+	if c, isCall := ctx.Value(callKey{}).(*call); isCall {
+		d, ok = c.take(action)
+	} else {
+		d, ok = s.take(msg)
+	}
+	if !ok {
+		return
+	}
+	method, _ := ctx.Value(methodKey{}).(string)
+	if s.duration != nil {
+		s.duration.With("grpc", method, action).Observe(d.Seconds())
+	}
+	if s.size != nil {
+		s.size.With("grpc", method, action).Observe(float64(length))
+	}
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.AddEvent("message."+action, trace.WithAttributes(
+			attribute.Int("message.size", length),
+			attribute.Int64("message.duration_us", d.Microseconds()),
+		))
+	}
+}
+
+func (s *serialization) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (s *serialization) HandleConn(context.Context, stats.ConnStats) {}
+
+// call is the proto codec of a client call recording the duration of its last
+// marshal and unmarshal, the messages of a call are sent and received in turn.
+type call struct {
+	base encoding.Codec
+
+	mu          sync.Mutex
+	marshaled   *time.Duration
+	unmarshaled *time.Duration
+}
+
+func newCall() *call {
+	return &call{base: newCodec(nil, nil)}
+}
+
+func (c *call) Marshal(v interface{}) ([]byte, error) {
+	start := time.Now()
+	data, err := c.base.Marshal(v)
+	if err == nil {
+		d := time.Since(start)
+		c.mu.Lock()
+		c.marshaled = &d
+		c.mu.Unlock()
+	}
+	return data, err
+}
+
+func (c *call) Unmarshal(data []byte, v interface{}) error {
+	start := time.Now()
+	err := c.base.Unmarshal(data, v)
+	if err == nil {
+		d := time.Since(start)
+		c.mu.Lock()
+		c.unmarshaled = &d
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *call) Name() string {
+	return c.base.Name()
+}
+
+func (c *call) take(action string) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := &c.marshaled
+	if action == "unmarshal" {
+		p = &c.unmarshaled
+	}
+	if *p == nil {
+		return 0, false
+	}
+	d := **p
+	*p = nil
+	return d, true
+}
@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+type testMetricObserver struct {
+	mu     *sync.Mutex
+	lvs    []string
+	values map[string]int
+}
+
+func newMetricObserver() *testMetricObserver {
+	return &testMetricObserver{mu: &sync.Mutex{}, values: make(map[string]int)}
+}
+
+func (o *testMetricObserver) With(lvs ...string) metrics.Observer {
+	return &testMetricObserver{mu: o.mu, lvs: lvs, values: o.values}
+}
+
+func (o *testMetricObserver) Observe(float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.values[strings.Join(o.lvs, ",")]++
+}
+
+func (o *testMetricObserver) count(lvs string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.values[lvs]
+}
+
+func TestSerialization(t *testing.T) {
+	serverDuration, serverSize := newMetricObserver(), newMetricObserver()
+	srv := NewServer(Serialization(serverDuration, serverSize))
+	srv.Mount("greeter", func(r grpc.ServiceRegistrar) {
+		pb.RegisterGreeterServer(r, &server{})
+	})
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() { _ = srv.Stop(context.Background()) }()
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientDuration, clientSize := newMetricObserver(), newMetricObserver()
+	other := &countingHandler{}
+	conn, err := DialInsecure(context.Background(), WithEndpoint(e.Host), WithSerialization(clientDuration, clientSize), WithStatsHandler(other))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); err != nil {
+		t.Fatal(err)
+	}
+
+	const method = "grpc,/helloworld.Greeter/SayHello,"
+	for _, o := range []*testMetricObserver{serverDuration, serverSize, clientDuration, clientSize} {
+		if o.count(method+"marshal") != 1 || o.count(method+"unmarshal") != 1 {
+			t.Errorf("observed %v", o.values)
+		}
+	}
+	if n := atomic.LoadInt32(&other.payloads); n != 2 {
+		t.Errorf("expect the other stats handler to get 2 payloads, got %d", n)
+	}
+	srv.serialization.mu.Lock()
+	defer srv.serialization.mu.Unlock()
+	if n := srv.serialization.order.Len(); n != 0 {
+		t.Errorf("%d timings pending", n)
+	}
+}
+
+type countingHandler struct {
+	payloads int32
+}
+
+func (h *countingHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *countingHandler) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch s.(type) {
+	case *stats.InPayload, *stats.OutPayload:
+		atomic.AddInt32(&h.payloads, 1)
+	}
+}
+
+func (h *countingHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *countingHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+func TestSerialization_Pending(t *testing.T) {
+	s := newSerialization(nil, nil)
+	shared := &pb.HelloReply{}
+	s.record(shared, time.Millisecond)
+	s.record(shared, 2*time.Millisecond)
+	for _, want := range []time.Duration{time.Millisecond, 2 * time.Millisecond} {
+		if d, ok := s.take(shared); !ok || d != want {
+			t.Errorf("got %v %v, want %v", d, ok, want)
+		}
+	}
+	if _, ok := s.take(shared); ok {
+		t.Error("expect no timing pending")
+	}
+	// the timings never reported are dropped, the oldest first.
+	first := &pb.HelloReply{}
+	s.record(first, time.Millisecond)
+	for i := 0; i < maxPendingTimings; i++ {
+		s.record(&pb.HelloReply{}, time.Millisecond)
+	}
+	last := &pb.HelloReply{}
+	s.record(last, time.Millisecond)
+	if _, ok := s.take(first); ok {
+		t.Error("expect the oldest timing dropped")
+	}
+	if _, ok := s.take(last); !ok {
+		t.Error("expect the latest timing kept")
+	}
+	if n := s.order.Len(); n != maxPendingTimings-1 || len(s.pending) != n {
+		t.Errorf("got %d timings, want %d", n, maxPendingTimings-1)
+	}
+}
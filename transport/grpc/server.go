@@ -7,26 +7,33 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/guard"
 	"github.com/go-kratos/kratos/v2/internal/endpoint"
 
 	apimd "github.com/go-kratos/kratos/v2/api/metadata"
 
 	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
+	"github.com/go-kratos/kratos/v2/internal/pause"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 
 	"google.golang.org/grpc"
+	channelz "google.golang.org/grpc/channelz/service"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/stats"
 )
 
 var (
 	_ transport.Server     = (*Server)(nil)
 	_ transport.Endpointer = (*Server)(nil)
+	_ guard.Reporter       = (*Server)(nil)
 )
 
 // ServerOption is gRPC server option.
@@ -53,6 +60,15 @@ func Timeout(timeout time.Duration) ServerOption {
 	}
 }
 
+// StopTimeout with the maximum duration Stop waits for the in-flight RPCs,
+// including the streams, before closing the connections, default is 0, it
+// waits until the context of Stop is done.
+func StopTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.stopTimeout = timeout
+	}
+}
+
 // Logger with server logger.
 func Logger(logger log.Logger) ServerOption {
 	return func(s *Server) {
@@ -60,7 +76,8 @@ func Logger(logger log.Logger) ServerOption {
 	}
 }
 
-// Middleware with server middleware.
+// Middleware with server middleware, it's called once per stream as well
+// with the grpc.ServerStream as the request and a nil reply.
 func Middleware(m ...middleware.Middleware) ServerOption {
 	return func(s *Server) {
 		s.middleware = m
@@ -81,6 +98,14 @@ func Listener(lis net.Listener) ServerOption {
 	}
 }
 
+// ReusePort with SO_REUSEPORT set on the listener, so that the new process
+// of a rolling restart binds the address before the old one stops.
+func ReusePort(enable bool) ServerOption {
+	return func(s *Server) {
+		s.reusePort = enable
+	}
+}
+
 // UnaryInterceptor returns a ServerOption that sets the UnaryServerInterceptor for the server.
 func UnaryInterceptor(in ...grpc.UnaryServerInterceptor) ServerOption {
 	return func(s *Server) {
@@ -102,6 +127,81 @@ func Options(opts ...grpc.ServerOption) ServerOption {
 	}
 }
 
+// Observer with connection and request observers.
+func Observer(obs ...transport.Observer) ServerOption {
+	return func(s *Server) {
+		s.observers = obs
+	}
+}
+
+// StatsHandler with gRPC stats handlers.
+func StatsHandler(h ...stats.Handler) ServerOption {
+	return func(s *Server) {
+		s.statsHandlers = h
+	}
+}
+
+// MarshalInterceptors with marshal interceptors, the first one is the outermost.
+// They replace the codec negotiated by the content-subtype with the proto codec.
+func MarshalInterceptors(in ...MarshalInterceptor) ServerOption {
+	return func(s *Server) {
+		s.marshalInts = in
+	}
+}
+
+// UnmarshalInterceptors with unmarshal interceptors, the first one is the outermost.
+// They replace the codec negotiated by the content-subtype with the proto codec.
+func UnmarshalInterceptors(in ...UnmarshalInterceptor) ServerOption {
+	return func(s *Server) {
+		s.unmarshalInts = in
+	}
+}
+
+// Serialization with the serialization metrics, the marshal and unmarshal durations
+// and the payload sizes of the messages are observed per operation and recorded as
+// events of the span in the stats context, either observer may be nil.
+// Like MarshalInterceptors, it replaces the codec negotiated by the content-subtype
+// with the proto codec, and a codec forced by grpc.ForceServerCodec in Options
+// replaces the measuring one, no durations are observed then.
+func Serialization(duration, size metrics.Observer) ServerOption {
+	return func(s *Server) {
+		s.serialization = newSerialization(duration, size)
+	}
+}
+
+// CustomHealth with the health service not registered, e.g. to register a
+// custom one. SetServingStatus has no effect then.
+func CustomHealth() ServerOption {
+	return func(s *Server) {
+		s.customHealth = true
+	}
+}
+
+// Reflection with the reflection service registered, default is true.
+func Reflection(enable bool) ServerOption {
+	return func(s *Server) {
+		s.reflection = enable
+	}
+}
+
+// OperationFunc derives the operation of a request from its full method.
+type OperationFunc func(fullMethod string) string
+
+// OperationExtractor with the func deriving the operation of the requests,
+// default is the full method, e.g. /helloworld.Greeter/SayHello.
+func OperationExtractor(f OperationFunc) ServerOption {
+	return func(s *Server) {
+		s.operation = f
+	}
+}
+
+// Channelz with the channelz service registered and channelz data collection enabled.
+func Channelz(enable bool) ServerOption {
+	return func(s *Server) {
+		s.channelz = enable
+	}
+}
+
 // Server is a gRPC server wrapper.
 type Server struct {
 	*grpc.Server
@@ -111,30 +211,53 @@ type Server struct {
 	err        error
 	network    string
 	address    string
+	reusePort  bool
 	endpoint   *url.URL
 	timeout    time.Duration
 	log        *log.Helper
 	middleware []middleware.Middleware
+	matcher    *matcher.Matcher
 	unaryInts  []grpc.UnaryServerInterceptor
 	streamInts []grpc.StreamServerInterceptor
 	grpcOpts   []grpc.ServerOption
 	health     *health.Server
 	metadata   *apimd.Server
+	observers  transport.Observers
+
+	statsHandlers []stats.Handler
+	channelz      bool
+	marshalInts   []MarshalInterceptor
+	unmarshalInts []UnmarshalInterceptor
+	operation     OperationFunc
+	mounts        mountTable
+	gate          *pause.Gate
+	pausedGauge   metrics.Gauge
+	pauses        metrics.Counter
+	serialization *serialization
+	customHealth  bool
+	stopTimeout   time.Duration
+	strict        bool
+	grace         time.Duration
+	leaks         metrics.Counter
+	reflection    bool
 }
 
 // NewServer creates a gRPC server by options.
 func NewServer(opts ...ServerOption) *Server {
 	srv := &Server{
-		baseCtx: context.Background(),
-		network: "tcp",
-		address: ":0",
-		timeout: 1 * time.Second,
-		health:  health.NewServer(),
-		log:     log.NewHelper(log.GetLogger()),
+		baseCtx:    context.Background(),
+		network:    "tcp",
+		address:    ":0",
+		timeout:    1 * time.Second,
+		health:     health.NewServer(),
+		log:        log.NewHelper(log.GetLogger()),
+		matcher:    matcher.New(),
+		reflection: true,
 	}
 	for _, o := range opts {
 		o(srv)
 	}
+	srv.gate = srv.newGate()
 	unaryInts := []grpc.UnaryServerInterceptor{
 		srv.unaryServerInterceptor(),
 	}
@@ -150,10 +273,28 @@ func NewServer(opts ...ServerOption) *Server {
 	grpcOpts := []grpc.ServerOption{
 		grpc.ChainUnaryInterceptor(unaryInts...),
 		grpc.ChainStreamInterceptor(streamInts...),
+		grpc.UnknownServiceHandler(srv.serveMounted),
+	}
+	if srv.serialization != nil {
+		srv.marshalInts = append(srv.marshalInts, srv.serialization.marshal)
+		srv.unmarshalInts = append(srv.unmarshalInts, srv.serialization.unmarshal)
+	}
+	if len(srv.marshalInts) > 0 || len(srv.unmarshalInts) > 0 {
+		grpcOpts = append(grpcOpts, grpc.ForceServerCodec(newCodec(srv.marshalInts, srv.unmarshalInts)))
 	}
 	if srv.tlsConf != nil {
 		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(srv.tlsConf)))
 	}
+	handlers := statsHandlers(srv.statsHandlers)
+	if srv.serialization != nil {
+		handlers = append(handlers, srv.serialization)
+	}
+	if len(srv.observers) > 0 {
+		handlers = append(handlers, &connObserver{observers: srv.observers})
+	}
+	if len(handlers) > 0 {
+		grpcOpts = append(grpcOpts, grpc.StatsHandler(handlers))
+	}
 	if len(srv.grpcOpts) > 0 {
 		grpcOpts = append(grpcOpts, srv.grpcOpts...)
 	}
@@ -162,13 +303,50 @@ func NewServer(opts ...ServerOption) *Server {
 	// listen and endpoint
 	srv.err = srv.listenAndEndpoint()
 	// internal register
-	grpc_health_v1.RegisterHealthServer(srv.Server, srv.health)
+	if !srv.customHealth {
+		grpc_health_v1.RegisterHealthServer(srv.Server, srv.health)
+	}
 	apimd.RegisterMetadataServer(srv.Server, srv.metadata)
-	reflection.Register(srv.Server)
+	if srv.reflection {
+		reflection.Register(srv.Server)
+	}
+	if srv.channelz {
+		channelz.RegisterChannelzServiceToServer(srv.Server)
+	}
 	return srv
 }
 
-// Endpoint return a real address to registry endpoint.
+// Use uses service or method middleware, applied after the server middleware:
+//
+//	srv.Use("/helloworld.Greeter/*", auth)
+//	srv.Use("/helloworld.Greeter/SayHello", ratelimit.Server())
+//
+// It must be called before the server starts.
+func (s *Server) Use(selector string, m ...middleware.Middleware) {
+	s.matcher.Add(selector, m...)
+}
+
+// SetServingStatus sets the serving status of service in the health service,
+// the empty service is the status of the server. The status of all services is
+// SERVING when the server starts and NOT_SERVING when it stops.
+func (s *Server) SetServingStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	s.health.SetServingStatus(service, status)
+}
+
+// Hazards returns the reflection service and the channelz service if registered.
+func (s *Server) Hazards() []guard.Hazard {
+	var hazards []guard.Hazard
+	if s.reflection {
+		hazards = append(hazards, guard.Hazard{Kind: guard.Reflection, Target: "/grpc.reflection.v1alpha.ServerReflection/"})
+	}
+	if s.channelz {
+		hazards = append(hazards, guard.Hazard{Kind: guard.DebugEndpoint, Target: "/grpc.channelz.v1.Channelz/"})
+	}
+	return hazards
+}
+
+// Endpoint return a real address to registry endpoint, the listener is bound
+// by NewServer so the real port of ":0" is known before the server starts.
 // examples:
 //   grpc://127.0.0.1:9000?isSecure=false
 func (s *Server) Endpoint() (*url.URL, error) {
@@ -186,20 +364,37 @@ func (s *Server) Start(ctx context.Context) error {
 	s.baseCtx = ctx
 	s.log.Infof("[gRPC] server listening on: %s", s.lis.Addr().String())
 	s.health.Resume()
-	return s.Serve(s.lis)
+	return s.Serve(s.gate.Listener(s.lis))
 }
 
-// Stop stop the gRPC server.
+// Stop stop the gRPC server gracefully, the connections are closed when the
+// stop timeout elapses or ctx is done before the in-flight RPCs complete.
 func (s *Server) Stop(ctx context.Context) error {
 	s.health.Shutdown()
-	s.GracefulStop()
+	if s.stopTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.stopTimeout)
+		defer cancel()
+	}
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.log.Warn("[gRPC] server couldn't stop gracefully in time, doing force stop")
+		s.Server.Stop()
+		<-done
+	}
 	s.log.Info("[gRPC] server stopping")
 	return nil
 }
 
 func (s *Server) listenAndEndpoint() error {
 	if s.lis == nil {
-		lis, err := net.Listen(s.network, s.address)
+		lis, err := host.Listen(s.network, s.address, s.reusePort)
 		if err != nil {
 			return err
 		}
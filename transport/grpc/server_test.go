@@ -8,16 +8,22 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/matcher"
 	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
 )
 
 // server is used to implement helloworld.GreeterServer.
@@ -70,7 +76,6 @@ func TestServer(t *testing.T) {
 			panic(err)
 		}
 	}()
-	time.Sleep(time.Second)
 	testClient(t, srv)
 	_ = srv.Stop(ctx)
 }
@@ -238,6 +243,23 @@ func TestOptions(t *testing.T) {
 	}
 }
 
+func TestStatsHandler(t *testing.T) {
+	o := &Server{}
+	v := []stats.Handler{statsHandlers{}}
+	StatsHandler(v...)(o)
+	if !reflect.DeepEqual(v, o.statsHandlers) {
+		t.Errorf("expect %v, got %v", v, o.statsHandlers)
+	}
+}
+
+func TestChannelz(t *testing.T) {
+	o := &Server{}
+	Channelz(true)(o)
+	if !o.channelz {
+		t.Errorf("expect %v, got %v", true, o.channelz)
+	}
+}
+
 type testResp struct {
 	Data string
 }
@@ -273,3 +295,266 @@ func TestListener(t *testing.T) {
 		t.Errorf("expect %v, got %v", lis, s.lis)
 	}
 }
+
+type testObserver struct {
+	transport.NopObserver
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *testObserver) add(event string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+}
+
+func (o *testObserver) OnAccept(transport.ConnInfo)    { o.add("accept") }
+func (o *testObserver) OnRequestStart(context.Context) { o.add("start") }
+func (o *testObserver) OnRequestEnd(_ context.Context, e error) {
+	o.add(fmt.Sprintf("end:%v", e != nil))
+}
+func (o *testObserver) OnClose(transport.ConnInfo) { o.add("close") }
+
+func TestObserver(t *testing.T) {
+	o := &testObserver{}
+	srv := NewServer(Observer(o))
+	pb.RegisterGreeterServer(srv, &server{})
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := DialInsecure(context.Background(), WithEndpoint(e.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "error"})
+	if err == nil {
+		t.Fatal("expect error, got nil")
+	}
+	_ = conn.Close()
+	_ = srv.Stop(context.Background())
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	expect := []string{"accept", "start", "end:true", "close"}
+	if !reflect.DeepEqual(expect, o.events) {
+		t.Errorf("expect %v, got %v", expect, o.events)
+	}
+}
+
+func TestMount(t *testing.T) {
+	srv := NewServer()
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() { _ = srv.Stop(context.Background()) }()
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := DialInsecure(context.Background(), WithEndpoint(e.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewGreeterClient(conn)
+	if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("expect unimplemented before mount, got %v", err)
+	}
+	srv.Mount("greeter", func(r grpc.ServiceRegistrar) {
+		pb.RegisterGreeterServer(r, &server{})
+	})
+	reply, err := client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "Hello kratos" {
+		t.Errorf("expect Hello kratos, got %s", reply.Message)
+	}
+	srv.Unmount("greeter")
+	if _, err = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"}); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("expect unimplemented after unmount, got %v", err)
+	}
+}
+
+func TestUse(t *testing.T) {
+	u, err := url.Parse("grpc://hello/world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{
+		baseCtx:  context.Background(),
+		endpoint: u,
+		matcher:  matcher.New(),
+	}
+	var calls []string
+	tag := func(name string) middleware.Middleware {
+		return func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				calls = append(calls, name)
+				return handler(ctx, req)
+			}
+		}
+	}
+	srv.Use("/helloworld.Greeter/*", tag("service"))
+	srv.Use("/helloworld.Greeter/SayHello", tag("method"))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	for _, method := range []string{"/helloworld.Greeter/SayHello", "/helloworld.Greeter/SayHi", "/other.Service/Call"} {
+		_, err = srv.unaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := []string{"service", "method", "service"}
+	if !reflect.DeepEqual(expect, calls) {
+		t.Errorf("expect %v, got %v", expect, calls)
+	}
+}
+
+func TestOperationExtractor(t *testing.T) {
+	u, err := url.Parse("grpc://hello/world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{baseCtx: context.Background(), endpoint: u}
+	OperationExtractor(func(fullMethod string) string {
+		return strings.TrimPrefix(fullMethod, "/helloworld.")
+	})(srv)
+	_, err = srv.unaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayHello"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		tr, _ := transport.FromServerContext(ctx)
+		if op := tr.Operation(); op != "Greeter/SayHello" {
+			t.Errorf("expect Greeter/SayHello, got %s", op)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	check := func(srv *Server, service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+		u, err := srv.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := DialInsecure(context.Background(), WithEndpoint(u.Host))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		resp, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+		return resp.GetStatus(), err
+	}
+
+	srv := NewServer()
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+	time.Sleep(50 * time.Millisecond)
+	if st, err := check(srv, ""); err != nil || st != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("got %v %v, want serving", st, err)
+	}
+	srv.SetServingStatus("helloworld.Greeter", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	if st, err := check(srv, "helloworld.Greeter"); err != nil || st != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("got %v %v, want not serving", st, err)
+	}
+
+	custom := NewServer(CustomHealth())
+	go func() { _ = custom.Start(context.Background()) }()
+	defer func() { _ = custom.Stop(context.Background()) }()
+	time.Sleep(50 * time.Millisecond)
+	if _, err := check(custom, ""); status.Code(err) != codes.Unimplemented {
+		t.Errorf("got %v, want the health service not registered", err)
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	started := make(chan struct{})
+	srv := NewServer(Timeout(0), StopTimeout(50*time.Millisecond))
+	pb.RegisterGreeterServer(srv, &funcGreeter{sayHello: func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Start(context.Background()) }()
+	conn, err := DialInsecure(context.Background(), WithEndpoint(u.Host), WithTimeout(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	go func() { _, _ = pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{}) }()
+	<-started
+
+	begin := time.Now()
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(begin); elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("got stopped in %s, want the in-flight RPC waited for 50ms", elapsed)
+	}
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context { return s.ctx }
+
+type ctxKey struct{}
+
+func TestStreamMiddleware(t *testing.T) {
+	u, err := url.Parse("grpc://hello/world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	srv := &Server{
+		baseCtx:  context.Background(),
+		endpoint: u,
+		matcher:  matcher.New(),
+		middleware: []middleware.Middleware{func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				tr, _ := transport.FromServerContext(ctx)
+				calls = append(calls, tr.Operation())
+				ss := req.(grpc.ServerStream)
+				return handler(context.WithValue(ctx, ctxKey{}, "value"), &testStream{ServerStream: ss, ctx: ss.Context()})
+			}
+		}},
+	}
+	srv.Mount("greeter", func(r grpc.ServiceRegistrar) {
+		pb.RegisterGreeterServer(r, &server{})
+	})
+	var mounted bool
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		if mounted {
+			return nil
+		}
+		if stream.Context().Value(ctxKey{}) != "value" {
+			t.Errorf("expect the context of the middleware")
+		}
+		if _, ok := stream.(*wrappedStream).ServerStream.(*testStream); !ok {
+			t.Errorf("expect the stream of the middleware")
+		}
+		return nil
+	}
+	for _, method := range []string{"/test.Stream/Watch", "/helloworld.Greeter/SayHello"} {
+		mounted = method == "/helloworld.Greeter/SayHello"
+		ss := &testStream{ctx: context.Background()}
+		if err = srv.streamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{FullMethod: method}, handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if expect := []string{"/test.Stream/Watch"}; !reflect.DeepEqual(expect, calls) {
+		t.Errorf("expect %v, got %v", expect, calls)
+	}
+}
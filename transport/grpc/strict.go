@@ -0,0 +1,52 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+// Strict with the strict mode: the handlers still running a grace period after
+// their context is done, canceled or past its deadline, are logged and counted
+// by operation as leaks, the counter may be nil. The calls and streams of the
+// gRPC and HTTP clients made while handling a request are canceled with it or
+// once the handler returns, even with a context detached from its cancellation
+// which keeps its values.
+func Strict(grace time.Duration, leaks metrics.Counter) ServerOption {
+	return func(s *Server) {
+		s.strict = true
+		s.grace = grace
+		s.leaks = leaks
+	}
+}
+
+// watch watches the handler of a request with the context ctx, until the
+// returned func is called when the handler returns. The calls of the request
+// are canceled by cancelCalls once ctx is done or the handler returns.
+func (s *Server) watch(ctx context.Context, operation string, cancelCalls func()) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+		cancelCalls()
+		timer := time.NewTimer(s.grace)
+		defer timer.Stop()
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+		s.log.Warnf("[gRPC] strict: handler of %s still running %s after its context is done: %v", operation, s.grace, ctx.Err())
+		if s.leaks != nil {
+			s.leaks.With("grpc", operation).Inc()
+		}
+	}()
+	return func() {
+		close(done)
+		cancelCalls()
+	}
+}
@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+type testLeakCounter struct {
+	lvs   []string
+	count int64
+}
+
+func (c *testLeakCounter) With(lvs ...string) metrics.Counter { c.lvs = lvs; return c }
+func (c *testLeakCounter) Inc()                               { atomic.AddInt64(&c.count, 1) }
+func (c *testLeakCounter) Add(delta float64)                  { atomic.AddInt64(&c.count, int64(delta)) }
+
+type funcGreeter struct {
+	pb.UnimplementedGreeterServer
+	sayHello func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error)
+}
+
+func (g *funcGreeter) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	return g.sayHello(ctx, in)
+}
+
+func startGreeter(t *testing.T, f func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error), opts ...ServerOption) pb.GreeterClient {
+	srv := NewServer(append(opts, Address(":0"))...)
+	pb.RegisterGreeterServer(srv, &funcGreeter{sayHello: f})
+	u, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Start(context.Background()) }()
+	t.Cleanup(func() { _ = srv.Stop(context.Background()) })
+	conn, err := DialInsecure(context.Background(), WithEndpoint(u.Host), WithTimeout(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewGreeterClient(conn)
+}
+
+func TestStrictLeak(t *testing.T) {
+	leaks := &testLeakCounter{}
+	client := startGreeter(t, func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+		if in.Name == "leak" {
+			time.Sleep(200 * time.Millisecond)
+			return &pb.HelloReply{}, nil
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, Timeout(20*time.Millisecond), Strict(20*time.Millisecond, leaks))
+
+	_, _ = client.SayHello(context.Background(), &pb.HelloRequest{Name: "leak"})
+	if atomic.LoadInt64(&leaks.count) != 1 || leaks.lvs[1] != "/helloworld.Greeter/SayHello" {
+		t.Fatalf("got %d leaks %v, want 1", atomic.LoadInt64(&leaks.count), leaks.lvs)
+	}
+	_, _ = client.SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt64(&leaks.count) != 1 {
+		t.Errorf("expect the handlers returning on cancellation not to leak")
+	}
+}
+
+// detached is a context detached from the cancellation of its parent.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+
+func TestStrictPropagation(t *testing.T) {
+	canceled := make(chan error, 1)
+	downstream := startGreeter(t, func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return nil, ctx.Err()
+	})
+	upstream := startGreeter(t, func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+		return downstream.SayHello(detached{ctx}, in)
+	}, Timeout(0), Strict(time.Second, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := upstream.SayHello(ctx, &pb.HelloRequest{Name: "kratos"}); err == nil {
+		t.Fatal("expect the call to be canceled")
+	}
+	select {
+	case err := <-canceled:
+		if err == nil {
+			t.Error("expect the downstream context to be done")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expect the downstream call to be canceled with the request")
+	}
+}
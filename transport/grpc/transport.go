@@ -6,7 +6,10 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
-var _ transport.Transporter = &Transport{}
+var (
+	_ transport.Transporter = &Transport{}
+	_ transport.Securer     = &Transport{}
+)
 
 // Transport is a gRPC transport.
 type Transport struct {
@@ -15,6 +18,7 @@ type Transport struct {
 	reqHeader   headerCarrier
 	replyHeader headerCarrier
 	filters     []selector.Filter
+	secure      bool
 }
 
 // Kind returns the transport kind.
@@ -47,6 +51,12 @@ func (tr *Transport) SelectFilters() []selector.Filter {
 	return tr.filters
 }
 
+// Secure reports whether the client connection is dialed with a TLS config,
+// see WithTLSConfig.
+func (tr *Transport) Secure() bool {
+	return tr.secure
+}
+
 type headerCarrier metadata.MD
 
 // Get returns the value associated with the passed key.
@@ -63,6 +73,11 @@ func (mc headerCarrier) Set(key string, value string) {
 	metadata.MD(mc).Set(key, value)
 }
 
+// Del deletes the values associated with key.
+func (mc headerCarrier) Del(key string) {
+	metadata.MD(mc).Delete(key)
+}
+
 // Keys lists the keys stored in this carrier.
 func (mc headerCarrier) Keys() []string {
 	keys := make([]string, 0, len(mc))
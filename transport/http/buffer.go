@@ -0,0 +1,64 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledBuffer is the capacity above which buffers aren't returned to the pool,
+// so that a few large bodies don't pin memory.
+const maxPooledBuffer = 64 << 10
+
+var (
+	bufferPool = sync.Pool{New: func() interface{} {
+		atomic.AddUint64(&bufferStats.News, 1)
+		return bytes.NewBuffer(make([]byte, 0, 4<<10))
+	}}
+	bufferStats BufferStats
+)
+
+// BufferStats are the counters of the body buffer pool.
+type BufferStats struct {
+	// Gets is the number of buffers taken from the pool.
+	Gets uint64
+	// News is the number of buffers allocated because the pool was empty.
+	News uint64
+	// Discards is the number of buffers too large to be returned to the pool.
+	Discards uint64
+}
+
+// PoolStats returns the counters of the buffer pool used to read the bodies.
+func PoolStats() BufferStats {
+	return BufferStats{
+		Gets:     atomic.LoadUint64(&bufferStats.Gets),
+		News:     atomic.LoadUint64(&bufferStats.News),
+		Discards: atomic.LoadUint64(&bufferStats.Discards),
+	}
+}
+
+// readBody reads r into a pooled buffer and returns a copy of the bytes of the
+// exact size, the codecs decoding them may retain them, e.g. aliasing the
+// strings into them, so the pooled buffer is never handed to the codecs.
+// A read allocates the copy only, instead of the buffers grown from scratch
+// by io.ReadAll. The responses aren't pooled, the codecs encode them into
+// the bytes they allocate.
+func readBody(r io.Reader) ([]byte, error) {
+	atomic.AddUint64(&bufferStats.Gets, 1)
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer putBuffer(buf)
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return append(make([]byte, 0, buf.Len()), buf.Bytes()...), nil
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		atomic.AddUint64(&bufferStats.Discards, 1)
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
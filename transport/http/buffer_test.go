@@ -0,0 +1,83 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestReadBody(t *testing.T) {
+	body := bytes.Repeat([]byte("k"), 8<<10)
+	before := PoolStats()
+	data, err := readBody(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Errorf("expect the body to be read")
+	}
+	// the bytes are owned by the caller, not reused by the next reads
+	if _, err = readBody(bytes.NewReader(bytes.Repeat([]byte("v"), len(body)))); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Errorf("expect the body not to be overwritten")
+	}
+	if n := PoolStats().Gets - before.Gets; n != 2 {
+		t.Errorf("expect 2 gets, got %d", n)
+	}
+
+	if _, err = readBody(bytes.NewReader(make([]byte, 2*maxPooledBuffer))); err != nil {
+		t.Fatal(err)
+	}
+	if n := PoolStats().Discards - before.Discards; n != 1 {
+		t.Errorf("expect the large buffer to be discarded, got %d discards", n)
+	}
+}
+
+// raceEnabled is set when the tests run with the race detector.
+var raceEnabled bool
+
+func TestReadBody_Allocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the pool drops the buffers with the race detector")
+	}
+	for _, size := range []int{1 << 10, 4 << 10, 16 << 10} {
+		body := bytes.Repeat([]byte("k"), size)
+		r := bytes.NewReader(body)
+		readAll := testing.AllocsPerRun(100, func() {
+			r.Reset(body)
+			_, _ = io.ReadAll(r)
+		})
+		pooled := testing.AllocsPerRun(100, func() {
+			r.Reset(body)
+			_, _ = readBody(r)
+		})
+		if pooled > 1 || pooled >= readAll {
+			t.Errorf("%dKB: expect a single allocation, got %v (io.ReadAll: %v)", size>>10, pooled, readAll)
+		}
+	}
+}
+
+func BenchmarkReadBody(b *testing.B) {
+	for _, size := range []int{1 << 10, 4 << 10, 16 << 10} {
+		body := bytes.Repeat([]byte("k"), size)
+		b.Run(fmt.Sprintf("ReadAll/%dKB", size>>10), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := io.ReadAll(bytes.NewReader(body)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Pool/%dKB", size>>10), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := readBody(bytes.NewReader(body)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
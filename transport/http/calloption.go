@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"time"
 )
 
 // CallOption configures a Call before it starts or extracts information from
@@ -20,6 +21,7 @@ type callInfo struct {
 	contentType  string
 	operation    string
 	pathTemplate string
+	timeout      time.Duration
 }
 
 // EmptyCallOption does not alter the Call configuration.
@@ -90,6 +92,22 @@ func (o PathTemplateCallOption) before(c *callInfo) error {
 	return nil
 }
 
+// CallTimeout with the timeout of the call, it can't exceed the client timeout.
+func CallTimeout(timeout time.Duration) CallOption {
+	return TimeoutCallOption{Timeout: timeout}
+}
+
+// TimeoutCallOption is set timeout for client call
+type TimeoutCallOption struct {
+	EmptyCallOption
+	Timeout time.Duration
+}
+
+func (o TimeoutCallOption) before(c *callInfo) error {
+	c.timeout = o.Timeout
+	return nil
+}
+
 // Header returns a CallOptions that retrieves the http response header
 // from server reply.
 func Header(header *http.Header) CallOption {
@@ -11,8 +11,9 @@ import (
 
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
+	ic "github.com/go-kratos/kratos/v2/internal/context"
 	"github.com/go-kratos/kratos/v2/internal/host"
-	"github.com/go-kratos/kratos/v2/internal/httputil"
+	kreply "github.com/go-kratos/kratos/v2/internal/reply"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/selector"
@@ -47,6 +48,7 @@ type clientOptions struct {
 	discovery    registry.Discovery
 	middleware   []middleware.Middleware
 	block        bool
+	tracker      *transport.Tracker
 }
 
 // WithTransport with client transport.
@@ -133,6 +135,13 @@ func WithTLSConfig(c *tls.Config) ClientOption {
 	}
 }
 
+// WithTracker with the tracker of the open clients, the client is untracked when closed.
+func WithTracker(t *transport.Tracker) ClientOption {
+	return func(o *clientOptions) {
+		o.tracker = t
+	}
+}
+
 // Client is an HTTP client.
 type Client struct {
 	opts     clientOptions
@@ -140,6 +149,7 @@ type Client struct {
 	r        *resolver
 	cc       *http.Client
 	insecure bool
+	untrack  func()
 }
 
 // NewClient returns an HTTP client.
@@ -176,7 +186,7 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 			return nil, fmt.Errorf("[http client] invalid endpoint format: %v", options.endpoint)
 		}
 	}
-	return &Client{
+	client := &Client{
 		opts:     options,
 		target:   target,
 		insecure: insecure,
@@ -185,7 +195,15 @@ func NewClient(ctx context.Context, opts ...ClientOption) (*Client, error) {
 			Timeout:   options.timeout,
 			Transport: options.transport,
 		},
-	}, nil
+	}
+	if options.tracker != nil {
+		client.untrack = options.tracker.Track(transport.ClientInfo{
+			Kind:     transport.KindHTTP,
+			Target:   options.endpoint,
+			Insecure: insecure,
+		}, client)
+	}
+	return client, nil
 }
 
 // Invoke makes an rpc call procedure for remote service.
@@ -216,6 +234,10 @@ func (client *Client) Invoke(ctx context.Context, method, path string, args inte
 	if contentType != "" {
 		req.Header.Set("Content-Type", c.contentType)
 	}
+	// the protobuf requests negotiate protobuf replies.
+	if codec := codecForContentType(c.contentType); codec != nil && codec.Name() == "proto" {
+		req.Header.Set("Accept", c.contentType)
+	}
 	if client.opts.userAgent != "" {
 		req.Header.Set("User-Agent", client.opts.userAgent)
 	}
@@ -225,11 +247,14 @@ func (client *Client) Invoke(ctx context.Context, method, path string, args inte
 		operation:    c.operation,
 		request:      req,
 		pathTemplate: c.pathTemplate,
+		secure:       client.target.Scheme == "https" || !client.insecure,
 	})
 	return client.invoke(ctx, req, args, reply, c, opts...)
 }
 
 func (client *Client) invoke(ctx context.Context, req *http.Request, args interface{}, reply interface{}, c callInfo, opts ...CallOption) error {
+	ctx, release := ic.MergeRequest(ctx)
+	defer release()
 	h := func(ctx context.Context, in interface{}) (interface{}, error) {
 		res, err := client.do(req.WithContext(ctx))
 		if res != nil {
@@ -242,16 +267,26 @@ func (client *Client) invoke(ctx context.Context, req *http.Request, args interf
 			return nil, err
 		}
 		defer res.Body.Close()
-		if err := client.opts.decoder(ctx, res, reply); err != nil {
+		target, _ := kreply.FromContext(ctx)
+		if err := client.opts.decoder(ctx, res, target); err != nil {
 			return nil, err
 		}
-		return reply, nil
+		return target, nil
 	}
 	if len(client.opts.middleware) > 0 {
 		h = middleware.Chain(client.opts.middleware...)(h)
 	}
-	_, err := h(ctx, args)
-	return err
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	out, err := h(kreply.NewContext(ctx, reply), args)
+	if err != nil {
+		return err
+	}
+	kreply.Copy(reply, out)
+	return nil
 }
 
 // Do send an HTTP request and decodes the body of response into target.
@@ -263,8 +298,36 @@ func (client *Client) Do(req *http.Request, opts ...CallOption) (*http.Response,
 			return nil, err
 		}
 	}
+	ctx, cancel := ic.MergeRequest(req.Context())
+	if c.timeout > 0 {
+		release := cancel
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, c.timeout)
+		cancel = func() {
+			cancelTimeout()
+			release()
+		}
+	}
+	res, err := client.do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// the timeout and the request of the gRPC strict mode cover reading the body.
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// cancelBody cancels the context of the request once the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
 
-	return client.do(req)
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
 }
 
 func (client *Client) do(req *http.Request) (*http.Response, error) {
@@ -301,6 +364,9 @@ func (client *Client) do(req *http.Request) (*http.Response, error) {
 
 // Close tears down the Transport and all underlying connections.
 func (client *Client) Close() error {
+	if client.untrack != nil {
+		client.untrack()
+	}
 	if client.r != nil {
 		return client.r.Close()
 	}
@@ -309,8 +375,11 @@ func (client *Client) Close() error {
 
 // DefaultRequestEncoder is an HTTP request encoder.
 func DefaultRequestEncoder(ctx context.Context, contentType string, in interface{}) ([]byte, error) {
-	name := httputil.ContentSubtype(contentType)
-	body, err := encoding.GetCodec(name).Marshal(in)
+	codec := codecForContentType(contentType)
+	if codec == nil {
+		return nil, fmt.Errorf("http: no codec for content type %q", contentType)
+	}
+	body, err := codec.Marshal(in)
 	if err != nil {
 		return nil, err
 	}
@@ -320,7 +389,7 @@ func DefaultRequestEncoder(ctx context.Context, contentType string, in interface
 // DefaultResponseDecoder is an HTTP response decoder.
 func DefaultResponseDecoder(ctx context.Context, res *http.Response, v interface{}) error {
 	defer res.Body.Close()
-	data, err := io.ReadAll(res.Body)
+	data, err := readBody(res.Body)
 	if err != nil {
 		return err
 	}
@@ -333,7 +402,7 @@ func DefaultErrorDecoder(ctx context.Context, res *http.Response) error {
 		return nil
 	}
 	defer res.Body.Close()
-	data, err := io.ReadAll(res.Body)
+	data, err := readBody(res.Body)
 	if err == nil {
 		e := new(errors.Error)
 		if err = CodecForResponse(res).Unmarshal(data, e); err == nil {
@@ -346,7 +415,7 @@ func DefaultErrorDecoder(ctx context.Context, res *http.Response) error {
 
 // CodecForResponse get encoding.Codec via http.Response
 func CodecForResponse(r *http.Response) encoding.Codec {
-	codec := encoding.GetCodec(httputil.ContentSubtype(r.Header.Get("Content-Type")))
+	codec := codecForContentType(r.Header.Get("Content-Type"))
 	if codec != nil {
 		return codec
 	}
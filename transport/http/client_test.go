@@ -9,12 +9,15 @@ import (
 	"fmt"
 	"io"
 	nethttp "net/http"
+	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	kratosErrors "github.com/go-kratos/kratos/v2/errors"
+	ic "github.com/go-kratos/kratos/v2/internal/context"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 )
@@ -306,3 +309,64 @@ func TestNewClient(t *testing.T) {
 		t.Error("err should not be equal to nil")
 	}
 }
+
+func TestCallTimeout(t *testing.T) {
+	ts := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(ts.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	err = client.Invoke(context.Background(), "GET", "/", nil, &map[string]string{}, CallTimeout(50*time.Millisecond))
+	if err == nil || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expect the call to time out, got %v after %v", err, time.Since(start))
+	}
+	req, _ := nethttp.NewRequest("GET", ts.URL, nil)
+	if _, err = client.Do(req, CallTimeout(50*time.Millisecond)); err == nil {
+		t.Error("expect the request to time out")
+	}
+}
+
+func TestClient_Request(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(srv.URL, "http://")), WithTimeout(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancelCalls := ic.WithRequest(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancelCalls)
+	done := make(chan error, 2)
+	go func() {
+		done <- client.Invoke(ic.Detach(ctx), nethttp.MethodGet, "/", nil, nil)
+	}()
+	go func() {
+		req, _ := nethttp.NewRequestWithContext(ic.Detach(ctx), nethttp.MethodGet, srv.URL, nil)
+		_, err := client.Do(req)
+		done <- err
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-done:
+			if err == nil {
+				t.Error("expect the calls canceled with the request")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("expect the calls canceled with the request")
+		}
+	}
+}
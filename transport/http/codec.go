@@ -1,8 +1,11 @@
 package http
 
 import (
-	"io"
+	"mime"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
@@ -21,13 +24,21 @@ type EncodeResponseFunc func(http.ResponseWriter, *http.Request, interface{}) er
 // EncodeErrorFunc is encode error func.
 type EncodeErrorFunc func(http.ResponseWriter, *http.Request, error)
 
+// DecodeInterceptor intercepts the request decoding, e.g. to decompress or
+// decrypt the body before next, or to validate the decoded object after it.
+type DecodeInterceptor func(next DecodeRequestFunc) DecodeRequestFunc
+
+// EncodeInterceptor intercepts the response encoding, e.g. to compress or
+// encrypt the body written by next.
+type EncodeInterceptor func(next EncodeResponseFunc) EncodeResponseFunc
+
 // DefaultRequestDecoder decodes the request body to object.
 func DefaultRequestDecoder(r *http.Request, v interface{}) error {
 	codec, ok := CodecForRequest(r, "Content-Type")
 	if !ok {
 		return errors.BadRequest("CODEC", r.Header.Get("Content-Type"))
 	}
-	data, err := io.ReadAll(r.Body)
+	data, err := readBody(r.Body)
 	if err != nil {
 		return errors.BadRequest("CODEC", err.Error())
 	}
@@ -39,12 +50,12 @@ func DefaultRequestDecoder(r *http.Request, v interface{}) error {
 
 // DefaultResponseEncoder encodes the object to the HTTP response.
 func DefaultResponseEncoder(w http.ResponseWriter, r *http.Request, v interface{}) error {
-	codec, _ := CodecForRequest(r, "Accept")
+	codec, contentType, _ := negotiate(r, "Accept")
 	data, err := codec.Marshal(v)
 	if err != nil {
 		return err
 	}
-	w.Header().Set("Content-Type", httputil.ContentType(codec.Name()))
+	w.Header().Set("Content-Type", contentType)
 	_, err = w.Write(data)
 	if err != nil {
 		return err
@@ -55,24 +66,73 @@ func DefaultResponseEncoder(w http.ResponseWriter, r *http.Request, v interface{
 // DefaultErrorEncoder encodes the error to the HTTP response.
 func DefaultErrorEncoder(w http.ResponseWriter, r *http.Request, err error) {
 	se := errors.FromError(err)
-	codec, _ := CodecForRequest(r, "Accept")
+	codec, contentType, _ := negotiate(r, "Accept")
 	body, err := codec.Marshal(se)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", httputil.ContentType(codec.Name()))
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(int(se.Code))
 	_, _ = w.Write(body)
 }
 
-// CodecForRequest get encoding.Codec via http.Request
+// CodecForRequest get encoding.Codec via http.Request, the media types listed
+// in the header are tried by quality, e.g. "application/x-protobuf, application/json;q=0.5".
 func CodecForRequest(r *http.Request, name string) (encoding.Codec, bool) {
-	for _, accept := range r.Header[name] {
-		codec := encoding.GetCodec(httputil.ContentSubtype(accept))
-		if codec != nil {
-			return codec, true
+	codec, _, ok := negotiate(r, name)
+	return codec, ok
+}
+
+// aliases maps the media subtypes of the common protobuf media types to the proto codec.
+var aliases = map[string]string{
+	"x-protobuf":          "proto",
+	"protobuf":            "proto",
+	"x-proto":             "proto",
+	"vnd.google.protobuf": "proto",
+}
+
+// codecForContentType returns the codec of a media type, nil if none.
+func codecForContentType(contentType string) encoding.Codec {
+	subtype := httputil.ContentSubtype(strings.ToLower(strings.TrimSpace(contentType)))
+	if name, ok := aliases[subtype]; ok {
+		subtype = name
+	}
+	return encoding.GetCodec(subtype)
+}
+
+// negotiate returns the codec and the media type of the highest quality
+// listed in the header, the JSON codec if none is registered.
+func negotiate(r *http.Request, name string) (encoding.Codec, string, bool) {
+	type candidate struct {
+		mediaType string
+		q         float64
+	}
+	var candidates []candidate
+	for _, value := range r.Header[name] {
+		for _, part := range strings.Split(value, ",") {
+			// the media type is kept when its parameters are malformed,
+			// with the default quality like an invalid q parameter.
+			mediaType, params, err := mime.ParseMediaType(part)
+			if err != nil && err != mime.ErrInvalidMediaParameter {
+				continue
+			}
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+			if q > 0 {
+				candidates = append(candidates, candidate{mediaType: mediaType, q: q})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		if codec := codecForContentType(c.mediaType); codec != nil {
+			return codec, c.mediaType, true
 		}
 	}
-	return encoding.GetCodec("json"), false
+	return encoding.GetCodec("json"), httputil.ContentType("json"), false
 }
@@ -2,12 +2,17 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"io"
 	nethttp "net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/go-kratos/kratos/v2/errors"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestDefaultRequestDecoder(t *testing.T) {
@@ -129,3 +134,61 @@ func TestCodecForRequest(t *testing.T) {
 		t.Errorf("expected %v, got %v", "json", c.Name())
 	}
 }
+
+func TestNegotiate(t *testing.T) {
+	req := &nethttp.Request{Header: make(nethttp.Header)}
+	req.Header.Set("Accept", "application/json;q=0.5, text/html, application/x-protobuf;q=0.9")
+	codec, contentType, ok := negotiate(req, "Accept")
+	if !ok || codec.Name() != "proto" || contentType != "application/x-protobuf" {
+		t.Errorf("got %v %s %v, want the proto codec", codec.Name(), contentType, ok)
+	}
+	req.Header.Set("Accept", "*/*")
+	if codec, contentType, ok = negotiate(req, "Accept"); ok || codec.Name() != "json" || contentType != "application/json" {
+		t.Errorf("got %v %s %v, want the json codec", codec.Name(), contentType, ok)
+	}
+	for _, accept := range []string{
+		"application/x-protobuf;q=abc, application/json;q=0.5",
+		"application/x-protobuf;version, application/json;q=0.5",
+	} {
+		req.Header.Set("Accept", accept)
+		if codec, contentType, ok = negotiate(req, "Accept"); !ok || codec.Name() != "proto" || contentType != "application/x-protobuf" {
+			t.Errorf("%s: got %v %s %v, want the proto codec", accept, codec.Name(), contentType, ok)
+		}
+	}
+}
+
+func TestProtobuf(t *testing.T) {
+	var contentType string
+	srv := NewServer()
+	srv.Route("/").POST("/hello", func(ctx Context) error {
+		contentType = ctx.Request().Header.Get("Content-Type")
+		var in wrapperspb.StringValue
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		if in.Value == "" {
+			return errors.BadRequest("NAME", "name is required")
+		}
+		return ctx.Result(nethttp.StatusOK, wrapperspb.String("Hello "+in.Value))
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	client, err := NewClient(context.Background(), WithEndpoint(strings.TrimPrefix(ts.URL, "http://")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header nethttp.Header
+	reply := &wrapperspb.StringValue{}
+	err = client.Invoke(context.Background(), "POST", "/hello", wrapperspb.String("kratos"), reply,
+		ContentType("application/x-protobuf"), Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Value != "Hello kratos" || contentType != "application/x-protobuf" || header.Get("Content-Type") != "application/x-protobuf" {
+		t.Errorf("got %q %s %s, want a protobuf exchange", reply.Value, contentType, header.Get("Content-Type"))
+	}
+	err = client.Invoke(context.Background(), "POST", "/hello", wrapperspb.String(""), reply, ContentType("application/x-protobuf"))
+	if errors.Reason(err) != "NAME" {
+		t.Errorf("expect the error decoded from protobuf, got %v", err)
+	}
+}
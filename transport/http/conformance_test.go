@@ -0,0 +1,58 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/transporttest"
+)
+
+const conformanceOperation = "/transporttest.Conformance/Call"
+
+type conformanceMessage struct {
+	Message string `json:"message"`
+}
+
+func TestConformance(t *testing.T) {
+	transporttest.Conformance(t, transporttest.Harness{
+		NewServer: func(h middleware.Handler, m ...middleware.Middleware) (transport.Server, error) {
+			srv := NewServer(Address("127.0.0.1:0"), Middleware(m...))
+			srv.Route("/").POST("/transporttest", func(ctx Context) error {
+				var in conformanceMessage
+				if err := ctx.Bind(&in); err != nil {
+					return err
+				}
+				SetOperation(ctx, conformanceOperation)
+				handler := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+					reply, err := h(ctx, req.(*conformanceMessage).Message)
+					if err != nil {
+						return nil, err
+					}
+					return &conformanceMessage{Message: reply.(string)}, nil
+				})
+				return ctx.Returns(handler(ctx, &in))
+			})
+			return srv, nil
+		},
+		Call: func(ctx context.Context, endpoint *url.URL, req string, m ...middleware.Middleware) (string, transport.Header, error) {
+			client, err := NewClient(ctx, WithEndpoint(endpoint.Host), WithMiddleware(m...))
+			if err != nil {
+				return "", nil, err
+			}
+			defer client.Close()
+			var (
+				out    conformanceMessage
+				header http.Header
+			)
+			err = client.Invoke(ctx, http.MethodPost, "/transporttest", &conformanceMessage{Message: req}, &out, Operation(conformanceOperation), Header(&header))
+			if err != nil {
+				return "", nil, err
+			}
+			return out.Message, headerCarrier(header), nil
+		},
+	})
+}
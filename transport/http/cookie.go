@@ -0,0 +1,208 @@
+package http
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidCookie is returned when a secure cookie is missing, tampered or expired.
+var ErrInvalidCookie = errors.New("http: invalid cookie")
+
+// ErrShortHashKey is returned by NewSecureCookie when the hash key is shorter
+// than 32 bytes, the cookies signed with it could be forged.
+var ErrShortHashKey = errors.New("http: the cookie hash key must be at least 32 bytes")
+
+const flashCookie = "kratos_flash"
+
+// SecureCookieOption is secure cookie option.
+type SecureCookieOption func(*SecureCookie)
+
+// WithMaxAge with the maximum age of the cookie values, default is 30 days.
+func WithMaxAge(maxAge time.Duration) SecureCookieOption {
+	return func(s *SecureCookie) {
+		s.maxAge = maxAge
+	}
+}
+
+// WithInsecure allows the cookies over plain HTTP, e.g. in development, the
+// cookies are only sent over HTTPS by default, including behind a proxy
+// terminating TLS.
+func WithInsecure() SecureCookieOption {
+	return func(s *SecureCookie) {
+		s.insecure = true
+	}
+}
+
+// SecureCookie writes and reads the cookies whose values are signed, and
+// encrypted if it has a block key. The values are bound to the cookie names
+// and expire after the maximum age.
+type SecureCookie struct {
+	hashKey  []byte
+	aead     cipher.AEAD
+	maxAge   time.Duration
+	insecure bool
+	now      func() time.Time
+}
+
+// NewSecureCookie new a secure cookie signing with hashKey of at least 32
+// random bytes, blockKey is the AES key of 16, 24 or 32 bytes encrypting the
+// values, or nil.
+func NewSecureCookie(hashKey, blockKey []byte, opts ...SecureCookieOption) (*SecureCookie, error) {
+	if len(hashKey) < 32 {
+		return nil, ErrShortHashKey
+	}
+	s := &SecureCookie{
+		hashKey: hashKey,
+		maxAge:  30 * 24 * time.Hour,
+		now:     time.Now,
+	}
+	if blockKey != nil {
+		block, err := aes.NewCipher(blockKey)
+		if err != nil {
+			return nil, err
+		}
+		if s.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s, nil
+}
+
+// SetCookie sets the cookie with its value encoded, the cookie is HttpOnly and
+// Secure unless WithInsecure, the path defaults to /.
+func (s *SecureCookie) SetCookie(ctx Context, cookie *http.Cookie) error {
+	value, err := s.Encode(cookie.Name, []byte(cookie.Value))
+	if err != nil {
+		return err
+	}
+	c := *cookie
+	c.Value = value
+	c.HttpOnly = true
+	c.Secure = !s.insecure
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if c.SameSite == 0 {
+		c.SameSite = http.SameSiteLaxMode
+	}
+	http.SetCookie(ctx.Response(), &c)
+	return nil
+}
+
+// Cookie returns the decoded value of the cookie name.
+func (s *SecureCookie) Cookie(ctx Context, name string) (string, error) {
+	cookie, err := ctx.Request().Cookie(name)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	value, err := s.Decode(name, cookie.Value)
+	return string(value), err
+}
+
+// DeleteCookie deletes the cookie name at path /.
+func (s *SecureCookie) DeleteCookie(ctx Context, name string) {
+	http.SetCookie(ctx.Response(), &http.Cookie{Name: name, Path: "/", MaxAge: -1, HttpOnly: true, Secure: !s.insecure})
+}
+
+// Encode encodes the value of the cookie name.
+func (s *SecureCookie) Encode(name string, value []byte) (string, error) {
+	payload := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(payload, uint64(s.now().Unix()))
+	payload = append(payload, value...)
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return "", err
+		}
+		payload = s.aead.Seal(nonce, nonce, payload, []byte(name))
+	}
+	payload = append(payload, s.sign(name, payload)...)
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// Decode decodes the value of the cookie name.
+func (s *SecureCookie) Decode(name, value string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) < sha256.Size {
+		return nil, ErrInvalidCookie
+	}
+	payload, mac := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if !hmac.Equal(mac, s.sign(name, payload)) {
+		return nil, ErrInvalidCookie
+	}
+	if s.aead != nil {
+		n := s.aead.NonceSize()
+		if len(payload) < n {
+			return nil, ErrInvalidCookie
+		}
+		if payload, err = s.aead.Open(nil, payload[:n], payload[n:], []byte(name)); err != nil {
+			return nil, ErrInvalidCookie
+		}
+	}
+	if len(payload) < 8 {
+		return nil, ErrInvalidCookie
+	}
+	created := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+	if s.maxAge > 0 && s.now().Sub(created) > s.maxAge {
+		return nil, ErrInvalidCookie
+	}
+	return payload[8:], nil
+}
+
+func (s *SecureCookie) sign(name string, payload []byte) []byte {
+	h := hmac.New(sha256.New, s.hashKey)
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// Flash is a message shown once on the next page, e.g. after a redirect.
+type Flash struct {
+	Kind    string `json:"k,omitempty"`
+	Message string `json:"m"`
+}
+
+// AddFlash adds flashes to the flashes of the request, in a secure cookie.
+func (s *SecureCookie) AddFlash(ctx Context, flashes ...Flash) error {
+	current, _ := s.flashes(ctx)
+	data, err := json.Marshal(append(current, flashes...))
+	if err != nil {
+		return err
+	}
+	return s.SetCookie(ctx, &http.Cookie{Name: flashCookie, Value: string(data)})
+}
+
+// Flashes returns the flashes of the request and deletes them.
+func (s *SecureCookie) Flashes(ctx Context) ([]Flash, error) {
+	if _, err := ctx.Request().Cookie(flashCookie); err != nil {
+		return nil, nil
+	}
+	s.DeleteCookie(ctx, flashCookie)
+	return s.flashes(ctx)
+}
+
+func (s *SecureCookie) flashes(ctx Context) ([]Flash, error) {
+	value, err := s.Cookie(ctx, flashCookie)
+	if err != nil {
+		return nil, err
+	}
+	var flashes []Flash
+	if err = json.Unmarshal([]byte(value), &flashes); err != nil {
+		return nil, ErrInvalidCookie
+	}
+	return flashes, nil
+}
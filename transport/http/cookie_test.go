@@ -0,0 +1,126 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var hashKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestSecureCookie(t *testing.T) {
+	for _, blockKey := range [][]byte{nil, []byte("0123456789abcdef")} {
+		s, err := NewSecureCookie(hashKey, blockKey, WithMaxAge(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := s.Encode("session", []byte("alice"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if blockKey != nil && strings.Contains(value, "alice") {
+			t.Errorf("expect the value to be encrypted")
+		}
+		if got, err := s.Decode("session", value); err != nil || string(got) != "alice" {
+			t.Errorf("got %s %v, want alice", got, err)
+		}
+		if _, err := s.Decode("other", value); !errors.Is(err, ErrInvalidCookie) {
+			t.Errorf("expect the value bound to the name, got %v", err)
+		}
+		if _, err := s.Decode("session", "x"+value[1:]); !errors.Is(err, ErrInvalidCookie) {
+			t.Errorf("expect the tampered value to be invalid, got %v", err)
+		}
+		s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		if _, err := s.Decode("session", value); !errors.Is(err, ErrInvalidCookie) {
+			t.Errorf("expect the value to expire, got %v", err)
+		}
+	}
+	if _, err := NewSecureCookie(hashKey, []byte("short")); err == nil {
+		t.Error("expect an invalid block key")
+	}
+	for _, key := range [][]byte{nil, []byte("hash"), hashKey[:31]} {
+		if _, err := NewSecureCookie(key, nil); !errors.Is(err, ErrShortHashKey) {
+			t.Errorf("expect the short hash key rejected, got %v", err)
+		}
+	}
+}
+
+func TestFlashAndRedirect(t *testing.T) {
+	s, err := NewSecureCookie(hashKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer()
+	r := srv.Route("/")
+	r.POST("/login", func(ctx Context) error {
+		if err := s.AddFlash(ctx, Flash{Kind: "info", Message: "welcome"}); err != nil {
+			return err
+		}
+		return Redirect(ctx, http.StatusSeeOther, "/home")
+	})
+	r.GET("/home", func(ctx Context) error {
+		flashes, err := s.Flashes(ctx)
+		if err != nil {
+			return err
+		}
+		var msgs []string
+		for _, f := range flashes {
+			msgs = append(msgs, f.Kind+":"+f.Message)
+		}
+		return ctx.String(http.StatusOK, strings.Join(msgs, ","))
+	})
+	r.GET("/bad", func(ctx Context) error {
+		return Redirect(ctx, http.StatusOK, "/home")
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login", nil))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/home" {
+		t.Fatalf("got %d %s, want a redirect to /home", rec.Code, rec.Header().Get("Location"))
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly || !cookies[0].Secure || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Fatalf("unexpected cookies %v", cookies)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/home", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Body.String() != "info:welcome" {
+		t.Errorf("got %q, want the flash", rec.Body.String())
+	}
+	if deleted := rec.Result().Cookies(); len(deleted) != 1 || deleted[0].MaxAge >= 0 {
+		t.Errorf("expect the flashes to be deleted, got %v", deleted)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/home", nil))
+	if rec.Body.String() != "" {
+		t.Errorf("got %q, want no flash", rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bad", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got %d, want an invalid redirect code", rec.Code)
+	}
+}
+
+func TestSecureCookie_Insecure(t *testing.T) {
+	s, err := NewSecureCookie(hashKey, nil, WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer()
+	srv.Route("/").GET("/", func(ctx Context) error {
+		return s.SetCookie(ctx, &http.Cookie{Name: "session", Value: "alice"})
+	})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if cookies := rec.Result().Cookies(); len(cookies) != 1 || cookies[0].Secure {
+		t.Errorf("expect the insecure cookie, got %v", cookies)
+	}
+}
@@ -1,6 +1,9 @@
 package http
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 // FilterFunc is a function which receives an http.Handler and returns another http.Handler.
 type FilterFunc func(http.Handler) http.Handler
@@ -14,3 +17,59 @@ func FilterChain(filters ...FilterFunc) FilterFunc {
 		return next
 	}
 }
+
+// RequestPredicate reports whether a request is selected.
+type RequestPredicate func(*http.Request) bool
+
+// FilterIf returns a FilterFunc running filter only for the requests selected
+// by p, evaluated per request:
+//
+//	http.FilterIf(http.PathPrefix("/admin/"), basicAuth)
+func FilterIf(p RequestPredicate, filter FilterFunc) FilterFunc {
+	return func(next http.Handler) http.Handler {
+		filtered := filter(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if p(req) {
+				filtered.ServeHTTP(w, req)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// MethodIs selects the requests with one of the methods.
+func MethodIs(methods ...string) RequestPredicate {
+	return func(req *http.Request) bool {
+		for _, m := range methods {
+			if req.Method == m {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// PathPrefix selects the requests of the paths with one of the prefixes.
+func PathPrefix(prefix ...string) RequestPredicate {
+	return func(req *http.Request) bool {
+		for _, p := range prefix {
+			if strings.HasPrefix(req.URL.Path, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HeaderIs selects the requests with the header key equal to value, or
+// present if value is empty.
+func HeaderIs(key, value string) RequestPredicate {
+	return func(req *http.Request) bool {
+		v := req.Header.Get(key)
+		if value == "" {
+			return v != ""
+		}
+		return v == value
+	}
+}
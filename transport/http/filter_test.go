@@ -0,0 +1,43 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFilterIf(t *testing.T) {
+	filter := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Filtered", "true")
+			next.ServeHTTP(w, r)
+		})
+	}
+	h := FilterChain(
+		FilterIf(PathPrefix("/admin/"), filter),
+		FilterIf(MethodIs(http.MethodPost, http.MethodPut), filter),
+		FilterIf(HeaderIs("X-Debug", ""), filter),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tests := []struct {
+		method string
+		path   string
+		header string
+		want   bool
+	}{
+		{http.MethodGet, "/admin/users", "", true},
+		{http.MethodGet, "/users", "", false},
+		{http.MethodPost, "/users", "", true},
+		{http.MethodGet, "/users", "1", true},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if test.header != "" {
+			req.Header.Set("X-Debug", test.header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if got := w.Header().Get("X-Filtered") == "true"; got != test.want {
+			t.Errorf("%s %s: filtered %v, want %v", test.method, test.path, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Preload is a resource preloaded by a page, e.g. its stylesheets and fonts.
+type Preload struct {
+	URL string
+	// As is the destination of the resource, e.g. "style", "script" or "font".
+	As string
+	// Type is the MIME type of the resource, e.g. "font/woff2".
+	Type string
+	// CrossOrigin is required for the fonts and the resources fetched with CORS.
+	CrossOrigin bool
+}
+
+// String returns the Link header value of the preload.
+func (p Preload) String() string {
+	var b strings.Builder
+	b.WriteString("<" + p.URL + ">; rel=preload")
+	if p.As != "" {
+		b.WriteString("; as=" + p.As)
+	}
+	if p.Type != "" {
+		b.WriteString(`; type="` + p.Type + `"`)
+	}
+	if p.CrossOrigin {
+		b.WriteString("; crossorigin")
+	}
+	return b.String()
+}
+
+// EarlyHints adds the Link headers of the preloads to the reply and sends
+// them in a 103 Early Hints response, so that the browser fetches them while
+// the page is rendered. It must be called before the reply is written. When
+// built with Go older than 1.19 the Link headers are only sent with the reply.
+func EarlyHints(ctx Context, preloads ...Preload) {
+	header := ctx.Response().Header()
+	for _, p := range preloads {
+		header.Add("Link", p.String())
+	}
+	writeEarlyHints(ctx.Response())
+}
+
+// Push pushes the preloads over HTTP/2, it returns http.ErrNotSupported when
+// the connection doesn't support push, e.g. HTTP/1.1 or push disabled by the
+// client, which is safe to ignore.
+func Push(ctx Context, preloads ...Preload) error {
+	pusher, ok := unwrapPusher(ctx.Response())
+	if !ok {
+		return http.ErrNotSupported
+	}
+	opts := &http.PushOptions{Header: http.Header{}}
+	if ae := ctx.Request().Header.Get("Accept-Encoding"); ae != "" {
+		opts.Header.Set("Accept-Encoding", ae)
+	}
+	for _, p := range preloads {
+		if err := pusher.Push(p.URL, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unwrapPusher returns the http.Pusher of w, unwrapping the response writers
+// wrapped by the filters.
+func unwrapPusher(w http.ResponseWriter) (http.Pusher, bool) {
+	for {
+		if p, ok := w.(http.Pusher); ok {
+			return p, true
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil, false
+		}
+		w = u.Unwrap()
+	}
+}
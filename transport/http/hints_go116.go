@@ -0,0 +1,9 @@
+//go:build !go1.19
+// +build !go1.19
+
+package http
+
+import "net/http"
+
+// writeEarlyHints does nothing, the informational responses aren't supported before Go 1.19.
+func writeEarlyHints(http.ResponseWriter) {}
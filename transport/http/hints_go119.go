@@ -0,0 +1,10 @@
+//go:build go1.19
+// +build go1.19
+
+package http
+
+import "net/http"
+
+func writeEarlyHints(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusEarlyHints)
+}
@@ -0,0 +1,56 @@
+//go:build go1.19
+// +build go1.19
+
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+)
+
+func TestEarlyHints(t *testing.T) {
+	css := Preload{URL: "/app.css", As: "style"}
+	font := Preload{URL: "/font.woff2", As: "font", Type: "font/woff2", CrossOrigin: true}
+	if s := font.String(); s != `</font.woff2>; rel=preload; as=font; type="font/woff2"; crossorigin` {
+		t.Errorf("unexpected link %s", s)
+	}
+	var pushErr error
+	srv := NewServer()
+	srv.Route("/").GET("/", func(ctx Context) error {
+		EarlyHints(ctx, css, font)
+		pushErr = Push(ctx, css, font)
+		return ctx.String(http.StatusOK, "page")
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = header["Link"]
+			}
+			return nil
+		},
+	}
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK || len(res.Header["Link"]) != 2 {
+		t.Errorf("got %d %v, want 200 with the links", res.StatusCode, res.Header["Link"])
+	}
+	if len(hints) != 2 || hints[0] != css.String() {
+		t.Errorf("unexpected early hints %v", hints)
+	}
+	if !errors.Is(pushErr, http.ErrNotSupported) {
+		t.Errorf("expect push not supported over HTTP/1.1, got %v", pushErr)
+	}
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+)
+
+type mounted struct {
+	name   string
+	router *mux.Router
+}
+
+// mountTable is a copy-on-write table of the routers mounted at runtime.
+type mountTable struct {
+	mu     sync.Mutex
+	routes atomic.Value // []mounted
+}
+
+func (t *mountTable) load() []mounted {
+	routes, _ := t.routes.Load().([]mounted)
+	return routes
+}
+
+func (t *mountTable) update(name string, router *mux.Router) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old := t.load()
+	routes := make([]mounted, 0, len(old)+1)
+	for _, m := range old {
+		if m.name != name {
+			routes = append(routes, m)
+		}
+	}
+	if router != nil {
+		routes = append(routes, mounted{name: name, router: router})
+	}
+	t.routes.Store(routes)
+}
+
+// Mount registers the routes added by register under name while the server
+// may be running, replacing the routes previously mounted under the same name:
+//
+//	srv.Mount("greeter", func(s *http.Server) {
+//		v1.RegisterGreeterHTTPServer(s, greeter)
+//	})
+//
+// The server passed to register only collects routes, it must not be started.
+// Mounted routes are matched after the routes registered on the server itself.
+func (s *Server) Mount(name string, register func(*Server)) {
+	// the child shares every setting of s but the listener, router and mounts.
+	child := &Server{
+		Server:      s.Server,
+		tlsConf:     s.tlsConf,
+		endpoint:    s.endpoint,
+		network:     s.network,
+		address:     s.address,
+		reusePort:   s.reusePort,
+		timeout:     s.timeout,
+		filters:     s.filters,
+		ms:          s.ms,
+		dec:         s.dec,
+		enc:         s.enc,
+		decInts:     s.decInts,
+		encInts:     s.encInts,
+		operation:   s.operation,
+		ene:         s.ene,
+		strictSlash: s.strictSlash,
+		log:         s.log,
+		observers:   s.observers,
+		gate:        s.gate,
+		pausedGauge: s.pausedGauge,
+		pauses:      s.pauses,
+		renderer:    s.renderer,
+	}
+	child.router = mux.NewRouter().StrictSlash(child.strictSlash)
+	child.router.Use(child.filter())
+	register(child)
+	s.mounts.update(name, child.router)
+}
+
+// Unmount removes the routes mounted under name.
+func (s *Server) Unmount(name string) {
+	s.mounts.update(name, nil)
+}
+
+func (s *Server) serveMounted(w http.ResponseWriter, req *http.Request) {
+	for _, m := range s.mounts.load() {
+		var match mux.RouteMatch
+		if m.router.Match(req, &match) && match.MatchErr == nil {
+			m.router.ServeHTTP(w, req)
+			return
+		}
+	}
+	http.NotFound(w, req)
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/encoding/json"
+)
+
+// NDJSONOption is NDJSON encoder option.
+type NDJSONOption func(*NDJSONEncoder)
+
+// FlushEvery with the number of rows buffered before a flush, default is 100.
+func FlushEvery(rows int) NDJSONOption {
+	return func(e *NDJSONEncoder) {
+		e.flushRows = rows
+	}
+}
+
+// FlushInterval with the maximum duration the rows are buffered, default is 1s.
+func FlushInterval(d time.Duration) NDJSONOption {
+	return func(e *NDJSONEncoder) {
+		e.flushInterval = d
+	}
+}
+
+// NDJSONEncoder streams newline-delimited JSON rows, e.g. for the export
+// endpoints, without building the whole list in memory. The writes block
+// while the client doesn't read, so the rows are produced at the pace of the
+// client, and Encode fails once the client goes away.
+type NDJSONEncoder struct {
+	ctx           Context
+	codec         encoding.Codec
+	flusher       http.Flusher
+	flushRows     int
+	flushInterval time.Duration
+
+	started   bool
+	pending   int
+	lastFlush time.Time
+}
+
+// NewNDJSONEncoder new an NDJSON encoder writing the response of ctx, the
+// header is written with the first row, so the handler may still fail before.
+func NewNDJSONEncoder(ctx Context, opts ...NDJSONOption) *NDJSONEncoder {
+	e := &NDJSONEncoder{
+		ctx:           ctx,
+		codec:         encoding.GetCodec(json.Name),
+		flushRows:     100,
+		flushInterval: time.Second,
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	e.flusher, _ = unwrapFlusher(ctx.Response())
+	return e
+}
+
+// Encode writes v as a row, proto messages are encoded with protojson.
+func (e *NDJSONEncoder) Encode(v interface{}) error {
+	if err := e.ctx.Request().Context().Err(); err != nil {
+		return err
+	}
+	data, err := e.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w := e.ctx.Response()
+	if !e.started {
+		e.started = true
+		e.lastFlush = time.Now()
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+	}
+	if _, err = w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	e.pending++
+	if e.pending >= e.flushRows || time.Since(e.lastFlush) >= e.flushInterval {
+		e.Flush()
+	}
+	return nil
+}
+
+// Flush sends the buffered rows to the client.
+func (e *NDJSONEncoder) Flush() {
+	if e.flusher != nil && e.started {
+		e.flusher.Flush()
+	}
+	e.pending = 0
+	e.lastFlush = time.Now()
+}
+
+// unwrapFlusher returns the http.Flusher of w, unwrapping the response
+// writers wrapped by the filters.
+func unwrapFlusher(w http.ResponseWriter) (http.Flusher, bool) {
+	for {
+		if f, ok := w.(http.Flusher); ok {
+			return f, true
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil, false
+		}
+		w = u.Unwrap()
+	}
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type row struct {
+	ID int `json:"id"`
+}
+
+func TestNDJSON(t *testing.T) {
+	stopped := make(chan error, 1)
+	srv := NewServer()
+	r := srv.Route("/")
+	r.GET("/export", func(ctx Context) error {
+		enc := NewNDJSONEncoder(ctx, FlushEvery(100))
+		for i := 0; i < 250; i++ {
+			if err := enc.Encode(&row{ID: i}); err != nil {
+				return err
+			}
+		}
+		enc.Flush()
+		return nil
+	})
+	r.GET("/endless", func(ctx Context) error {
+		enc := NewNDJSONEncoder(ctx, FlushEvery(1))
+		for i := 0; ; i++ {
+			if err := enc.Encode(&row{ID: i}); err != nil {
+				stopped <- err
+				return err
+			}
+		}
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("got content type %s", ct)
+	}
+	scanner := bufio.NewScanner(res.Body)
+	n := 0
+	for ; scanner.Scan(); n++ {
+		var v row
+		if err = json.Unmarshal(scanner.Bytes(), &v); err != nil || v.ID != n {
+			t.Fatalf("unexpected row %s: %v", scanner.Text(), err)
+		}
+	}
+	if n != 250 {
+		t.Errorf("got %d rows, want 250", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/endless", nil)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner = bufio.NewScanner(res.Body)
+	for i := 0; i < 10; i++ {
+		if !scanner.Scan() {
+			t.Fatalf("expect the rows, got %v", scanner.Err())
+		}
+	}
+	cancel()
+	res.Body.Close()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expect the stream to stop once the client goes away")
+	}
+}
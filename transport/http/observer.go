@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// observedListener is a net.Listener emitting connection events to observers.
+type observedListener struct {
+	net.Listener
+	observers transport.Observers
+}
+
+func (l *observedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	c := &observedConn{
+		Conn:      conn,
+		observers: l.observers,
+		info: transport.ConnInfo{
+			Kind:       transport.KindHTTP,
+			LocalAddr:  conn.LocalAddr(),
+			RemoteAddr: conn.RemoteAddr(),
+		},
+	}
+	l.observers.OnAccept(c.info)
+	return c, nil
+}
+
+type observedConn struct {
+	net.Conn
+	once      sync.Once
+	observers transport.Observers
+	info      transport.ConnInfo
+}
+
+func (c *observedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() {
+		c.observers.OnClose(c.info)
+	})
+	return err
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// OperationFunc derives the operation of a request from the request and the
+// path template of its route, the operation names the request in logs and
+// metrics so it should have a low cardinality.
+type OperationFunc func(req *http.Request, pathTemplate string) string
+
+// TemplateOperation is the default OperationFunc, it returns the path template, e.g. /users/{id}.
+func TemplateOperation(_ *http.Request, pathTemplate string) string {
+	return pathTemplate
+}
+
+// NormalizedPathOperation returns the request path with the identifier segments,
+// numbers, UUIDs and long hexadecimal strings, replaced by {id}, e.g. /static/{id}/app.js.
+// It suits the catch-all routes whose path template is too coarse.
+func NormalizedPathOperation(req *http.Request, _ string) string {
+	return NormalizePath(req.URL.Path)
+}
+
+// NormalizePath replaces the identifier segments of path by {id}.
+func NormalizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if isIdentifier(s) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	digits := true
+	for i, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+		case c >= 'a' && c <= 'f', c >= 'A' && c <= 'F':
+			digits = false
+		case c == '-' && len(s) == 36 && (i == 8 || i == 13 || i == 18 || i == 23):
+			digits = false
+		default:
+			return false
+		}
+	}
+	return digits || len(s) >= 16
+}
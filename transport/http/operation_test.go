@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := map[string]string{
+		"/users/123": "/users/{id}",
+		"/users/123/orders/f47ac10b-58cc-4372-a567-0e02b2c3d479": "/users/{id}/orders/{id}",
+		"/objects/9f86d081884c7d65":                              "/objects/{id}",
+		"/v1/users/me":                                           "/v1/users/me",
+		"/static/app.js":                                         "/static/app.js",
+	}
+	for path, expect := range tests {
+		if got := NormalizePath(path); got != expect {
+			t.Errorf("NormalizePath(%s): expect %s, got %s", path, expect, got)
+		}
+	}
+}
+
+func TestOperationExtractor(t *testing.T) {
+	for _, test := range []struct {
+		opt    ServerOption
+		expect string
+	}{
+		{OperationExtractor(TemplateOperation), "/files/"},
+		{OperationExtractor(NormalizedPathOperation), "/files/{id}/a.txt"},
+	} {
+		var operation string
+		srv := NewServer(test.opt)
+		srv.HandlePrefix("/files/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tr, _ := transport.FromServerContext(r.Context())
+			operation = tr.Operation()
+		}))
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/files/42/a.txt", nil))
+		if operation != test.expect {
+			t.Errorf("expect %s, got %s", test.expect, operation)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package partial implements partial responses: the clients select the fields
+// of the response with a fields query parameter, in the syntax of Google APIs:
+//
+//	GET /v1/users/1?fields=name,address(city,zip),tags
+//
+// The fields are the JSON or the proto names of the proto message fields,
+// nested with "/" or grouped with parentheses.
+package partial
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const reason = "INVALID_FIELDS"
+
+// Option is partial response option.
+type Option func(*options)
+
+type options struct {
+	param string
+}
+
+// WithParam with the query parameter selecting the fields, default is fields.
+func WithParam(param string) Option {
+	return func(o *options) {
+		o.param = param
+	}
+}
+
+// Response returns an encode interceptor trimming the proto responses to the
+// requested fields, the other responses are encoded as is:
+//
+//	http.NewServer(http.EncodeInterceptors(partial.Response()))
+func Response(opts ...Option) khttp.EncodeInterceptor {
+	o := &options{param: "fields"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next khttp.EncodeResponseFunc) khttp.EncodeResponseFunc {
+		return func(w http.ResponseWriter, r *http.Request, v interface{}) error {
+			fields := r.URL.Query().Get(o.param)
+			m, ok := v.(proto.Message)
+			if fields == "" || !ok {
+				return next(w, r, v)
+			}
+			tree, err := Parse(fields)
+			if err != nil {
+				return errors.BadRequest(reason, err.Error())
+			}
+			m = proto.Clone(m)
+			if err := tree.Apply(m.ProtoReflect()); err != nil {
+				return errors.BadRequest(reason, err.Error())
+			}
+			return next(w, r, m)
+		}
+	}
+}
+
+// Mask is a tree of selected fields, a field with an empty subtree is selected as a whole.
+type Mask map[string]Mask
+
+// Parse parses a fields selector such as "a,b/c,d(e,f/g)", a field selected
+// as a whole, e.g. "a(b),a", wins over its selected subfields.
+func Parse(fields string) (Mask, error) {
+	p := &parser{s: fields}
+	mask, err := p.list()
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.s) {
+		return nil, fmt.Errorf("fields: unexpected %q at %d", p.s[p.i], p.i)
+	}
+	mask.normalize()
+	return mask, nil
+}
+
+type parser struct {
+	s string
+	i int
+}
+
+// list parses selections separated by commas, the fields selected as a whole
+// have a nil subtree while parsing.
+func (p *parser) list() (Mask, error) {
+	mask := Mask{}
+	for {
+		if err := p.selection(mask); err != nil {
+			return nil, err
+		}
+		if p.i >= len(p.s) || p.s[p.i] != ',' {
+			return mask, nil
+		}
+		p.i++
+	}
+}
+
+// selection parses a path of names separated by "/", optionally followed by a parenthesized list.
+func (p *parser) selection(mask Mask) error {
+	for {
+		start := p.i
+		for p.i < len(p.s) && !strings.ContainsRune(",/()", rune(p.s[p.i])) {
+			p.i++
+		}
+		name := strings.TrimSpace(p.s[start:p.i])
+		if name == "" {
+			return fmt.Errorf("fields: missing field name at %d", start)
+		}
+		sub, ok := mask[name]
+		whole := ok && sub == nil
+		if !ok {
+			sub = Mask{}
+			mask[name] = sub
+		}
+		if p.i >= len(p.s) {
+			mask[name] = nil
+			return nil
+		}
+		switch p.s[p.i] {
+		case '/':
+			p.i++
+			if mask = sub; whole {
+				// the subfields of a whole field are parsed and dropped.
+				mask = Mask{}
+			}
+		case '(':
+			p.i++
+			list, err := p.list()
+			if err != nil {
+				return err
+			}
+			if p.i >= len(p.s) || p.s[p.i] != ')' {
+				return fmt.Errorf("fields: missing ) at %d", p.i)
+			}
+			p.i++
+			if !whole {
+				sub.merge(list)
+			}
+			return nil
+		default:
+			mask[name] = nil
+			return nil
+		}
+	}
+}
+
+// merge merges the selections of src into mask.
+func (mask Mask) merge(src Mask) {
+	for name, sub := range src {
+		dst, ok := mask[name]
+		switch {
+		case !ok:
+			mask[name] = sub
+		case dst == nil || sub == nil:
+			mask[name] = nil
+		default:
+			dst.merge(sub)
+		}
+	}
+}
+
+// normalize replaces the nil subtrees of the whole fields with empty ones.
+func (mask Mask) normalize() {
+	for name, sub := range mask {
+		if sub == nil {
+			mask[name] = Mask{}
+			continue
+		}
+		sub.normalize()
+	}
+}
+
+// Apply clears the fields of m which aren't selected by the mask.
+func (mask Mask) Apply(m protoreflect.Message) error {
+	if len(mask) == 0 {
+		return nil
+	}
+	fds := m.Descriptor().Fields()
+	selected := make(map[protoreflect.FieldNumber]Mask, len(mask))
+	for name, sub := range mask {
+		fd := fds.ByJSONName(name)
+		if fd == nil {
+			fd = fds.ByName(protoreflect.Name(name))
+		}
+		if fd == nil {
+			return fmt.Errorf("fields: unknown field %q in %s", name, m.Descriptor().FullName())
+		}
+		if len(sub) > 0 && fd.Message() == nil {
+			return fmt.Errorf("fields: field %q in %s has no subfields", name, m.Descriptor().FullName())
+		}
+		selected[fd.Number()] = sub
+	}
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := selected[fd.Number()]
+		if !ok {
+			m.Clear(fd)
+			return true
+		}
+		if len(sub) == 0 {
+			return true
+		}
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = sub.Apply(list.Get(i).Message())
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() == nil {
+				err = fmt.Errorf("fields: field %q in %s has no subfields", fd.Name(), m.Descriptor().FullName())
+				return false
+			}
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				err = sub.Apply(v.Message())
+				return err == nil
+			})
+		default:
+			err = sub.Apply(v.Message())
+		}
+		return err == nil
+	})
+	return err
+}
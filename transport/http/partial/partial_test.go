@@ -0,0 +1,81 @@
+package partial
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/testdata/complex"
+)
+
+func TestParse(t *testing.T) {
+	mask, err := Parse("a,b/c,d(e,f/g),b/h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := Mask{
+		"a": {},
+		"b": {"c": {}, "h": {}},
+		"d": {"e": {}, "f": {"g": {}}},
+	}
+	if !reflect.DeepEqual(expect, mask) {
+		t.Errorf("expect %v, got %v", expect, mask)
+	}
+	for fields, expect := range map[string]Mask{
+		"a(b),a":          {"a": {}},
+		"a,a(b)":          {"a": {}},
+		"a/b,a,a/c":       {"a": {}},
+		"a(b(c)),a(b(d))": {"a": {"b": {"c": {}, "d": {}}}},
+		"a(b),a(b/c)":     {"a": {"b": {}}},
+	} {
+		if mask, err := Parse(fields); err != nil || !reflect.DeepEqual(expect, mask) {
+			t.Errorf("%s: expect %v, got %v %v", fields, expect, mask, err)
+		}
+	}
+	for _, fields := range []string{"a,", "a(b", "a)b", "/a"} {
+		if _, err := Parse(fields); err == nil {
+			t.Errorf("%s: expect a parse error", fields)
+		}
+	}
+}
+
+func TestResponse(t *testing.T) {
+	var encoded interface{}
+	enc := Response()(func(w http.ResponseWriter, r *http.Request, v interface{}) error {
+		encoded = v
+		return nil
+	})
+	reply := &complex.Complex{
+		Id:     1,
+		NoOne:  "one",
+		Simple: &complex.Simple{Component: "component"},
+		Age:    18,
+		Map:    map[string]string{"k": "v"},
+	}
+
+	if err := enc(httptest.NewRecorder(), httptest.NewRequest("GET", "/?fields=id,very_simple(component),map", nil), reply); err != nil {
+		t.Fatal(err)
+	}
+	expect := &complex.Complex{
+		Id:     1,
+		Simple: &complex.Simple{Component: "component"},
+		Map:    map[string]string{"k": "v"},
+	}
+	if !proto.Equal(expect, encoded.(proto.Message)) {
+		t.Errorf("expect %v, got %v", expect, encoded)
+	}
+	if reply.Age != 18 {
+		t.Error("expect the reply to be left unchanged")
+	}
+
+	if err := enc(httptest.NewRecorder(), httptest.NewRequest("GET", "/?fields=unknown", nil), reply); !errors.IsBadRequest(err) {
+		t.Errorf("expect bad request, got %v", err)
+	}
+	if err := enc(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), reply); err != nil || encoded != reply {
+		t.Errorf("expect the reply to be encoded as is, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/internal/pause"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Pauser = (*Server)(nil)
+
+// PauseMetrics with accept pause metrics, paused is set to 1 while the
+// server is paused and pauses counts the pauses, either may be nil.
+func PauseMetrics(paused metrics.Gauge, pauses metrics.Counter) ServerOption {
+	return func(s *Server) {
+		s.pausedGauge = paused
+		s.pauses = pauses
+	}
+}
+
+// Pause stops accepting new connections, the accepted ones are still served
+// and the new ones wait in the listen backlog until Resume.
+func (s *Server) Pause() {
+	s.gate.Pause()
+}
+
+// Resume resumes accepting new connections.
+func (s *Server) Resume() {
+	s.gate.Resume()
+}
+
+// Paused reports whether the server is paused.
+func (s *Server) Paused() bool {
+	return s.gate.Paused()
+}
+
+func (s *Server) newGate() *pause.Gate {
+	return pause.New(func(paused bool) {
+		if paused {
+			s.log.Info("[HTTP] server paused accepting connections")
+			if s.pauses != nil {
+				s.pauses.Inc()
+			}
+			if s.pausedGauge != nil {
+				s.pausedGauge.Set(1)
+			}
+			s.observers.OnPause(transport.KindHTTP)
+			return
+		}
+		s.log.Info("[HTTP] server resumed accepting connections")
+		if s.pausedGauge != nil {
+			s.pausedGauge.Set(0)
+		}
+		s.observers.OnResume(transport.KindHTTP)
+	})
+}
+
+// PauseHandler returns an admin handler controlling the accept pause of
+// servers: GET reports whether they are paused, POST with action=pause or
+// action=resume pauses or resumes them.
+//
+//	admin.Handle("/debug/pause", http.PauseHandler(httpSrv, grpcSrv))
+//
+// Mount it on a server which is not paused by it, e.g. a dedicated admin server.
+func PauseHandler(servers ...transport.Pauser) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			switch action := r.FormValue("action"); action {
+			case "pause":
+				for _, s := range servers {
+					s.Pause()
+				}
+			case "resume":
+				for _, s := range servers {
+					s.Resume()
+				}
+			default:
+				http.Error(w, fmt.Sprintf("unknown action %q", action), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		paused := true
+		for _, s := range servers {
+			paused = paused && s.Paused()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]bool{"paused": paused})
+	})
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type pauseObserver struct {
+	testObserver
+}
+
+func (o *pauseObserver) OnPause(kind transport.Kind)  { o.add("pause:" + kind.String()) }
+func (o *pauseObserver) OnResume(kind transport.Kind) { o.add("resume:" + kind.String()) }
+
+func TestPauseHandler(t *testing.T) {
+	o := &pauseObserver{}
+	srv := NewServer(Observer(o))
+	h := PauseHandler(srv)
+
+	for _, test := range []struct {
+		method string
+		target string
+		code   int
+		body   string
+	}{
+		{http.MethodGet, "/", http.StatusOK, `{"paused":false}`},
+		{http.MethodPost, "/?action=pause", http.StatusOK, `{"paused":true}`},
+		{http.MethodPost, "/?action=pause", http.StatusOK, `{"paused":true}`},
+		{http.MethodPost, "/?action=resume", http.StatusOK, `{"paused":false}`},
+		{http.MethodPost, "/?action=stop", http.StatusBadRequest, ""},
+		{http.MethodDelete, "/", http.StatusMethodNotAllowed, ""},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(test.method, test.target, nil))
+		if w.Code != test.code {
+			t.Errorf("%s %s: code = %d, want %d", test.method, test.target, w.Code, test.code)
+		}
+		if test.body != "" && strings.TrimSpace(w.Body.String()) != test.body {
+			t.Errorf("%s %s: body = %s, want %s", test.method, test.target, w.Body, test.body)
+		}
+	}
+	expect := []string{"pause:http", "resume:http"}
+	if !reflect.DeepEqual(expect, o.events) {
+		t.Errorf("expect %v, got %v", expect, o.events)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build race
+// +build race
+
+package http
+
+func init() {
+	// the race detector drops the pooled buffers at random.
+	raceEnabled = true
+}
@@ -0,0 +1,20 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Redirect replies with a redirect to url, code is one of 301, 302, 303, 307
+// and 308, e.g. 303 after a form is posted. A relative url is resolved against
+// the request path.
+func Redirect(ctx Context, code int, url string) error {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return fmt.Errorf("http: invalid redirect code %d", code)
+	}
+	http.Redirect(ctx.Response(), ctx.Request(), url, code)
+	return nil
+}
@@ -50,7 +50,7 @@ type resolver struct {
 }
 
 func newResolver(ctx context.Context, discovery registry.Discovery, target *Target, rebalancer selector.Rebalancer, block, insecure bool) (*resolver, error) {
-	watcher, err := discovery.Watch(ctx, target.Endpoint)
+	watcher, err := registry.Resilient(discovery).Watch(ctx, target.Endpoint)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +98,7 @@ func newResolver(ctx context.Context, discovery registry.Discovery, target *Targ
 		for {
 			services, err := watcher.Next()
 			if err != nil {
-				if errors.Is(err, context.Canceled) {
+				if errors.Is(err, context.Canceled) || errors.Is(err, registry.ErrWatcherStopped) {
 					return
 				}
 				r.logger.Errorf("http client watch service %v got unexpected error:=%v", target, err)
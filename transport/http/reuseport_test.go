@@ -0,0 +1,20 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package http
+
+import "testing"
+
+func TestReusePort(t *testing.T) {
+	s1 := NewServer(Address("127.0.0.1:0"), ReusePort(true))
+	e, err := s1.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s1.lis.Close()
+	s2 := NewServer(Address(e.Host), ReusePort(true))
+	if _, err := s2.Endpoint(); err != nil {
+		t.Fatalf("expect the port shared, got %v", err)
+	}
+	_ = s2.lis.Close()
+}
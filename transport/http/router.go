@@ -43,9 +43,12 @@ func (r *Router) Handle(method, relativePath string, h HandlerFunc, filters ...F
 	next := http.Handler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		ctx := r.pool.Get().(Context)
 		ctx.Reset(res, req)
-		if err := h(ctx); err != nil {
+		r.srv.observers.OnRequestStart(ctx)
+		err := h(ctx)
+		if err != nil {
 			r.srv.ene(res, req, err)
 		}
+		r.srv.observers.OnRequestEnd(ctx, err)
 		ctx.Reset(nil, nil)
 		r.pool.Put(ctx)
 	}))
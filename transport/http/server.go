@@ -7,12 +7,16 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/guard"
 	"github.com/go-kratos/kratos/v2/internal/endpoint"
 
 	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/internal/pause"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 
@@ -22,6 +26,7 @@ import (
 var (
 	_ transport.Server     = (*Server)(nil)
 	_ transport.Endpointer = (*Server)(nil)
+	_ guard.Reporter       = (*Server)(nil)
 )
 
 // ServerOption is an HTTP server option.
@@ -83,6 +88,28 @@ func ResponseEncoder(en EncodeResponseFunc) ServerOption {
 	}
 }
 
+// DecodeInterceptors with request decode interceptors, the first one is the outermost.
+func DecodeInterceptors(in ...DecodeInterceptor) ServerOption {
+	return func(o *Server) {
+		o.decInts = in
+	}
+}
+
+// EncodeInterceptors with response encode interceptors, the first one is the outermost.
+func EncodeInterceptors(in ...EncodeInterceptor) ServerOption {
+	return func(o *Server) {
+		o.encInts = in
+	}
+}
+
+// OperationExtractor with the func deriving the operation of the requests,
+// default is TemplateOperation.
+func OperationExtractor(f OperationFunc) ServerOption {
+	return func(o *Server) {
+		o.operation = f
+	}
+}
+
 // ErrorEncoder with error encoder.
 func ErrorEncoder(en EncodeErrorFunc) ServerOption {
 	return func(o *Server) {
@@ -113,6 +140,22 @@ func Listener(lis net.Listener) ServerOption {
 	}
 }
 
+// ReusePort with SO_REUSEPORT set on the listener, so that the new process
+// of a rolling restart binds the address before the old one stops.
+func ReusePort(enable bool) ServerOption {
+	return func(s *Server) {
+		s.reusePort = enable
+	}
+}
+
+// Observer with connection and request observers,
+// request events are emitted for routes registered with Route.
+func Observer(obs ...transport.Observer) ServerOption {
+	return func(s *Server) {
+		s.observers = obs
+	}
+}
+
 // Server is an HTTP server wrapper.
 type Server struct {
 	*http.Server
@@ -122,15 +165,25 @@ type Server struct {
 	err         error
 	network     string
 	address     string
+	reusePort   bool
 	timeout     time.Duration
 	filters     []FilterFunc
 	ms          []middleware.Middleware
 	dec         DecodeRequestFunc
 	enc         EncodeResponseFunc
+	decInts     []DecodeInterceptor
+	encInts     []EncodeInterceptor
+	operation   OperationFunc
 	ene         EncodeErrorFunc
 	strictSlash bool
 	router      *mux.Router
 	log         *log.Helper
+	observers   transport.Observers
+	mounts      mountTable
+	gate        *pause.Gate
+	pausedGauge metrics.Gauge
+	pauses      metrics.Counter
+	renderer    Renderer
 }
 
 // NewServer creates an HTTP server by options.
@@ -142,13 +195,22 @@ func NewServer(opts ...ServerOption) *Server {
 		dec:         DefaultRequestDecoder,
 		enc:         DefaultResponseEncoder,
 		ene:         DefaultErrorEncoder,
+		operation:   TemplateOperation,
 		strictSlash: true,
 		log:         log.NewHelper(log.GetLogger()),
 	}
 	for _, o := range opts {
 		o(srv)
 	}
+	for i := len(srv.decInts) - 1; i >= 0; i-- {
+		srv.dec = srv.decInts[i](srv.dec)
+	}
+	for i := len(srv.encInts) - 1; i >= 0; i-- {
+		srv.enc = srv.encInts[i](srv.enc)
+	}
+	srv.gate = srv.newGate()
 	srv.router = mux.NewRouter().StrictSlash(srv.strictSlash)
+	srv.router.NotFoundHandler = http.HandlerFunc(srv.serveMounted)
 	srv.router.Use(srv.filter())
 	srv.Server = &http.Server{
 		Handler:   FilterChain(srv.filters...)(srv.router),
@@ -183,6 +245,18 @@ func (s *Server) HandleHeader(key, val string, h http.HandlerFunc) {
 	s.router.Headers(key, val).Handler(h)
 }
 
+// Hazards returns the routes under /debug/, e.g. pprof.
+func (s *Server) Hazards() []guard.Hazard {
+	var hazards []guard.Hazard
+	_ = s.router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if tpl, err := route.GetPathTemplate(); err == nil && strings.HasPrefix(tpl, "/debug/") {
+			hazards = append(hazards, guard.Hazard{Kind: guard.DebugEndpoint, Target: tpl})
+		}
+		return nil
+	})
+	return hazards
+}
+
 // ServeHTTP should write reply headers and data to the ResponseWriter and then return.
 func (s *Server) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	s.Handler.ServeHTTP(res, req)
@@ -209,7 +283,7 @@ func (s *Server) filter() mux.MiddlewareFunc {
 			}
 			tr := &Transport{
 				endpoint:     s.endpoint.String(),
-				operation:    pathTemplate,
+				operation:    s.operation(req, pathTemplate),
 				reqHeader:    headerCarrier(req.Header),
 				replyHeader:  headerCarrier(w.Header()),
 				request:      req,
@@ -221,7 +295,8 @@ func (s *Server) filter() mux.MiddlewareFunc {
 	}
 }
 
-// Endpoint return a real address to registry endpoint.
+// Endpoint return a real address to registry endpoint, the listener is bound
+// by NewServer so the real port of ":0" is known before the server starts.
 // examples:
 //   http://127.0.0.1:8000?isSecure=false
 func (s *Server) Endpoint() (*url.URL, error) {
@@ -240,11 +315,15 @@ func (s *Server) Start(ctx context.Context) error {
 		return ctx
 	}
 	s.log.Infof("[HTTP] server listening on: %s", s.lis.Addr().String())
+	lis := s.gate.Listener(s.lis)
+	if len(s.observers) > 0 {
+		lis = &observedListener{Listener: lis, observers: s.observers}
+	}
 	var err error
 	if s.tlsConf != nil {
-		err = s.ServeTLS(s.lis, "", "")
+		err = s.ServeTLS(lis, "", "")
 	} else {
-		err = s.Serve(s.lis)
+		err = s.Serve(lis)
 	}
 	if !errors.Is(err, http.ErrServerClosed) {
 		return err
@@ -260,7 +339,7 @@ func (s *Server) Stop(ctx context.Context) error {
 
 func (s *Server) listenAndEndpoint() error {
 	if s.lis == nil {
-		lis, err := net.Listen(s.network, s.address)
+		lis, err := host.Listen(s.network, s.address, s.reusePort)
 		if err != nil {
 			return err
 		}
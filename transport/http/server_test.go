@@ -5,16 +5,20 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
 
 	"github.com/go-kratos/kratos/v2/internal/host"
 )
@@ -46,7 +50,6 @@ func TestServer(t *testing.T) {
 			panic(err)
 		}
 	}()
-	time.Sleep(time.Second)
 	testHeader(t, srv)
 	testClient(t, srv)
 	if srv.Stop(ctx) != nil {
@@ -252,6 +255,44 @@ func TestResponseEncoder(t *testing.T) {
 	}
 }
 
+func TestCodecInterceptors(t *testing.T) {
+	var calls []string
+	dec := func(name string) DecodeInterceptor {
+		return func(next DecodeRequestFunc) DecodeRequestFunc {
+			return func(r *http.Request, v interface{}) error {
+				calls = append(calls, name)
+				return next(r, v)
+			}
+		}
+	}
+	enc := func(name string) EncodeInterceptor {
+		return func(next EncodeResponseFunc) EncodeResponseFunc {
+			return func(w http.ResponseWriter, r *http.Request, v interface{}) error {
+				calls = append(calls, name)
+				return next(w, r, v)
+			}
+		}
+	}
+	srv := NewServer(
+		RequestDecoder(func(*http.Request, interface{}) error {
+			calls = append(calls, "decode")
+			return nil
+		}),
+		ResponseEncoder(func(http.ResponseWriter, *http.Request, interface{}) error {
+			calls = append(calls, "encode")
+			return nil
+		}),
+		DecodeInterceptors(dec("d1"), dec("d2")),
+		EncodeInterceptors(enc("e1"), enc("e2")),
+	)
+	_ = srv.dec(nil, nil)
+	_ = srv.enc(nil, nil, nil)
+	expect := []string{"d1", "d2", "decode", "e1", "e2", "encode"}
+	if !reflect.DeepEqual(expect, calls) {
+		t.Errorf("expect %v, got %v", expect, calls)
+	}
+}
+
 func TestErrorEncoder(t *testing.T) {
 	o := &Server{}
 	v := func(http.ResponseWriter, *http.Request, error) {}
@@ -278,3 +319,106 @@ func TestListener(t *testing.T) {
 		t.Errorf("expected %v got %v", lis, s.lis)
 	}
 }
+
+type testObserver struct {
+	transport.NopObserver
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *testObserver) add(event string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+}
+
+func (o *testObserver) OnAccept(transport.ConnInfo)    { o.add("accept") }
+func (o *testObserver) OnRequestStart(context.Context) { o.add("start") }
+func (o *testObserver) OnRequestEnd(_ context.Context, e error) {
+	o.add(fmt.Sprintf("end:%v", e != nil))
+}
+func (o *testObserver) OnClose(transport.ConnInfo) { o.add("close") }
+
+func TestObserver(t *testing.T) {
+	o := &testObserver{}
+	srv := NewServer(Observer(o))
+	srv.Route("/").GET("/observer", func(ctx Context) error {
+		return ctx.String(http.StatusOK, "ok")
+	})
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.Get(fmt.Sprintf("http://%s/observer", e.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	_ = srv.Stop(context.Background())
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	expect := []string{"accept", "start", "end:false", "close"}
+	if !reflect.DeepEqual(expect, o.events) {
+		t.Errorf("expect %v, got %v", expect, o.events)
+	}
+}
+
+func TestMount(t *testing.T) {
+	srv := NewServer()
+	go func() {
+		_ = srv.Start(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() { _ = srv.Stop(context.Background()) }()
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func() int {
+		res, err := http.Get(fmt.Sprintf("http://%s/plugin/hello", e.Host))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.Body.Close()
+		return res.StatusCode
+	}
+	if code := get(); code != http.StatusNotFound {
+		t.Fatalf("expect 404 before mount, got %d", code)
+	}
+	srv.Mount("plugin", func(s *Server) {
+		s.Route("/plugin").GET("/hello", func(ctx Context) error {
+			return ctx.String(http.StatusOK, "hello")
+		})
+	})
+	if code := get(); code != http.StatusOK {
+		t.Fatalf("expect 200 after mount, got %d", code)
+	}
+	srv.Unmount("plugin")
+	if code := get(); code != http.StatusNotFound {
+		t.Fatalf("expect 404 after unmount, got %d", code)
+	}
+}
+
+func TestMount_Settings(t *testing.T) {
+	set, err := NewTemplateSet(testTemplateFS(), "pages/*.html", WithLayouts("layouts/*.html"), WithFuncs(template.FuncMap{"upper": strings.ToUpper}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(Templates(set))
+	srv.Mount("plugin", func(s *Server) {
+		s.Route("/plugin").GET("/hello", func(ctx Context) error {
+			return Render(ctx, http.StatusOK, "pages/index.html", "mounted")
+		})
+	})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/plugin/hello", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "<title>kratos</title>Hello MOUNTED" {
+		t.Errorf("expect the mounted routes rendered, got %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+)
+
+// Renderer renders the named templates.
+type Renderer interface {
+	Render(w io.Writer, name string, data interface{}) error
+}
+
+// Templates with the renderer of the HTML templates rendered by Render.
+func Templates(r Renderer) ServerOption {
+	return func(s *Server) {
+		s.renderer = r
+	}
+}
+
+// Render renders the template name of the server with data as an HTML response.
+// The template is rendered before anything is written, so a failing template
+// results in an error response rather than a partial page.
+func Render(ctx Context, code int, name string, data interface{}) error {
+	w, ok := ctx.(*wrapper)
+	if !ok || w.router.srv.renderer == nil {
+		return errors.New("http: no template renderer, see the Templates option")
+	}
+	var buf bytes.Buffer
+	if err := w.router.srv.renderer.Render(&buf, name, data); err != nil {
+		return err
+	}
+	return ctx.Blob(code, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// TemplateOption is template set option.
+type TemplateOption func(*TemplateSet)
+
+// WithLayouts with the glob patterns of the layouts parsed with each page, the
+// first layout matched is the root template, its blocks are defined by the pages:
+//
+//	{{/* layouts/base.html */}}
+//	<html><body>{{block "content" .}}{{end}}</body></html>
+//
+//	{{/* pages/index.html */}}
+//	{{define "content"}}Hello {{.Name}}{{end}}
+func WithLayouts(patterns ...string) TemplateOption {
+	return func(t *TemplateSet) {
+		t.layouts = patterns
+	}
+}
+
+// WithFuncs with the functions of the templates.
+func WithFuncs(funcs template.FuncMap) TemplateOption {
+	return func(t *TemplateSet) {
+		t.funcs = funcs
+	}
+}
+
+// WithReload reparses the templates on each render, e.g. in development with
+// os.DirFS, so the changes are visible without restart.
+func WithReload(reload bool) TemplateOption {
+	return func(t *TemplateSet) {
+		t.reload = reload
+	}
+}
+
+// TemplateSet is a Renderer of the HTML templates of a file system, e.g. an
+// embed.FS, each page is parsed with the layouts and named by its path.
+type TemplateSet struct {
+	fsys    fs.FS
+	pages   string
+	layouts []string
+	funcs   template.FuncMap
+	reload  bool
+
+	mu        sync.RWMutex
+	templates map[string]*template.Template
+}
+
+// NewTemplateSet parses the pages of fsys matching the glob pattern pages, e.g. pages/*.html.
+func NewTemplateSet(fsys fs.FS, pages string, opts ...TemplateOption) (*TemplateSet, error) {
+	t := &TemplateSet{fsys: fsys, pages: pages}
+	for _, o := range opts {
+		o(t)
+	}
+	if err := t.parseAll(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render renders the page name, e.g. pages/index.html, with data.
+func (t *TemplateSet) Render(w io.Writer, name string, data interface{}) error {
+	if t.reload {
+		if ok, _ := path.Match(t.pages, name); !ok {
+			return fmt.Errorf("http: template %q not found", name)
+		}
+		tmpl, err := t.parse(name)
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, data)
+	}
+	t.mu.RLock()
+	tmpl, ok := t.templates[name]
+	t.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("http: template %q not found", name)
+	}
+	return tmpl.Execute(w, data)
+}
+
+func (t *TemplateSet) parseAll() error {
+	pages, err := fs.Glob(t.fsys, t.pages)
+	if err != nil {
+		return err
+	}
+	templates := make(map[string]*template.Template, len(pages))
+	for _, page := range pages {
+		if templates[page], err = t.parse(page); err != nil {
+			return err
+		}
+	}
+	t.mu.Lock()
+	t.templates = templates
+	t.mu.Unlock()
+	return nil
+}
+
+func (t *TemplateSet) parse(page string) (*template.Template, error) {
+	var files []string
+	for _, pattern := range t.layouts {
+		matches, err := fs.Glob(t.fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	files = append(files, page)
+	return template.New(path.Base(files[0])).Funcs(t.funcs).ParseFS(t.fsys, files...)
+}
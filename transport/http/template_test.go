@@ -0,0 +1,89 @@
+package http
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testTemplateFS() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`<title>{{block "title" .}}kratos{{end}}</title>{{block "content" .}}{{end}}`)},
+		"pages/index.html":  {Data: []byte(`{{define "content"}}Hello {{upper .}}{{end}}`)},
+		"pages/about.html":  {Data: []byte(`{{define "title"}}about{{end}}{{define "content"}}<b>{{.}}</b>{{end}}`)},
+	}
+}
+
+func TestTemplateSet(t *testing.T) {
+	fsys := testTemplateFS()
+	opts := []TemplateOption{WithLayouts("layouts/*.html"), WithFuncs(template.FuncMap{"upper": strings.ToUpper})}
+	set, err := NewTemplateSet(fsys, "pages/*.html", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"pages/index.html", "kratos", "<title>kratos</title>Hello KRATOS"},
+		{"pages/about.html", "<go>", "<title>about</title><b>&lt;go&gt;</b>"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := set.Render(&buf, test.name, test.data); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.want {
+			t.Errorf("got %s, want %s", buf.String(), test.want)
+		}
+	}
+	if err := set.Render(&bytes.Buffer{}, "pages/missing.html", nil); err == nil {
+		t.Error("expect a missing template")
+	}
+
+	fsys["pages/index.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}Bye{{end}}`)}
+	var buf bytes.Buffer
+	_ = set.Render(&buf, "pages/index.html", nil)
+	if buf.String() != "<title>kratos</title>Hello " {
+		t.Errorf("expect the templates parsed once, got %s", buf.String())
+	}
+	set, err = NewTemplateSet(fsys, "pages/*.html", append(opts, WithReload(true))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys["pages/new.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}New{{end}}`)}
+	buf.Reset()
+	if err := set.Render(&buf, "pages/new.html", nil); err != nil || buf.String() != "<title>kratos</title>New" {
+		t.Errorf("expect the templates reloaded, got %s %v", buf.String(), err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	set, err := NewTemplateSet(testTemplateFS(), "pages/*.html", WithLayouts("layouts/*.html"), WithFuncs(template.FuncMap{"upper": strings.ToUpper}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(Templates(set))
+	srv.Route("/").GET("/{name}", func(ctx Context) error {
+		return Render(ctx, http.StatusOK, "pages/index.html", ctx.Vars().Get("name"))
+	})
+	srv.Route("/").GET("/broken/page", func(ctx Context) error {
+		return Render(ctx, http.StatusOK, "pages/missing.html", nil)
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/kratos", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "<title>kratos</title>Hello KRATOS" || rec.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("got %d %s %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/broken/page", nil))
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "<title>") {
+		t.Errorf("expect an error response, got %d %s", rec.Code, rec.Body.String())
+	}
+}
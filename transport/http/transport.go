@@ -7,7 +7,10 @@ import (
 	"github.com/go-kratos/kratos/v2/transport"
 )
 
-var _ Transporter = &Transport{}
+var (
+	_ Transporter       = &Transport{}
+	_ transport.Securer = &Transport{}
+)
 
 // Transporter is http Transporter
 type Transporter interface {
@@ -24,6 +27,7 @@ type Transport struct {
 	replyHeader  headerCarrier
 	request      *http.Request
 	pathTemplate string
+	secure       bool
 }
 
 // Kind returns the transport kind.
@@ -61,6 +65,11 @@ func (tr *Transport) PathTemplate() string {
 	return tr.pathTemplate
 }
 
+// Secure reports whether the client request uses transport security.
+func (tr *Transport) Secure() bool {
+	return tr.secure
+}
+
 // SetOperation sets the transport operation.
 func SetOperation(ctx context.Context, op string) {
 	if tr, ok := transport.FromServerContext(ctx); ok {
@@ -82,6 +91,11 @@ func (hc headerCarrier) Set(key string, value string) {
 	http.Header(hc).Set(key, value)
 }
 
+// Del deletes the values associated with key.
+func (hc headerCarrier) Del(key string) {
+	http.Header(hc).Del(key)
+}
+
 // Keys lists the keys stored in this carrier.
 func (hc headerCarrier) Keys() []string {
 	keys := make([]string, 0, len(hc))
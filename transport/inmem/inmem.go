@@ -0,0 +1,54 @@
+// Package inmem implements an in-memory listener, to serve the gRPC and HTTP
+// servers and to dial them without network, e.g. in tests and benchmarks.
+package inmem
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Endpoint is the endpoint of the clients dialing a listener, any endpoint
+// without resolver works as the dialer ignores it.
+const Endpoint = "inmem"
+
+const bufSize = 1 << 20
+
+// Listener is an in-memory net.Listener.
+type Listener struct {
+	*bufconn.Listener
+}
+
+// Listen returns an in-memory listener.
+func Listen() *Listener {
+	return &Listener{Listener: bufconn.Listen(bufSize)}
+}
+
+// Addr returns a loopback TCP address, the servers register TCP endpoints only.
+func (l *Listener) Addr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+}
+
+// Dial dials the listener, the network and the address are ignored.
+func (l *Listener) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	return l.DialContext(ctx)
+}
+
+// DialOption returns the gRPC dial option dialing the listener:
+//
+//	conn, err := grpc.DialInsecure(ctx, grpc.WithEndpoint(inmem.Endpoint), grpc.WithOptions(lis.DialOption()))
+func (l *Listener) DialOption() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return l.DialContext(ctx)
+	})
+}
+
+// Transport returns the HTTP transport dialing the listener:
+//
+//	client, err := http.NewClient(ctx, http.WithEndpoint(inmem.Endpoint), http.WithTransport(lis.Transport()))
+func (l *Listener) Transport() *http.Transport {
+	return &http.Transport{DialContext: l.Dial}
+}
@@ -0,0 +1,63 @@
+package inmem
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/go-kratos/kratos/v2/internal/testdata/helloworld"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type greeter struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (greeter) SayHello(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	return &pb.HelloReply{Message: "Hello " + in.Name}, nil
+}
+
+func TestGRPC(t *testing.T) {
+	lis := Listen()
+	srv := grpc.NewServer(grpc.Listener(lis))
+	pb.RegisterGreeterServer(srv, greeter{})
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+
+	conn, err := grpc.DialInsecure(context.Background(), grpc.WithEndpoint(Endpoint), grpc.WithOptions(lis.DialOption()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	reply, err := pb.NewGreeterClient(conn).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "Hello kratos" {
+		t.Errorf("got %q, want %q", reply.Message, "Hello kratos")
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	lis := Listen()
+	srv := http.NewServer(http.Listener(lis))
+	pb.RegisterGreeterHTTPServer(srv, greeter{})
+	go func() { _ = srv.Start(context.Background()) }()
+	defer func() { _ = srv.Stop(context.Background()) }()
+
+	client, err := http.NewClient(context.Background(), http.WithEndpoint(Endpoint), http.WithTransport(lis.Transport()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	reply, err := pb.NewGreeterHTTPClient(client).SayHello(context.Background(), &pb.HelloRequest{Name: "kratos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Message != "Hello kratos" {
+		t.Errorf("got %q, want %q", reply.Message, "Hello kratos")
+	}
+}
@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"context"
+	"net"
+)
+
+// ConnInfo describes a transport connection.
+type ConnInfo struct {
+	Kind       Kind
+	LocalAddr  net.Addr
+	RemoteAddr net.Addr
+}
+
+// Observer receives connection and request events of a transport server,
+// implementations must be safe for concurrent use and should not block.
+type Observer interface {
+	// OnAccept is called when a connection is accepted.
+	OnAccept(info ConnInfo)
+	// OnRequestStart is called before a request is handled,
+	// ctx carries the server Transporter.
+	OnRequestStart(ctx context.Context)
+	// OnRequestEnd is called after a request is handled.
+	OnRequestEnd(ctx context.Context, err error)
+	// OnClose is called when a connection is closed.
+	OnClose(info ConnInfo)
+}
+
+// NopObserver is an Observer ignoring all events,
+// embed it to implement only some of the events.
+type NopObserver struct{}
+
+// OnAccept implements Observer.
+func (NopObserver) OnAccept(ConnInfo) {}
+
+// OnRequestStart implements Observer.
+func (NopObserver) OnRequestStart(context.Context) {}
+
+// OnRequestEnd implements Observer.
+func (NopObserver) OnRequestEnd(context.Context, error) {}
+
+// OnClose implements Observer.
+func (NopObserver) OnClose(ConnInfo) {}
+
+// Observers is an Observer dispatching events to each of its observers.
+type Observers []Observer
+
+// OnAccept implements Observer.
+func (os Observers) OnAccept(info ConnInfo) {
+	for _, o := range os {
+		o.OnAccept(info)
+	}
+}
+
+// OnRequestStart implements Observer.
+func (os Observers) OnRequestStart(ctx context.Context) {
+	for _, o := range os {
+		o.OnRequestStart(ctx)
+	}
+}
+
+// OnRequestEnd implements Observer.
+func (os Observers) OnRequestEnd(ctx context.Context, err error) {
+	for _, o := range os {
+		o.OnRequestEnd(ctx, err)
+	}
+}
+
+// OnClose implements Observer.
+func (os Observers) OnClose(info ConnInfo) {
+	for _, o := range os {
+		o.OnClose(info)
+	}
+}
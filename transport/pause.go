@@ -0,0 +1,36 @@
+package transport
+
+// Pauser is a server which can pause accepting new connections,
+// e.g. for maintenance or backpressure, while serving the existing ones.
+type Pauser interface {
+	Pause()
+	Resume()
+	Paused() bool
+}
+
+// PauseObserver is implemented by the Observers interested in the
+// accept pause events of a Pauser.
+type PauseObserver interface {
+	// OnPause is called when the server pauses accepting new connections.
+	OnPause(kind Kind)
+	// OnResume is called when the server resumes accepting new connections.
+	OnResume(kind Kind)
+}
+
+// OnPause dispatches the event to the observers implementing PauseObserver.
+func (os Observers) OnPause(kind Kind) {
+	for _, o := range os {
+		if po, ok := o.(PauseObserver); ok {
+			po.OnPause(kind)
+		}
+	}
+}
+
+// OnResume dispatches the event to the observers implementing PauseObserver.
+func (os Observers) OnResume(kind Kind) {
+	for _, o := range os {
+		if po, ok := o.(PauseObserver); ok {
+			po.OnResume(kind)
+		}
+	}
+}
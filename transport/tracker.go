@@ -0,0 +1,250 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/guard"
+)
+
+// ClientInfo is the info of an open client.
+type ClientInfo struct {
+	Kind   Kind
+	Target string
+	// Insecure reports whether the client dials without TLS.
+	Insecure bool
+	Created  time.Time
+}
+
+// ErrClientNotRegistered is returned for a named client not registered.
+var ErrClientNotRegistered = errors.New("transport: client not registered")
+
+// ClientConfig is the governance config of a named client, set in a single
+// place by Configure, e.g. from the config file, apart from the modules using
+// the client, and applied by its dialer.
+type ClientConfig struct {
+	// Endpoint is the target of the client, e.g. discovery:///user.
+	Endpoint string
+	// Timeout is the timeout of the calls.
+	Timeout time.Duration
+	// Insecure reports whether the client dials without TLS.
+	Insecure bool
+}
+
+// Dialer dials a named client with its config, it should track the client,
+// e.g. with the WithTracker option of the transports, so that the client
+// closed elsewhere is dialed again.
+type Dialer func(ctx context.Context, c ClientConfig) (io.Closer, error)
+
+type namedClient struct {
+	mu     sync.Mutex
+	dial   Dialer
+	closer io.Closer
+	// id is the id of the client tracked.
+	id uint64
+}
+
+type trackedClient struct {
+	info   ClientInfo
+	closer io.Closer
+}
+
+// Tracker tracks the open clients so that they're closed on shutdown, e.g.
+// by the app after the servers stop, and the leaked ones can be enumerated.
+type Tracker struct {
+	mu      sync.Mutex
+	next    uint64
+	clients map[uint64]trackedClient
+	named   map[string]*namedClient
+	configs map[string]ClientConfig
+}
+
+// NewTracker new a client tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		clients: make(map[uint64]trackedClient),
+		named:   make(map[string]*namedClient),
+		configs: make(map[string]ClientConfig),
+	}
+}
+
+// Configure sets the governance config of the named clients, applied when
+// they are dialed.
+func (t *Tracker) Configure(configs map[string]ClientConfig) {
+	t.mu.Lock()
+	for name, c := range configs {
+		t.configs[name] = c
+	}
+	t.mu.Unlock()
+}
+
+// Register registers the client name dialed on first use, so the modules
+// share one client per target, of the config set by Configure:
+//
+//	tracker.Register("user", func(ctx context.Context, c transport.ClientConfig) (io.Closer, error) {
+//		return grpc.Dial(ctx, grpc.WithEndpoint(c.Endpoint), grpc.WithTimeout(c.Timeout), grpc.WithDiscovery(r), grpc.WithTracker(tracker))
+//	})
+func (t *Tracker) Register(name string, dial Dialer) {
+	t.mu.Lock()
+	t.named[name] = &namedClient{dial: dial}
+	t.mu.Unlock()
+}
+
+// Client returns the client name, dialed once and shared by the callers, and
+// dialed again once closed. A failed dial is retried by the next call. The
+// client is owned by the tracker, which closes it on Close, the callers must
+// not close it.
+func (t *Tracker) Client(ctx context.Context, name string) (io.Closer, error) {
+	t.mu.Lock()
+	n, ok := t.named[name]
+	config := t.configs[name]
+	t.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrClientNotRegistered, name)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closer != nil && t.open(n.id) {
+		return n.closer, nil
+	}
+	c, err := n.dial(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	id, ok := t.lookup(c)
+	if !ok {
+		// the client not tracked by its dialer is tracked by its name
+		target := config.Endpoint
+		if target == "" {
+			target = name
+		}
+		t.Track(ClientInfo{Target: target, Insecure: config.Insecure}, c)
+		id, _ = t.lookup(c)
+	}
+	n.closer, n.id = c, id
+	return c, nil
+}
+
+// open reports whether the client id is tracked and not closed.
+func (t *Tracker) open(id uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.clients[id]
+	return ok && !closed(c.closer)
+}
+
+// lookup returns the id of the client tracked as c, or as a wrapper of c.
+func (t *Tracker) lookup(c io.Closer) (uint64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, tc := range t.clients {
+		if same(tc.closer, c) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// Track tracks the client c until untrack is called, e.g. by its Close, or the
+// tracker closes it, the creation time of info defaults to now. If c
+// implements Closed() bool, it's untracked once closed elsewhere, at the
+// latest by the next Track.
+func (t *Tracker) Track(info ClientInfo, c io.Closer) (untrack func()) {
+	if info.Created.IsZero() {
+		info.Created = time.Now()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, tc := range t.clients {
+		if closed(tc.closer) {
+			delete(t.clients, id)
+		}
+	}
+	id := t.next
+	t.next++
+	t.clients[id] = trackedClient{info: info, closer: c}
+	return func() {
+		t.mu.Lock()
+		delete(t.clients, id)
+		t.mu.Unlock()
+	}
+}
+
+// Clients returns the open clients in creation order.
+func (t *Tracker) Clients() []ClientInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ids := make([]uint64, 0, len(t.clients))
+	for id, c := range t.clients {
+		if closed(c.closer) {
+			delete(t.clients, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	infos := make([]ClientInfo, 0, len(ids))
+	for _, id := range ids {
+		infos = append(infos, t.clients[id].info)
+	}
+	return infos
+}
+
+// Close closes the open clients, including the named ones dialed again by
+// their next use, it returns the last error.
+func (t *Tracker) Close() error {
+	t.mu.Lock()
+	clients := t.clients
+	t.clients = make(map[uint64]trackedClient)
+	named := make([]*namedClient, 0, len(t.named))
+	for _, n := range t.named {
+		named = append(named, n)
+	}
+	t.mu.Unlock()
+	for _, n := range named {
+		n.mu.Lock()
+		n.closer = nil
+		n.mu.Unlock()
+	}
+	var err error
+	for _, c := range clients {
+		if closed(c.closer) {
+			continue
+		}
+		if e := c.closer.Close(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Hazards returns the open clients dialing without TLS.
+func (t *Tracker) Hazards() []guard.Hazard {
+	var hazards []guard.Hazard
+	for _, c := range t.Clients() {
+		if c.Insecure {
+			hazards = append(hazards, guard.Hazard{Kind: guard.InsecureDial, Target: c.Target})
+		}
+	}
+	return hazards
+}
+
+func closed(c io.Closer) bool {
+	v, ok := c.(interface{ Closed() bool })
+	return ok && v.Closed()
+}
+
+// same reports whether the tracked client is c, or wraps c with Unwrap.
+func same(tracked, c io.Closer) bool {
+	if reflect.TypeOf(tracked) == reflect.TypeOf(c) && reflect.TypeOf(c).Comparable() && tracked == c {
+		return true
+	}
+	u, ok := tracked.(interface{ Unwrap() io.Closer })
+	return ok && same(u.Unwrap(), c)
+}
@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"testing"
+)
+
+type testCloser struct {
+	closed bool
+}
+
+func (c *testCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *testCloser) Closed() bool {
+	return c.closed
+}
+
+func TestTracker_Track(t *testing.T) {
+	tracker := NewTracker()
+	for i := 0; i < 10; i++ {
+		c := &testCloser{}
+		tracker.Track(ClientInfo{Target: "test"}, c)
+		_ = c.Close()
+	}
+	tracker.Track(ClientInfo{Target: "test"}, &testCloser{})
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if n := len(tracker.clients); n != 1 {
+		t.Errorf("expect the closed clients untracked, got %d clients", n)
+	}
+}
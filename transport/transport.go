@@ -54,6 +54,12 @@ type Transporter interface {
 	ReplyHeader() Header
 }
 
+// Securer is implemented by the client transports to report whether
+// the requests are sent over a secure connection, e.g. TLS.
+type Securer interface {
+	Secure() bool
+}
+
 // Kind defines the type of Transport
 type Kind string
 
@@ -0,0 +1,204 @@
+// Package transporttest provides the conformance suite of the transports, run
+// by the tests of each transport with a Harness adapting it:
+//
+//	func TestConformance(t *testing.T) {
+//		transporttest.Conformance(t, transporttest.Harness{
+//			NewServer: func(h middleware.Handler, m ...middleware.Middleware) (transport.Server, error) { ... },
+//			Call:      func(ctx context.Context, endpoint *url.URL, req string, m ...middleware.Middleware) (string, transport.Header, error) { ... },
+//		})
+//	}
+package transporttest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	// HeaderKey is the header key set by the suite.
+	HeaderKey = "x-transporttest"
+	// Reason is the reason of the errors returned by the suite.
+	Reason = "TRANSPORTTEST"
+)
+
+// Timeout is the time the servers are expected to start and stop within.
+var Timeout = 10 * time.Second
+
+// Harness adapts a transport to the conformance suite.
+type Harness struct {
+	// NewServer new a server with the middlewares serving h, which is called
+	// with the string request of Call and returns the string reply.
+	NewServer func(h middleware.Handler, m ...middleware.Middleware) (transport.Server, error)
+	// Call calls the server at endpoint with the request through a client with
+	// the middlewares, returning the reply and its header.
+	Call func(ctx context.Context, endpoint *url.URL, req string, m ...middleware.Middleware) (string, transport.Header, error)
+}
+
+// Conformance runs the conformance suite of the transport of h:
+//
+//   - Lifecycle: the endpoint of the server is known before Start, which
+//     returns nil once Stop is called, and the calls then fail.
+//   - Middleware: the client then the server middlewares are called in order.
+//   - Header: the request header set by the client middlewares is received by
+//     the server, the reply header set by the server is received by the client,
+//     and the transports of both sides have a kind and an operation.
+//   - Error: the errors of the handler are received with their code and reason.
+func Conformance(t *testing.T, h Harness) {
+	t.Run("Lifecycle", func(t *testing.T) { testLifecycle(t, h) })
+	t.Run("Middleware", func(t *testing.T) { testMiddleware(t, h) })
+	t.Run("Header", func(t *testing.T) { testHeader(t, h) })
+	t.Run("Error", func(t *testing.T) { testError(t, h) })
+}
+
+func echo(_ context.Context, req interface{}) (interface{}, error) {
+	return req, nil
+}
+
+// serve new and starts a server, returning its endpoint and the stop of it.
+func serve(t *testing.T, h Harness, handler middleware.Handler, m ...middleware.Middleware) (*url.URL, func() error) {
+	t.Helper()
+	srv, err := h.NewServer(handler, m...)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	e, ok := srv.(transport.Endpointer)
+	if !ok {
+		t.Fatalf("NewServer: expect a transport.Endpointer, got %T", srv)
+	}
+	endpoint, err := e.Endpoint()
+	if err != nil {
+		t.Fatalf("Endpoint: %v", err)
+	}
+	if endpoint == nil || strings.HasSuffix(endpoint.Host, ":0") {
+		t.Fatalf("Endpoint: expect the real address before Start, got %v", endpoint)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(context.Background())
+	}()
+	var once sync.Once
+	var stopErr error
+	stop := func() error {
+		once.Do(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+			defer cancel()
+			if stopErr = srv.Stop(ctx); stopErr != nil {
+				return
+			}
+			select {
+			case stopErr = <-done:
+			case <-time.After(Timeout):
+				stopErr = fmt.Errorf("expect Start to return once stopped")
+			}
+		})
+		return stopErr
+	}
+	t.Cleanup(func() { _ = stop() })
+	return endpoint, stop
+}
+
+func testLifecycle(t *testing.T, h Harness) {
+	endpoint, stop := serve(t, h, echo)
+	reply, _, err := h.Call(context.Background(), endpoint, "hello")
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if reply != "hello" {
+		t.Fatalf("Call: expect the reply hello, got %q", reply)
+	}
+	if err = stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, _, err = h.Call(ctx, endpoint, "hello"); err == nil {
+		t.Fatal("Call: expect an error once stopped")
+	}
+}
+
+func testMiddleware(t *testing.T, h Harness) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	record := func(name string) middleware.Middleware {
+		return func(next middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				mu.Lock()
+				calls = append(calls, name)
+				mu.Unlock()
+				return next(ctx, req)
+			}
+		}
+	}
+	endpoint, _ := serve(t, h, record("handler")(echo), record("server1"), record("server2"))
+	if _, _, err := h.Call(context.Background(), endpoint, "hello", record("client1"), record("client2")); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := "client1 client2 server1 server2 handler"; strings.Join(calls, " ") != want {
+		t.Fatalf("expect the middlewares called in order %s, got %v", want, calls)
+	}
+}
+
+func testHeader(t *testing.T, h Harness) {
+	server := func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, errors.InternalServer(Reason, "no server transport")
+			}
+			if tr.Kind() == "" || tr.Operation() == "" {
+				return nil, errors.InternalServer(Reason, fmt.Sprintf("expect the kind and the operation, got %q %q", tr.Kind(), tr.Operation()))
+			}
+			if v := tr.RequestHeader().Get(HeaderKey); v != "request" {
+				return nil, errors.BadRequest(Reason, fmt.Sprintf("expect the request header, got %q", v))
+			}
+			tr.ReplyHeader().Set(HeaderKey, "reply")
+			return next(ctx, req)
+		}
+	}
+	client := func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromClientContext(ctx)
+			if !ok {
+				return nil, fmt.Errorf("no client transport")
+			}
+			if tr.Kind() == "" || tr.Operation() == "" {
+				return nil, fmt.Errorf("expect the kind and the operation, got %q %q", tr.Kind(), tr.Operation())
+			}
+			tr.RequestHeader().Set(HeaderKey, "request")
+			return next(ctx, req)
+		}
+	}
+	endpoint, _ := serve(t, h, echo, server)
+	_, header, err := h.Call(context.Background(), endpoint, "hello", client)
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if header == nil || header.Get(HeaderKey) != "reply" {
+		t.Fatalf("Call: expect the reply header %s", HeaderKey)
+	}
+}
+
+func testError(t *testing.T, h Harness) {
+	handler := func(_ context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.NotFound(Reason, fmt.Sprint(req)).WithMetadata(map[string]string{"key": "value"})
+	}
+	endpoint, _ := serve(t, h, handler)
+	_, _, err := h.Call(context.Background(), endpoint, "not found")
+	e := errors.FromError(err)
+	if e == nil || e.Code != 404 || e.Reason != Reason || e.Message != "not found" || e.Metadata["key"] != "value" {
+		t.Fatalf("Call: expect the not found error of reason %s, got %v", Reason, err)
+	}
+}